@@ -8,6 +8,8 @@ import (
 
 	"github.com/journal/internal/db"
 	"github.com/journal/internal/evaluation"
+	"github.com/journal/internal/ollama"
+	"github.com/journal/internal/service"
 )
 
 func main() {
@@ -17,11 +19,13 @@ func main() {
 		dbUser      = flag.String("user", os.Getenv("USER"), "PostgreSQL user")
 		dbPassword  = flag.String("password", "", "PostgreSQL password")
 		dbName      = flag.String("dbname", "journal_db", "PostgreSQL database name")
-		command     = flag.String("cmd", "", "Command to run: generate, evaluate, report")
+		command     = flag.String("cmd", "", "Command to run: generate, evaluate, report, cleanup")
 		outputDir   = flag.String("output", "evaluation_results", "Output directory for results")
 		testSetSize = flag.Int("size", 100, "Number of test entries to generate")
 		searchMode  = flag.String("mode", "all", "Search mode to evaluate: classic, vector, hybrid, all")
 		format      = flag.String("format", "html", "Report format: html, json, csv")
+		dryRun      = flag.Bool("dry-run", false, "Generate test files without inserting entries into the database")
+		label       = flag.String("label", "", "Run label recorded in metrics and file names (evaluate, report)")
 	)
 	flag.Parse()
 
@@ -40,7 +44,7 @@ func main() {
 	}
 
 	if *command == "" {
-		log.Fatal("Command is required. Use -cmd flag with: generate, evaluate, or report")
+		log.Fatal("Command is required. Use -cmd flag with: generate, evaluate, report, or cleanup")
 	}
 
 	// Connect to database
@@ -50,20 +54,26 @@ func main() {
 	}
 	defer database.Close()
 
+	// Searches run through the real journal service; vector and hybrid modes
+	// need Ollama for query embeddings
+	ollamaURL := os.Getenv("OLLAMA_URL")
+	processor := ollama.NewProcessor(ollama.NewClient(ollamaURL))
+	journalService := service.NewJournalService(database, processor, nil, nil, nil)
+
 	// Create evaluator
-	evaluator := evaluation.NewEvaluator(database, *outputDir)
+	evaluator := evaluation.NewEvaluator(database, *outputDir, journalService)
 
 	switch *command {
 	case "generate":
 		log.Printf("Generating %d test entries...", *testSetSize)
-		if err := evaluator.GenerateTestData(*testSetSize); err != nil {
+		if err := evaluator.GenerateTestData(*testSetSize, *dryRun); err != nil {
 			log.Fatalf("Failed to generate test data: %v", err)
 		}
 		log.Println("Test data generation complete")
 
 	case "evaluate":
 		log.Printf("Evaluating search mode: %s", *searchMode)
-		results, err := evaluator.RunEvaluation(*searchMode)
+		results, err := evaluator.RunEvaluation(*searchMode, *label)
 		if err != nil {
 			log.Fatalf("Failed to run evaluation: %v", err)
 		}
@@ -81,13 +91,20 @@ func main() {
 
 	case "report":
 		log.Printf("Generating %s report...", *format)
-		reportPath, err := evaluator.GenerateReport(*format)
+		reportPath, err := evaluator.GenerateReport(*format, *label)
 		if err != nil {
 			log.Fatalf("Failed to generate report: %v", err)
 		}
 		log.Printf("Report generated: %s", reportPath)
 
+	case "cleanup":
+		deleted, err := evaluator.CleanupTestData()
+		if err != nil {
+			log.Fatalf("Failed to clean up test data: %v", err)
+		}
+		log.Printf("Deleted %d generated test entries", deleted)
+
 	default:
-		log.Fatalf("Unknown command: %s. Use generate, evaluate, or report", *command)
+		log.Fatalf("Unknown command: %s. Use generate, evaluate, report, or cleanup", *command)
 	}
 }
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/journal/internal/db"
+	"github.com/journal/internal/service"
+)
+
+func main() {
+	var command string
+	flag.StringVar(&command, "cmd", "", "Maintenance task to run: clean-orphans")
+	flag.Parse()
+
+	if command != "clean-orphans" {
+		log.Fatal("Invalid command. Use -cmd clean-orphans")
+	}
+
+	// Database configuration
+	dbHost := getEnv("DB_HOST", "localhost")
+	dbPort := getEnv("DB_PORT", "5432")
+	dbUser := getEnv("DB_USER", "postgres")
+	dbPassword := getEnv("DB_PASSWORD", "")
+	dbName := getEnv("DB_NAME", "journal_db")
+
+	// Connect to database
+	database, err := db.NewConnection(dbHost, dbPort, dbUser, dbPassword, dbName)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer database.Close()
+
+	// Maintenance tasks only touch the database, so no AI or fetch clients
+	journalService := service.NewJournalService(database, nil, nil, nil, nil)
+
+	cleaned, err := journalService.CleanOrphanedAssociations()
+	if err != nil {
+		log.Fatalf("Failed to clean orphaned associations: %v", err)
+	}
+	log.Printf("Cleaned %d orphaned collection associations", cleaned)
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -1,26 +1,36 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
 	"runtime/debug"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"github.com/journal/internal/auth"
 	"github.com/journal/internal/db"
 	"github.com/journal/internal/events"
 	"github.com/journal/internal/handlers"
 	"github.com/journal/internal/jsonrpc"
 	"github.com/journal/internal/logger"
 	"github.com/journal/internal/mcp"
+	"github.com/journal/internal/middleware"
 	"github.com/journal/internal/ollama"
 	"github.com/journal/internal/service"
+	"github.com/journal/internal/webhooks"
 )
 
+// maxImportSize caps uploads to /api/import
+const maxImportSize = 32 << 20
+
 func main() {
 	// Set up panic recovery
 	defer func() {
@@ -30,6 +40,11 @@ func main() {
 		}
 	}()
 
+	// LOG_FORMAT=json switches server logs to structured JSON lines
+	if err := logger.SetupServerLogging(getEnv("LOG_FORMAT", "text"), os.Stderr); err != nil {
+		log.Fatalf("Invalid LOG_FORMAT: %v", err)
+	}
+
 	// Database configuration
 	dbHost := getEnv("DB_HOST", "localhost")
 	dbPort := getEnv("DB_PORT", "5432")
@@ -50,25 +65,255 @@ func main() {
 	// Initialize Ollama client
 	ollamaURL := getEnv("OLLAMA_URL", "http://localhost:11434")
 	ollamaClient := ollama.NewClient(ollamaURL)
+	if timeout := getEnv("OLLAMA_TIMEOUT", ""); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			log.Fatalf("Invalid OLLAMA_TIMEOUT: %v", err)
+		}
+		ollamaClient.WithTimeout(d)
+	}
+	if timeout := getEnv("OLLAMA_EMBED_TIMEOUT", ""); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			log.Fatalf("Invalid OLLAMA_EMBED_TIMEOUT: %v", err)
+		}
+		ollamaClient.WithEmbeddingTimeout(d)
+	}
+
+	// Load the models in the background so the first entry does not pay the
+	// cold-start cost; Ollama being down at boot is not fatal
+	if getEnv("OLLAMA_WARMUP", "true") == "true" {
+		warmupTimeout := ollama.DefaultWarmupTimeout
+		if timeout := getEnv("OLLAMA_WARMUP_TIMEOUT", ""); timeout != "" {
+			d, err := time.ParseDuration(timeout)
+			if err != nil {
+				log.Fatalf("Invalid OLLAMA_WARMUP_TIMEOUT: %v", err)
+			}
+			warmupTimeout = d
+		}
+		go func() {
+			start := time.Now()
+			if err := ollamaClient.Warmup(warmupTimeout); err != nil {
+				log.Printf("Ollama warmup failed, models will load on first use: %v", err)
+				return
+			}
+			log.Printf("Ollama models %s and %s ready after %s", ollama.ChatModel, ollama.EmbeddingModel, time.Since(start).Round(time.Millisecond))
+		}()
+	}
 	processor := ollama.NewProcessor(ollamaClient)
+	if maxChars := getEnv("OLLAMA_MAX_INPUT_CHARS", ""); maxChars != "" {
+		n, err := strconv.Atoi(maxChars)
+		if err != nil {
+			log.Fatalf("Invalid OLLAMA_MAX_INPUT_CHARS: %v", err)
+		}
+		processor.WithMaxInputLength(n)
+	}
+	if temp := getEnv("OLLAMA_TEMPERATURE", ""); temp != "" {
+		t, err := strconv.ParseFloat(temp, 32)
+		if err != nil {
+			log.Fatalf("Invalid OLLAMA_TEMPERATURE: %v", err)
+		}
+		if _, err := processor.WithTemperature(float32(t)); err != nil {
+			log.Fatalf("Invalid OLLAMA_TEMPERATURE: %v", err)
+		}
+	}
+	if path := getEnv("OLLAMA_PROMPT_TEMPLATE_FILE", ""); path != "" {
+		template, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read OLLAMA_PROMPT_TEMPLATE_FILE: %v", err)
+		}
+		if _, err := processor.WithPromptTemplate(string(template)); err != nil {
+			log.Fatalf("Invalid prompt template %s: %v", path, err)
+		}
+		log.Printf("Using custom analysis prompt from %s", path)
+	}
+	if path := getEnv("OLLAMA_PROMPT_EXAMPLES_FILE", ""); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read OLLAMA_PROMPT_EXAMPLES_FILE: %v", err)
+		}
+		examples, err := ollama.ParsePromptExamples(data)
+		if err != nil {
+			log.Fatalf("Invalid prompt examples %s: %v", path, err)
+		}
+		if _, err := processor.WithPromptExamples(examples); err != nil {
+			log.Fatalf("Invalid prompt examples %s: %v", path, err)
+		}
+		log.Printf("Using %d analysis prompt examples from %s", len(examples), path)
+	}
+
+	if spec := getEnv("OLLAMA_EMBED_FIELDS", ""); spec != "" {
+		fields, err := ollama.ParseEmbeddingFields(spec)
+		if err != nil {
+			log.Fatalf("Invalid OLLAMA_EMBED_FIELDS: %v", err)
+		}
+		processor.WithEmbeddingFields(fields)
+		log.Printf("Embedding entry content with fields: %s", spec)
+	}
 
 	// Initialize MCP client
 	mcpURL := getEnv("MCP_AGENT_URL", "http://localhost:8081")
-	mcpClient := mcp.NewClient(mcpURL)
+	mcpRetries, err := strconv.Atoi(getEnv("MCP_MAX_RETRIES", "2"))
+	if err != nil {
+		log.Fatalf("Invalid MCP_MAX_RETRIES: %v", err)
+	}
+	mcpClient := mcp.NewClient(mcpURL, mcpRetries)
+
+	// SSE heartbeat interval, and how long one stream may stay open (0 keeps
+	// streams open until the client disconnects)
+	sseHeartbeat, err := time.ParseDuration(getEnv("SSE_HEARTBEAT_INTERVAL", "30s"))
+	if err != nil || sseHeartbeat <= 0 {
+		log.Fatalf("Invalid SSE_HEARTBEAT_INTERVAL: %q", getEnv("SSE_HEARTBEAT_INTERVAL", "30s"))
+	}
+	sseMaxDuration, err := time.ParseDuration(getEnv("SSE_MAX_DURATION", "1h"))
+	if err != nil {
+		log.Fatalf("Invalid SSE_MAX_DURATION: %v", err)
+	}
+
+	// Event buffer depths; events beyond them are dropped and counted in
+	// system.info
+	sseClientBuffer, err := strconv.Atoi(getEnv("SSE_CLIENT_BUFFER", strconv.Itoa(events.DefaultClientBuffer)))
+	if err != nil {
+		log.Fatalf("Invalid SSE_CLIENT_BUFFER: %v", err)
+	}
+	sseBroadcastBuffer, err := strconv.Atoi(getEnv("SSE_BROADCAST_BUFFER", strconv.Itoa(events.DefaultBroadcastBuffer)))
+	if err != nil {
+		log.Fatalf("Invalid SSE_BROADCAST_BUFFER: %v", err)
+	}
 
 	// Initialize event broadcaster
-	broadcaster := events.NewBroadcaster()
+	broadcaster := events.NewBroadcaster().WithBuffers(sseClientBuffer, sseBroadcastBuffer)
 	broadcaster.Start()
 
+	// Optional outgoing webhook for entry processed/failed events
+	if webhookURL := getEnv("WEBHOOK_URL", ""); webhookURL != "" {
+		broadcaster.AddHook(webhooks.NewNotifier(webhookURL, getEnv("WEBHOOK_SECRET", "")).HandleEvent)
+		log.Printf("Webhook notifications enabled: %s", webhookURL)
+	}
+
 	// Initialize processing logger
 	processingLogger := logger.NewProcessingLogger(database.DB)
 
+	// Optional live streaming of processing logs as entry.log SSE events. The
+	// logger cannot tell which user owns an entry, so this stays off when
+	// multi-tenancy is enabled.
+	if getEnv("LIVE_LOGS", "false") == "true" {
+		if getEnv("MULTI_TENANT", "false") == "true" {
+			log.Println("LIVE_LOGS ignored: live log streaming is not supported in multi-tenant mode")
+		} else if err := processingLogger.EnableLiveLogs(broadcaster, getEnv("LIVE_LOGS_LEVEL", "info"), 250*time.Millisecond); err != nil {
+			log.Fatalf("Invalid LIVE_LOGS_LEVEL: %v", err)
+		} else {
+			log.Println("Live processing log streaming enabled")
+		}
+	}
+
+	// Optional processing log retention; logs of failed entries are always kept
+	if days, err := strconv.Atoi(getEnv("LOG_RETENTION_DAYS", "0")); err == nil && days > 0 {
+		processingLogger.StartRetentionCleaner(time.Duration(days)*24*time.Hour, time.Hour)
+		log.Printf("Processing log retention enabled: %d days", days)
+	}
+
+	// Multi-tenancy: each API key maps to a user and all queries are scoped to it
+	multiTenant := getEnv("MULTI_TENANT", "false") == "true"
+	keyStore := auth.ParseKeyStore(getEnv("API_KEYS", ""))
+	if multiTenant {
+		if keyStore.Len() == 0 {
+			log.Fatalf("MULTI_TENANT is enabled but no API_KEYS are configured")
+		}
+		log.Printf("Multi-tenant mode enabled with %d API keys", keyStore.Len())
+	}
+
+	// Fetched URLs are stored in the database and reused for this long
+	fetchCacheTTL, err := time.ParseDuration(getEnv("FETCH_CACHE_TTL", "24h"))
+	if err != nil {
+		log.Fatalf("Invalid FETCH_CACHE_TTL: %v", err)
+	}
+
+	maxContentBytes, err := strconv.Atoi(getEnv("MAX_ENTRY_BYTES", strconv.Itoa(service.DefaultMaxContentBytes)))
+	if err != nil {
+		log.Fatalf("Invalid MAX_ENTRY_BYTES: %v", err)
+	}
+
+	vectorProbes, err := strconv.Atoi(getEnv("IVFFLAT_PROBES", "0"))
+	if err != nil || vectorProbes < 0 {
+		log.Fatalf("Invalid IVFFLAT_PROBES: %q", getEnv("IVFFLAT_PROBES", "0"))
+	}
+
+	maxFetchURLs, err := strconv.Atoi(getEnv("MAX_FETCH_URLS", strconv.Itoa(service.DefaultMaxFetchURLs)))
+	if err != nil || maxFetchURLs <= 0 {
+		log.Fatalf("Invalid MAX_FETCH_URLS: %q", getEnv("MAX_FETCH_URLS", ""))
+	}
+
+	maxResultLimit, err := strconv.Atoi(getEnv("MAX_RESULT_LIMIT", strconv.Itoa(service.DefaultMaxResultLimit)))
+	if err != nil || maxResultLimit <= 0 {
+		log.Fatalf("Invalid MAX_RESULT_LIMIT: %q", getEnv("MAX_RESULT_LIMIT", ""))
+	}
+
+	deleteGracePeriod, err := time.ParseDuration(getEnv("DELETE_GRACE_PERIOD", service.DefaultDeleteGracePeriod.String()))
+	if err != nil || deleteGracePeriod <= 0 {
+		log.Fatalf("Invalid DELETE_GRACE_PERIOD: %q", getEnv("DELETE_GRACE_PERIOD", ""))
+	}
+
+	processingTimeout, err := time.ParseDuration(getEnv("PROCESSING_TIMEOUT", service.DefaultProcessingTimeout.String()))
+	if err != nil || processingTimeout <= 0 {
+		log.Fatalf("Invalid PROCESSING_TIMEOUT: %q", getEnv("PROCESSING_TIMEOUT", ""))
+	}
+
+	similarityMetric, err := service.ParseSimilarityMetric(getEnv("SIMILARITY_METRIC", string(service.MetricCosine)))
+	if err != nil {
+		log.Fatalf("Invalid SIMILARITY_METRIC: %v", err)
+	}
+
 	// Initialize services
-	journalService := service.NewJournalService(database, processor, mcpClient, broadcaster, processingLogger)
+	journalService := service.NewJournalService(database, processor, mcpClient, broadcaster, processingLogger).
+		WithConfig(service.Config{
+			MultiTenant:         multiTenant,
+			StreamAnalysis:      getEnv("STREAM_ANALYSIS", "false") == "true",
+			FetchCacheTTL:       fetchCacheTTL,
+			MaxContentBytes:     maxContentBytes,
+			VectorProbes:        vectorProbes,
+			MaxFetchURLs:        maxFetchURLs,
+			MaxResultLimit:      maxResultLimit,
+			DeleteGracePeriod:   deleteGracePeriod,
+			ProcessingTimeout:   processingTimeout,
+			SimilarityMetric:    similarityMetric,
+			StrictEmbeddings:    getEnv("STRICT_EMBEDDINGS", "false") == "true",
+			SanitizeContent:     getEnv("SANITIZE_CONTENT", "false") == "true",
+			KeepOriginalContent: getEnv("KEEP_ORIGINAL_CONTENT", "false") == "true",
+		})
+
+	// An index built for another metric is ignored by the planner, so vector
+	// searches would still work but scan every row
+	if mismatches, err := journalService.VectorIndexMismatches(); err != nil {
+		log.Printf("Vector index check failed: %v", err)
+	} else {
+		for _, mismatch := range mismatches {
+			log.Printf("Vector index mismatch: %s", mismatch)
+		}
+	}
+
+	// Deleted entries stay restorable for the grace period, then are purged
+	journalService.StartDeletedEntryPurger()
+
+	// Optional sweeper for entries stuck mid-pipeline (e.g. after a crash)
+	if interval := getEnv("STUCK_SWEEP_INTERVAL", ""); interval != "" {
+		sweepInterval, err := time.ParseDuration(interval)
+		if err != nil {
+			log.Fatalf("Invalid STUCK_SWEEP_INTERVAL: %v", err)
+		}
+		threshold, err := time.ParseDuration(getEnv("STUCK_THRESHOLD", "10m"))
+		if err != nil {
+			log.Fatalf("Invalid STUCK_THRESHOLD: %v", err)
+		}
+		retry := getEnv("STUCK_ACTION", "fail") == "retry"
+		journalService.StartStuckEntrySweeper(threshold, sweepInterval, retry)
+		log.Printf("Stuck entry sweeper enabled: every %s, threshold %s, retry %v", sweepInterval, threshold, retry)
+	}
 
 	// Initialize handlers
 	journalHandlers := handlers.NewJournalHandlers(journalService)
-	evaluationHandler := handlers.NewEvaluationHandler(database, broadcaster, journalService)
+	evaluationHandler := handlers.NewEvaluationHandler(database, broadcaster, journalService, getEnv("EVALUATION_OUTPUT_DIR", "evaluation_results"))
+	systemHandlers := handlers.NewSystemHandlers(ollamaClient, mcpClient, broadcaster)
 
 	// Create JSON-RPC server
 	rpcServer := jsonrpc.NewServer()
@@ -77,19 +322,61 @@ func main() {
 	rpcServer.RegisterMethod("journal.create", journalHandlers.CreateEntry)
 	rpcServer.RegisterMethod("journal.update", journalHandlers.UpdateEntry)
 	rpcServer.RegisterMethod("journal.get", journalHandlers.GetEntry)
+	rpcServer.RegisterMethod("journal.getMany", journalHandlers.GetEntries)
+	rpcServer.RegisterMethod("journal.getHistory", journalHandlers.GetEntryHistory)
+	rpcServer.RegisterMethod("journal.restoreVersion", journalHandlers.RestoreVersion)
+	rpcServer.RegisterMethod("journal.addAttachment", journalHandlers.AddAttachment)
+	rpcServer.RegisterMethod("journal.delete", journalHandlers.DeleteEntry)
+	rpcServer.RegisterMethod("journal.restore", journalHandlers.RestoreEntry)
+	rpcServer.RegisterMethod("journal.getRelated", journalHandlers.GetRelatedEntries)
+	rpcServer.RegisterMethod("journal.findDuplicates", journalHandlers.FindDuplicates)
+	rpcServer.RegisterMethod("journal.onThisDay", journalHandlers.GetOnThisDay)
 	rpcServer.RegisterMethod("journal.search", journalHandlers.Search)
+	rpcServer.RegisterMethod("journal.count", journalHandlers.CountEntries)
 	rpcServer.RegisterMethod("journal.toggleFavorite", journalHandlers.ToggleFavorite)
+	rpcServer.RegisterMethod("journal.batchFavorite", journalHandlers.BatchFavorite)
+	rpcServer.RegisterMethod("journal.togglePin", journalHandlers.TogglePin)
+	rpcServer.RegisterMethod("journal.merge", journalHandlers.MergeEntries)
+	rpcServer.RegisterMethod("journal.exportEntry", journalHandlers.ExportEntry)
 	rpcServer.RegisterMethod("journal.getProcessingLogs", journalHandlers.GetProcessingLogs)
+	rpcServer.RegisterMethod("journal.listByStage", journalHandlers.ListByStage)
+	rpcServer.RegisterMethod("journal.listLowQuality", journalHandlers.ListLowQuality)
 	rpcServer.RegisterMethod("journal.analyzeFailure", journalHandlers.AnalyzeFailure)
+	rpcServer.RegisterMethod("journal.analyzeAllFailures", journalHandlers.AnalyzeAllFailures)
 	rpcServer.RegisterMethod("journal.retryProcessing", journalHandlers.RetryProcessing)
+	rpcServer.RegisterMethod("journal.cancelProcessing", journalHandlers.CancelProcessing)
+	rpcServer.RegisterMethod("journal.process", journalHandlers.ProcessDraft)
+	rpcServer.RegisterMethod("journal.reprocess", journalHandlers.ReprocessEntry)
+	rpcServer.RegisterMethod("journal.reprocessAll", journalHandlers.ReprocessAll)
+	rpcServer.RegisterMethod("journal.cancelReprocess", journalHandlers.CancelReprocess)
 	rpcServer.RegisterMethod("journal.getSearchSuggestions", journalHandlers.GetSearchSuggestions)
-	
+	rpcServer.RegisterMethod("journal.getFacet", journalHandlers.GetFacet)
+	rpcServer.RegisterMethod("journal.clearSearchHistory", journalHandlers.ClearSearchHistory)
+	rpcServer.RegisterMethod("journal.suggestCollections", journalHandlers.SuggestCollections)
+	rpcServer.RegisterMethod("journal.getAnalytics", journalHandlers.GetAnalytics)
+	rpcServer.RegisterMethod("journal.getSentimentTrend", journalHandlers.GetSentimentTrend)
+	rpcServer.RegisterMethod("journal.getTopicGraph", journalHandlers.GetTopicGraph)
+	rpcServer.RegisterMethod("journal.purgeLogs", journalHandlers.PurgeLogs)
+
 	// Register collection methods
 	rpcServer.RegisterMethod("collection.create", journalHandlers.CreateCollection)
+	rpcServer.RegisterMethod("collection.createSmart", journalHandlers.CreateSmartCollection)
 	rpcServer.RegisterMethod("collection.list", journalHandlers.GetCollections)
+	rpcServer.RegisterMethod("collection.update", journalHandlers.UpdateCollection)
+	rpcServer.RegisterMethod("collection.delete", journalHandlers.DeleteCollection)
+	rpcServer.RegisterMethod("collection.entries", journalHandlers.GetCollectionEntries)
+	rpcServer.RegisterMethod("collection.export", journalHandlers.ExportCollection)
 	rpcServer.RegisterMethod("collection.addEntry", journalHandlers.AddToCollection)
 	rpcServer.RegisterMethod("collection.removeEntry", journalHandlers.RemoveFromCollection)
-	
+	rpcServer.RegisterMethod("collection.batchAddEntries", journalHandlers.BatchAddToCollection)
+	rpcServer.RegisterMethod("collection.batchRemoveEntries", journalHandlers.BatchRemoveFromCollection)
+
+	// Register maintenance methods
+	rpcServer.RegisterMethod("admin.cleanOrphanedAssociations", journalHandlers.CleanOrphanedAssociations)
+
+	// Register system methods
+	rpcServer.RegisterMethod("system.info", systemHandlers.Info)
+
 	// Register evaluation methods
 	evaluationHandler.Register(rpcServer)
 
@@ -101,7 +388,7 @@ func main() {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Cache-Control")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Cache-Control, Authorization, X-API-Key")
 
 			if r.Method == "OPTIONS" {
 				w.WriteHeader(http.StatusOK)
@@ -112,15 +399,22 @@ func main() {
 		})
 	})
 
-	// Routes
-	router.Handle("/api/rpc", rpcServer).Methods("POST", "OPTIONS")
+	// API routes require an API key in multi-tenant mode
+	api := router.PathPrefix("/api").Subrouter()
+	if multiTenant {
+		api.Use(keyStore.Middleware)
+	}
+
+	// Routes. RPC results and exports can be large, so they are gzipped for
+	// clients that accept it; the SSE stream is left uncompressed.
+	api.Handle("/rpc", middleware.Gzip(rpcServer)).Methods("POST", "OPTIONS")
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`{"status": "healthy"}`))
 	}).Methods("GET")
 
 	// SSE endpoint
-	router.HandleFunc("/api/events", func(w http.ResponseWriter, r *http.Request) {
+	api.HandleFunc("/events", func(w http.ResponseWriter, r *http.Request) {
 		// Set SSE headers
 		w.Header().Set("Content-Type", "text/event-stream")
 		w.Header().Set("Cache-Control", "no-cache")
@@ -131,7 +425,7 @@ func main() {
 		clientID := uuid.New().String()
 
 		// Register client
-		client := broadcaster.RegisterClient(clientID)
+		client := broadcaster.RegisterUserClient(clientID, auth.UserIDFromContext(r.Context()))
 		defer broadcaster.UnregisterClient(client)
 
 		// Create flusher
@@ -142,27 +436,52 @@ func main() {
 		}
 
 		// Send initial connection event
-		fmt.Fprintf(w, "event: connected\ndata: {\"client_id\":\"%s\"}\n\n", clientID)
+		if _, err := fmt.Fprintf(w, "event: connected\ndata: {\"client_id\":\"%s\"}\n\n", clientID); err != nil {
+			return
+		}
 		flusher.Flush()
 
 		// Create a ticker for heartbeat
-		heartbeat := time.NewTicker(30 * time.Second)
+		heartbeat := time.NewTicker(sseHeartbeat)
 		defer heartbeat.Stop()
 
+		// Close long-lived streams so abandoned connections cannot pile up;
+		// EventSource clients reconnect on their own
+		var maxDuration <-chan time.Time
+		if sseMaxDuration > 0 {
+			timer := time.NewTimer(sseMaxDuration)
+			defer timer.Stop()
+			maxDuration = timer.C
+		}
+
 		for {
 			select {
-			case event := <-client.Events:
+			case event, ok := <-client.Events:
+				if !ok {
+					return
+				}
 				// Send event to client
-				if sseData, err := events.FormatSSE(event); err == nil {
-					fmt.Fprint(w, sseData)
-					flusher.Flush()
+				sseData, err := events.FormatSSE(event)
+				if err != nil {
+					continue
+				}
+				if _, err := fmt.Fprint(w, sseData); err != nil {
+					// Client went away before its context was cancelled
+					return
 				}
+				flusher.Flush()
 
 			case <-heartbeat.C:
 				// Send heartbeat to keep connection alive
-				fmt.Fprint(w, ":heartbeat\n\n")
+				if _, err := fmt.Fprint(w, ":heartbeat\n\n"); err != nil {
+					return
+				}
 				flusher.Flush()
 
+			case <-maxDuration:
+				log.Printf("SSE client %s reached the %s connection limit", clientID, sseMaxDuration)
+				return
+
 			case <-r.Context().Done():
 				// Client disconnected
 				return
@@ -171,17 +490,56 @@ func main() {
 	}).Methods("GET")
 
 	// Export endpoint
-	router.HandleFunc("/api/export", func(w http.ResponseWriter, r *http.Request) {
+	api.Handle("/export", middleware.Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Parse query parameters
 		format := r.URL.Query().Get("format")
 		if format == "" {
 			format = "json"
 		}
 
+		// A raw backup streams every entry as JSON lines with no cap, in a
+		// form /api/import accepts back
+		if r.URL.Query().Get("raw") == "true" {
+			if format != "jsonl" {
+				http.Error(w, "raw export is only available as format=jsonl", http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", service.RawExportContentType)
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", service.ExportFilename("", format)))
+			if err := journalService.ForUser(auth.UserIDFromContext(r.Context())).StreamRawExport(w); err != nil {
+				log.Printf("Raw export failed: %v", err)
+			}
+			return
+		}
+
+		// Content in markdown and csv is cut to maxContentLength characters
+		// when set; the default keeps it whole
+		maxContentLength := 0
+		if raw := r.URL.Query().Get("maxContentLength"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				http.Error(w, "maxContentLength must be a non-negative integer", http.StatusBadRequest)
+				return
+			}
+			maxContentLength = n
+		}
+
+		// A single collection is exported under its own name
+		if collectionID := r.URL.Query().Get("collection_id"); collectionID != "" {
+			data, contentType, filename, err := journalService.ForUser(auth.UserIDFromContext(r.Context())).ExportCollection(collectionID, format, maxContentLength)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", contentType)
+			w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+			w.Write(data)
+			return
+		}
+
 		// Build search params from query
 		params := service.SearchParams{
 			Query: r.URL.Query().Get("query"),
-			Limit: 1000, // Export up to 1000 entries
 		}
 
 		// Handle favorites filter
@@ -195,21 +553,73 @@ func main() {
 			params.CollectionIDs = strings.Split(collections, ",")
 		}
 
-		// Export entries
-		data, contentType, err := journalService.ExportEntries(params, format)
+		// Handle date presets such as 7d or this_month
+		params.DateRange = r.URL.Query().Get("date_range")
+		if err := params.ApplyDateRange(time.Now()); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		contentType, err := service.ExportContentType(format)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
 		// Set headers
-		filename := fmt.Sprintf("journal-export-%s.%s", time.Now().Format("2006-01-02"), format)
+		filename := service.ExportFilename("", format)
 		w.Header().Set("Content-Type", contentType)
 		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
 
-		// Write data
-		w.Write(data)
-	}).Methods("GET", "OPTIONS")
+		// Stream entries as they are read; once writing has started an
+		// error can only truncate the download
+		if err := journalService.ForUser(auth.UserIDFromContext(r.Context())).StreamExport(w, params, format, maxContentLength); err != nil {
+			log.Printf("Export failed: %v", err)
+		}
+	}))).Methods("GET", "OPTIONS")
+
+	// Import endpoint: a Markdown file with front matter, a zip of them, or a
+	// raw JSON lines backup
+	api.HandleFunc("/import", func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, fmt.Sprintf("missing import file: %v", err), http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+
+		data, err := io.ReadAll(file)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Parse by content rather than trusting the file name
+		var entries []service.ImportedEntry
+		switch {
+		case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+			entries, err = service.ParseMarkdownZip(data)
+		case service.IsRawImport(data):
+			entries, err = service.ParseRawImport(bytes.NewReader(data))
+		default:
+			entries, err = service.ParseMarkdownImport(bytes.NewReader(data))
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err := journalService.ForUser(auth.UserIDFromContext(r.Context())).ImportEntries(entries)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		log.Printf("Imported %s: %d parsed, %d imported, %d skipped, %d failed", header.Filename, result.Parsed, result.Imported, result.Skipped, result.Failed)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}).Methods("POST", "OPTIONS")
 
 	// Start server
 	port := getEnv("PORT", "8080")
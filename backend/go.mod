@@ -9,6 +9,7 @@ require (
 	github.com/lib/pq v1.10.9
 	github.com/pgvector/pgvector-go v0.2.2
 	github.com/stretchr/testify v1.10.0
+	golang.org/x/text v0.25.0
 )
 
 require (
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type contextKey string
+
+const userIDKey contextKey = "user_id"
+
+// KeyStore maps API keys to the user IDs they authenticate as
+type KeyStore struct {
+	keys map[string]string
+}
+
+// ParseKeyStore builds a KeyStore from a comma separated list of key:user_id
+// pairs, e.g. "k3y-alice:alice,k3y-bob:bob". Malformed pairs are ignored.
+func ParseKeyStore(spec string) *KeyStore {
+	store := &KeyStore{keys: make(map[string]string)}
+
+	for _, pair := range strings.Split(spec, ",") {
+		key, userID, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || key == "" || userID == "" {
+			continue
+		}
+		store.keys[key] = userID
+	}
+
+	return store
+}
+
+// Len returns the number of configured API keys
+func (ks *KeyStore) Len() int {
+	return len(ks.keys)
+}
+
+// Lookup returns the user ID bound to an API key
+func (ks *KeyStore) Lookup(key string) (string, bool) {
+	userID, ok := ks.keys[key]
+	return userID, ok
+}
+
+// Middleware rejects requests without a valid API key and stores the
+// authenticated user ID in the request context. The key is read from the
+// Authorization bearer token, the X-API-Key header, or the api_key query
+// parameter (EventSource and download links cannot set headers).
+func (ks *KeyStore) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userID, ok := ks.Lookup(apiKeyFromRequest(r))
+		if !ok {
+			http.Error(w, "invalid or missing API key", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(ContextWithUserID(r.Context(), userID)))
+	})
+}
+
+func apiKeyFromRequest(r *http.Request) string {
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(bearer)
+	}
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("api_key")
+}
+
+// ContextWithUserID returns a copy of ctx carrying the authenticated user ID
+func ContextWithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserIDFromContext returns the authenticated user ID, or "" when the request
+// was not authenticated (single-tenant mode)
+func UserIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDKey).(string)
+	return userID
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKeyStore(t *testing.T) {
+	store := ParseKeyStore("key-a:alice, key-b:bob,malformed,:nobody,key-c:")
+
+	assert.Equal(t, 2, store.Len())
+
+	userID, ok := store.Lookup("key-a")
+	assert.True(t, ok)
+	assert.Equal(t, "alice", userID)
+
+	userID, ok = store.Lookup("key-b")
+	assert.True(t, ok)
+	assert.Equal(t, "bob", userID)
+
+	_, ok = store.Lookup("malformed")
+	assert.False(t, ok)
+}
+
+func TestMiddleware(t *testing.T) {
+	store := ParseKeyStore("key-a:alice")
+
+	var seenUser string
+	handler := store.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenUser = UserIDFromContext(r.Context())
+	}))
+
+	tests := []struct {
+		name       string
+		setup      func(r *http.Request)
+		wantStatus int
+		wantUser   string
+	}{
+		{"bearer token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer key-a") }, http.StatusOK, "alice"},
+		{"api key header", func(r *http.Request) { r.Header.Set("X-API-Key", "key-a") }, http.StatusOK, "alice"},
+		{"query parameter", func(r *http.Request) { r.URL.RawQuery = "api_key=key-a" }, http.StatusOK, "alice"},
+		{"unknown key", func(r *http.Request) { r.Header.Set("X-API-Key", "nope") }, http.StatusUnauthorized, ""},
+		{"missing key", func(r *http.Request) {}, http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seenUser = ""
+			req := httptest.NewRequest(http.MethodPost, "/api/rpc", nil)
+			tt.setup(req)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantUser, seenUser)
+		})
+	}
+}
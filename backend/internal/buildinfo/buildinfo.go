@@ -0,0 +1,13 @@
+// Package buildinfo holds build metadata injected at link time, e.g.
+//
+//	go build -ldflags "-X github.com/journal/internal/buildinfo.Version=v1.2.0 \
+//	  -X github.com/journal/internal/buildinfo.Commit=$(git rev-parse --short HEAD) \
+//	  -X github.com/journal/internal/buildinfo.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+// Set with -ldflags -X; unset in `go run` and test builds
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
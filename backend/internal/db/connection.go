@@ -19,11 +19,22 @@ func NewConnection(host, port, user, password, dbname string) (*DB, error) {
 		psqlInfo += fmt.Sprintf(" password=%s", password)
 	}
 
+	pool, err := PoolConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
 	db, err := sql.Open("postgres", psqlInfo)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	// As in database/sql, 0 means no limit for open connections and lifetime,
+	// and no idle connections kept
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+
 	if err := db.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
@@ -31,24 +42,51 @@ func NewConnection(host, port, user, password, dbname string) (*DB, error) {
 	// Register pgvector
 	// Note: pgvector-go types are registered automatically when used
 
-	log.Println("Database connection established")
+	log.Printf("Database connection established (max open: %d, max idle: %d, max lifetime: %s)",
+		pool.MaxOpenConns, pool.MaxIdleConns, pool.ConnMaxLifetime)
 
 	return &DB{db}, nil
 }
 
+// migration is a named schema change applied by RunMigrations
+type migration struct {
+	name string
+	sql  string
+}
+
+// migrations lists every schema change in the order it must be applied
+var migrations = []migration{
+	{name: "initial schema", sql: CreateTablesSQL},
+	{name: "processing tracker", sql: AddProcessingTrackerSQL},
+	{name: "user scoping", sql: AddUserScopingSQL},
+	{name: "smart collections", sql: AddSmartCollectionsSQL},
+	{name: "entry chunks", sql: AddEntryChunksSQL},
+	{name: "fetched urls", sql: AddFetchedURLsSQL},
+	{name: "text search config", sql: AddTSConfigSQL},
+	{name: "search history", sql: AddSearchHistorySQL},
+	{name: "pinned entries", sql: AddPinnedAtSQL},
+	{name: "idempotency keys", sql: AddIdempotencyKeysSQL},
+	{name: "attachments", sql: AddAttachmentsSQL},
+	{name: "soft delete", sql: AddSoftDeleteSQL},
+	{name: "completed without embedding stage", sql: AddCompletedNoEmbeddingSQL},
+	{name: "cancelled stage", sql: AddCancelledStageSQL},
+	{name: "original content", sql: AddOriginalContentSQL},
+}
+
+// SchemaVersion is the number of migrations this build applies, and the name
+// of the latest one. RunMigrations applies all of them on every run, so a
+// migrated database is always at this version.
+func SchemaVersion() (int, string) {
+	return len(migrations), migrations[len(migrations)-1].name
+}
+
 func (db *DB) RunMigrations() error {
 	log.Println("Running database migrations...")
 
-	// Run initial schema
-	_, err := db.Exec(CreateTablesSQL)
-	if err != nil {
-		return fmt.Errorf("failed to run initial migrations: %w", err)
-	}
-
-	// Run processing tracker migration
-	_, err = db.Exec(AddProcessingTrackerSQL)
-	if err != nil {
-		return fmt.Errorf("failed to run processing tracker migration: %w", err)
+	for _, m := range migrations {
+		if _, err := db.Exec(m.sql); err != nil {
+			return fmt.Errorf("failed to run %s migration: %w", m.name, err)
+		}
 	}
 
 	log.Println("Migrations completed successfully")
@@ -0,0 +1,9 @@
+package db
+
+const AddAttachmentsSQL = `
+-- File and image references supplied with an entry: a JSON array of
+-- {filename, mime, size, url}. Only metadata is stored, never file contents.
+ALTER TABLE journal_entries ADD COLUMN IF NOT EXISTS attachments JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX IF NOT EXISTS idx_journal_entries_has_attachments ON journal_entries ((attachments <> '[]'::jsonb));
+`
@@ -0,0 +1,6 @@
+package db
+
+const AddCancelledStageSQL = `
+-- Entries whose processing was stopped with journal.cancelProcessing
+ALTER TYPE processing_stage ADD VALUE IF NOT EXISTS 'cancelled';
+`
@@ -0,0 +1,17 @@
+package db
+
+const AddEntryChunksSQL = `
+-- Embeddings of overlapping slices of long entries, so vector search can
+-- match the most relevant part instead of a diluted whole-entry vector
+CREATE TABLE IF NOT EXISTS entry_chunks (
+    entry_id UUID NOT NULL REFERENCES journal_entries(id) ON DELETE CASCADE,
+    chunk_index INT NOT NULL,
+    content TEXT NOT NULL,
+    embedding vector(768) NOT NULL,
+    PRIMARY KEY (entry_id, chunk_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_entry_chunks_embedding ON entry_chunks
+USING ivfflat (embedding vector_cosine_ops)
+WITH (lists = 100);
+`
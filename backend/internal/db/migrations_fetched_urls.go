@@ -0,0 +1,15 @@
+package db
+
+const AddFetchedURLsSQL = `
+-- Results from the MCP fetch agent, shared across entries and restarts
+CREATE TABLE IF NOT EXISTS fetched_urls (
+    url TEXT PRIMARY KEY,
+    title TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL DEFAULT '',
+    source TEXT NOT NULL DEFAULT '',
+    metadata JSONB NOT NULL DEFAULT '{}',
+    fetched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_fetched_urls_fetched_at ON fetched_urls(fetched_at);
+`
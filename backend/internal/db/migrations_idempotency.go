@@ -0,0 +1,16 @@
+package db
+
+const AddIdempotencyKeysSQL = `
+-- Client-supplied keys for journal.create, so retried requests return the
+-- entry created by the first one instead of inserting a duplicate. owner is
+-- the user ID in multi-tenant mode and empty otherwise.
+CREATE TABLE IF NOT EXISTS entry_idempotency_keys (
+    owner TEXT NOT NULL DEFAULT '',
+    key TEXT NOT NULL,
+    entry_id UUID NOT NULL REFERENCES journal_entries(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (owner, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_entry_idempotency_keys_created_at ON entry_idempotency_keys(created_at);
+`
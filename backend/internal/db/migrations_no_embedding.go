@@ -0,0 +1,7 @@
+package db
+
+const AddCompletedNoEmbeddingSQL = `
+-- Entries whose analysis succeeded but whose embedding failed; they keep
+-- their analysis and wait for a retry to add the embedding
+ALTER TYPE processing_stage ADD VALUE IF NOT EXISTS 'completed_no_embedding';
+`
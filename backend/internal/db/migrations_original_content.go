@@ -0,0 +1,7 @@
+package db
+
+const AddOriginalContentSQL = `
+-- Content as submitted, kept when sanitizing changed it and
+-- KEEP_ORIGINAL_CONTENT is set; NULL otherwise
+ALTER TABLE journal_entries ADD COLUMN IF NOT EXISTS original_content TEXT;
+`
@@ -0,0 +1,8 @@
+package db
+
+const AddPinnedAtSQL = `
+-- When an entry was pinned to the top of the timeline; NULL when unpinned
+ALTER TABLE journal_entries ADD COLUMN IF NOT EXISTS pinned_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_journal_entries_pinned_at ON journal_entries(pinned_at) WHERE pinned_at IS NOT NULL;
+`
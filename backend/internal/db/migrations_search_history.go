@@ -0,0 +1,15 @@
+package db
+
+const AddSearchHistorySQL = `
+-- Queries run through journal.search, used for recent-search suggestions
+CREATE TABLE IF NOT EXISTS search_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id TEXT,
+    query TEXT NOT NULL,
+    mode TEXT NOT NULL,
+    result_count INTEGER NOT NULL DEFAULT 0,
+    searched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_search_history_user_searched_at ON search_history(user_id, searched_at DESC);
+`
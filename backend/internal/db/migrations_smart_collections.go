@@ -0,0 +1,7 @@
+package db
+
+const AddSmartCollectionsSQL = `
+-- Smart collections store a search instead of manual membership
+ALTER TABLE collections ADD COLUMN IF NOT EXISTS is_smart BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE collections ADD COLUMN IF NOT EXISTS query_params JSONB;
+`
@@ -0,0 +1,9 @@
+package db
+
+const AddSoftDeleteSQL = `
+-- When an entry was deleted; it is hidden while set and purged once older
+-- than the undo window
+ALTER TABLE journal_entries ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_journal_entries_deleted_at ON journal_entries(deleted_at) WHERE deleted_at IS NOT NULL;
+`
@@ -0,0 +1,14 @@
+package db
+
+const AddUserScopingSQL = `
+-- Owner of each entry and collection; NULL in single-tenant installs
+ALTER TABLE journal_entries ADD COLUMN IF NOT EXISTS user_id TEXT;
+ALTER TABLE collections ADD COLUMN IF NOT EXISTS user_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_journal_entries_user_id ON journal_entries(user_id);
+CREATE INDEX IF NOT EXISTS idx_collections_user_id ON collections(user_id);
+
+-- Collection names only need to be unique per user
+ALTER TABLE collections DROP CONSTRAINT IF EXISTS collections_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_collections_user_name ON collections(COALESCE(user_id, ''), name);
+`
@@ -0,0 +1,27 @@
+package db
+
+const AddTSConfigSQL = `
+-- Text search configuration per entry, so stemming follows the entry's
+-- language. Entries written before detection keep 'english'.
+ALTER TABLE journal_entries ADD COLUMN IF NOT EXISTS ts_config regconfig NOT NULL DEFAULT 'english';
+
+-- Regenerate tsv from ts_config. A generated column's expression cannot be
+-- altered in place, so the column is recreated once.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_attrdef d
+        JOIN pg_attribute a ON a.attrelid = d.adrelid AND a.attnum = d.adnum
+        WHERE d.adrelid = 'journal_entries'::regclass
+        AND a.attname = 'tsv'
+        AND pg_get_expr(d.adbin, d.adrelid) LIKE '%ts_config%'
+    ) THEN
+        ALTER TABLE journal_entries DROP COLUMN IF EXISTS tsv;
+        ALTER TABLE journal_entries ADD COLUMN tsv tsvector
+            GENERATED ALWAYS AS (to_tsvector(ts_config, content)) STORED;
+    END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_journal_entries_tsv ON journal_entries USING GIN(tsv);
+`
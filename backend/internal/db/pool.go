@@ -0,0 +1,58 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Default pool limits. Entry processing runs in background goroutines that
+// each hold a connection while writing results, so the pool is bounded to
+// stay well below Postgres' default max_connections of 100.
+const (
+	DefaultMaxOpenConns    = 25
+	DefaultMaxIdleConns    = 10
+	DefaultConnMaxLifetime = 30 * time.Minute
+)
+
+// PoolConfig bounds the database connection pool
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// PoolConfigFromEnv reads DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS and
+// DB_CONN_MAX_LIFETIME, using the defaults for unset variables
+func PoolConfigFromEnv() (PoolConfig, error) {
+	config := PoolConfig{
+		MaxOpenConns:    DefaultMaxOpenConns,
+		MaxIdleConns:    DefaultMaxIdleConns,
+		ConnMaxLifetime: DefaultConnMaxLifetime,
+	}
+
+	if value := os.Getenv("DB_MAX_OPEN_CONNS"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid DB_MAX_OPEN_CONNS: %w", err)
+		}
+		config.MaxOpenConns = n
+	}
+	if value := os.Getenv("DB_MAX_IDLE_CONNS"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid DB_MAX_IDLE_CONNS: %w", err)
+		}
+		config.MaxIdleConns = n
+	}
+	if value := os.Getenv("DB_CONN_MAX_LIFETIME"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return config, fmt.Errorf("invalid DB_CONN_MAX_LIFETIME: %w", err)
+		}
+		config.ConnMaxLifetime = d
+	}
+
+	return config, nil
+}
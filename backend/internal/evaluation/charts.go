@@ -0,0 +1,150 @@
+package evaluation
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Geometry shared by the HTML report charts, in SVG user units
+const (
+	chartPlotHeight = 200.0
+	chartTopPadding = 20.0
+	chartAxisSpace  = 40.0 // below the plot, for mode labels
+	chartLeftSpace  = 40.0 // left of the plot, for the value axis
+	chartBarWidth   = 28.0
+	chartBarGap     = 6.0
+	chartGroupGap   = 48.0
+)
+
+// barChart is a self-contained SVG bar chart rendered by the HTML report
+// template, so reports need no external chart library
+type barChart struct {
+	Width  float64
+	Height float64
+	Bars   []chartBar
+	Labels []chartLabel
+	Ticks  []chartLabel
+	Legend []chartLegendItem
+	AxisY  float64 // y of the baseline
+}
+
+type chartBar struct {
+	X, Y, Width, Height float64
+	Color               string
+	Title               string // Tooltip shown on hover
+}
+
+type chartLabel struct {
+	X, Y float64
+	Text string
+}
+
+type chartLegendItem struct {
+	Color string
+	Text  string
+}
+
+// chartSeries is one metric drawn as a bar in every mode's group
+type chartSeries struct {
+	name  string
+	color string
+	value func(*SearchMetrics) float64
+	label func(float64) string
+}
+
+// qualityChart groups precision, recall and F1 bars per search mode on a
+// shared 0-1 scale
+func qualityChart(metrics map[string]*SearchMetrics) barChart {
+	score := func(v float64) string { return fmt.Sprintf("%.3f", v) }
+	return buildBarChart(metrics, 1, []chartSeries{
+		{name: "Precision", color: "#007bff", value: func(m *SearchMetrics) float64 { return m.Precision }, label: score},
+		{name: "Recall", color: "#28a745", value: func(m *SearchMetrics) float64 { return m.Recall }, label: score},
+		{name: "F1 Score", color: "#fd7e14", value: func(m *SearchMetrics) float64 { return m.F1Score }, label: score},
+	})
+}
+
+// latencyChart draws one average latency bar per search mode, scaled to the
+// slowest mode
+func latencyChart(metrics map[string]*SearchMetrics) barChart {
+	maxLatency := 0.0
+	for _, m := range metrics {
+		if m.AvgLatency > maxLatency {
+			maxLatency = m.AvgLatency
+		}
+	}
+	if maxLatency == 0 {
+		maxLatency = 1
+	}
+
+	return buildBarChart(metrics, maxLatency, []chartSeries{
+		{name: "Avg Latency", color: "#6f42c1", value: func(m *SearchMetrics) float64 { return m.AvgLatency },
+			label: func(v float64) string { return fmt.Sprintf("%.1fms", v) }},
+	})
+}
+
+// sortedModes returns the search modes in metrics in alphabetical order
+func sortedModes(metrics map[string]*SearchMetrics) []string {
+	modes := make([]string, 0, len(metrics))
+	for mode := range metrics {
+		modes = append(modes, mode)
+	}
+	sort.Strings(modes)
+	return modes
+}
+
+// buildBarChart lays out one group of bars per mode, in mode order, with
+// values scaled so scaleMax fills the plot height
+func buildBarChart(metrics map[string]*SearchMetrics, scaleMax float64, series []chartSeries) barChart {
+	modes := sortedModes(metrics)
+
+	groupWidth := float64(len(series))*chartBarWidth + float64(len(series)-1)*chartBarGap
+	axisY := chartTopPadding + chartPlotHeight
+
+	chart := barChart{
+		Width:  chartLeftSpace + float64(len(modes))*(groupWidth+chartGroupGap),
+		Height: axisY + chartAxisSpace,
+		AxisY:  axisY,
+	}
+
+	for i, mode := range modes {
+		groupX := chartLeftSpace + chartGroupGap/2 + float64(i)*(groupWidth+chartGroupGap)
+
+		for j, s := range series {
+			value := s.value(metrics[mode])
+			height := value / scaleMax * chartPlotHeight
+			if height < 0 {
+				height = 0
+			}
+			chart.Bars = append(chart.Bars, chartBar{
+				X:      groupX + float64(j)*(chartBarWidth+chartBarGap),
+				Y:      axisY - height,
+				Width:  chartBarWidth,
+				Height: height,
+				Color:  s.color,
+				Title:  fmt.Sprintf("%s %s: %s", mode, s.name, s.label(value)),
+			})
+		}
+
+		chart.Labels = append(chart.Labels, chartLabel{
+			X:    groupX + groupWidth/2,
+			Y:    axisY + 20,
+			Text: mode,
+		})
+	}
+
+	for _, fraction := range []float64{0, 0.5, 1} {
+		chart.Ticks = append(chart.Ticks, chartLabel{
+			X:    chartLeftSpace - 6,
+			Y:    axisY - fraction*chartPlotHeight + 4,
+			Text: series[0].label(fraction * scaleMax),
+		})
+	}
+
+	if len(series) > 1 {
+		for _, s := range series {
+			chart.Legend = append(chart.Legend, chartLegendItem{Color: s.color, Text: s.name})
+		}
+	}
+
+	return chart
+}
@@ -0,0 +1,62 @@
+package evaluation
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQualityChartLayout(t *testing.T) {
+	chart := qualityChart(map[string]*SearchMetrics{
+		"vector":  {Precision: 0.5, Recall: 1, F1Score: 0.25},
+		"classic": {Precision: 1},
+	})
+
+	require.Len(t, chart.Bars, 6)
+	require.Len(t, chart.Legend, 3)
+
+	// Modes are laid out alphabetically, so classic's precision comes first
+	assert.Equal(t, "classic", chart.Labels[0].Text)
+	assert.Equal(t, chartPlotHeight, chart.Bars[0].Height)
+	assert.Equal(t, chart.AxisY-chartPlotHeight, chart.Bars[0].Y)
+
+	assert.Equal(t, chartPlotHeight/2, chart.Bars[3].Height)
+	assert.Equal(t, chartPlotHeight, chart.Bars[4].Height)
+	assert.Equal(t, chartPlotHeight/4, chart.Bars[5].Height)
+}
+
+func TestLatencyChartScalesToSlowestMode(t *testing.T) {
+	chart := latencyChart(map[string]*SearchMetrics{
+		"classic": {AvgLatency: 10},
+		"hybrid":  {AvgLatency: 40},
+	})
+
+	require.Len(t, chart.Bars, 2)
+	assert.Equal(t, chartPlotHeight/4, chart.Bars[0].Height)
+	assert.Equal(t, chartPlotHeight, chart.Bars[1].Height)
+	assert.Empty(t, chart.Legend)
+}
+
+func TestHTMLReportEmbedsCharts(t *testing.T) {
+	reporter := NewReporter(t.TempDir())
+
+	path, err := reporter.GenerateHTMLReport(map[string]*SearchMetrics{
+		"classic": {Precision: 0.8, Recall: 0.6, F1Score: 0.69, AvgLatency: 12,
+			Categories: map[string]*CategoryMetrics{"health": {Precision: 0.5, Recall: 0.5, F1Score: 0.5, TestCount: 2}}},
+	})
+	require.NoError(t, err)
+
+	html, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	report := string(html)
+	assert.Equal(t, 2, strings.Count(report, "<svg"))
+	assert.Contains(t, report, `fill="#007bff"`)
+	assert.Contains(t, report, "classic Precision: 0.800")
+	assert.Contains(t, report, "<td>health</td>")
+	assert.NotContains(t, report, "ZgotmplZ")
+	assert.NotContains(t, report, "<script")
+}
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/journal/internal/db"
@@ -34,6 +36,7 @@ func NewEvaluator(database *db.DB, outputDir string, journalService *service.Jou
 // SearchMetrics holds evaluation metrics for a search mode
 type SearchMetrics struct {
 	Mode       string       `json:"mode"`
+	Label      string       `json:"label,omitempty"` // run label given to RunEvaluation
 	Precision  float64      `json:"precision"`
 	Recall     float64      `json:"recall"`
 	F1Score    float64      `json:"f1_score"`
@@ -42,8 +45,24 @@ type SearchMetrics struct {
 	AvgLatency float64      `json:"avg_latency_ms"`
 	TestCases  []TestResult `json:"test_cases"`
 	Timestamp  time.Time    `json:"timestamp"`
+
+	// Categories breaks precision, recall and F1 down by the primary topic
+	// each test query targets
+	Categories map[string]*CategoryMetrics `json:"categories,omitempty"`
+}
+
+// CategoryMetrics holds metrics for the test cases of one category
+type CategoryMetrics struct {
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1Score   float64 `json:"f1_score"`
+	TestCount int     `json:"test_count"`
 }
 
+// uncategorized groups test cases without a category, such as entity and
+// filter searches
+const uncategorized = "uncategorized"
+
 // TestResult represents the result of a single test case
 type TestResult struct {
 	TestID      string          `json:"test_id"`
@@ -54,11 +73,19 @@ type TestResult struct {
 	Recall      float64         `json:"recall"`
 	Latency     time.Duration   `json:"latency_ms"`
 	Filters     json.RawMessage `json:"filters,omitempty"`
+	Category    string          `json:"category,omitempty"`
+
+	RelevanceGrades map[string]float64 `json:"relevance_grades,omitempty"`
 }
 
-// GenerateTestData creates synthetic test data
-func (e *Evaluator) GenerateTestData(size int) error {
+// GenerateTestData creates synthetic test data. With dryRun the entries and
+// test cases are only written to the output directory; the database is not
+// touched.
+func (e *Evaluator) GenerateTestData(size int, dryRun bool) error {
 	log.Printf("Generating %d test entries...", size)
+	if dryRun {
+		log.Println("Dry run: entries will not be inserted into the database")
+	}
 
 	// Ensure output directory exists
 	dataDir := filepath.Join(e.outputDir, "data")
@@ -67,7 +94,7 @@ func (e *Evaluator) GenerateTestData(size int) error {
 	}
 
 	// Generate test entries
-	entries, err := e.generator.GenerateEntries(size)
+	entries, err := e.generator.GenerateEntries(size, dryRun)
 	if err != nil {
 		return fmt.Errorf("failed to generate entries: %w", err)
 	}
@@ -101,8 +128,20 @@ func (e *Evaluator) GenerateTestData(size int) error {
 	return nil
 }
 
-// RunEvaluation executes evaluation for specified search modes
-func (e *Evaluator) RunEvaluation(mode string) (map[string]*SearchMetrics, error) {
+// CleanupTestData deletes the entries inserted by GenerateTestData and
+// returns how many were removed. Files in the output directory are kept.
+func (e *Evaluator) CleanupTestData() (int64, error) {
+	return e.generator.DeleteTestEntries()
+}
+
+// RunEvaluation executes evaluation for specified search modes. label, when
+// set, is recorded in the metrics and their file names so parallel
+// experiments stay apart.
+func (e *Evaluator) RunEvaluation(mode, label string) (map[string]*SearchMetrics, error) {
+	if err := ValidateRunLabel(label); err != nil {
+		return nil, err
+	}
+
 	results := make(map[string]*SearchMetrics)
 
 	modes := []string{}
@@ -122,6 +161,7 @@ func (e *Evaluator) RunEvaluation(mode string) (map[string]*SearchMetrics, error
 		if err != nil {
 			return nil, fmt.Errorf("failed to evaluate %s search: %w", searchMode, err)
 		}
+		metrics.Label = label
 
 		results[searchMode] = metrics
 
@@ -181,6 +221,8 @@ func (e *Evaluator) evaluateSearchMode(mode string) (*SearchMetrics, error) {
 		metrics.MRR = e.calculateMRR(metrics.TestCases)
 	}
 
+	metrics.Categories = categoryBreakdown(metrics.TestCases)
+
 	return metrics, nil
 }
 
@@ -286,6 +328,9 @@ func (e *Evaluator) runTestCase(mode string, testCase TestCase) (*TestResult, er
 		Recall:      recall,
 		Latency:     time.Since(start),
 		Filters:     testCase.Filters,
+		Category:    testCase.Category,
+
+		RelevanceGrades: testCase.RelevanceGrades,
 	}, nil
 }
 
@@ -322,40 +367,31 @@ func calculatePrecisionRecall(expected, actual []string) (precision, recall floa
 
 // calculateNDCG calculates Normalized Discounted Cumulative Gain
 func (e *Evaluator) calculateNDCG(results []TestResult) float64 {
-	// Simplified NDCG calculation
-	// In practice, this would consider ranking positions
+	// Standard DCG with a log2(rank+1) discount, so scores are comparable
+	// with other IR tooling
 	var totalNDCG float64
 	validResults := 0
 
 	for _, result := range results {
 		if len(result.ExpectedIDs) > 0 {
+			grades := relevanceGrades(result)
+
 			// Calculate DCG for actual results
 			dcg := 0.0
 			for i, id := range result.ActualIDs {
-				relevance := 0.0
-				for j, expectedID := range result.ExpectedIDs {
-					if id == expectedID {
-						// Higher relevance for items appearing earlier in expected list
-						relevance = float64(len(result.ExpectedIDs)-j) / float64(len(result.ExpectedIDs))
-						break
-					}
-				}
-				if i == 0 {
-					dcg += relevance
-				} else {
-					dcg += relevance / (float64(i) + 1)
-				}
+				dcg += grades[id] / math.Log2(float64(i)+2)
 			}
 
-			// Calculate ideal DCG
+			// Calculate ideal DCG from the grades in descending order
+			ideal := make([]float64, 0, len(grades))
+			for _, grade := range grades {
+				ideal = append(ideal, grade)
+			}
+			sort.Sort(sort.Reverse(sort.Float64Slice(ideal)))
+
 			idealDCG := 0.0
-			for i := 0; i < len(result.ExpectedIDs); i++ {
-				relevance := float64(len(result.ExpectedIDs)-i) / float64(len(result.ExpectedIDs))
-				if i == 0 {
-					idealDCG += relevance
-				} else {
-					idealDCG += relevance / (float64(i) + 1)
-				}
+			for i, relevance := range ideal {
+				idealDCG += relevance / math.Log2(float64(i)+2)
 			}
 
 			if idealDCG > 0 {
@@ -371,6 +407,59 @@ func (e *Evaluator) calculateNDCG(results []TestResult) float64 {
 	return 0.0
 }
 
+// categoryBreakdown averages precision and recall over the test cases of
+// each category. Unlike the headline metrics it counts cases that found
+// nothing, since those are the topic-specific weaknesses it exists to show.
+func categoryBreakdown(results []TestResult) map[string]*CategoryMetrics {
+	if len(results) == 0 {
+		return nil
+	}
+
+	categories := make(map[string]*CategoryMetrics)
+	for _, result := range results {
+		category := result.Category
+		if category == "" {
+			category = uncategorized
+		}
+
+		cm, ok := categories[category]
+		if !ok {
+			cm = &CategoryMetrics{}
+			categories[category] = cm
+		}
+		cm.Precision += result.Precision
+		cm.Recall += result.Recall
+		cm.TestCount++
+	}
+
+	for _, cm := range categories {
+		cm.Precision /= float64(cm.TestCount)
+		cm.Recall /= float64(cm.TestCount)
+		if cm.Precision+cm.Recall > 0 {
+			cm.F1Score = 2 * (cm.Precision * cm.Recall) / (cm.Precision + cm.Recall)
+		}
+	}
+
+	return categories
+}
+
+// relevanceGrades returns the relevance of each expected ID in result: its
+// RelevanceGrades when set, otherwise grades falling linearly from 1 with the
+// position in ExpectedIDs
+func relevanceGrades(result TestResult) map[string]float64 {
+	if len(result.RelevanceGrades) > 0 {
+		return result.RelevanceGrades
+	}
+
+	grades := make(map[string]float64, len(result.ExpectedIDs))
+	for j, id := range result.ExpectedIDs {
+		if _, seen := grades[id]; !seen {
+			grades[id] = float64(len(result.ExpectedIDs)-j) / float64(len(result.ExpectedIDs))
+		}
+	}
+	return grades
+}
+
 // calculateMRR calculates Mean Reciprocal Rank
 func (e *Evaluator) calculateMRR(results []TestResult) float64 {
 	var totalRR float64
@@ -405,8 +494,7 @@ func (e *Evaluator) saveMetrics(mode string, metrics *SearchMetrics) error {
 		return fmt.Errorf("failed to create reports directory: %w", err)
 	}
 
-	timestamp := time.Now().Format("20060102_150405")
-	filePath := filepath.Join(reportsDir, fmt.Sprintf("%s_metrics_%s.json", mode, timestamp))
+	filePath := filepath.Join(reportsDir, runFileName(mode+"_metrics", metrics.Label, "json", time.Now()))
 
 	file, err := os.Create(filePath)
 	if err != nil {
@@ -419,13 +507,18 @@ func (e *Evaluator) saveMetrics(mode string, metrics *SearchMetrics) error {
 	return encoder.Encode(metrics)
 }
 
-// GenerateReport creates a formatted report of evaluation results
-func (e *Evaluator) GenerateReport(format string) (string, error) {
+// GenerateReport creates a formatted report of evaluation results. With a
+// label it reports that run's latest metrics; otherwise the latest of any run.
+func (e *Evaluator) GenerateReport(format, label string) (string, error) {
+	if err := ValidateRunLabel(label); err != nil {
+		return "", err
+	}
+
 	// Load latest metrics for all modes
 	metrics := make(map[string]*SearchMetrics)
 
 	for _, mode := range []string{"classic", "vector", "hybrid"} {
-		latestMetrics, err := e.loadLatestMetrics(mode)
+		latestMetrics, err := e.loadLatestMetrics(mode, label)
 		if err != nil {
 			log.Printf("Warning: no metrics found for %s search", mode)
 			continue
@@ -438,6 +531,7 @@ func (e *Evaluator) GenerateReport(format string) (string, error) {
 	}
 
 	reporter := NewReporter(e.outputDir)
+	reporter.label = label
 
 	switch format {
 	case "html":
@@ -451,16 +545,27 @@ func (e *Evaluator) GenerateReport(format string) (string, error) {
 	}
 }
 
-// loadLatestMetrics loads the most recent metrics for a search mode
-func (e *Evaluator) loadLatestMetrics(mode string) (*SearchMetrics, error) {
+// loadLatestMetrics loads the most recent metrics for a search mode, limited
+// to runs with label when it is set
+func (e *Evaluator) loadLatestMetrics(mode, label string) (*SearchMetrics, error) {
 	reportsDir := filepath.Join(e.outputDir, "reports")
 	pattern := filepath.Join(reportsDir, fmt.Sprintf("%s_metrics_*.json", mode))
 
-	files, err := filepath.Glob(pattern)
+	matches, err := filepath.Glob(pattern)
 	if err != nil {
 		return nil, err
 	}
 
+	files := matches
+	if label != "" {
+		files = nil
+		for _, f := range matches {
+			if fileRunLabel(f, mode+"_metrics") == label {
+				files = append(files, f)
+			}
+		}
+	}
+
 	if len(files) == 0 {
 		return nil, fmt.Errorf("no metrics files found for %s", mode)
 	}
@@ -483,6 +588,6 @@ func (e *Evaluator) loadLatestMetrics(mode string) (*SearchMetrics, error) {
 }
 
 // GetLatestMetrics is a public method for getting latest metrics
-func (e *Evaluator) GetLatestMetrics(mode string) (*SearchMetrics, error) {
-	return e.loadLatestMetrics(mode)
+func (e *Evaluator) GetLatestMetrics(mode, label string) (*SearchMetrics, error) {
+	return e.loadLatestMetrics(mode, label)
 }
@@ -0,0 +1,80 @@
+package evaluation
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateNDCG(t *testing.T) {
+	e := &Evaluator{}
+
+	// Expected [a b c] grades a=1, b=2/3, c=1/3.
+	// DCG  = (2/3)/log2(2) + 1/log2(3)                   = 1.297598
+	// IDCG = 1/log2(2) + (2/3)/log2(3) + (1/3)/log2(4)   = 1.587289
+	results := []TestResult{{
+		ExpectedIDs: []string{"a", "b", "c"},
+		ActualIDs:   []string{"b", "a", "x"},
+	}}
+	assert.InDelta(t, 0.817494, e.calculateNDCG(results), 1e-6)
+
+	perfect := []TestResult{{
+		ExpectedIDs: []string{"a", "b", "c"},
+		ActualIDs:   []string{"a", "b", "c"},
+	}}
+	assert.InDelta(t, 1.0, e.calculateNDCG(perfect), 1e-9)
+
+	assert.Equal(t, 0.0, e.calculateNDCG(nil))
+}
+
+func TestCalculateNDCGWithRelevanceGrades(t *testing.T) {
+	e := &Evaluator{}
+
+	// DCG  = 0.5/log2(2) + 1/log2(3)   = 1.130930
+	// IDCG = 1/log2(2) + 0.5/log2(3)   = 1.315465
+	results := []TestResult{{
+		ExpectedIDs:     []string{"b", "a"},
+		ActualIDs:       []string{"b", "a"},
+		RelevanceGrades: map[string]float64{"a": 1, "b": 0.5},
+	}}
+	assert.InDelta(t, 0.859719, e.calculateNDCG(results), 1e-6)
+}
+
+func TestDeleteTestEntries(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	generator := NewTestDataGenerator(&db.DB{DB: mockDB})
+
+	mock.ExpectExec(`DELETE FROM journal_entries WHERE processed_data->'metadata'->>'is_test_data' = 'true'`).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+
+	deleted, err := generator.DeleteTestEntries()
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCategoryBreakdown(t *testing.T) {
+	categories := categoryBreakdown([]TestResult{
+		{Category: "technology", Precision: 1, Recall: 1},
+		{Category: "technology", Precision: 0.5, Recall: 0.5},
+		{Category: "relationships", Precision: 0, Recall: 0},
+		{Precision: 0.25, Recall: 1},
+	})
+
+	require.Len(t, categories, 3)
+	assert.InDelta(t, 0.75, categories["technology"].Precision, 1e-9)
+	assert.InDelta(t, 0.75, categories["technology"].F1Score, 1e-9)
+	assert.Equal(t, 2, categories["technology"].TestCount)
+
+	// Cases that found nothing still count toward their category
+	assert.Equal(t, 1, categories["relationships"].TestCount)
+	assert.Equal(t, 0.0, categories["relationships"].F1Score)
+
+	assert.InDelta(t, 0.4, categories[uncategorized].F1Score, 1e-9)
+}
@@ -49,6 +49,11 @@ type TestCase struct {
 	ExpectedIDs []string        `json:"expected_ids"`
 	SearchMode  string          `json:"search_mode,omitempty"`
 	VectorMode  string          `json:"vector_mode,omitempty"`
+	Category    string          `json:"category,omitempty"` // Primary topic the query targets
+
+	// RelevanceGrades optionally grades expected IDs (higher is more
+	// relevant) for NDCG; without it relevance follows ExpectedIDs order
+	RelevanceGrades map[string]float64 `json:"relevance_grades,omitempty"`
 }
 
 // Predefined test data patterns
@@ -84,14 +89,19 @@ var (
 	}
 )
 
-// GenerateEntries creates synthetic journal entries
-func (g *TestDataGenerator) GenerateEntries(count int) ([]TestEntry, error) {
+// GenerateEntries creates synthetic journal entries. Unless dryRun is set
+// they are also inserted into journal_entries.
+func (g *TestDataGenerator) GenerateEntries(count int, dryRun bool) ([]TestEntry, error) {
 	entries := make([]TestEntry, count)
 
 	for i := 0; i < count; i++ {
 		entry := g.generateSingleEntry(i)
 		entries[i] = entry
 
+		if dryRun {
+			continue
+		}
+
 		// Also insert into database for realistic testing
 		if err := g.insertTestEntry(entry); err != nil {
 			return nil, fmt.Errorf("failed to insert test entry: %w", err)
@@ -188,6 +198,10 @@ func (g *TestDataGenerator) selectRandom(items []string, n int) []string {
 	return shuffled[:n]
 }
 
+// testDataMarker is set in the processed_data metadata of generated entries
+// so they can be told apart from real ones
+const testDataMarker = "is_test_data"
+
 // insertTestEntry inserts a test entry into the database
 func (g *TestDataGenerator) insertTestEntry(entry TestEntry) error {
 	// Create processed data matching the expected format
@@ -197,6 +211,7 @@ func (g *TestDataGenerator) insertTestEntry(entry TestEntry) error {
 		"entities":  entry.Entities,
 		"sentiment": entry.Sentiment,
 		"keywords":  entry.Keywords,
+		"metadata":  map[string]interface{}{testDataMarker: true},
 	}
 
 	processedJSON, err := json.Marshal(processedData)
@@ -224,6 +239,19 @@ func (g *TestDataGenerator) insertTestEntry(entry TestEntry) error {
 	return err
 }
 
+// DeleteTestEntries removes every entry inserted by the generator, found by
+// the test data marker in its processed_data metadata, and returns how many
+// were deleted
+func (g *TestDataGenerator) DeleteTestEntries() (int64, error) {
+	result, err := g.db.Exec(
+		"DELETE FROM journal_entries WHERE processed_data->'metadata'->>'" + testDataMarker + "' = 'true'",
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete test entries: %w", err)
+	}
+	return result.RowsAffected()
+}
+
 // GenerateClassicSearchTests creates test cases for classic search
 func (g *TestDataGenerator) GenerateClassicSearchTests(entries []TestEntry) []TestCase {
 	testCases := []TestCase{}
@@ -239,6 +267,7 @@ func (g *TestDataGenerator) GenerateClassicSearchTests(entries []TestEntry) []Te
 				Query:       entry.Keywords[0],
 				ExpectedIDs: []string{entry.ID},
 				SearchMode:  "classic",
+				Category:    entry.Category,
 			})
 		}
 	}
@@ -259,6 +288,7 @@ func (g *TestDataGenerator) GenerateClassicSearchTests(entries []TestEntry) []Te
 				Query:       topic,
 				ExpectedIDs: expectedIDs,
 				SearchMode:  "classic",
+				Category:    topic,
 			})
 		}
 	}
@@ -276,7 +306,7 @@ func (g *TestDataGenerator) GenerateClassicSearchTests(entries []TestEntry) []Te
 				ID:          fmt.Sprintf("classic_entity_%s", strings.ReplaceAll(entity, " ", "_")),
 				Name:        fmt.Sprintf("Entity search: %s", entity),
 				Description: "Test entity-based search",
-				Query:       fmt.Sprintf("\"%s\"", entity), // Exact phrase
+				Query:       fmt.Sprintf("\"%s\"", strings.ReplaceAll(entity, "\"", "")), // Exact phrase for websearch_to_tsquery
 				ExpectedIDs: expectedIDs,
 				SearchMode:  "classic",
 			})
@@ -325,11 +355,12 @@ func (g *TestDataGenerator) GenerateVectorSearchTests(entries []TestEntry) []Tes
 		for i, queryEntry := range queryEntries {
 			// Find related entries based on mode
 			var expectedIDs []string
+			var grades map[string]float64
 
 			switch mode {
 			case "similar":
 				// Find entries with similar topics/entities
-				expectedIDs = g.findSimilarEntries(queryEntry, entries, 5)
+				expectedIDs, grades = g.findSimilarEntries(queryEntry, entries, 5)
 			case "explore":
 				// Find entries with some overlap but different focus
 				expectedIDs = g.findExploratoryEntries(queryEntry, entries, 5)
@@ -339,13 +370,15 @@ func (g *TestDataGenerator) GenerateVectorSearchTests(entries []TestEntry) []Tes
 			}
 
 			testCases = append(testCases, TestCase{
-				ID:          fmt.Sprintf("vector_%s_%d", mode, i),
-				Name:        fmt.Sprintf("Vector %s: %s", mode, queryEntry.Title),
-				Description: fmt.Sprintf("Test %s mode vector search", mode),
-				Query:       queryEntry.Content[:min(100, len(queryEntry.Content))],
-				ExpectedIDs: expectedIDs,
-				SearchMode:  "vector",
-				VectorMode:  mode,
+				ID:              fmt.Sprintf("vector_%s_%d", mode, i),
+				Name:            fmt.Sprintf("Vector %s: %s", mode, queryEntry.Title),
+				Description:     fmt.Sprintf("Test %s mode vector search", mode),
+				Query:           queryEntry.Content[:min(100, len(queryEntry.Content))],
+				ExpectedIDs:     expectedIDs,
+				SearchMode:      "vector",
+				VectorMode:      mode,
+				Category:        queryEntry.Category,
+				RelevanceGrades: grades,
 			})
 		}
 	}
@@ -371,6 +404,7 @@ func (g *TestDataGenerator) GenerateHybridSearchTests(entries []TestEntry) []Tes
 				Query:       entry.Keywords[0],
 				ExpectedIDs: expectedIDs,
 				SearchMode:  "hybrid",
+				Category:    entry.Category,
 			})
 		}
 	}
@@ -403,6 +437,7 @@ func (g *TestDataGenerator) GenerateHybridSearchTests(entries []TestEntry) []Tes
 			Query:       nq.query,
 			ExpectedIDs: expectedIDs,
 			SearchMode:  "hybrid",
+			Category:    nq.topics[0],
 		})
 	}
 
@@ -463,8 +498,11 @@ func (g *TestDataGenerator) selectDiverseEntries(entries []TestEntry, count int)
 	return diverse
 }
 
-func (g *TestDataGenerator) findSimilarEntries(query TestEntry, entries []TestEntry, count int) []string {
+// findSimilarEntries returns up to count entries sharing a topic with query,
+// graded by the fraction of query's topics they share
+func (g *TestDataGenerator) findSimilarEntries(query TestEntry, entries []TestEntry, count int) ([]string, map[string]float64) {
 	similar := []string{}
+	grades := map[string]float64{}
 
 	for _, entry := range entries {
 		if entry.ID == query.ID {
@@ -483,6 +521,7 @@ func (g *TestDataGenerator) findSimilarEntries(query TestEntry, entries []TestEn
 
 		if overlap > 0 {
 			similar = append(similar, entry.ID)
+			grades[entry.ID] = float64(overlap) / float64(len(query.Topics))
 		}
 
 		if len(similar) >= count {
@@ -490,7 +529,7 @@ func (g *TestDataGenerator) findSimilarEntries(query TestEntry, entries []TestEn
 		}
 	}
 
-	return similar
+	return similar, grades
 }
 
 func (g *TestDataGenerator) findExploratoryEntries(query TestEntry, entries []TestEntry, count int) []string {
@@ -8,12 +8,14 @@ import (
 	"html/template"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 )
 
 // Reporter generates evaluation reports in various formats
 type Reporter struct {
 	outputDir string
+	label     string // run label added to report names and headers
 }
 
 // NewReporter creates a new reporter instance
@@ -118,19 +120,35 @@ func (r *Reporter) GenerateHTMLReport(metrics map[string]*SearchMetrics) (string
             background: #f8f9fa;
             border-radius: 6px;
         }
-        .bar {
+        .chart-container h3 {
+            margin-top: 0;
+            color: #495057;
+            font-size: 16px;
+        }
+        .chart {
+            display: block;
+            margin-bottom: 20px;
+            font-size: 12px;
+            fill: #495057;
+        }
+        .chart-legend span {
+            display: inline-block;
+            margin-right: 16px;
+            font-size: 13px;
+        }
+        .chart-legend i {
             display: inline-block;
-            height: 20px;
-            background: #007bff;
-            margin-right: 10px;
-            border-radius: 3px;
+            width: 12px;
+            height: 12px;
+            margin-right: 6px;
+            vertical-align: middle;
         }
     </style>
 </head>
 <body>
     <div class="container">
         <h1>Search Evaluation Report</h1>
-        <p class="timestamp">Generated: {{.Timestamp}}</p>
+        <p class="timestamp">Generated: {{.Timestamp}}{{if .Label}} • Run: {{.Label}}{{end}}</p>
         
         <h2>Summary</h2>
         <div class="metrics-grid">
@@ -178,15 +196,41 @@ func (r *Reporter) GenerateHTMLReport(metrics map[string]*SearchMetrics) (string
             </tbody>
         </table>
 
+        <h2>Category Breakdown</h2>
+        {{range $mode, $metrics := .Metrics}}
+        {{if $metrics.Categories}}
+        <h3>{{$mode}} Search</h3>
+        <table>
+            <thead>
+                <tr>
+                    <th>Category</th>
+                    <th>Precision</th>
+                    <th>Recall</th>
+                    <th>F1 Score</th>
+                    <th>Test Cases</th>
+                </tr>
+            </thead>
+            <tbody>
+                {{range $category, $cm := $metrics.Categories}}
+                <tr>
+                    <td>{{$category}}</td>
+                    <td>{{printf "%.3f" $cm.Precision}}</td>
+                    <td>{{printf "%.3f" $cm.Recall}}</td>
+                    <td class="{{GetScoreStatus $cm.F1Score}}">{{printf "%.3f" $cm.F1Score}}</td>
+                    <td>{{$cm.TestCount}}</td>
+                </tr>
+                {{end}}
+            </tbody>
+        </table>
+        {{end}}
+        {{end}}
+
         <h2>Performance Distribution</h2>
         <div class="chart-container">
-            {{range $mode, $metrics := .Metrics}}
-            <div style="margin: 10px 0;">
-                <strong>{{$mode}}:</strong>
-                <span class="bar" style="width: {{GetF1BarWidth $metrics}}px;"></span>
-                <span>{{printf "%.1f%%" (GetF1Percentage $metrics)}}</span>
-            </div>
-            {{end}}
+            <h3>Precision, Recall and F1</h3>
+            {{template "barChart" QualityChart .Metrics}}
+            <h3>Average Latency</h3>
+            {{template "barChart" LatencyChart .Metrics}}
         </div>
 
         <h2>Test Case Results</h2>
@@ -223,24 +267,34 @@ func (r *Reporter) GenerateHTMLReport(metrics map[string]*SearchMetrics) (string
         {{end}}
     </div>
 </body>
-</html>`
+</html>
+
+{{define "barChart"}}
+{{if .Legend}}
+<div class="chart-legend">
+    {{range .Legend}}<span><i style="background: {{.Color}}"></i>{{.Text}}</span>{{end}}
+</div>
+{{end}}
+<svg class="chart" width="{{.Width}}" height="{{.Height}}" viewBox="0 0 {{.Width}} {{.Height}}" xmlns="http://www.w3.org/2000/svg">
+    <line x1="{{ChartLeft}}" y1="{{.AxisY}}" x2="{{.Width}}" y2="{{.AxisY}}" stroke="#adb5bd"/>
+    {{range .Ticks}}<text x="{{.X}}" y="{{.Y}}" text-anchor="end">{{.Text}}</text>{{end}}
+    {{range .Bars}}<rect x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}" fill="{{.Color}}" rx="2"><title>{{.Title}}</title></rect>{{end}}
+    {{range .Labels}}<text x="{{.X}}" y="{{.Y}}" text-anchor="middle">{{.Text}}</text>{{end}}
+</svg>
+{{end}}`
 
 	// Create template functions
 	funcMap := template.FuncMap{
 		"GetF1Status": func(metrics *SearchMetrics) string {
-			if metrics.F1Score >= 0.8 {
-				return "status-good"
-			} else if metrics.F1Score >= 0.6 {
-				return "status-warning"
-			}
-			return "status-poor"
+			return scoreStatus(metrics.F1Score)
 		},
+		"GetScoreStatus": scoreStatus,
 		"GetF1Percentage": func(metrics *SearchMetrics) float64 {
 			return metrics.F1Score * 100
 		},
-		"GetF1BarWidth": func(metrics *SearchMetrics) int {
-			return int(metrics.F1Score * 300)
-		},
+		"QualityChart": qualityChart,
+		"LatencyChart": latencyChart,
+		"ChartLeft":    func() float64 { return chartLeftSpace },
 		"GetTruncatedQuery": func(test TestResult) string {
 			if len(test.Query) > 50 {
 				return test.Query[:50] + "..."
@@ -261,9 +315,11 @@ func (r *Reporter) GenerateHTMLReport(metrics map[string]*SearchMetrics) (string
 	var buf bytes.Buffer
 	data := struct {
 		Timestamp time.Time
+		Label     string
 		Metrics   map[string]*SearchMetrics
 	}{
 		Timestamp: time.Now(),
+		Label:     r.label,
 		Metrics:   metrics,
 	}
 
@@ -272,9 +328,7 @@ func (r *Reporter) GenerateHTMLReport(metrics map[string]*SearchMetrics) (string
 	}
 
 	// Save report
-	timestamp := time.Now().Format("20060102_150405")
-	filename := fmt.Sprintf("evaluation_report_%s.html", timestamp)
-	filePath := filepath.Join(r.outputDir, "reports", filename)
+	filePath := r.reportPath("html")
 
 	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
 		return "", fmt.Errorf("failed to create reports directory: %w", err)
@@ -291,10 +345,12 @@ func (r *Reporter) GenerateHTMLReport(metrics map[string]*SearchMetrics) (string
 func (r *Reporter) GenerateJSONReport(metrics map[string]*SearchMetrics) (string, error) {
 	report := struct {
 		GeneratedAt time.Time                 `json:"generated_at"`
+		Label       string                    `json:"label,omitempty"`
 		Summary     map[string]SummaryMetrics `json:"summary"`
 		Detailed    map[string]*SearchMetrics `json:"detailed"`
 	}{
 		GeneratedAt: time.Now(),
+		Label:       r.label,
 		Summary:     make(map[string]SummaryMetrics),
 		Detailed:    metrics,
 	}
@@ -313,9 +369,7 @@ func (r *Reporter) GenerateJSONReport(metrics map[string]*SearchMetrics) (string
 	}
 
 	// Save report
-	timestamp := time.Now().Format("20060102_150405")
-	filename := fmt.Sprintf("evaluation_report_%s.json", timestamp)
-	filePath := filepath.Join(r.outputDir, "reports", filename)
+	filePath := r.reportPath("json")
 
 	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
 		return "", fmt.Errorf("failed to create reports directory: %w", err)
@@ -339,9 +393,7 @@ func (r *Reporter) GenerateJSONReport(metrics map[string]*SearchMetrics) (string
 // GenerateCSVReport creates a CSV report
 func (r *Reporter) GenerateCSVReport(metrics map[string]*SearchMetrics) (string, error) {
 	// Save report
-	timestamp := time.Now().Format("20060102_150405")
-	filename := fmt.Sprintf("evaluation_report_%s.csv", timestamp)
-	filePath := filepath.Join(r.outputDir, "reports", filename)
+	filePath := r.reportPath("csv")
 
 	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
 		return "", fmt.Errorf("failed to create reports directory: %w", err)
@@ -382,6 +434,34 @@ func (r *Reporter) GenerateCSVReport(metrics map[string]*SearchMetrics) (string,
 		}
 	}
 
+	// Write category breakdown
+	writer.Write([]string{}) // Empty row
+	writer.Write([]string{"Category Breakdown"})
+	writer.Write([]string{"Mode", "Category", "Precision", "Recall", "F1 Score", "Test Cases"})
+
+	for _, mode := range sortedModes(metrics) {
+		categories := make([]string, 0, len(metrics[mode].Categories))
+		for category := range metrics[mode].Categories {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+
+		for _, category := range categories {
+			cm := metrics[mode].Categories[category]
+			row := []string{
+				mode,
+				category,
+				fmt.Sprintf("%.3f", cm.Precision),
+				fmt.Sprintf("%.3f", cm.Recall),
+				fmt.Sprintf("%.3f", cm.F1Score),
+				fmt.Sprintf("%d", cm.TestCount),
+			}
+			if err := writer.Write(row); err != nil {
+				return "", fmt.Errorf("failed to write category: %w", err)
+			}
+		}
+	}
+
 	// Write test case details
 	writer.Write([]string{}) // Empty row
 	writer.Write([]string{"Test Case Details"})
@@ -411,6 +491,16 @@ func (r *Reporter) GenerateCSVReport(metrics map[string]*SearchMetrics) (string,
 	return filePath, nil
 }
 
+// scoreStatus maps a 0-1 score to the report's status CSS class
+func scoreStatus(score float64) string {
+	if score >= 0.8 {
+		return "status-good"
+	} else if score >= 0.6 {
+		return "status-warning"
+	}
+	return "status-poor"
+}
+
 // SummaryMetrics for JSON report
 type SummaryMetrics struct {
 	Precision  float64 `json:"precision"`
@@ -421,3 +511,8 @@ type SummaryMetrics struct {
 	AvgLatency float64 `json:"avg_latency_ms"`
 	TestCount  int     `json:"test_count"`
 }
+
+// reportPath names a new report file in the reports directory
+func (r *Reporter) reportPath(ext string) string {
+	return filepath.Join(r.outputDir, "reports", runFileName("evaluation_report", r.label, ext, time.Now()))
+}
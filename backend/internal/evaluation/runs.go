@@ -0,0 +1,49 @@
+package evaluation
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// runTimestampLayout names result files. Milliseconds keep two runs started
+// in the same second from overwriting each other, and the fixed width keeps
+// names sorting in time order.
+const runTimestampLayout = "20060102_150405.000"
+
+// runLabelPattern limits labels to characters that are safe in file names
+var runLabelPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// ValidateRunLabel checks a run label. Labels name parallel experiments, such
+// as "baseline" or "probes-10", so their results can be told apart; the empty
+// label is valid and means an unlabelled run.
+func ValidateRunLabel(label string) error {
+	if label != "" && !runLabelPattern.MatchString(label) {
+		return fmt.Errorf("invalid run label %q: use up to 64 letters, digits, '-' or '_'", label)
+	}
+	return nil
+}
+
+// runFileName builds "<prefix>_<timestamp>[_<label>].<ext>"
+func runFileName(prefix, label, ext string, t time.Time) string {
+	name := prefix + "_" + t.Format(runTimestampLayout)
+	if label != "" {
+		name += "_" + label
+	}
+	return name + "." + ext
+}
+
+// fileRunLabel returns the label in a file name written by runFileName, or ""
+// for an unlabelled run
+func fileRunLabel(path, prefix string) string {
+	rest := strings.TrimPrefix(filepath.Base(path), prefix+"_")
+	rest = strings.TrimSuffix(rest, filepath.Ext(rest))
+	// The timestamp holds one underscore, between date and time
+	parts := strings.SplitN(rest, "_", 3)
+	if len(parts) < 3 {
+		return ""
+	}
+	return parts[2]
+}
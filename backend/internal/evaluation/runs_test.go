@@ -0,0 +1,47 @@
+package evaluation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRunLabel(t *testing.T) {
+	assert.NoError(t, ValidateRunLabel(""))
+	assert.NoError(t, ValidateRunLabel("probes-10_v2"))
+	assert.Error(t, ValidateRunLabel("../escape"))
+	assert.Error(t, ValidateRunLabel("has space"))
+	assert.Error(t, ValidateRunLabel("-leading"))
+}
+
+func TestRunFileNameRoundTripsLabel(t *testing.T) {
+	at := time.Date(2024, 6, 1, 9, 30, 15, 123_000_000, time.UTC)
+
+	name := runFileName("classic_metrics", "baseline_v2", "json", at)
+	assert.Equal(t, "classic_metrics_20240601_093015.123_baseline_v2.json", name)
+	assert.Equal(t, "baseline_v2", fileRunLabel("/tmp/reports/"+name, "classic_metrics"))
+
+	assert.Equal(t, "", fileRunLabel(runFileName("classic_metrics", "", "json", at), "classic_metrics"))
+	// Files written before labels had second precision only
+	assert.Equal(t, "", fileRunLabel("classic_metrics_20240601_093015.json", "classic_metrics"))
+}
+
+func TestLatestMetricsByLabel(t *testing.T) {
+	e := &Evaluator{outputDir: t.TempDir()}
+
+	require.NoError(t, e.saveMetrics("vector", &SearchMetrics{Mode: "vector", Label: "baseline", Precision: 0.5}))
+	require.NoError(t, e.saveMetrics("vector", &SearchMetrics{Mode: "vector", Label: "probes-10", Precision: 0.7}))
+
+	baseline, err := e.GetLatestMetrics("vector", "baseline")
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, baseline.Precision)
+
+	latest, err := e.GetLatestMetrics("vector", "")
+	require.NoError(t, err)
+	assert.Equal(t, "probes-10", latest.Label)
+
+	_, err = e.GetLatestMetrics("vector", "missing")
+	assert.Error(t, err)
+}
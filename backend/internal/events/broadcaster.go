@@ -3,11 +3,25 @@ package events
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+const (
+	// DefaultClientBuffer is how many events a client may fall behind before
+	// further events are dropped for it
+	DefaultClientBuffer = 64
+	// DefaultBroadcastBuffer is how many events may wait for the broadcast
+	// loop before new events are dropped
+	DefaultBroadcastBuffer = 256
+)
+
+// slowClientGrace is how long one delivery waits, in total, for clients whose
+// buffer is full before their copy of the event is dropped
+const slowClientGrace = 50 * time.Millisecond
+
 // EventType represents the type of event
 type EventType string
 
@@ -18,6 +32,18 @@ const (
 	EventEntryFailed     EventType = "entry.failed"
 	EventEntryUpdated    EventType = "entry.updated"
 	EventEntryDeleted    EventType = "entry.deleted"
+	EventEntryLog        EventType = "entry.log"
+	EventEntryCancelled  EventType = "entry.cancelled"
+	EventEntryRelated    EventType = "entry.related"
+
+	EventEntryAnalyzingProgress EventType = "entry.analyzing.progress"
+
+	EventReprocessProgress  EventType = "reprocess.progress"
+	EventReprocessCompleted EventType = "reprocess.completed"
+
+	EventCollectionCreated EventType = "collection.created"
+	EventCollectionUpdated EventType = "collection.updated"
+	EventCollectionDeleted EventType = "collection.deleted"
 )
 
 // Event represents a server-sent event
@@ -26,13 +52,23 @@ type Event struct {
 	EntryID   string      `json:"entry_id,omitempty"`
 	Data      interface{} `json:"data"`
 	Timestamp time.Time   `json:"timestamp"`
+	UserID    string      `json:"-"` // Restricts delivery to one user's clients when set
 }
 
 // Client represents a connected SSE client
 type Client struct {
 	ID     string
+	UserID string
 	Events chan *Event
 	Done   chan bool
+
+	dropped atomic.Int64
+}
+
+// Dropped returns how many events were skipped because the client's buffer
+// was full
+func (c *Client) Dropped() int64 {
+	return c.dropped.Load()
 }
 
 // Broadcaster manages SSE event broadcasting
@@ -41,17 +77,74 @@ type Broadcaster struct {
 	register   chan *Client
 	unregister chan *Client
 	broadcast  chan *Event
+	hooks      []func(*Event)
 	mu         sync.RWMutex
+
+	clientBuffer int
+
+	// Events lost because the broadcast channel was full, and deliveries
+	// skipped because a client's buffer was full
+	droppedBroadcast  atomic.Int64
+	droppedSlowClient atomic.Int64
 }
 
 // NewBroadcaster creates a new event broadcaster
 func NewBroadcaster() *Broadcaster {
 	return &Broadcaster{
-		clients:    make(map[string]*Client),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		broadcast:  make(chan *Event),
+		clients:      make(map[string]*Client),
+		register:     make(chan *Client),
+		unregister:   make(chan *Client),
+		broadcast:    make(chan *Event, DefaultBroadcastBuffer),
+		clientBuffer: DefaultClientBuffer,
+	}
+}
+
+// WithBuffers overrides the per-client and broadcast channel depths. It must
+// be called before Start; values below 1 keep the defaults.
+func (b *Broadcaster) WithBuffers(clientBuffer, broadcastBuffer int) *Broadcaster {
+	if clientBuffer > 0 {
+		b.clientBuffer = clientBuffer
+	}
+	if broadcastBuffer > 0 {
+		b.broadcast = make(chan *Event, broadcastBuffer)
+	}
+	return b
+}
+
+// ClientStats reports the events dropped for one connected client
+type ClientStats struct {
+	ID      string `json:"id"`
+	Dropped int64  `json:"dropped"`
+}
+
+// Stats summarizes broadcaster health for monitoring
+type Stats struct {
+	Clients           int           `json:"clients"`
+	ClientBuffer      int           `json:"client_buffer"`
+	BroadcastBuffer   int           `json:"broadcast_buffer"`
+	DroppedBroadcast  int64         `json:"dropped_broadcast"`
+	DroppedSlowClient int64         `json:"dropped_slow_client"`
+	SlowClients       []ClientStats `json:"slow_clients,omitempty"` // Connected clients that have missed events
+}
+
+// Stats returns event loss counters since the broadcaster was created
+func (b *Broadcaster) Stats() Stats {
+	stats := Stats{
+		ClientBuffer:      b.clientBuffer,
+		BroadcastBuffer:   cap(b.broadcast),
+		DroppedBroadcast:  b.droppedBroadcast.Load(),
+		DroppedSlowClient: b.droppedSlowClient.Load(),
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	stats.Clients = len(b.clients)
+	for _, client := range b.clients {
+		if dropped := client.Dropped(); dropped > 0 {
+			stats.SlowClients = append(stats.SlowClients, ClientStats{ID: client.ID, Dropped: dropped})
+		}
 	}
+	return stats
 }
 
 // Start begins the broadcaster event loop
@@ -63,7 +156,7 @@ func (b *Broadcaster) Start() {
 				b.mu.Lock()
 				b.clients[client.ID] = client
 				b.mu.Unlock()
-				log.Printf("SSE client registered: %s", client.ID)
+				slog.Info("SSE client registered", "client_id", client.ID)
 
 			case client := <-b.unregister:
 				b.mu.Lock()
@@ -72,30 +165,83 @@ func (b *Broadcaster) Start() {
 					delete(b.clients, client.ID)
 				}
 				b.mu.Unlock()
-				log.Printf("SSE client unregistered: %s", client.ID)
+				slog.Info("SSE client unregistered", "client_id", client.ID)
 
 			case event := <-b.broadcast:
-				b.mu.RLock()
-				for _, client := range b.clients {
-					select {
-					case client.Events <- event:
-						// Event sent successfully
-					default:
-						// Client is slow, skip this event
-						log.Printf("Skipping event for slow client: %s", client.ID)
-					}
-				}
-				b.mu.RUnlock()
+				b.deliver(event)
 			}
 		}
 	}()
 }
 
+// deliver hands event to every matching client. The client list is
+// snapshotted so the lock is not held while sending. Clients whose buffer is
+// momentarily full are retried until slowClientGrace has passed, after every
+// other client has been served, so a transient spike neither drops their
+// events nor holds up healthy clients.
+func (b *Broadcaster) deliver(event *Event) {
+	b.mu.RLock()
+	clients := make([]*Client, 0, len(b.clients))
+	for _, client := range b.clients {
+		if event.UserID != "" && event.UserID != client.UserID {
+			continue
+		}
+		clients = append(clients, client)
+	}
+	b.mu.RUnlock()
+
+	var busy []*Client
+	for _, client := range clients {
+		select {
+		case client.Events <- event:
+			// Event sent successfully
+		default:
+			busy = append(busy, client)
+		}
+	}
+	if len(busy) == 0 {
+		return
+	}
+
+	grace := time.NewTimer(slowClientGrace)
+	defer grace.Stop()
+	expired := false
+	for _, client := range busy {
+		if !expired {
+			select {
+			case client.Events <- event:
+				// Client caught up within the grace period
+				continue
+			case <-grace.C:
+				expired = true
+			}
+		} else {
+			select {
+			case client.Events <- event:
+				continue
+			default:
+			}
+		}
+
+		// Client is slow, skip this event
+		client.dropped.Add(1)
+		b.droppedSlowClient.Add(1)
+		slog.Warn("Skipping event for slow client", "client_id", client.ID, "event", event.Type)
+	}
+}
+
 // RegisterClient registers a new SSE client
 func (b *Broadcaster) RegisterClient(clientID string) *Client {
+	return b.RegisterUserClient(clientID, "")
+}
+
+// RegisterUserClient registers a new SSE client that also receives events
+// scoped to userID
+func (b *Broadcaster) RegisterUserClient(clientID, userID string) *Client {
 	client := &Client{
 		ID:     clientID,
-		Events: make(chan *Event, 10), // Buffer to handle bursts
+		UserID: userID,
+		Events: make(chan *Event, b.clientBuffer), // Buffer to handle bursts
 		Done:   make(chan bool),
 	}
 	b.register <- client
@@ -107,20 +253,44 @@ func (b *Broadcaster) UnregisterClient(client *Client) {
 	b.unregister <- client
 }
 
+// AddHook registers fn to be called with every event sent through
+// SendEvent or SendUserEvent, whether or not any client is connected. Hooks
+// run on the sender's goroutine and must not block.
+func (b *Broadcaster) AddHook(fn func(*Event)) {
+	b.mu.Lock()
+	b.hooks = append(b.hooks, fn)
+	b.mu.Unlock()
+}
+
 // SendEvent broadcasts an event to all connected clients
 func (b *Broadcaster) SendEvent(eventType EventType, entryID string, data interface{}) {
+	b.SendUserEvent("", eventType, entryID, data)
+}
+
+// SendUserEvent broadcasts an event to the clients of a single user. An empty
+// userID delivers the event to every client.
+func (b *Broadcaster) SendUserEvent(userID string, eventType EventType, entryID string, data interface{}) {
 	event := &Event{
 		Type:      string(eventType),
 		EntryID:   entryID,
 		Data:      data,
 		Timestamp: time.Now(),
+		UserID:    userID,
+	}
+
+	b.mu.RLock()
+	hooks := b.hooks
+	b.mu.RUnlock()
+	for _, hook := range hooks {
+		hook(event)
 	}
 
 	select {
 	case b.broadcast <- event:
 		// Event queued for broadcast
 	default:
-		log.Printf("Event broadcast channel full, dropping event")
+		b.droppedBroadcast.Add(1)
+		slog.Warn("Event broadcast channel full, dropping event", "event", event.Type)
 	}
 }
 
@@ -136,7 +306,8 @@ func (b *Broadcaster) Broadcast(eventType string, data interface{}) {
 	case b.broadcast <- event:
 		// Event queued for broadcast
 	default:
-		log.Printf("Event broadcast channel full, dropping event")
+		b.droppedBroadcast.Add(1)
+		slog.Warn("Event broadcast channel full, dropping event", "event", event.Type)
 	}
 }
 
@@ -0,0 +1,93 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlowClientDropsAreCounted(t *testing.T) {
+	b := NewBroadcaster().WithBuffers(1, 8)
+	b.Start()
+
+	client := b.RegisterClient("slow")
+	defer b.UnregisterClient(client)
+
+	for i := 0; i < 3; i++ {
+		b.SendEvent(EventEntryUpdated, "e1", nil)
+	}
+
+	// The first event fills the client's buffer; the other two are skipped
+	require.Eventually(t, func() bool { return b.Stats().DroppedSlowClient == 2 }, time.Second, time.Millisecond)
+
+	stats := b.Stats()
+	assert.Equal(t, 1, stats.Clients)
+	assert.Equal(t, int64(0), stats.DroppedBroadcast)
+	require.Len(t, stats.SlowClients, 1)
+	assert.Equal(t, ClientStats{ID: "slow", Dropped: 2}, stats.SlowClients[0])
+}
+
+func TestFullBroadcastChannelDropsAreCounted(t *testing.T) {
+	// Not started, so nothing drains the broadcast channel
+	b := NewBroadcaster().WithBuffers(1, 2)
+
+	for i := 0; i < 5; i++ {
+		b.SendEvent(EventEntryUpdated, "e1", nil)
+	}
+
+	assert.Equal(t, int64(3), b.Stats().DroppedBroadcast)
+	assert.Equal(t, 2, b.Stats().BroadcastBuffer)
+}
+
+func TestSlowReaderDoesNotStarveOtherClients(t *testing.T) {
+	b := NewBroadcaster().WithBuffers(1, 64)
+	b.Start()
+
+	slow := b.RegisterClient("slow") // Never reads
+	defer b.UnregisterClient(slow)
+	fast := b.RegisterClient("fast")
+	defer b.UnregisterClient(fast)
+
+	const n = 5
+	for i := 0; i < n; i++ {
+		b.SendEvent(EventEntryUpdated, "e1", i)
+	}
+
+	for i := 0; i < n; i++ {
+		select {
+		case event := <-fast.Events:
+			assert.Equal(t, i, event.Data)
+		case <-time.After(time.Second):
+			t.Fatalf("fast client received %d of %d events", i, n)
+		}
+	}
+
+	require.Eventually(t, func() bool { return slow.Dropped() == n-1 }, time.Second, time.Millisecond)
+	assert.Equal(t, int64(0), fast.Dropped())
+}
+
+func TestBusyClientCatchesUpWithinGrace(t *testing.T) {
+	b := NewBroadcaster().WithBuffers(1, 64)
+	b.Start()
+
+	busy := b.RegisterClient("busy")
+	defer b.UnregisterClient(busy)
+
+	b.SendEvent(EventEntryUpdated, "e1", 0)
+	b.SendEvent(EventEntryUpdated, "e1", 1)
+
+	// Drain after a pause shorter than the grace period; the second event
+	// waits for room instead of being dropped
+	time.Sleep(slowClientGrace / 5)
+	for i := 0; i < 2; i++ {
+		select {
+		case event := <-busy.Events:
+			assert.Equal(t, i, event.Data)
+		case <-time.After(time.Second):
+			t.Fatalf("busy client received %d of 2 events", i)
+		}
+	}
+	assert.Equal(t, int64(0), busy.Dropped())
+}
@@ -1,10 +1,10 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
-	"path/filepath"
 
 	"github.com/journal/internal/db"
 	"github.com/journal/internal/evaluation"
@@ -21,9 +21,9 @@ type EvaluationHandler struct {
 	journalService *service.JournalService
 }
 
-// NewEvaluationHandler creates a new evaluation handler
-func NewEvaluationHandler(database *db.DB, broadcaster *events.Broadcaster, journalService *service.JournalService) *EvaluationHandler {
-	outputDir := filepath.Join(".", "evaluation_results")
+// NewEvaluationHandler creates a new evaluation handler that writes test data
+// and reports under outputDir
+func NewEvaluationHandler(database *db.DB, broadcaster *events.Broadcaster, journalService *service.JournalService, outputDir string) *EvaluationHandler {
 	return &EvaluationHandler{
 		db:             database,
 		broadcaster:    broadcaster,
@@ -34,7 +34,8 @@ func NewEvaluationHandler(database *db.DB, broadcaster *events.Broadcaster, jour
 
 // GenerateTestDataParams contains parameters for generating test data
 type GenerateTestDataParams struct {
-	Size int `json:"size"`
+	Size   int  `json:"size"`
+	DryRun bool `json:"dry_run"` // Write test files without inserting entries
 }
 
 // GenerateTestDataResult contains the result of test data generation
@@ -45,7 +46,7 @@ type GenerateTestDataResult struct {
 }
 
 // GenerateTestData generates synthetic test data for evaluation
-func (h *EvaluationHandler) GenerateTestData(rawParams json.RawMessage) (interface{}, error) {
+func (h *EvaluationHandler) GenerateTestData(ctx context.Context, rawParams json.RawMessage) (interface{}, error) {
 	var params GenerateTestDataParams
 	if err := json.Unmarshal(rawParams, &params); err != nil {
 		return nil, fmt.Errorf("invalid params: %w", err)
@@ -57,11 +58,12 @@ func (h *EvaluationHandler) GenerateTestData(rawParams json.RawMessage) (interfa
 
 	// Broadcast start event
 	h.broadcaster.Broadcast("evaluation.generate.started", map[string]interface{}{
-		"size": params.Size,
+		"size":    params.Size,
+		"dry_run": params.DryRun,
 	})
 
 	// Generate test data
-	err := h.evaluator.GenerateTestData(params.Size)
+	err := h.evaluator.GenerateTestData(params.Size, params.DryRun)
 	if err != nil {
 		h.broadcaster.Broadcast("evaluation.generate.failed", map[string]interface{}{
 			"error": err.Error(),
@@ -83,7 +85,8 @@ func (h *EvaluationHandler) GenerateTestData(rawParams json.RawMessage) (interfa
 
 // RunEvaluationParams contains parameters for running evaluation
 type RunEvaluationParams struct {
-	Mode string `json:"mode"` // "all", "classic", "vector", or "hybrid"
+	Mode  string `json:"mode"`  // "all", "classic", "vector", or "hybrid"
+	Label string `json:"label"` // Optional run name kept in the metrics and file names
 }
 
 // RunEvaluationResult contains evaluation metrics
@@ -93,7 +96,7 @@ type RunEvaluationResult struct {
 }
 
 // RunEvaluation runs evaluation for specified search modes
-func (h *EvaluationHandler) RunEvaluation(rawParams json.RawMessage) (interface{}, error) {
+func (h *EvaluationHandler) RunEvaluation(ctx context.Context, rawParams json.RawMessage) (interface{}, error) {
 	var params RunEvaluationParams
 	if err := json.Unmarshal(rawParams, &params); err != nil {
 		return nil, fmt.Errorf("invalid params: %w", err)
@@ -102,10 +105,14 @@ func (h *EvaluationHandler) RunEvaluation(rawParams json.RawMessage) (interface{
 	if params.Mode == "" {
 		params.Mode = "all"
 	}
+	if err := evaluation.ValidateRunLabel(params.Label); err != nil {
+		return nil, service.Invalidf("%v", err)
+	}
 
 	// Broadcast start event
 	h.broadcaster.Broadcast("evaluation.run.started", map[string]interface{}{
-		"mode": params.Mode,
+		"mode":  params.Mode,
+		"label": params.Label,
 	})
 
 	// Track progress
@@ -130,7 +137,7 @@ func (h *EvaluationHandler) RunEvaluation(rawParams json.RawMessage) (interface{
 		})
 
 		log.Printf("Evaluating %s search...", mode)
-		metrics, err := h.evaluator.RunEvaluation(mode)
+		metrics, err := h.evaluator.RunEvaluation(mode, params.Label)
 		if err != nil {
 			h.broadcaster.Broadcast("evaluation.run.failed", map[string]interface{}{
 				"mode":  mode,
@@ -148,6 +155,7 @@ func (h *EvaluationHandler) RunEvaluation(rawParams json.RawMessage) (interface{
 	// Broadcast completion
 	h.broadcaster.Broadcast("evaluation.run.completed", map[string]interface{}{
 		"modes": modes,
+		"label": params.Label,
 	})
 
 	return RunEvaluationResult{
@@ -159,6 +167,7 @@ func (h *EvaluationHandler) RunEvaluation(rawParams json.RawMessage) (interface{
 // GenerateReportParams contains parameters for report generation
 type GenerateReportParams struct {
 	Format string `json:"format"` // "html", "json", or "csv"
+	Label  string `json:"label"`  // Report this run's metrics; empty uses the latest of any run
 }
 
 // GenerateReportResult contains the generated report
@@ -170,7 +179,7 @@ type GenerateReportResult struct {
 }
 
 // GenerateReport generates evaluation report in specified format
-func (h *EvaluationHandler) GenerateReport(rawParams json.RawMessage) (interface{}, error) {
+func (h *EvaluationHandler) GenerateReport(ctx context.Context, rawParams json.RawMessage) (interface{}, error) {
 	var params GenerateReportParams
 	if err := json.Unmarshal(rawParams, &params); err != nil {
 		return nil, fmt.Errorf("invalid params: %w", err)
@@ -180,8 +189,12 @@ func (h *EvaluationHandler) GenerateReport(rawParams json.RawMessage) (interface
 		params.Format = "html"
 	}
 
+	if err := evaluation.ValidateRunLabel(params.Label); err != nil {
+		return nil, service.Invalidf("%v", err)
+	}
+
 	// Generate report
-	reportPath, err := h.evaluator.GenerateReport(params.Format)
+	reportPath, err := h.evaluator.GenerateReport(params.Format, params.Label)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate report: %w", err)
 	}
@@ -194,7 +207,9 @@ func (h *EvaluationHandler) GenerateReport(rawParams json.RawMessage) (interface
 }
 
 // GetLatestResultsParams contains parameters for getting latest results
-type GetLatestResultsParams struct{}
+type GetLatestResultsParams struct {
+	Label string `json:"label"` // Limit to runs with this label
+}
 
 // GetLatestResultsResult contains the latest evaluation results
 type GetLatestResultsResult struct {
@@ -204,12 +219,19 @@ type GetLatestResultsResult struct {
 }
 
 // GetLatestResults retrieves the most recent evaluation results
-func (h *EvaluationHandler) GetLatestResults(rawParams json.RawMessage) (interface{}, error) {
+func (h *EvaluationHandler) GetLatestResults(ctx context.Context, rawParams json.RawMessage) (interface{}, error) {
+	var params GetLatestResultsParams
+	if len(rawParams) > 0 {
+		if err := json.Unmarshal(rawParams, &params); err != nil {
+			return nil, fmt.Errorf("invalid params: %w", err)
+		}
+	}
+
 	results := make(map[string]*evaluation.SearchMetrics)
 
 	// Try to load latest metrics for each mode
 	for _, mode := range []string{"classic", "vector", "hybrid"} {
-		metrics, err := h.evaluator.GetLatestMetrics(mode)
+		metrics, err := h.evaluator.GetLatestMetrics(mode, params.Label)
 		if err == nil {
 			results[mode] = metrics
 		}
@@ -224,7 +246,8 @@ func (h *EvaluationHandler) GetLatestResults(rawParams json.RawMessage) (interfa
 
 // RunFullEvaluationParams contains parameters for full evaluation
 type RunFullEvaluationParams struct {
-	Size int `json:"size"`
+	Size  int    `json:"size"`
+	Label string `json:"label"` // Optional run name kept in the metrics and file names
 }
 
 // RunFullEvaluationResult contains full evaluation results
@@ -236,7 +259,7 @@ type RunFullEvaluationResult struct {
 }
 
 // RunFullEvaluation runs complete evaluation pipeline
-func (h *EvaluationHandler) RunFullEvaluation(rawParams json.RawMessage) (interface{}, error) {
+func (h *EvaluationHandler) RunFullEvaluation(ctx context.Context, rawParams json.RawMessage) (interface{}, error) {
 	var params RunFullEvaluationParams
 	if err := json.Unmarshal(rawParams, &params); err != nil {
 		return nil, fmt.Errorf("invalid params: %w", err)
@@ -245,6 +268,9 @@ func (h *EvaluationHandler) RunFullEvaluation(rawParams json.RawMessage) (interf
 	if params.Size <= 0 {
 		params.Size = 100
 	}
+	if err := evaluation.ValidateRunLabel(params.Label); err != nil {
+		return nil, service.Invalidf("%v", err)
+	}
 
 	// Step 1: Generate test data
 	h.broadcaster.Broadcast("evaluation.full.progress", map[string]interface{}{
@@ -252,7 +278,7 @@ func (h *EvaluationHandler) RunFullEvaluation(rawParams json.RawMessage) (interf
 		"message": fmt.Sprintf("Generating %d test entries...", params.Size),
 	})
 
-	err := h.evaluator.GenerateTestData(params.Size)
+	err := h.evaluator.GenerateTestData(params.Size, false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate test data: %w", err)
 	}
@@ -263,7 +289,7 @@ func (h *EvaluationHandler) RunFullEvaluation(rawParams json.RawMessage) (interf
 		"message": "Running evaluation for all search modes...",
 	})
 
-	metrics, err := h.evaluator.RunEvaluation("all")
+	metrics, err := h.evaluator.RunEvaluation("all", params.Label)
 	if err != nil {
 		return nil, fmt.Errorf("failed to run evaluation: %w", err)
 	}
@@ -276,7 +302,7 @@ func (h *EvaluationHandler) RunFullEvaluation(rawParams json.RawMessage) (interf
 
 	reportPaths := make(map[string]string)
 	for _, format := range []string{"html", "json", "csv"} {
-		path, err := h.evaluator.GenerateReport(format)
+		path, err := h.evaluator.GenerateReport(format, params.Label)
 		if err != nil {
 			log.Printf("Warning: failed to generate %s report: %v", format, err)
 			continue
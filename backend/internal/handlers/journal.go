@@ -1,9 +1,14 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"strings"
+	"time"
 
+	"github.com/journal/internal/auth"
+	"github.com/journal/internal/logger"
+	"github.com/journal/internal/models"
 	"github.com/journal/internal/service"
 )
 
@@ -15,41 +20,113 @@ func NewJournalHandlers(service *service.JournalService) *JournalHandlers {
 	return &JournalHandlers{service: service}
 }
 
+// scoped returns the journal service bound to the authenticated user
+func (h *JournalHandlers) scoped(ctx context.Context) *service.JournalService {
+	return h.service.ForUser(auth.UserIDFromContext(ctx))
+}
+
 // CreateEntryParams for creating journal entries
 type CreateEntryParams struct {
-	Content string `json:"content"`
+	Content        string             `json:"content"`
+	IdempotencyKey string             `json:"idempotency_key,omitempty"`
+	Attachments    models.Attachments `json:"attachments,omitempty"`
+	// CreatedAt dates the entry in the past, e.g. when migrating an old
+	// journal; it must not be in the future. Defaults to now.
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	// Process runs AI analysis and embedding on the new entry (default
+	// true); false stores a draft that journal.process can analyze later
+	Process *bool `json:"process,omitempty"`
 }
 
-func (h *JournalHandlers) CreateEntry(params json.RawMessage) (interface{}, error) {
+func (h *JournalHandlers) CreateEntry(ctx context.Context, params json.RawMessage) (interface{}, error) {
 	var p CreateEntryParams
 	if err := json.Unmarshal(params, &p); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w", err)
+		return nil, service.Invalidf("invalid parameters: %v", err)
 	}
 
-	if p.Content == "" {
-		return nil, fmt.Errorf("content cannot be empty")
+	if strings.TrimSpace(p.Content) == "" {
+		return nil, service.Invalidf("content cannot be empty")
 	}
 
-	return h.service.CreateEntry(p.Content)
+	if err := service.ValidateAttachments(p.Attachments); err != nil {
+		return nil, err
+	}
+
+	var createdAt time.Time
+	if p.CreatedAt != nil {
+		createdAt = *p.CreatedAt
+	}
+
+	process := p.Process == nil || *p.Process
+
+	svc := h.scoped(ctx)
+	entry, err := svc.CreateEntryIdempotent(p.Content, p.IdempotencyKey, createdAt, process)
+	if err != nil || len(p.Attachments) == 0 {
+		return entry, err
+	}
+	return withAttachments(svc, entry, p.Attachments)
+}
+
+// withAttachments stores attachments on a just-written entry and returns it
+// with them set
+func withAttachments(svc *service.JournalService, entry *models.JournalEntry, attachments models.Attachments) (*models.JournalEntry, error) {
+	if err := svc.SetAttachments(entry.ID, attachments); err != nil {
+		return nil, err
+	}
+	entry.Attachments = attachments
+	return entry, nil
 }
 
 // UpdateEntryParams for updating journal entries
 type UpdateEntryParams struct {
 	ID      string `json:"id"`
 	Content string `json:"content"`
+	// Attachments replaces the entry's attachments when set; when omitted
+	// the new version keeps the original's
+	Attachments *models.Attachments `json:"attachments,omitempty"`
 }
 
-func (h *JournalHandlers) UpdateEntry(params json.RawMessage) (interface{}, error) {
+func (h *JournalHandlers) UpdateEntry(ctx context.Context, params json.RawMessage) (interface{}, error) {
 	var p UpdateEntryParams
 	if err := json.Unmarshal(params, &p); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w", err)
+		return nil, service.Invalidf("invalid parameters: %v", err)
 	}
 
 	if p.ID == "" || p.Content == "" {
-		return nil, fmt.Errorf("id and content are required")
+		return nil, service.Invalidf("id and content are required")
+	}
+
+	if p.Attachments != nil {
+		if err := service.ValidateAttachments(*p.Attachments); err != nil {
+			return nil, err
+		}
+	}
+
+	svc := h.scoped(ctx)
+	entry, err := svc.UpdateEntry(p.ID, p.Content)
+	if err != nil || p.Attachments == nil {
+		return entry, err
+	}
+	return withAttachments(svc, entry, *p.Attachments)
+}
+
+// AddAttachmentParams for attaching a file's metadata to an entry
+type AddAttachmentParams struct {
+	EntryID    string            `json:"entry_id"`
+	Attachment models.Attachment `json:"attachment"`
+}
+
+func (h *JournalHandlers) AddAttachment(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p AddAttachmentParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
 	}
 
-	return h.service.UpdateEntry(p.ID, p.Content)
+	if p.EntryID == "" {
+		return nil, service.Invalidf("entry_id is required")
+	}
+
+	return h.scoped(ctx).AddAttachment(p.EntryID, p.Attachment)
 }
 
 // GetEntryParams for retrieving a single entry
@@ -57,29 +134,167 @@ type GetEntryParams struct {
 	ID string `json:"id"`
 }
 
-func (h *JournalHandlers) GetEntry(params json.RawMessage) (interface{}, error) {
+func (h *JournalHandlers) GetEntry(ctx context.Context, params json.RawMessage) (interface{}, error) {
 	var p GetEntryParams
 	if err := json.Unmarshal(params, &p); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w", err)
+		return nil, service.Invalidf("invalid parameters: %v", err)
 	}
 
 	if p.ID == "" {
-		return nil, fmt.Errorf("id is required")
+		return nil, service.Invalidf("id is required")
+	}
+
+	return h.scoped(ctx).GetEntry(p.ID)
+}
+
+// GetEntriesParams for retrieving several entries at once
+type GetEntriesParams struct {
+	IDs []string `json:"ids"`
+}
+
+// GetEntries returns the requested entries in order, skipping unknown IDs
+func (h *JournalHandlers) GetEntries(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p GetEntriesParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if len(p.IDs) == 0 {
+		return nil, service.Invalidf("ids are required")
+	}
+
+	return h.scoped(ctx).GetEntries(p.IDs)
+}
+
+// GetEntryHistory returns every version of an entry, oldest first
+func (h *JournalHandlers) GetEntryHistory(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p GetEntryParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.ID == "" {
+		return nil, service.Invalidf("id is required")
+	}
+
+	return h.scoped(ctx).GetEntryHistory(p.ID)
+}
+
+// DeleteEntry deletes an entry; it can be restored until the grace period ends
+func (h *JournalHandlers) DeleteEntry(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p GetEntryParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.ID == "" {
+		return nil, service.Invalidf("id is required")
+	}
+
+	return h.scoped(ctx).DeleteEntry(p.ID)
+}
+
+// RestoreEntry undoes a delete that has not been purged yet
+func (h *JournalHandlers) RestoreEntry(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p GetEntryParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.ID == "" {
+		return nil, service.Invalidf("id is required")
+	}
+
+	return h.scoped(ctx).RestoreEntry(p.ID)
+}
+
+// GetRelatedEntriesParams for finding entries similar to a given entry
+type GetRelatedEntriesParams struct {
+	EntryID string `json:"entry_id"`
+	Limit   int    `json:"limit"`
+}
+
+func (h *JournalHandlers) GetRelatedEntries(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p GetRelatedEntriesParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.EntryID == "" {
+		return nil, service.Invalidf("entry_id is required")
+	}
+
+	return h.scoped(ctx).GetRelatedEntries(p.EntryID, p.Limit)
+}
+
+// OnThisDayParams for resurfacing entries from the same date in past years
+type OnThisDayParams struct {
+	Date *time.Time `json:"date"`
+}
+
+func (h *JournalHandlers) GetOnThisDay(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p OnThisDayParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, service.Invalidf("invalid parameters: %v", err)
+		}
+	}
+
+	var date time.Time
+	if p.Date != nil {
+		date = *p.Date
+	}
+
+	return h.scoped(ctx).GetOnThisDay(date)
+}
+
+// FindDuplicatesParams for detecting near-identical entries
+type FindDuplicatesParams struct {
+	Threshold float32 `json:"threshold"`
+}
+
+func (h *JournalHandlers) FindDuplicates(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p FindDuplicatesParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, service.Invalidf("invalid parameters: %v", err)
+		}
+	}
+
+	return h.scoped(ctx).FindDuplicates(p.Threshold)
+}
+
+// RestoreVersionParams for restoring an earlier version of an entry
+type RestoreVersionParams struct {
+	VersionID string `json:"version_id"`
+}
+
+func (h *JournalHandlers) RestoreVersion(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p RestoreVersionParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.VersionID == "" {
+		return nil, service.Invalidf("version_id is required")
 	}
 
-	return h.service.GetEntry(p.ID)
+	return h.scoped(ctx).RestoreVersion(p.VersionID)
 }
 
 // SearchParams wrapper
 type SearchParamsWrapper struct {
 	service.SearchParams
 	SearchType string `json:"search_type"` // "classic", "vector", "hybrid"
+	// Paginate returns classic results as {entries, next_cursor}; pass
+	// next_cursor back as after_cursor to fetch the following page
+	Paginate bool `json:"paginate"`
 }
 
-func (h *JournalHandlers) Search(params json.RawMessage) (interface{}, error) {
+func (h *JournalHandlers) Search(ctx context.Context, params json.RawMessage) (interface{}, error) {
 	var p SearchParamsWrapper
 	if err := json.Unmarshal(params, &p); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w", err)
+		return nil, service.Invalidf("invalid parameters: %v", err)
 	}
 
 	// Set defaults
@@ -89,21 +304,77 @@ func (h *JournalHandlers) Search(params json.RawMessage) (interface{}, error) {
 	if p.SearchType == "" {
 		p.SearchType = "classic"
 	}
+	if err := p.ApplyDateRange(time.Now()); err != nil {
+		return nil, err
+	}
+
+	svc := h.scoped(ctx)
 
+	var results []models.JournalEntry
+	var err error
 	switch p.SearchType {
 	case "classic":
-		return h.service.ClassicSearch(p.SearchParams)
+		if p.Paginate || p.AfterCursor != "" {
+			page, err := svc.ClassicSearchPage(p.SearchParams)
+			if err != nil {
+				return nil, err
+			}
+			svc.RecordSearch(p.Query, p.SearchType, len(page.Entries))
+			return page, nil
+		}
+		results, err = svc.ClassicSearch(p.SearchParams)
 	case "vector":
 		if p.Query == "" {
 			// Return empty array for empty query
 			return []interface{}{}, nil
 		}
-		return h.service.VectorSearch(p.SearchParams)
+		results, err = svc.VectorSearch(p.SearchParams)
 	case "hybrid":
-		return h.service.HybridSearch(p.SearchParams)
+		// Hybrid results are wrapped so the UI can tell when semantic
+		// search was unavailable
+		result, err := svc.HybridSearchResult(p.SearchParams)
+		if err != nil {
+			return nil, err
+		}
+		svc.RecordSearch(p.Query, p.SearchType, len(result.Entries))
+		return result, nil
 	default:
-		return nil, fmt.Errorf("invalid search_type: %s", p.SearchType)
+		return nil, service.Invalidf("invalid search_type: %s", p.SearchType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	svc.RecordSearch(p.Query, p.SearchType, len(results))
+	return results, nil
+}
+
+func (h *JournalHandlers) CountEntries(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p service.SearchParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+	if err := p.ApplyDateRange(time.Now()); err != nil {
+		return nil, err
+	}
+
+	count, err := h.scoped(ctx).CountEntries(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]int{"count": count}, nil
+}
+
+func (h *JournalHandlers) ClearSearchHistory(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	deleted, err := h.scoped(ctx).ClearSearchHistory()
+	if err != nil {
+		return nil, err
 	}
+
+	return map[string]interface{}{
+		"deleted": deleted,
+	}, nil
 }
 
 // ToggleFavoriteParams for toggling favorites
@@ -111,44 +382,274 @@ type ToggleFavoriteParams struct {
 	ID string `json:"id"`
 }
 
-func (h *JournalHandlers) ToggleFavorite(params json.RawMessage) (interface{}, error) {
+func (h *JournalHandlers) ToggleFavorite(ctx context.Context, params json.RawMessage) (interface{}, error) {
 	var p ToggleFavoriteParams
 	if err := json.Unmarshal(params, &p); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w", err)
+		return nil, service.Invalidf("invalid parameters: %v", err)
 	}
 
 	if p.ID == "" {
-		return nil, fmt.Errorf("id is required")
+		return nil, service.Invalidf("id is required")
 	}
 
-	if err := h.service.ToggleFavorite(p.ID); err != nil {
+	if err := h.scoped(ctx).ToggleFavorite(p.ID); err != nil {
 		return nil, err
 	}
 
 	return map[string]string{"status": "success"}, nil
 }
 
+// BatchFavoriteParams for setting the favorite flag of several entries
+type BatchFavoriteParams struct {
+	IDs      []string `json:"ids"`
+	Favorite bool     `json:"favorite"`
+}
+
+func (h *JournalHandlers) BatchFavorite(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p BatchFavoriteParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	updated, err := h.scoped(ctx).BatchSetFavorite(p.IDs, p.Favorite)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]int{"updated": updated}, nil
+}
+
+// TogglePinParams for pinning entries to the top of the timeline
+type TogglePinParams struct {
+	ID string `json:"id"`
+}
+
+func (h *JournalHandlers) TogglePin(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p TogglePinParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.ID == "" {
+		return nil, service.Invalidf("id is required")
+	}
+
+	pinnedAt, err := h.scoped(ctx).TogglePin(p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"pinned":    pinnedAt != nil,
+		"pinned_at": pinnedAt,
+	}, nil
+}
+
+// MergeEntriesParams for merging duplicate entries
+type MergeEntriesParams struct {
+	KeepID   string   `json:"keep_id"`
+	MergeIDs []string `json:"merge_ids"`
+}
+
+func (h *JournalHandlers) MergeEntries(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p MergeEntriesParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.KeepID == "" || len(p.MergeIDs) == 0 {
+		return nil, service.Invalidf("keep_id and merge_ids are required")
+	}
+
+	return h.scoped(ctx).MergeEntries(p.KeepID, p.MergeIDs)
+}
+
 // Collection handlers
 type CreateCollectionParams struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
 }
 
-func (h *JournalHandlers) CreateCollection(params json.RawMessage) (interface{}, error) {
+func (h *JournalHandlers) CreateCollection(ctx context.Context, params json.RawMessage) (interface{}, error) {
 	var p CreateCollectionParams
 	if err := json.Unmarshal(params, &p); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w", err)
+		return nil, service.Invalidf("invalid parameters: %v", err)
 	}
 
 	if p.Name == "" {
-		return nil, fmt.Errorf("name is required")
+		return nil, service.Invalidf("name is required")
+	}
+
+	return h.scoped(ctx).CreateCollection(p.Name, p.Description)
+}
+
+// CreateSmartCollectionParams for creating a collection backed by a saved search
+type CreateSmartCollectionParams struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Query       service.SearchParams `json:"query_params"`
+}
+
+func (h *JournalHandlers) CreateSmartCollection(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p CreateSmartCollectionParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.Name == "" {
+		return nil, service.Invalidf("name is required")
+	}
+
+	return h.scoped(ctx).CreateSmartCollection(p.Name, p.Description, p.Query)
+}
+
+// GetCollectionEntriesParams for listing the entries in a collection
+type GetCollectionEntriesParams struct {
+	CollectionID string `json:"collection_id"`
+	Limit        int    `json:"limit"`
+	Offset       int    `json:"offset"`
+}
+
+func (h *JournalHandlers) GetCollectionEntries(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p GetCollectionEntriesParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.CollectionID == "" {
+		return nil, service.Invalidf("collection_id is required")
+	}
+
+	return h.scoped(ctx).GetCollectionEntries(p.CollectionID, p.Limit, p.Offset)
+}
+
+// ExportCollectionParams for exporting a single collection
+type ExportCollectionParams struct {
+	CollectionID string `json:"collection_id"`
+	Format       string `json:"format"` // json, markdown or csv (default markdown)
+
+	// MaxContentLength truncates markdown and csv content when positive
+	MaxContentLength int `json:"max_content_length,omitempty"`
+}
+
+func (h *JournalHandlers) ExportCollection(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p ExportCollectionParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.CollectionID == "" {
+		return nil, service.Invalidf("collection_id is required")
+	}
+	if p.Format == "" {
+		p.Format = "markdown"
+	}
+	if p.MaxContentLength < 0 {
+		return nil, service.Invalidf("max_content_length must not be negative")
+	}
+
+	data, contentType, filename, err := h.scoped(ctx).ExportCollection(p.CollectionID, p.Format, p.MaxContentLength)
+	if err != nil {
+		return nil, err
 	}
 
-	return h.service.CreateCollection(p.Name, p.Description)
+	return map[string]interface{}{
+		"filename":     filename,
+		"content_type": contentType,
+		"content":      string(data),
+	}, nil
 }
 
-func (h *JournalHandlers) GetCollections(params json.RawMessage) (interface{}, error) {
-	return h.service.GetCollections()
+// ExportEntryParams for exporting a single entry
+type ExportEntryParams struct {
+	ID string `json:"id"`
+}
+
+func (h *JournalHandlers) ExportEntry(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p ExportEntryParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.ID == "" {
+		return nil, service.Invalidf("id is required")
+	}
+
+	data, filename, err := h.scoped(ctx).ExportEntry(p.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"filename":     filename,
+		"content_type": "text/markdown",
+		"content":      string(data),
+	}, nil
+}
+
+func (h *JournalHandlers) GetCollections(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	return h.scoped(ctx).GetCollections()
+}
+
+// SuggestCollectionsParams for suggesting collections for an entry
+type SuggestCollectionsParams struct {
+	EntryID  string  `json:"entry_id"`
+	MinScore float64 `json:"min_score"`
+}
+
+func (h *JournalHandlers) SuggestCollections(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p SuggestCollectionsParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.EntryID == "" {
+		return nil, service.Invalidf("entry_id is required")
+	}
+
+	return h.scoped(ctx).SuggestCollections(p.EntryID, p.MinScore)
+}
+
+// UpdateCollectionParams for renaming a collection
+type UpdateCollectionParams struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (h *JournalHandlers) UpdateCollection(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p UpdateCollectionParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.ID == "" || p.Name == "" {
+		return nil, service.Invalidf("id and name are required")
+	}
+
+	return h.scoped(ctx).UpdateCollection(p.ID, p.Name, p.Description)
+}
+
+// DeleteCollectionParams for deleting a collection
+type DeleteCollectionParams struct {
+	ID string `json:"id"`
+}
+
+func (h *JournalHandlers) DeleteCollection(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p DeleteCollectionParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.ID == "" {
+		return nil, service.Invalidf("id is required")
+	}
+
+	if err := h.scoped(ctx).DeleteCollection(p.ID); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"status": "success"}, nil
 }
 
 type CollectionOperationParams struct {
@@ -156,56 +657,115 @@ type CollectionOperationParams struct {
 	CollectionID string `json:"collection_id"`
 }
 
-func (h *JournalHandlers) AddToCollection(params json.RawMessage) (interface{}, error) {
+func (h *JournalHandlers) AddToCollection(ctx context.Context, params json.RawMessage) (interface{}, error) {
 	var p CollectionOperationParams
 	if err := json.Unmarshal(params, &p); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w", err)
+		return nil, service.Invalidf("invalid parameters: %v", err)
 	}
 
 	if p.EntryID == "" || p.CollectionID == "" {
-		return nil, fmt.Errorf("entry_id and collection_id are required")
+		return nil, service.Invalidf("entry_id and collection_id are required")
 	}
 
-	if err := h.service.AddToCollection(p.EntryID, p.CollectionID); err != nil {
+	if err := h.scoped(ctx).AddToCollection(p.EntryID, p.CollectionID); err != nil {
 		return nil, err
 	}
 
 	return map[string]string{"status": "success"}, nil
 }
 
-func (h *JournalHandlers) RemoveFromCollection(params json.RawMessage) (interface{}, error) {
+func (h *JournalHandlers) RemoveFromCollection(ctx context.Context, params json.RawMessage) (interface{}, error) {
 	var p CollectionOperationParams
 	if err := json.Unmarshal(params, &p); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w", err)
+		return nil, service.Invalidf("invalid parameters: %v", err)
 	}
 
 	if p.EntryID == "" || p.CollectionID == "" {
-		return nil, fmt.Errorf("entry_id and collection_id are required")
+		return nil, service.Invalidf("entry_id and collection_id are required")
 	}
 
-	if err := h.service.RemoveFromCollection(p.EntryID, p.CollectionID); err != nil {
+	if err := h.scoped(ctx).RemoveFromCollection(p.EntryID, p.CollectionID); err != nil {
 		return nil, err
 	}
 
 	return map[string]string{"status": "success"}, nil
 }
 
+// BatchCollectionParams for adding or removing several entries at once
+type BatchCollectionParams struct {
+	CollectionID string   `json:"collection_id"`
+	EntryIDs     []string `json:"entry_ids"`
+}
+
+func (h *JournalHandlers) BatchAddToCollection(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p BatchCollectionParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.CollectionID == "" {
+		return nil, service.Invalidf("collection_id is required")
+	}
+
+	updated, err := h.scoped(ctx).BatchAddToCollection(p.CollectionID, p.EntryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]int{"updated": updated}, nil
+}
+
+func (h *JournalHandlers) BatchRemoveFromCollection(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p BatchCollectionParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.CollectionID == "" {
+		return nil, service.Invalidf("collection_id is required")
+	}
+
+	updated, err := h.scoped(ctx).BatchRemoveFromCollection(p.CollectionID, p.EntryIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]int{"updated": updated}, nil
+}
+
 // GetProcessingLogsParams for retrieving processing logs
 type GetProcessingLogsParams struct {
-	EntryID string `json:"entry_id"`
+	EntryID string     `json:"entry_id"`
+	Level   string     `json:"level"` // Minimum level: "warn" returns warn and error
+	Stage   string     `json:"stage"`
+	Since   *time.Time `json:"since"`
+	Limit   int        `json:"limit"` // 0 returns every matching log
+	Offset  int        `json:"offset"`
+	Newest  bool       `json:"newest"` // Newest first; the default is oldest first
 }
 
-func (h *JournalHandlers) GetProcessingLogs(params json.RawMessage) (interface{}, error) {
+func (h *JournalHandlers) GetProcessingLogs(ctx context.Context, params json.RawMessage) (interface{}, error) {
 	var p GetProcessingLogsParams
 	if err := json.Unmarshal(params, &p); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w", err)
+		return nil, service.Invalidf("invalid parameters: %v", err)
 	}
 
 	if p.EntryID == "" {
-		return nil, fmt.Errorf("entry_id is required")
+		return nil, service.Invalidf("entry_id is required")
+	}
+
+	if p.Limit < 0 || p.Offset < 0 {
+		return nil, service.Invalidf("limit and offset must not be negative")
 	}
 
-	logs, err := h.service.GetProcessingLogs(p.EntryID)
+	logs, err := h.scoped(ctx).GetProcessingLogs(p.EntryID, logger.LogFilter{
+		MinLevel: p.Level,
+		Stage:    models.ProcessingStage(p.Stage),
+		Since:    p.Since,
+		Limit:    p.Limit,
+		Offset:   p.Offset,
+		Newest:   p.Newest,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -213,22 +773,108 @@ func (h *JournalHandlers) GetProcessingLogs(params json.RawMessage) (interface{}
 	return logs, nil
 }
 
+// PurgeLogsParams for deleting old processing logs
+type PurgeLogsParams struct {
+	OlderThanDays int `json:"older_than_days"`
+}
+
+func (h *JournalHandlers) PurgeLogs(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p PurgeLogsParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, service.Invalidf("invalid parameters: %v", err)
+		}
+	}
+
+	if p.OlderThanDays <= 0 {
+		return nil, service.Invalidf("older_than_days must be a positive number of days")
+	}
+
+	deleted, err := h.scoped(ctx).PurgeProcessingLogs(time.Duration(p.OlderThanDays) * 24 * time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{"status": "success", "deleted": deleted}, nil
+}
+
+func (h *JournalHandlers) CleanOrphanedAssociations(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	cleaned, err := h.service.CleanOrphanedAssociations()
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{"status": "success", "cleaned": cleaned}, nil
+}
+
+// ListByStageParams for listing entries in one processing stage
+type ListByStageParams struct {
+	Stage models.ProcessingStage `json:"stage"`
+	Limit int                    `json:"limit"`
+}
+
+func (h *JournalHandlers) ListByStage(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p ListByStageParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.Stage == "" {
+		return nil, service.Invalidf("stage is required")
+	}
+
+	return h.scoped(ctx).GetEntriesByStage(p.Stage, p.Limit)
+}
+
+// ListLowQualityParams for listing entries whose analysis looks thin
+type ListLowQualityParams struct {
+	Threshold float64 `json:"threshold,omitempty"`
+	Limit     int     `json:"limit"`
+}
+
+func (h *JournalHandlers) ListLowQuality(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p ListLowQualityParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, service.Invalidf("invalid parameters: %v", err)
+		}
+	}
+
+	return h.scoped(ctx).ListLowQuality(p.Threshold, p.Limit)
+}
+
+// AnalyzeAllFailuresParams for summarizing every failed entry
+type AnalyzeAllFailuresParams struct {
+	UseAI bool `json:"use_ai"` // refine each entry's causes with the model (slow)
+}
+
+func (h *JournalHandlers) AnalyzeAllFailures(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p AnalyzeAllFailuresParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, service.Invalidf("invalid parameters: %v", err)
+		}
+	}
+
+	return h.scoped(ctx).AnalyzeAllFailures(p.UseAI)
+}
+
 // AnalyzeFailureParams for analyzing processing failures
 type AnalyzeFailureParams struct {
 	EntryID string `json:"entry_id"`
 }
 
-func (h *JournalHandlers) AnalyzeFailure(params json.RawMessage) (interface{}, error) {
+func (h *JournalHandlers) AnalyzeFailure(ctx context.Context, params json.RawMessage) (interface{}, error) {
 	var p AnalyzeFailureParams
 	if err := json.Unmarshal(params, &p); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w", err)
+		return nil, service.Invalidf("invalid parameters: %v", err)
 	}
 
 	if p.EntryID == "" {
-		return nil, fmt.Errorf("entry_id is required")
+		return nil, service.Invalidf("entry_id is required")
 	}
 
-	analysis, err := h.service.AnalyzeFailure(p.EntryID)
+	analysis, err := h.scoped(ctx).AnalyzeFailure(p.EntryID)
 	if err != nil {
 		return nil, err
 	}
@@ -241,23 +887,172 @@ type RetryProcessingParams struct {
 	EntryID string `json:"entry_id"`
 }
 
-func (h *JournalHandlers) RetryProcessing(params json.RawMessage) (interface{}, error) {
+func (h *JournalHandlers) RetryProcessing(ctx context.Context, params json.RawMessage) (interface{}, error) {
 	var p RetryProcessingParams
 	if err := json.Unmarshal(params, &p); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w", err)
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.EntryID == "" {
+		return nil, service.Invalidf("entry_id is required")
+	}
+
+	if err := h.scoped(ctx).RetryProcessing(p.EntryID); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"status": "processing"}, nil
+}
+
+// CancelProcessingParams for stopping an entry's processing
+type CancelProcessingParams struct {
+	EntryID string `json:"entry_id"`
+}
+
+func (h *JournalHandlers) CancelProcessing(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p CancelProcessingParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.EntryID == "" {
+		return nil, service.Invalidf("entry_id is required")
+	}
+
+	if err := h.scoped(ctx).CancelProcessing(p.EntryID); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"status": "cancelled"}, nil
+}
+
+// ProcessDraftParams for analyzing a draft
+type ProcessDraftParams struct {
+	EntryID string `json:"entry_id"`
+}
+
+func (h *JournalHandlers) ProcessDraft(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p ProcessDraftParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
 	}
 
 	if p.EntryID == "" {
-		return nil, fmt.Errorf("entry_id is required")
+		return nil, service.Invalidf("entry_id is required")
 	}
 
-	if err := h.service.RetryProcessing(p.EntryID); err != nil {
+	if err := h.scoped(ctx).ProcessDraft(p.EntryID); err != nil {
 		return nil, err
 	}
 
 	return map[string]string{"status": "processing"}, nil
 }
 
-func (h *JournalHandlers) GetSearchSuggestions(params json.RawMessage) (interface{}, error) {
-	return h.service.GetSearchSuggestions()
+// ReprocessEntryParams for re-running the pipeline on one entry. Confirm
+// must be set because the entry's current analysis is replaced.
+type ReprocessEntryParams struct {
+	EntryID string `json:"entry_id"`
+	Confirm bool   `json:"confirm"`
+}
+
+func (h *JournalHandlers) ReprocessEntry(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p ReprocessEntryParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	if p.EntryID == "" {
+		return nil, service.Invalidf("entry_id is required")
+	}
+	if !p.Confirm {
+		return nil, service.Invalidf("confirm must be true: reprocessing replaces the entry's analysis")
+	}
+
+	if err := h.scoped(ctx).ReprocessEntry(p.EntryID); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"status": "processing"}, nil
+}
+
+// ReprocessAll re-runs the full pipeline over every entry matching the
+// filter in the background; progress arrives as reprocess.progress events
+func (h *JournalHandlers) ReprocessAll(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var filter service.ReprocessFilter
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &filter); err != nil {
+			return nil, service.Invalidf("invalid parameters: %v", err)
+		}
+	}
+
+	return h.scoped(ctx).ReprocessAll(filter)
+}
+
+func (h *JournalHandlers) CancelReprocess(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	if err := h.scoped(ctx).CancelReprocess(); err != nil {
+		return nil, err
+	}
+
+	return map[string]string{"status": "cancelled"}, nil
+}
+
+func (h *JournalHandlers) GetSearchSuggestions(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	return h.scoped(ctx).GetSearchSuggestions()
+}
+
+// GetFacetParams selects the topics or entities to list for autocomplete
+type GetFacetParams struct {
+	Kind   string `json:"kind"`
+	Prefix string `json:"prefix,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+func (h *JournalHandlers) GetFacet(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p GetFacetParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, service.Invalidf("invalid parameters: %v", err)
+	}
+
+	return h.scoped(ctx).GetFacet(p.Kind, p.Prefix, p.Limit)
+}
+
+func (h *JournalHandlers) GetAnalytics(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p service.AnalyticsParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, service.Invalidf("invalid parameters: %v", err)
+		}
+	}
+
+	return h.scoped(ctx).GetAnalytics(p)
+}
+
+// GetSentimentTrendParams selects the range and bucket size of the mood
+// chart; omitted dates leave the range open
+type GetSentimentTrendParams struct {
+	StartDate   *time.Time `json:"start_date,omitempty"`
+	EndDate     *time.Time `json:"end_date,omitempty"`
+	Granularity string     `json:"granularity,omitempty"`
+}
+
+func (h *JournalHandlers) GetSentimentTrend(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p GetSentimentTrendParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, service.Invalidf("invalid parameters: %v", err)
+		}
+	}
+
+	var start, end time.Time
+	if p.StartDate != nil {
+		start = *p.StartDate
+	}
+	if p.EndDate != nil {
+		end = *p.EndDate
+	}
+	return h.scoped(ctx).GetSentimentTrend(start, end, p.Granularity)
+}
+
+func (h *JournalHandlers) GetTopicGraph(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	return h.scoped(ctx).GetTopicGraph()
 }
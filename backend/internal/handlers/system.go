@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"runtime"
+
+	"github.com/journal/internal/buildinfo"
+	"github.com/journal/internal/db"
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/mcp"
+	"github.com/journal/internal/ollama"
+)
+
+// SystemHandlers reports on the server build and its dependencies
+type SystemHandlers struct {
+	ollamaClient *ollama.Client
+	mcpClient    *mcp.Client
+	broadcaster  *events.Broadcaster
+}
+
+func NewSystemHandlers(ollamaClient *ollama.Client, mcpClient *mcp.Client, broadcaster *events.Broadcaster) *SystemHandlers {
+	return &SystemHandlers{
+		ollamaClient: ollamaClient,
+		mcpClient:    mcpClient,
+		broadcaster:  broadcaster,
+	}
+}
+
+// DependencyStatus reports whether a service the server relies on answered
+type DependencyStatus struct {
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ModelStatus reports whether a configured Ollama model is installed
+type ModelStatus struct {
+	Name      string `json:"name"`
+	Installed bool   `json:"installed"`
+}
+
+type SystemInfo struct {
+	Version         string           `json:"version"`
+	Commit          string           `json:"commit"`
+	BuildDate       string           `json:"build_date"`
+	GoVersion       string           `json:"go_version"`
+	SchemaVersion   int              `json:"schema_version"`
+	SchemaMigration string           `json:"schema_migration"`
+	ChatModel       ModelStatus      `json:"chat_model"`
+	EmbeddingModel  ModelStatus      `json:"embedding_model"`
+	Ollama          DependencyStatus `json:"ollama"`
+	MCPAgent        DependencyStatus `json:"mcp_agent"`
+	Events          events.Stats     `json:"events"`
+}
+
+// Info returns build metadata, the schema version, whether Ollama, its
+// models and the MCP agent are available, and SSE event loss counters
+func (h *SystemHandlers) Info(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	schemaVersion, schemaMigration := db.SchemaVersion()
+	info := SystemInfo{
+		Version:         buildinfo.Version,
+		Commit:          buildinfo.Commit,
+		BuildDate:       buildinfo.BuildDate,
+		GoVersion:       runtime.Version(),
+		SchemaVersion:   schemaVersion,
+		SchemaMigration: schemaMigration,
+		ChatModel:       ModelStatus{Name: ollama.ChatModel},
+		EmbeddingModel:  ModelStatus{Name: ollama.EmbeddingModel},
+		Events:          h.broadcaster.Stats(),
+	}
+
+	if models, err := h.ollamaClient.ListModels(ctx); err != nil {
+		info.Ollama.Error = err.Error()
+	} else {
+		info.Ollama.Reachable = true
+		info.ChatModel.Installed = ollama.HasModel(models, ollama.ChatModel)
+		info.EmbeddingModel.Installed = ollama.HasModel(models, ollama.EmbeddingModel)
+	}
+
+	if err := h.mcpClient.Health(ctx); err != nil {
+		info.MCPAgent.Error = err.Error()
+	} else {
+		info.MCPAgent.Reachable = true
+	}
+
+	return info, nil
+}
@@ -1,10 +1,24 @@
 package jsonrpc
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+
+	"github.com/journal/internal/service"
+)
+
+// Application error codes, from the implementation-defined server error
+// range. Validation errors use the standard invalid params code.
+const (
+	CodeInvalidParams      = -32602
+	CodeServerError        = -32000
+	CodeNotFound           = -32001
+	CodeConflict           = -32002
+	CodeServiceUnavailable = -32003
 )
 
 type Request struct {
@@ -27,7 +41,9 @@ type Error struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
-type Handler func(params json.RawMessage) (interface{}, error)
+// Handler processes a single JSON-RPC call. The context is the HTTP request
+// context, carrying request-scoped values such as the authenticated user.
+type Handler func(ctx context.Context, params json.RawMessage) (interface{}, error)
 
 type Server struct {
 	handlers map[string]Handler
@@ -66,16 +82,34 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := handler(req.Params)
+	result, err := handler(r.Context(), req.Params)
 	if err != nil {
 		log.Printf("Error in method %s: %v", req.Method, err)
-		s.writeError(w, req.ID, -32000, "Server error", err.Error())
+		code, message := errorCode(err)
+		s.writeError(w, req.ID, code, message, err.Error())
 		return
 	}
 
 	s.writeResult(w, req.ID, result)
 }
 
+// errorCode maps a handler error to its JSON-RPC code and message. Errors
+// that carry no service error class are reported as generic server errors.
+func errorCode(err error) (int, string) {
+	switch {
+	case errors.Is(err, service.ErrValidation):
+		return CodeInvalidParams, "Invalid params"
+	case errors.Is(err, service.ErrNotFound):
+		return CodeNotFound, "Not found"
+	case errors.Is(err, service.ErrConflict):
+		return CodeConflict, "Conflict"
+	case errors.Is(err, service.ErrServiceUnavailable):
+		return CodeServiceUnavailable, "Service unavailable"
+	default:
+		return CodeServerError, "Server error"
+	}
+}
+
 func (s *Server) writeError(w http.ResponseWriter, id interface{}, code int, message string, data interface{}) {
 	resp := Response{
 		JSONRPC: "2.0",
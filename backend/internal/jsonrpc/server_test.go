@@ -0,0 +1,51 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/journal/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeHTTPErrorCodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		code    int
+		message string
+	}{
+		{"not found", service.NotFoundf("entry not found"), CodeNotFound, "Not found"},
+		{"wrapped not found", fmt.Errorf("failed to load: %w", service.NotFoundf("entry not found")), CodeNotFound, "Not found"},
+		{"validation", service.Invalidf("id is required"), CodeInvalidParams, "Invalid params"},
+		{"conflict", service.Conflictf("a collection named %q already exists", "Work"), CodeConflict, "Conflict"},
+		{"unavailable", service.Unavailablef("Ollama service is not running"), CodeServiceUnavailable, "Service unavailable"},
+		{"untyped", fmt.Errorf("boom"), CodeServerError, "Server error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer()
+			server.RegisterMethod("test.fail", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+				return nil, tt.err
+			})
+
+			body := `{"jsonrpc":"2.0","method":"test.fail","id":1}`
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(body)))
+
+			var resp Response
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+			require.NotNil(t, resp.Error)
+			assert.Equal(t, tt.code, resp.Error.Code)
+			assert.Equal(t, tt.message, resp.Error.Message)
+			assert.Equal(t, tt.err.Error(), resp.Error.Data)
+		})
+	}
+}
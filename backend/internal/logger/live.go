@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/models"
+)
+
+// liveLogs forwards log entries to SSE clients as entry.log events. Events
+// below minLevel are never sent, and info/debug events are throttled per
+// entry so a chatty stage cannot flood slow clients. Warnings and errors are
+// always forwarded.
+type liveLogs struct {
+	broadcaster *events.Broadcaster
+	minLevel    int
+	interval    time.Duration
+	lastSent    map[string]time.Time
+	mu          sync.Mutex
+}
+
+// EnableLiveLogs streams log entries at or above minLevel through the
+// broadcaster, sending at most one info/debug event per entry per interval.
+// Call it once at startup before any entries are processed.
+func (pl *ProcessingLogger) EnableLiveLogs(broadcaster *events.Broadcaster, minLevel string, interval time.Duration) error {
+	levels, err := levelsAtOrAbove(minLevel)
+	if err != nil {
+		return err
+	}
+
+	pl.live = &liveLogs{
+		broadcaster: broadcaster,
+		minLevel:    len(logLevels) - len(levels),
+		interval:    interval,
+		lastSent:    make(map[string]time.Time),
+	}
+	return nil
+}
+
+// publish sends a log entry if it passes the level and throttle checks
+func (l *liveLogs) publish(logEntry models.ProcessingLog) {
+	rank := levelRank(logEntry.Level)
+	if rank < l.minLevel {
+		return
+	}
+
+	if rank < levelRank("warn") {
+		l.mu.Lock()
+		last, seen := l.lastSent[logEntry.EntryID]
+		if seen && logEntry.CreatedAt.Sub(last) < l.interval {
+			l.mu.Unlock()
+			return
+		}
+		l.lastSent[logEntry.EntryID] = logEntry.CreatedAt
+		l.mu.Unlock()
+	}
+
+	l.broadcaster.SendEvent(events.EventEntryLog, logEntry.EntryID, map[string]interface{}{
+		"level":      logEntry.Level,
+		"stage":      logEntry.Stage,
+		"message":    logEntry.Message,
+		"details":    logEntry.Details,
+		"created_at": logEntry.CreatedAt,
+	})
+}
+
+// forget drops throttle state for an entry that finished processing
+func (l *liveLogs) forget(entryID string) {
+	l.mu.Lock()
+	delete(l.lastSent, entryID)
+	l.mu.Unlock()
+}
+
+// levelRank returns the severity index of a level, or -1 if unknown
+func levelRank(level string) int {
+	for i, l := range logLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
@@ -3,12 +3,14 @@ package logger
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 
 	"github.com/journal/internal/models"
+	"github.com/lib/pq"
 )
 
 // ProcessingLogger handles logging for journal entry processing stages
@@ -16,6 +18,7 @@ type ProcessingLogger struct {
 	db      *sql.DB
 	buffers map[string]*LogBuffer
 	mu      sync.RWMutex
+	live    *liveLogs // nil unless EnableLiveLogs was called
 }
 
 // LogBuffer temporarily stores logs before batch insertion
@@ -74,6 +77,10 @@ func (pl *ProcessingLogger) log(entryID string, stage models.ProcessingStage, le
 	log.Printf("[%s] Entry %s - Stage: %s - %s: %s %s",
 		level, entryID, stage, level, message, string(detailsJSON))
 
+	if pl.live != nil {
+		pl.live.publish(logEntry)
+	}
+
 	// Add to buffer
 	pl.mu.Lock()
 	buffer, exists := pl.buffers[entryID]
@@ -96,7 +103,9 @@ func (pl *ProcessingLogger) log(entryID string, stage models.ProcessingStage, le
 	}
 }
 
-// UpdateStage updates the processing stage and logs the transition
+// UpdateStage updates the processing stage and logs the transition. A
+// cancelled entry keeps its stage, so a pipeline still winding down cannot
+// move it on.
 func (pl *ProcessingLogger) UpdateStage(entryID string, stage models.ProcessingStage) error {
 	// Log the stage transition
 	pl.LogInfo(entryID, stage, fmt.Sprintf("Transitioning to stage: %s", stage), nil)
@@ -105,7 +114,7 @@ func (pl *ProcessingLogger) UpdateStage(entryID string, stage models.ProcessingS
 	query := `
 		UPDATE journal_entries 
 		SET processing_stage = $1, updated_at = $2
-		WHERE id = $3`
+		WHERE id = $3 AND processing_stage <> 'cancelled'`
 
 	_, err := pl.db.Exec(query, stage, time.Now(), entryID)
 	if err != nil {
@@ -120,28 +129,51 @@ func (pl *ProcessingLogger) UpdateStage(entryID string, stage models.ProcessingS
 		// Mark processing as started
 		query = `UPDATE journal_entries SET processing_started_at = $1 WHERE id = $2`
 		_, err = pl.db.Exec(query, time.Now(), entryID)
-	} else if stage == models.StageCompleted || stage == models.StageFailed {
+	} else if stage == models.StageCompleted || stage == models.StageCompletedNoEmbedding || stage == models.StageFailed {
 		// Mark processing as completed
 		query = `UPDATE journal_entries SET processing_completed_at = $1 WHERE id = $2`
 		_, err = pl.db.Exec(query, time.Now(), entryID)
+
+		if pl.live != nil {
+			pl.live.forget(entryID)
+		}
 	}
 
 	return err
 }
 
-// SetError sets the processing error for an entry
+// SetError sets the processing error for an entry. Cancelled entries are
+// left cancelled rather than marked failed.
 func (pl *ProcessingLogger) SetError(entryID string, stage models.ProcessingStage, err error) error {
-	pl.LogError(entryID, stage, "Processing failed", map[string]interface{}{
+	details := map[string]interface{}{
 		"error": err.Error(),
-	})
+	}
+	// Timeouts are recorded explicitly so failure analysis can tell them
+	// apart from the service being down
+	var timeout interface{ Timeout() bool }
+	if errors.As(err, &timeout) && timeout.Timeout() {
+		details["error_type"] = "timeout"
+	}
+	// Likewise a missing Ollama model, so the fix can name it precisely
+	var missing interface{ MissingModel() string }
+	if errors.As(err, &missing) {
+		details["error_type"] = "model_not_found"
+		details["model"] = missing.MissingModel()
+	}
+	pl.LogError(entryID, stage, "Processing failed", details)
 
 	// Update the database
 	query := `
 		UPDATE journal_entries 
 		SET processing_stage = $1, processing_error = $2, processing_completed_at = $3
-		WHERE id = $4`
+		WHERE id = $4 AND processing_stage <> 'cancelled'`
 
 	_, dbErr := pl.db.Exec(query, models.StageFailed, err.Error(), time.Now(), entryID)
+
+	if pl.live != nil {
+		pl.live.forget(entryID)
+	}
+
 	if dbErr != nil {
 		return fmt.Errorf("failed to set processing error: %w", dbErr)
 	}
@@ -151,54 +183,90 @@ func (pl *ProcessingLogger) SetError(entryID string, stage models.ProcessingStag
 
 // GetLogs retrieves all logs for a specific entry
 func (pl *ProcessingLogger) GetLogs(entryID string) ([]models.ProcessingLog, error) {
+	return pl.GetFilteredLogs(entryID, LogFilter{})
+}
+
+// GetLogsByStage retrieves logs for a specific entry and stage
+func (pl *ProcessingLogger) GetLogsByStage(entryID string, stage models.ProcessingStage) ([]models.ProcessingLog, error) {
+	return pl.GetFilteredLogs(entryID, LogFilter{Stage: stage})
+}
+
+// logLevels lists the log levels from least to most severe
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// LogFilter narrows the logs returned by GetFilteredLogs. Zero values
+// disable the corresponding filter.
+type LogFilter struct {
+	MinLevel string                 // Only logs at this level or more severe
+	Stage    models.ProcessingStage // Only logs for this stage
+	Since    *time.Time             // Only logs created at or after this time
+	Limit    int                    // At most this many logs
+	Offset   int                    // Skip this many logs, in the requested order
+	Newest   bool                   // Newest first instead of oldest first
+}
+
+// levelsAtOrAbove returns the levels at least as severe as minLevel
+func levelsAtOrAbove(minLevel string) ([]string, error) {
+	for i, level := range logLevels {
+		if level == minLevel {
+			return logLevels[i:], nil
+		}
+	}
+	return nil, fmt.Errorf("invalid log level %q (expected one of debug, info, warn, error)", minLevel)
+}
+
+// GetFilteredLogs retrieves logs for an entry matching the filter
+func (pl *ProcessingLogger) GetFilteredLogs(entryID string, filter LogFilter) ([]models.ProcessingLog, error) {
 	// Flush any pending logs first
 	pl.flushBuffer(entryID)
 
 	query := `
 		SELECT id, entry_id, stage, level, message, details, created_at
 		FROM processing_logs
-		WHERE entry_id = $1
-		ORDER BY created_at ASC`
-
-	rows, err := pl.db.Query(query, entryID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query logs: %w", err)
-	}
-	defer rows.Close()
-
-	var logs []models.ProcessingLog
-	for rows.Next() {
-		var log models.ProcessingLog
-		var detailsJSON []byte
+		WHERE entry_id = $1`
+	args := []interface{}{entryID}
 
-		err := rows.Scan(&log.ID, &log.EntryID, &log.Stage, &log.Level,
-			&log.Message, &detailsJSON, &log.CreatedAt)
+	if filter.MinLevel != "" {
+		levels, err := levelsAtOrAbove(filter.MinLevel)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan log: %w", err)
+			return nil, err
 		}
+		args = append(args, pq.Array(levels))
+		query += fmt.Sprintf(" AND level = ANY($%d)", len(args))
+	}
 
-		if len(detailsJSON) > 0 {
-			json.Unmarshal(detailsJSON, &log.Details)
-		}
+	if filter.Stage != "" {
+		args = append(args, filter.Stage)
+		query += fmt.Sprintf(" AND stage = $%d", len(args))
+	}
 
-		logs = append(logs, log)
+	if filter.Since != nil {
+		args = append(args, *filter.Since)
+		query += fmt.Sprintf(" AND created_at >= $%d", len(args))
 	}
 
-	return logs, nil
-}
+	if filter.Limit < 0 || filter.Offset < 0 {
+		return nil, fmt.Errorf("limit and offset must not be negative")
+	}
 
-// GetLogsByStage retrieves logs for a specific entry and stage
-func (pl *ProcessingLogger) GetLogsByStage(entryID string, stage models.ProcessingStage) ([]models.ProcessingLog, error) {
-	// Flush any pending logs first
-	pl.flushBuffer(entryID)
+	// id breaks ties between logs written in the same instant so pages
+	// neither skip nor repeat rows
+	if filter.Newest {
+		query += " ORDER BY created_at DESC, id DESC"
+	} else {
+		query += " ORDER BY created_at ASC, id ASC"
+	}
 
-	query := `
-		SELECT id, entry_id, stage, level, message, details, created_at
-		FROM processing_logs
-		WHERE entry_id = $1 AND stage = $2
-		ORDER BY created_at ASC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
 
-	rows, err := pl.db.Query(query, entryID, stage)
+	rows, err := pl.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query logs: %w", err)
 	}
@@ -284,15 +352,96 @@ func (pl *ProcessingLogger) backgroundFlusher() {
 	defer ticker.Stop()
 
 	for range ticker.C {
-		pl.mu.RLock()
-		entryIDs := make([]string, 0, len(pl.buffers))
-		for entryID := range pl.buffers {
-			entryIDs = append(entryIDs, entryID)
+		pl.flushAll()
+	}
+}
+
+// flushAll writes every pending buffer to the database
+func (pl *ProcessingLogger) flushAll() {
+	pl.mu.RLock()
+	entryIDs := make([]string, 0, len(pl.buffers))
+	for entryID := range pl.buffers {
+		entryIDs = append(entryIDs, entryID)
+	}
+	pl.mu.RUnlock()
+
+	for _, entryID := range entryIDs {
+		pl.flushBuffer(entryID)
+	}
+}
+
+// purgeBatchSize bounds how many rows a single DELETE removes so retention
+// cleanup never holds long locks on processing_logs
+const purgeBatchSize = 1000
+
+// PurgeOldLogs deletes logs created before the cutoff and returns how many
+// rows were removed. Logs of entries still in the failed stage are kept so
+// users can continue analyzing those failures.
+func (pl *ProcessingLogger) PurgeOldLogs(before time.Time) (int64, error) {
+	return pl.purgeLogs(before, "")
+}
+
+// PurgeUserLogs is PurgeOldLogs restricted to the logs of entries owned by
+// userID, so one tenant cannot purge another tenant's logs. Logs of entries
+// that no longer exist have no owner and are left to PurgeOldLogs.
+func (pl *ProcessingLogger) PurgeUserLogs(before time.Time, userID string) (int64, error) {
+	return pl.purgeLogs(before, userID)
+}
+
+func (pl *ProcessingLogger) purgeLogs(before time.Time, userID string) (int64, error) {
+	// Flush pending logs first so nothing buffered is written after the purge
+	pl.flushAll()
+
+	args := []interface{}{before, purgeBatchSize}
+	owner := ""
+	if userID != "" {
+		owner = " AND je.user_id = $3"
+		args = append(args, userID)
+	}
+
+	query := `
+		DELETE FROM processing_logs
+		WHERE id IN (
+			SELECT l.id
+			FROM processing_logs l
+			LEFT JOIN journal_entries je ON je.id = l.entry_id
+			WHERE l.created_at < $1
+			AND (je.processing_stage IS NULL OR je.processing_stage <> 'failed')` + owner + `
+			LIMIT $2
+		)`
+
+	var total int64
+	for {
+		result, err := pl.db.Exec(query, args...)
+		if err != nil {
+			return total, fmt.Errorf("failed to purge logs: %w", err)
+		}
+
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return total, fmt.Errorf("failed to count purged logs: %w", err)
 		}
-		pl.mu.RUnlock()
+		total += deleted
 
-		for _, entryID := range entryIDs {
-			pl.flushBuffer(entryID)
+		if deleted < purgeBatchSize {
+			break
 		}
 	}
+
+	log.Printf("Purged %d processing logs older than %s", total, before.Format(time.RFC3339))
+	return total, nil
+}
+
+// StartRetentionCleaner periodically purges logs older than retention
+func (pl *ProcessingLogger) StartRetentionCleaner(retention, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := pl.PurgeOldLogs(time.Now().Add(-retention)); err != nil {
+				log.Printf("Processing log retention cleanup failed: %v", err)
+			}
+		}
+	}()
 }
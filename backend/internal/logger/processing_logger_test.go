@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeOldLogsDeletesInBatches(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	pl := &ProcessingLogger{db: mockDB, buffers: make(map[string]*LogBuffer)}
+	cutoff := time.Now().Add(-30 * 24 * time.Hour)
+
+	// A full batch means there may be more rows, so a second DELETE runs
+	mock.ExpectExec(`DELETE FROM processing_logs`).
+		WithArgs(cutoff, purgeBatchSize).
+		WillReturnResult(sqlmock.NewResult(0, purgeBatchSize))
+	mock.ExpectExec(`DELETE FROM processing_logs(.*)processing_stage <> 'failed'`).
+		WithArgs(cutoff, purgeBatchSize).
+		WillReturnResult(sqlmock.NewResult(0, 42))
+
+	deleted, err := pl.PurgeOldLogs(cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, int64(purgeBatchSize+42), deleted)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeUserLogsOnlyMatchesOwnEntries(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	pl := &ProcessingLogger{db: mockDB, buffers: make(map[string]*LogBuffer)}
+	cutoff := time.Now().Add(-30 * 24 * time.Hour)
+
+	mock.ExpectExec(`DELETE FROM processing_logs(.*)AND je.user_id = \$3`).
+		WithArgs(cutoff, purgeBatchSize, "alice").
+		WillReturnResult(sqlmock.NewResult(0, 5))
+
+	deleted, err := pl.PurgeUserLogs(cutoff, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), deleted)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFilteredLogsPushesFiltersIntoSQL(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	pl := &ProcessingLogger{db: mockDB, buffers: make(map[string]*LogBuffer)}
+
+	rows := sqlmock.NewRows([]string{"id", "entry_id", "stage", "level", "message", "details", "created_at"}).
+		AddRow("log-1", "entry-1", "analyzing", "error", "Processing failed", `{"error":"boom"}`, time.Now())
+
+	mock.ExpectQuery(`WHERE entry_id = \$1 AND level = ANY\(\$2\) AND stage = \$3 ORDER BY created_at ASC, id ASC`).
+		WithArgs("entry-1", `{"warn","error"}`, models.StageAnalyzing).
+		WillReturnRows(rows)
+
+	logs, err := pl.GetFilteredLogs("entry-1", LogFilter{MinLevel: "warn", Stage: models.StageAnalyzing})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, "error", logs[0].Level)
+	assert.Equal(t, "boom", logs[0].Details["error"])
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFilteredLogsPaginatesNewestFirst(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	pl := &ProcessingLogger{db: mockDB, buffers: make(map[string]*LogBuffer)}
+	since := time.Now().Add(-time.Hour)
+
+	mock.ExpectQuery(`WHERE entry_id = \$1 AND created_at >= \$2 ORDER BY created_at DESC, id DESC LIMIT \$3 OFFSET \$4`).
+		WithArgs("entry-1", since, 50, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "entry_id", "stage", "level", "message", "details", "created_at"}))
+
+	_, err = pl.GetFilteredLogs("entry-1", LogFilter{Since: &since, Limit: 50, Offset: 100, Newest: true})
+	require.NoError(t, err)
+
+	_, err = pl.GetFilteredLogs("entry-1", LogFilter{Limit: -1})
+	assert.Error(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFilteredLogsRejectsUnknownLevel(t *testing.T) {
+	mockDB, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	pl := &ProcessingLogger{db: mockDB, buffers: make(map[string]*LogBuffer)}
+
+	_, err = pl.GetFilteredLogs("entry-1", LogFilter{MinLevel: "critical"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid log level")
+}
@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+)
+
+// SetupServerLogging selects the format of the server's own logs. "text" (or
+// "") keeps the standard human-readable log lines. "json" emits one JSON
+// object per line with time, level, msg and any structured fields, for log
+// aggregators; plain log.Printf calls are routed through the same handler
+// and logged at INFO.
+func SetupServerLogging(format string, w io.Writer) error {
+	switch format {
+	case "", "text":
+		return nil
+	case "json":
+		slog.SetDefault(slog.New(slog.NewJSONHandler(w, nil)))
+		return nil
+	default:
+		return fmt.Errorf("unknown log format %q (want text or json)", format)
+	}
+}
@@ -0,0 +1,49 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupServerLoggingJSON(t *testing.T) {
+	defaultLogger := slog.Default()
+	logFlags, logOutput := log.Flags(), log.Writer()
+	defer func() {
+		slog.SetDefault(defaultLogger)
+		log.SetFlags(logFlags)
+		log.SetOutput(logOutput)
+	}()
+
+	var buf bytes.Buffer
+	require.NoError(t, SetupServerLogging("json", &buf))
+
+	slog.Error("Failed to process entry", "entry_id", "e1")
+	log.Printf("legacy %s", "line")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+
+	var structured map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &structured))
+	assert.Equal(t, "ERROR", structured["level"])
+	assert.Equal(t, "Failed to process entry", structured["msg"])
+	assert.Equal(t, "e1", structured["entry_id"])
+	assert.Contains(t, structured, "time")
+
+	// Unconverted log.Printf calls become INFO records
+	var legacy map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &legacy))
+	assert.Equal(t, "INFO", legacy["level"])
+	assert.Equal(t, "legacy line", legacy["msg"])
+}
+
+func TestSetupServerLoggingRejectsUnknownFormat(t *testing.T) {
+	assert.Error(t, SetupServerLogging("xml", &bytes.Buffer{}))
+}
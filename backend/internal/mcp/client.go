@@ -4,36 +4,75 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/journal/internal/models"
 )
 
+// DefaultRetryBackoff is the wait before the first retry; each further retry
+// doubles it, up to maxRetryBackoff
+const (
+	DefaultRetryBackoff = 500 * time.Millisecond
+	maxRetryBackoff     = 5 * time.Second
+)
+
 type Client struct {
 	baseURL    string
 	httpClient *http.Client
+	maxRetries int
+	backoff    time.Duration
 }
 
-func NewClient(baseURL string) *Client {
+// NewClient creates a client for the MCP fetch agent. A fetch that fails with
+// a connection error or a 5xx response is retried up to maxRetries times;
+// 4xx responses are not retried.
+func NewClient(baseURL string, maxRetries int) *Client {
 	if baseURL == "" {
 		baseURL = "http://localhost:8081"
 	}
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
 
 	return &Client{
 		baseURL: baseURL,
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		maxRetries: maxRetries,
+		backoff:    DefaultRetryBackoff,
 	}
 }
 
+// WithBackoff sets the wait before the first retry
+func (c *Client) WithBackoff(d time.Duration) *Client {
+	c.backoff = d
+	return c
+}
+
 type FetchRequest struct {
 	URL    string `json:"url"`
 	Reason string `json:"reason"`
 }
 
+// retryableError marks a failure worth retrying: the agent was unreachable
+// or returned a server error
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
 func (c *Client) FetchURL(ctx context.Context, url, reason string) (*models.ExtractedURL, error) {
 	request := FetchRequest{
 		URL:    url,
@@ -45,7 +84,30 @@ func (c *Client) FetchURL(ctx context.Context, url, reason string) (*models.Extr
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/fetch", bytes.NewBuffer(jsonData))
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		result, err := c.fetch(ctx, jsonData)
+		var retryable *retryableError
+		if err == nil || !errors.As(err, &retryable) || attempt >= c.maxRetries {
+			if err != nil && attempt > 0 {
+				return nil, fmt.Errorf("%w (after %d attempts)", err, attempt+1)
+			}
+			return result, err
+		}
+
+		log.Printf("MCP fetch of %s failed (attempt %d of %d), retrying in %s: %v", url, attempt+1, c.maxRetries+1, backoff, err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to send request: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+// fetch makes a single request to the agent
+func (c *Client) fetch(ctx context.Context, body []byte) (*models.ExtractedURL, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/fetch", bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -54,10 +116,17 @@ func (c *Client) FetchURL(ctx context.Context, url, reason string) (*models.Extr
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		err = fmt.Errorf("failed to send request: %w", err)
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, &retryableError{err: err}
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return nil, &retryableError{err: fmt.Errorf("unexpected status code: %d", resp.StatusCode)}
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
@@ -70,6 +139,28 @@ func (c *Client) FetchURL(ctx context.Context, url, reason string) (*models.Extr
 	return &result, nil
 }
 
+// Health checks that the agent is reachable and reports itself healthy
+func (c *Client) Health(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/health", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach agent: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
 // FetchURLsForEntry fetches all URLs mentioned in a journal entry's processed data
 func (c *Client) FetchURLsForEntry(ctx context.Context, entry *models.JournalEntry) error {
 	// Look for URLs to fetch in metadata
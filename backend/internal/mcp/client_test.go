@@ -0,0 +1,76 @@
+package mcp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchURLRetriesServerErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"url":"https://example.com","title":"Example"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 2).WithBackoff(time.Millisecond)
+
+	result, err := client.FetchURL(context.Background(), "https://example.com", "")
+	require.NoError(t, err)
+	assert.Equal(t, "Example", result.Title)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestFetchURLGivesUpAfterMaxRetries(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 1).WithBackoff(time.Millisecond)
+
+	_, err := client.FetchURL(context.Background(), "https://example.com", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status code: 503 (after 2 attempts)")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestFetchURLDoesNotRetryClientErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, 3).WithBackoff(time.Millisecond)
+
+	_, err := client.FetchURL(context.Background(), "https://example.com", "")
+	require.Error(t, err)
+	assert.Equal(t, "unexpected status code: 422", err.Error())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestFetchURLRetriesConnectionErrors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	client := NewClient(url, 1).WithBackoff(time.Millisecond)
+
+	_, err := client.FetchURL(context.Background(), "https://example.com", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "after 2 attempts")
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGzip(t *testing.T) {
+	handler := Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"result":"ok"}`))
+	}))
+
+	t.Run("compresses when accepted", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/rpc", nil)
+		req.Header.Set("Accept-Encoding", "deflate, gzip")
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		reader, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, `{"result":"ok"}`, string(body))
+	})
+
+	t.Run("passes through otherwise", func(t *testing.T) {
+		for _, accept := range []string{"", "deflate", "gzip;q=0"} {
+			req := httptest.NewRequest(http.MethodPost, "/api/rpc", nil)
+			req.Header.Set("Accept-Encoding", accept)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			assert.Empty(t, rec.Header().Get("Content-Encoding"), accept)
+			assert.Equal(t, `{"result":"ok"}`, rec.Body.String(), accept)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"github.com/pgvector/pgvector-go"
 	"time"
@@ -15,21 +16,41 @@ type JournalEntry struct {
 	CreatedAt             time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt             time.Time       `json:"updated_at" db:"updated_at"`
 	IsFavorite            bool            `json:"is_favorite" db:"is_favorite"`
+	PinnedAt              *time.Time      `json:"pinned_at,omitempty" db:"pinned_at"`
 	CollectionIDs         []string        `json:"collection_ids" db:"collection_ids"`
 	OriginalEntryID       *string         `json:"original_entry_id,omitempty" db:"original_entry_id"`
 	ProcessingStage       ProcessingStage `json:"processing_stage" db:"processing_stage"`
 	ProcessingStartedAt   *time.Time      `json:"processing_started_at,omitempty" db:"processing_started_at"`
 	ProcessingCompletedAt *time.Time      `json:"processing_completed_at,omitempty" db:"processing_completed_at"`
 	ProcessingError       *string         `json:"processing_error,omitempty" db:"processing_error"`
+	Attachments           Attachments     `json:"attachments" db:"attachments"`
+	OriginalContent       *string         `json:"original_content,omitempty" db:"original_content"`
 }
 
+// Attachment describes a file or image referenced by an entry. Only the
+// metadata is stored; the file itself lives wherever URL points. Attachments
+// are never sent to the model for analysis.
+type Attachment struct {
+	Filename string `json:"filename"`
+	MIME     string `json:"mime,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// Attachments is stored as a JSONB array
+type Attachments []Attachment
+
+// ProcessedData is the AI analysis of an entry. Mood maps emotion names
+// (joy, anxiety, gratitude, ...) to intensities between 0 and 1; entries
+// analyzed before mood scoring have none.
 type ProcessedData struct {
-	Summary       string         `json:"summary"`
-	ExtractedURLs []ExtractedURL `json:"extracted_urls"`
-	Entities      []string       `json:"entities"`
-	Topics        []string       `json:"topics"`
-	Sentiment     string         `json:"sentiment"`
-	Metadata      map[string]any `json:"metadata"`
+	Summary       string             `json:"summary"`
+	ExtractedURLs []ExtractedURL     `json:"extracted_urls"`
+	Entities      []string           `json:"entities"`
+	Topics        []string           `json:"topics"`
+	Sentiment     string             `json:"sentiment"`
+	Mood          map[string]float64 `json:"mood,omitempty"`
+	Metadata      map[string]any     `json:"metadata"`
 }
 
 type ExtractedURL struct {
@@ -38,14 +59,32 @@ type ExtractedURL struct {
 	Content     string    `json:"content"`
 	ExtractedAt time.Time `json:"extracted_at"`
 	Source      string    `json:"source"`
+	// Metadata carries flags from the fetch agent, such as LowContentKey
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// LowContentKey flags, in ExtractedURL.Metadata, a fetched page with too
+// little real content to embed, such as a consent wall or soft 404
+const LowContentKey = "low_content"
+
+// IsLowContent reports whether the fetch agent flagged the page as low content
+func (u ExtractedURL) IsLowContent() bool {
+	low, _ := u.Metadata[LowContentKey].(bool)
+	return low
 }
 
+// Collection groups entries either by manual membership or, when IsSmart is
+// set, by re-running the SearchParams stored in QueryParams. EntryCount only
+// counts manual members.
 type Collection struct {
-	ID          string    `json:"id" db:"id"`
-	Name        string    `json:"name" db:"name"`
-	Description string    `json:"description" db:"description"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID          string          `json:"id" db:"id"`
+	Name        string          `json:"name" db:"name"`
+	Description string          `json:"description" db:"description"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+	EntryCount  int             `json:"entry_count" db:"entry_count"`
+	IsSmart     bool            `json:"is_smart" db:"is_smart"`
+	QueryParams json.RawMessage `json:"query_params,omitempty" db:"query_params"`
 }
 
 // ProcessingStage represents the current stage of journal entry processing
@@ -58,8 +97,27 @@ const (
 	StageGeneratingEmbeddings ProcessingStage = "generating_embeddings"
 	StageCompleted            ProcessingStage = "completed"
 	StageFailed               ProcessingStage = "failed"
+
+	// StageCompletedNoEmbedding is an analyzed entry whose embedding could
+	// not be generated. It is found by classic search but not vector
+	// search until a retry fills in the embedding.
+	StageCompletedNoEmbedding ProcessingStage = "completed_no_embedding"
+
+	// StageCancelled is an entry whose processing was stopped on request
+	// before it finished. Whatever the pipeline had not stored is lost; a
+	// retry starts over.
+	StageCancelled ProcessingStage = "cancelled"
 )
 
+// Valid reports whether s is one of the known processing stages
+func (s ProcessingStage) Valid() bool {
+	switch s {
+	case StageCreated, StageAnalyzing, StageFetchingURLs, StageGeneratingEmbeddings, StageCompleted, StageFailed, StageCompletedNoEmbedding, StageCancelled:
+		return true
+	}
+	return false
+}
+
 // Scan implements the sql.Scanner interface
 func (s *ProcessingStage) Scan(value interface{}) error {
 	if value == nil {
@@ -82,6 +140,29 @@ func (s ProcessingStage) Value() (driver.Value, error) {
 	return string(s), nil
 }
 
+// Scan implements the sql.Scanner interface. NULL scans as no attachments.
+func (a *Attachments) Scan(value interface{}) error {
+	*a = Attachments{}
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, a)
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	default:
+		return fmt.Errorf("cannot scan type %T into Attachments", value)
+	}
+}
+
+// Value implements the driver.Valuer interface, storing nil as an empty array
+func (a Attachments) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(a)
+}
+
 // ProcessingLog represents a log entry for a specific processing stage
 type ProcessingLog struct {
 	ID        string                 `json:"id" db:"id"`
@@ -0,0 +1,78 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"unicode"
+)
+
+// Long entries are embedded in overlapping chunks in addition to the
+// whole-entry vector. Entries up to ChunkSize characters get no chunks.
+const (
+	ChunkSize    = 1500
+	ChunkOverlap = 200
+)
+
+// ChunkEmbedding is the embedding of one slice of an entry's content
+type ChunkEmbedding struct {
+	Index     int
+	Content   string
+	Embedding []float32
+}
+
+// ChunkText splits text into pieces of at most size characters, each starting
+// overlap characters before the previous one ended. Cuts are moved back to the
+// nearest whitespace when one is close so words are not split. Text that fits
+// in a single chunk returns nil.
+func ChunkText(text string, size, overlap int) []string {
+	runes := []rune(text)
+	if len(runes) <= size || size <= overlap {
+		return nil
+	}
+
+	chunks := []string{}
+	for start := 0; start < len(runes); {
+		end := start + size
+		if end >= len(runes) {
+			chunks = append(chunks, string(runes[start:]))
+			break
+		}
+
+		for i := end; i > end-size/10; i-- {
+			if unicode.IsSpace(runes[i]) {
+				end = i
+				break
+			}
+		}
+
+		chunks = append(chunks, string(runes[start:end]))
+		if end-overlap > start {
+			start = end - overlap
+		} else {
+			start = end
+		}
+	}
+
+	return chunks
+}
+
+// CreateChunkEmbeddings embeds each chunk of a long entry's content. It
+// returns nil for content short enough to need no chunks.
+func (p *Processor) CreateChunkEmbeddings(ctx context.Context, content string) ([]ChunkEmbedding, error) {
+	chunks := ChunkText(content, ChunkSize, ChunkOverlap)
+	if chunks == nil {
+		return nil, nil
+	}
+
+	vectors, err := p.client.CreateEmbeddings(ctx, EmbeddingModel, chunks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed chunks: %w", err)
+	}
+
+	embeddings := make([]ChunkEmbedding, 0, len(chunks))
+	for i, chunk := range chunks {
+		embeddings = append(embeddings, ChunkEmbedding{Index: i, Content: chunk, Embedding: vectors[i]})
+	}
+
+	return embeddings, nil
+}
@@ -0,0 +1,27 @@
+package ollama
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkText(t *testing.T) {
+	assert.Nil(t, ChunkText("short entry", 100, 10))
+
+	text := strings.Repeat("word ", 100) // 500 characters
+	chunks := ChunkText(text, 200, 50)
+
+	assert.Greater(t, len(chunks), 2)
+	for _, chunk := range chunks {
+		assert.LessOrEqual(t, len([]rune(chunk)), 200)
+	}
+
+	// Consecutive chunks overlap and together cover the whole text
+	for i := 1; i < len(chunks); i++ {
+		tail := chunks[i-1][len(chunks[i-1])-40:]
+		assert.Contains(t, chunks[i], tail)
+	}
+	assert.True(t, strings.HasSuffix(text, chunks[len(chunks)-1]))
+}
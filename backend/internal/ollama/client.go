@@ -1,17 +1,40 @@
 package ollama
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 )
 
+// Default request timeouts. Chat covers a full analysis, which can take
+// minutes for long entries on modest hardware; embeddings return in seconds.
+const (
+	DefaultChatTimeout      = 120 * time.Second
+	DefaultEmbeddingTimeout = 30 * time.Second
+)
+
+// Models the processor uses for analysis and embeddings
+const (
+	ChatModel      = "qwen3:8b"
+	EmbeddingModel = "nomic-embed-text"
+)
+
+// statusTimeout bounds quick status requests such as listing models
+const statusTimeout = 5 * time.Second
+
 type Client struct {
-	baseURL    string
-	httpClient *http.Client
+	baseURL          string
+	httpClient       *http.Client
+	chatTimeout      time.Duration
+	embeddingTimeout time.Duration
 }
 
 func NewClient(baseURL string) *Client {
@@ -21,10 +44,122 @@ func NewClient(baseURL string) *Client {
 
 	return &Client{
 		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: 120 * time.Second, // Increased timeout for large content
-		},
+		// Timeouts are applied per request so chat and embeddings can differ
+		httpClient:       &http.Client{},
+		chatTimeout:      DefaultChatTimeout,
+		embeddingTimeout: DefaultEmbeddingTimeout,
+	}
+}
+
+// WithTimeout sets how long a chat request (analysis) may take, including
+// reading a streamed response. A value <= 0 disables the limit.
+func (c *Client) WithTimeout(d time.Duration) *Client {
+	c.chatTimeout = d
+	return c
+}
+
+// WithEmbeddingTimeout sets how long an embedding request may take. A value
+// <= 0 disables the limit.
+func (c *Client) WithEmbeddingTimeout(d time.Duration) *Client {
+	c.embeddingTimeout = d
+	return c
+}
+
+// TimeoutError reports an Ollama request that did not finish in time
+type TimeoutError struct {
+	Operation string
+	Limit     time.Duration
+	Err       error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("ollama %s request timed out after %s: %v", e.Operation, e.Limit, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Timeout lets callers recognize the error without importing this package,
+// as with net.Error
+func (e *TimeoutError) Timeout() bool {
+	return true
+}
+
+// ErrModelNotFound matches, with errors.Is, a ModelNotFoundError for any model
+var ErrModelNotFound = errors.New("model not found")
+
+// ModelNotFoundError reports a request for a model that has not been pulled
+// into Ollama
+type ModelNotFoundError struct {
+	Model string
+}
+
+func (e *ModelNotFoundError) Error() string {
+	return fmt.Sprintf("ollama model %q is not installed: run `ollama pull %s`", e.Model, e.Model)
+}
+
+func (e *ModelNotFoundError) Is(target error) bool {
+	return target == ErrModelNotFound
+}
+
+// MissingModel lets callers recognize the error and name the model without
+// importing this package, as Timeout does for TimeoutError
+func (e *ModelNotFoundError) MissingModel() string {
+	return e.Model
+}
+
+// statusError builds the error for a non-200 response. Ollama answers a
+// request for a model it does not have with 404 and a body such as
+// {"error":"model \"qwen3:8b\" not found, try pulling it first"}, which
+// becomes a ModelNotFoundError for the requested model.
+func statusError(resp *http.Response, model string) error {
+	body, _ := io.ReadAll(resp.Body)
+
+	var apiErr struct {
+		Error string `json:"error"`
 	}
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+		msg := strings.ToLower(apiErr.Error)
+		if strings.Contains(msg, "model") && strings.Contains(msg, "not found") {
+			return &ModelNotFoundError{Model: model}
+		}
+	}
+
+	return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+}
+
+// post sends a JSON request with the given timeout, abandoning it early if
+// ctx is done. The returned cancel function must be called once the response
+// body has been read.
+func (c *Client) post(ctx context.Context, path string, body []byte, timeout time.Duration) (*http.Response, context.CancelFunc, error) {
+	cancel := context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return resp, cancel, nil
+}
+
+// timeoutError wraps err in a TimeoutError if it was caused by the request
+// deadline, and returns it unchanged otherwise
+func timeoutError(operation string, timeout time.Duration, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &TimeoutError{Operation: operation, Limit: timeout, Err: err}
+	}
+	return err
 }
 
 type ChatRequest struct {
@@ -41,7 +176,13 @@ type Message struct {
 }
 
 type Options struct {
-	Temperature float32 `json:"temperature,omitempty"`
+	// Temperature is a pointer so an explicit 0 is still sent
+	Temperature *float32 `json:"temperature,omitempty"`
+}
+
+// temperature returns a pointer for Options.Temperature
+func temperature(t float32) *float32 {
+	return &t
 }
 
 type ChatResponse struct {
@@ -49,6 +190,45 @@ type ChatResponse struct {
 	CreatedAt time.Time `json:"created_at"`
 	Message   Message   `json:"message"`
 	Done      bool      `json:"done"`
+
+	// Timing and token counts, only present on the final response. Durations
+	// are in nanoseconds.
+	TotalDuration      int64 `json:"total_duration,omitempty"`
+	LoadDuration       int64 `json:"load_duration,omitempty"`
+	PromptEvalCount    int   `json:"prompt_eval_count,omitempty"`
+	PromptEvalDuration int64 `json:"prompt_eval_duration,omitempty"`
+	EvalCount          int   `json:"eval_count,omitempty"`
+	EvalDuration       int64 `json:"eval_duration,omitempty"`
+}
+
+// Usage summarizes how much work the model did for one chat request
+type Usage struct {
+	PromptTokens       int           `json:"prompt_tokens"`
+	GenerationTokens   int           `json:"generation_tokens"`
+	LoadDuration       time.Duration `json:"load_duration"`
+	PromptDuration     time.Duration `json:"prompt_duration"`
+	GenerationDuration time.Duration `json:"generation_duration"`
+	TotalDuration      time.Duration `json:"total_duration"`
+}
+
+// Usage returns the token counts and timings reported with the response
+func (r *ChatResponse) Usage() Usage {
+	return Usage{
+		PromptTokens:       r.PromptEvalCount,
+		GenerationTokens:   r.EvalCount,
+		LoadDuration:       time.Duration(r.LoadDuration),
+		PromptDuration:     time.Duration(r.PromptEvalDuration),
+		GenerationDuration: time.Duration(r.EvalDuration),
+		TotalDuration:      time.Duration(r.TotalDuration),
+	}
+}
+
+// TokensPerSecond is the generation speed, or 0 if no timing was reported
+func (u Usage) TokensPerSecond() float64 {
+	if u.GenerationDuration <= 0 {
+		return 0
+	}
+	return float64(u.GenerationTokens) / u.GenerationDuration.Seconds()
 }
 
 type EmbeddingRequest struct {
@@ -56,74 +236,256 @@ type EmbeddingRequest struct {
 	Input string `json:"input"`
 }
 
+// BatchEmbeddingRequest embeds several inputs in one call
+type BatchEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
 type EmbeddingResponse struct {
 	Model      string      `json:"model"`
 	Embeddings [][]float32 `json:"embeddings"`
 }
 
-func (c *Client) Chat(request ChatRequest) (*ChatResponse, error) {
+func (c *Client) Chat(ctx context.Context, request ChatRequest) (*ChatResponse, error) {
 	jsonData, err := json.Marshal(request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(
-		fmt.Sprintf("%s/api/chat", c.baseURL),
-		"application/json",
-		bytes.NewBuffer(jsonData),
-	)
+	resp, cancel, err := c.post(ctx, "/api/chat", jsonData, c.chatTimeout)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", timeoutError("chat", c.chatTimeout, err))
 	}
+	defer cancel()
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+		return nil, statusError(resp, request.Model)
 	}
 
 	var chatResp ChatResponse
 	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("failed to decode response: %w", timeoutError("chat", c.chatTimeout, err))
 	}
 
 	return &chatResp, nil
 }
 
-func (c *Client) CreateEmbedding(model, text string) ([]float32, error) {
-	request := EmbeddingRequest{
+// ErrStreamingUnsupported is returned by ChatStream when the server rejects a
+// streaming request before sending any output
+var ErrStreamingUnsupported = errors.New("streaming chat is not supported")
+
+// ChatStream sends request with streaming enabled and calls onChunk for every
+// partial message as it arrives. It returns the complete response with all
+// chunks' content concatenated.
+func (c *Client) ChatStream(ctx context.Context, request ChatRequest, onChunk func(ChatResponse)) (*ChatResponse, error) {
+	request.Stream = true
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, cancel, err := c.post(ctx, "/api/chat", jsonData, c.chatTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", timeoutError("chat", c.chatTimeout, err))
+	}
+	defer cancel()
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// Only a server that rejects the request itself is taken to lack
+		// streaming. Anything else, such as a missing model or an overloaded
+		// server, would fail the same way without streaming, so it is
+		// reported as is rather than retried.
+		err := statusError(resp, request.Model)
+		if errors.Is(err, ErrModelNotFound) {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusNotImplemented {
+			return nil, fmt.Errorf("%w: %v", ErrStreamingUnsupported, err)
+		}
+		return nil, err
+	}
+
+	// The response is newline delimited JSON, one ChatResponse per chunk
+	var full ChatResponse
+	var content strings.Builder
+	chunks := 0
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk ChatResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			if chunks == 0 {
+				return nil, fmt.Errorf("%w: %v", ErrStreamingUnsupported, err)
+			}
+			return nil, fmt.Errorf("failed to decode stream chunk: %w", err)
+		}
+		chunks++
+
+		content.WriteString(chunk.Message.Content)
+		if onChunk != nil {
+			onChunk(chunk)
+		}
+
+		if chunk.Done {
+			// The final chunk carries the timing and token counts
+			full = chunk
+			break
+		}
+		full.Model = chunk.Model
+		full.CreatedAt = chunk.CreatedAt
+		full.Message.Role = chunk.Message.Role
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", timeoutError("chat", c.chatTimeout, err))
+	}
+	if !full.Done {
+		return nil, fmt.Errorf("stream ended before completion")
+	}
+
+	full.Message.Content = content.String()
+	return &full, nil
+}
+
+func (c *Client) CreateEmbedding(ctx context.Context, model, text string) ([]float32, error) {
+	embeddings, err := c.embed(ctx, model, EmbeddingRequest{
 		Model: model,
 		Input: text,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(embeddings) == 0 {
+		return nil, fmt.Errorf("no embeddings returned")
+	}
+
+	return embeddings[0], nil
+}
+
+// CreateEmbeddings embeds several texts in one request and returns the
+// vectors in the same order. If the batch request fails for any reason other
+// than a timeout, each text is embedded separately instead.
+func (c *Client) CreateEmbeddings(ctx context.Context, model string, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
 	}
 
+	embeddings, err := c.embed(ctx, model, BatchEmbeddingRequest{
+		Model: model,
+		Input: texts,
+	})
+	if err == nil && len(embeddings) == len(texts) {
+		return embeddings, nil
+	}
+
+	// Neither a timeout, a cancelled caller nor a missing model would go
+	// better one text at a time
+	var timeout *TimeoutError
+	if errors.As(err, &timeout) || errors.Is(err, ErrModelNotFound) || ctx.Err() != nil {
+		return nil, err
+	}
+	if err == nil {
+		err = fmt.Errorf("got %d embeddings for %d inputs", len(embeddings), len(texts))
+	}
+	log.Printf("Batch embedding failed, embedding %d texts one at a time: %v", len(texts), err)
+
+	embeddings = make([][]float32, len(texts))
+	for i, text := range texts {
+		embedding, err := c.CreateEmbedding(ctx, model, text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed text %d: %w", i, err)
+		}
+		embeddings[i] = embedding
+	}
+	return embeddings, nil
+}
+
+// embed sends an embedding request, single or batch, to /api/embed
+func (c *Client) embed(ctx context.Context, model string, request interface{}) ([][]float32, error) {
 	jsonData, err := json.Marshal(request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := c.httpClient.Post(
-		fmt.Sprintf("%s/api/embed", c.baseURL),
-		"application/json",
-		bytes.NewBuffer(jsonData),
-	)
+	resp, cancel, err := c.post(ctx, "/api/embed", jsonData, c.embeddingTimeout)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", timeoutError("embedding", c.embeddingTimeout, err))
 	}
+	defer cancel()
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+		return nil, statusError(resp, model)
 	}
 
 	var embResp EmbeddingResponse
 	if err := json.NewDecoder(resp.Body).Decode(&embResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", timeoutError("embedding", c.embeddingTimeout, err))
+	}
+
+	return embResp.Embeddings, nil
+}
+
+// ListModels returns the names of the models installed in Ollama, as shown
+// by `ollama list` (for example "nomic-embed-text:latest")
+func (c *Client) ListModels(ctx context.Context) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, statusTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	if len(embResp.Embeddings) == 0 {
-		return nil, fmt.Errorf("no embeddings returned")
+	names := make([]string, 0, len(tags.Models))
+	for _, m := range tags.Models {
+		names = append(names, m.Name)
 	}
+	return names, nil
+}
 
-	return embResp.Embeddings[0], nil
+// HasModel reports whether model is among installed, treating a name without
+// a tag as ":latest"
+func HasModel(installed []string, model string) bool {
+	if !strings.Contains(model, ":") {
+		model += ":latest"
+	}
+	for _, name := range installed {
+		if !strings.Contains(name, ":") {
+			name += ":latest"
+		}
+		if name == model {
+			return true
+		}
+	}
+	return false
 }
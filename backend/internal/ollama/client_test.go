@@ -0,0 +1,236 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientTimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(200 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL).
+		WithTimeout(20 * time.Millisecond).
+		WithEmbeddingTimeout(10 * time.Millisecond)
+
+	_, err := client.Chat(context.Background(), ChatRequest{Model: "qwen3:8b"})
+	var timeout *TimeoutError
+	require.True(t, errors.As(err, &timeout), "got %v", err)
+	assert.Equal(t, "chat", timeout.Operation)
+	assert.Equal(t, 20*time.Millisecond, timeout.Limit)
+
+	_, err = client.CreateEmbedding(context.Background(), "nomic-embed-text", "hello")
+	require.True(t, errors.As(err, &timeout), "got %v", err)
+	assert.Equal(t, "embedding", timeout.Operation)
+}
+
+func TestClientErrorsAreNotTimeouts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL).Chat(context.Background(), ChatRequest{Model: "qwen3:8b"})
+	require.Error(t, err)
+	var timeout *TimeoutError
+	assert.False(t, errors.As(err, &timeout))
+}
+
+func TestCreateEmbeddingsBatch(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var request BatchEmbeddingRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, []string{"one", "two"}, request.Input)
+		w.Write([]byte(`{"embeddings":[[1,0],[0,1]]}`))
+	}))
+	defer server.Close()
+
+	embeddings, err := NewClient(server.URL).CreateEmbeddings(context.Background(), "nomic-embed-text", []string{"one", "two"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0}, {0, 1}}, embeddings)
+	assert.Equal(t, 1, requests)
+}
+
+func TestCreateEmbeddingsFallsBackToSingleRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		switch input := request["input"].(type) {
+		case []interface{}:
+			http.Error(w, "batch input not supported", http.StatusBadRequest)
+		case string:
+			fmt.Fprintf(w, `{"embeddings":[[%d]]}`, len(input))
+		}
+	}))
+	defer server.Close()
+
+	embeddings, err := NewClient(server.URL).CreateEmbeddings(context.Background(), "nomic-embed-text", []string{"a", "bbb"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1}, {3}}, embeddings)
+}
+
+func TestModelNotFound(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error":"model \"missing:1b\" not found, try pulling it first"}`)
+	}))
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	_, err := client.Chat(context.Background(), ChatRequest{Model: "missing:1b"})
+	require.ErrorIs(t, err, ErrModelNotFound)
+	var notFound *ModelNotFoundError
+	require.True(t, errors.As(err, &notFound))
+	assert.Equal(t, "missing:1b", notFound.Model)
+	assert.Contains(t, err.Error(), "ollama pull missing:1b")
+
+	// Streaming does not fall back to a request that would fail the same way
+	_, err = client.ChatStream(context.Background(), ChatRequest{Model: "missing:1b"}, func(ChatResponse) {})
+	assert.ErrorIs(t, err, ErrModelNotFound)
+	assert.NotErrorIs(t, err, ErrStreamingUnsupported)
+
+	requests = 0
+	_, err = client.CreateEmbeddings(context.Background(), "missing-embed", []string{"a", "b"})
+	require.ErrorIs(t, err, ErrModelNotFound)
+	assert.Contains(t, err.Error(), "ollama pull missing-embed")
+	assert.Equal(t, 1, requests, "no per-text fallback for a missing model")
+}
+
+func TestOtherStatusErrorsAreNotModelNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"out of memory"}`, http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL).Chat(context.Background(), ChatRequest{Model: "qwen3:8b"})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrModelNotFound)
+	assert.Contains(t, err.Error(), "unexpected status code 500")
+}
+
+func TestListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/tags", r.URL.Path)
+		w.Write([]byte(`{"models":[{"name":"qwen3:8b"},{"name":"nomic-embed-text:latest"}]}`))
+	}))
+	defer server.Close()
+
+	models, err := NewClient(server.URL).ListModels(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"qwen3:8b", "nomic-embed-text:latest"}, models)
+
+	assert.True(t, HasModel(models, ChatModel))
+	assert.True(t, HasModel(models, EmbeddingModel))
+	assert.False(t, HasModel(models, "qwen3:14b"))
+}
+
+// streamServer answers streaming chat requests with one NDJSON chunk per
+// piece of content, followed by a final chunk carrying the usage counts
+func streamServer(pieces []string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		enc := json.NewEncoder(w)
+		for _, piece := range pieces {
+			enc.Encode(ChatResponse{Model: "qwen3:8b", Message: Message{Role: "assistant", Content: piece}})
+		}
+		enc.Encode(ChatResponse{Model: "qwen3:8b", Message: Message{Role: "assistant"}, Done: true, EvalCount: len(pieces), PromptEvalCount: 7})
+	}))
+}
+
+func TestChatStream(t *testing.T) {
+	server := streamServer([]string{"Hel", "lo", " world"})
+	defer server.Close()
+
+	var chunks []string
+	response, err := NewClient(server.URL).ChatStream(context.Background(), ChatRequest{Model: "qwen3:8b"}, func(chunk ChatResponse) {
+		chunks = append(chunks, chunk.Message.Content)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Hello world", response.Message.Content)
+	assert.Equal(t, "assistant", response.Message.Role)
+	assert.Equal(t, []string{"Hel", "lo", " world", ""}, chunks)
+	assert.Equal(t, 3, response.Usage().GenerationTokens)
+	assert.Equal(t, 7, response.Usage().PromptTokens)
+}
+
+func TestChatStreamErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     http.HandlerFunc
+		unsupported bool
+	}{
+		{
+			name: "not implemented",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "streaming not supported", http.StatusNotImplemented)
+			},
+			unsupported: true,
+		},
+		{
+			name: "bad request",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, `{"error":"unknown field stream"}`, http.StatusBadRequest)
+			},
+			unsupported: true,
+		},
+		{
+			name: "undecodable first chunk",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("data: not json\n"))
+			},
+			unsupported: true,
+		},
+		{
+			name: "overloaded server",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "server busy", http.StatusServiceUnavailable)
+			},
+		},
+		{
+			name: "internal error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "out of memory", http.StatusInternalServerError)
+			},
+		},
+		{
+			name: "undecodable later chunk",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"message":{"content":"Hi"}}` + "\nnot json\n"))
+			},
+		},
+		{
+			name: "stream ends early",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"message":{"content":"Hi"}}` + "\n"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			_, err := NewClient(server.URL).ChatStream(context.Background(), ChatRequest{Model: "qwen3:8b"}, nil)
+			require.Error(t, err)
+			assert.Equal(t, tt.unsupported, errors.Is(err, ErrStreamingUnsupported), "got %v", err)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package ollama
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/journal/internal/models"
+)
+
+// EmbeddingFields selects which parts of an entry's analysis are embedded
+// alongside its content. Entry embeddings are only computed when an entry is
+// processed, so after changing the fields existing entries must be
+// reprocessed (for example with journal.retryProcessing) before their
+// vectors are comparable with new ones.
+type EmbeddingFields struct {
+	Summary   bool
+	Topics    bool
+	Entities  bool
+	Sentiment bool
+	URLs      bool
+}
+
+// DefaultEmbeddingFields embeds the content with every analysis field
+var DefaultEmbeddingFields = EmbeddingFields{
+	Summary:   true,
+	Topics:    true,
+	Entities:  true,
+	Sentiment: true,
+	URLs:      true,
+}
+
+// ParseEmbeddingFields parses a comma separated list of fields to embed with
+// the content: summary, topics, entities, sentiment and urls. "content" alone
+// (or an empty list) embeds only the content; "all" selects every field.
+func ParseEmbeddingFields(spec string) (EmbeddingFields, error) {
+	var fields EmbeddingFields
+	for _, name := range strings.Split(spec, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "", "content":
+		case "all":
+			fields = DefaultEmbeddingFields
+		case "summary":
+			fields.Summary = true
+		case "topics":
+			fields.Topics = true
+		case "entities":
+			fields.Entities = true
+		case "sentiment":
+			fields.Sentiment = true
+		case "urls":
+			fields.URLs = true
+		default:
+			return fields, fmt.Errorf("unknown embedding field %q (expected content, summary, topics, entities, sentiment, urls or all)", name)
+		}
+	}
+	return fields, nil
+}
+
+// WithEmbeddingFields sets which analysis fields are embedded with the
+// content
+func (p *Processor) WithEmbeddingFields(fields EmbeddingFields) *Processor {
+	p.embeddingFields = fields
+	return p
+}
+
+// embeddingText composes the text embedded for an entry: its content, then
+// one line per selected analysis field, then the title of each fetched URL
+// the fetch agent did not flag as low content
+func (f EmbeddingFields) embeddingText(entry models.JournalEntry) string {
+	var lines []string
+	if f.Summary {
+		lines = append(lines, "Summary: "+entry.ProcessedData.Summary)
+	}
+	if f.Topics {
+		lines = append(lines, "Topics: "+strings.Join(entry.ProcessedData.Topics, ", "))
+	}
+	if f.Entities {
+		lines = append(lines, "Entities: "+strings.Join(entry.ProcessedData.Entities, ", "))
+	}
+	if f.Sentiment {
+		lines = append(lines, "Sentiment: "+entry.ProcessedData.Sentiment)
+	}
+
+	text := entry.Content
+	if len(lines) > 0 {
+		text += "\n\n" + strings.Join(lines, "\n")
+	}
+
+	if f.URLs {
+		for _, url := range entry.ProcessedData.ExtractedURLs {
+			if url.IsLowContent() {
+				continue
+			}
+			text += fmt.Sprintf("\n\nFrom %s: %s", url.URL, url.Title)
+		}
+	}
+
+	return text
+}
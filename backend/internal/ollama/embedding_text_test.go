@@ -0,0 +1,45 @@
+package ollama
+
+import (
+	"testing"
+
+	"github.com/journal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddingText(t *testing.T) {
+	entry := models.JournalEntry{
+		Content: "Hiked the ridge with Sam",
+		ProcessedData: models.ProcessedData{
+			Summary:   "A hike",
+			Topics:    []string{"hiking", "outdoors"},
+			Entities:  []string{"Sam"},
+			Sentiment: "positive",
+			ExtractedURLs: []models.ExtractedURL{
+				{URL: "https://trails.example", Title: "Ridge Trail"},
+				{URL: "https://maps.example", Title: "Just a moment...", Metadata: map[string]interface{}{models.LowContentKey: true}},
+			},
+		},
+	}
+
+	assert.Equal(t, "Hiked the ridge with Sam\n\nSummary: A hike\nTopics: hiking, outdoors\nEntities: Sam\nSentiment: positive"+
+		"\n\nFrom https://trails.example: Ridge Trail", DefaultEmbeddingFields.embeddingText(entry))
+
+	contentOnly, err := ParseEmbeddingFields("content")
+	require.NoError(t, err)
+	assert.Equal(t, "Hiked the ridge with Sam", contentOnly.embeddingText(entry))
+
+	topics, err := ParseEmbeddingFields("content, Topics")
+	require.NoError(t, err)
+	assert.Equal(t, "Hiked the ridge with Sam\n\nTopics: hiking, outdoors", topics.embeddingText(entry))
+}
+
+func TestParseEmbeddingFields(t *testing.T) {
+	fields, err := ParseEmbeddingFields("all")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultEmbeddingFields, fields)
+
+	_, err = ParseEmbeddingFields("summary,mood")
+	assert.Error(t, err)
+}
@@ -3,29 +3,99 @@ package ollama
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"strings"
+	"unicode"
 
 	"github.com/journal/internal/models"
 )
 
+// DefaultMaxInputChars is the longest entry sent to the model unmodified.
+// qwen3:8b runs with a few thousand tokens of context by default; at roughly
+// four characters per token this leaves room for the prompt and the answer.
+const DefaultMaxInputChars = 12000
+
+// DefaultTemperature is the sampling temperature used for analysis
+const DefaultTemperature = 0.3
+
 type Processor struct {
-	client *Client
+	client          *Client
+	maxInputChars   int
+	temperature     float32
+	promptTemplate  string
+	examples        []PromptExample
+	embeddingFields EmbeddingFields
 }
 
 func NewProcessor(client *Client) *Processor {
-	return &Processor{client: client}
+	return &Processor{
+		client:          client,
+		maxInputChars:   DefaultMaxInputChars,
+		temperature:     DefaultTemperature,
+		promptTemplate:  DefaultPromptTemplate,
+		examples:        DefaultPromptExamples,
+		embeddingFields: DefaultEmbeddingFields,
+	}
+}
+
+// WithTemperature sets the sampling temperature for journal analysis. Higher
+// values give more varied summaries; 0 is deterministic.
+func (p *Processor) WithTemperature(t float32) (*Processor, error) {
+	if t < 0 || t > 2 {
+		return nil, fmt.Errorf("temperature must be between 0 and 2, got %v", t)
+	}
+	p.temperature = t
+	return p, nil
+}
+
+// WithPromptTemplate replaces the analysis prompt. The template must contain
+// ContentPlaceholder exactly once, which is replaced with the entry content,
+// and may contain ExamplesPlaceholder once for the few-shot examples. The
+// output schema is still enforced, so custom prompts only change how the
+// model fills in the fields, not their shape.
+func (p *Processor) WithPromptTemplate(template string) (*Processor, error) {
+	if n := strings.Count(template, ContentPlaceholder); n != 1 {
+		return nil, fmt.Errorf("prompt template must contain %s exactly once, found %d", ContentPlaceholder, n)
+	}
+	if n := strings.Count(template, ExamplesPlaceholder); n > 1 {
+		return nil, fmt.Errorf("prompt template may contain %s at most once, found %d", ExamplesPlaceholder, n)
+	}
+	p.promptTemplate = template
+	return p, nil
+}
+
+// WithPromptExamples replaces the few-shot examples shown to the model, for
+// example with entries from a specialized journal. No examples leaves the
+// prompt zero-shot.
+func (p *Processor) WithPromptExamples(examples []PromptExample) (*Processor, error) {
+	for i, example := range examples {
+		if err := example.validate(); err != nil {
+			return nil, fmt.Errorf("invalid prompt example %d: %w", i+1, err)
+		}
+	}
+	p.examples = examples
+	return p, nil
+}
+
+// WithMaxInputLength sets how many characters of an entry are sent for
+// analysis; longer entries are truncated. A value <= 0 disables the limit.
+func (p *Processor) WithMaxInputLength(chars int) *Processor {
+	p.maxInputChars = chars
+	return p
 }
 
 // JournalAnalysis represents the structured output from Qwen
 type JournalAnalysis struct {
-	Summary   string         `json:"summary"`
-	Entities  []string       `json:"entities"`
-	Topics    []string       `json:"topics"`
-	Sentiment string         `json:"sentiment"`
-	URLs      []URLToFetch   `json:"urls_to_fetch"`
-	Metadata  map[string]any `json:"metadata"`
+	Summary   string             `json:"summary"`
+	Entities  []string           `json:"entities"`
+	Topics    []string           `json:"topics"`
+	Sentiment string             `json:"sentiment"`
+	Mood      map[string]float64 `json:"mood"`
+	URLs      []URLToFetch       `json:"urls_to_fetch"`
+	Metadata  map[string]any     `json:"metadata"`
 }
 
 type URLToFetch struct {
@@ -33,8 +103,158 @@ type URLToFetch struct {
 	Reason string `json:"reason"`
 }
 
-// ProcessJournalEntry analyzes a journal entry and returns structured data
-func (p *Processor) ProcessJournalEntry(content string) (*models.ProcessedData, error) {
+// ProcessJournalEntry analyzes a journal entry and returns structured data.
+// The metadata's AnalysisQualityKey scores how complete the analysis looks.
+func (p *Processor) ProcessJournalEntry(ctx context.Context, content string) (*models.ProcessedData, error) {
+	processedData, _, err := p.ProcessJournalEntryWithUsage(ctx, content)
+	return processedData, err
+}
+
+// ProcessJournalEntryWithUsage is ProcessJournalEntry that also reports the
+// model's token counts and timings
+func (p *Processor) ProcessJournalEntryWithUsage(ctx context.Context, content string) (*models.ProcessedData, Usage, error) {
+	input, truncated := p.guardInput(content)
+
+	response, err := p.client.Chat(ctx, p.analysisRequest(input))
+	if err != nil {
+		return nil, Usage{}, fmt.Errorf("failed to process with Qwen: %w", err)
+	}
+
+	processedData, err := parseAnalysis(response.Message.Content)
+	if err != nil {
+		return nil, response.Usage(), err
+	}
+	markTruncated(processedData, truncated, content)
+	markQuality(processedData, input)
+
+	return processedData, response.Usage(), nil
+}
+
+// guardInput truncates content that would overflow the model's context
+// window, cutting at the last whitespace before the limit when possible
+func (p *Processor) guardInput(content string) (string, bool) {
+	if p.maxInputChars <= 0 {
+		return content, false
+	}
+
+	runes := []rune(content)
+	if len(runes) <= p.maxInputChars {
+		return content, false
+	}
+
+	cut := p.maxInputChars
+	for i := cut; i > cut*9/10; i-- {
+		if unicode.IsSpace(runes[i]) {
+			cut = i
+			break
+		}
+	}
+
+	log.Printf("WARNING: entry content is %d characters, truncating to %d for analysis", len(runes), cut)
+	return string(runes[:cut]), true
+}
+
+// markTruncated records in the metadata that only part of content was analyzed
+func markTruncated(processedData *models.ProcessedData, truncated bool, content string) {
+	if !truncated {
+		return
+	}
+	processedData.Metadata["truncated"] = true
+	processedData.Metadata["original_length"] = len([]rune(content))
+}
+
+// AnalysisProgress describes a streaming analysis that is still running
+type AnalysisProgress struct {
+	Tokens         int    `json:"tokens"`
+	PartialSummary string `json:"partial_summary"`
+}
+
+// progressEvery is how many streamed chunks pass between progress callbacks
+const progressEvery = 16
+
+// ProcessJournalEntryStreaming analyzes a journal entry like
+// ProcessJournalEntry but streams the model output, calling onProgress
+// periodically with the number of chunks received and the summary generated
+// so far. If the server does not support streaming it falls back to the
+// non-streaming request.
+func (p *Processor) ProcessJournalEntryStreaming(ctx context.Context, content string, onProgress func(AnalysisProgress)) (*models.ProcessedData, Usage, error) {
+	input, truncated := p.guardInput(content)
+	request := p.analysisRequest(input)
+
+	var partial strings.Builder
+	tokens := 0
+	response, err := p.client.ChatStream(ctx, request, func(chunk ChatResponse) {
+		partial.WriteString(chunk.Message.Content)
+		tokens++
+		if onProgress != nil && tokens%progressEvery == 0 {
+			onProgress(AnalysisProgress{
+				Tokens:         tokens,
+				PartialSummary: partialSummary(partial.String()),
+			})
+		}
+	})
+	if errors.Is(err, ErrStreamingUnsupported) {
+		log.Printf("Streaming analysis unavailable, falling back: %v", err)
+		return p.ProcessJournalEntryWithUsage(ctx, content)
+	}
+	if err != nil {
+		return nil, Usage{}, fmt.Errorf("failed to process with Qwen: %w", err)
+	}
+
+	processedData, err := parseAnalysis(response.Message.Content)
+	if err != nil {
+		return nil, response.Usage(), err
+	}
+	markTruncated(processedData, truncated, content)
+	markQuality(processedData, input)
+
+	return processedData, response.Usage(), nil
+}
+
+// partialSummary extracts the (possibly unterminated) summary string from a
+// partially generated analysis JSON document, decoding its escapes
+func partialSummary(partialJSON string) string {
+	key := strings.Index(partialJSON, `"summary"`)
+	if key < 0 {
+		return ""
+	}
+	rest := partialJSON[key+len(`"summary"`):]
+	open := strings.Index(rest, `"`)
+	if open < 0 {
+		return ""
+	}
+	rest = rest[open+1:]
+
+	// The string ends at the first unescaped quote, or is still being generated
+	end := len(rest)
+	for i := 0; i < len(rest); i++ {
+		if rest[i] == '\\' {
+			i++
+		} else if rest[i] == '"' {
+			end = i
+			break
+		}
+	}
+	raw := rest[:end]
+
+	// Generation may have stopped inside an escape such as \u00e9; drop the
+	// incomplete escape rather than show it. No escape is longer than six
+	// bytes.
+	for cut := 0; cut <= 6 && cut <= len(raw); cut++ {
+		var summary string
+		if json.Unmarshal([]byte(`"`+raw[:len(raw)-cut]+`"`), &summary) == nil {
+			return summaryWhitespace.Replace(summary)
+		}
+	}
+	return ""
+}
+
+// summaryWhitespace flattens line breaks so a partial summary fits on one line
+var summaryWhitespace = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ")
+
+// analysisRequest builds the structured-output chat request for an entry.
+// The schema is always enforced through Format, whatever prompt is used.
+func (p *Processor) analysisRequest(content string) ChatRequest {
 	// Define the JSON schema for structured output
 	schema := json.RawMessage(`{
 		"type": "object",
@@ -43,6 +263,11 @@ func (p *Processor) ProcessJournalEntry(content string) (*models.ProcessedData,
 			"entities": {"type": "array", "items": {"type": "string"}, "description": "Named entities mentioned (people, places, organizations)"},
 			"topics": {"type": "array", "items": {"type": "string"}, "description": "Main topics or themes"},
 			"sentiment": {"type": "string", "enum": ["positive", "negative", "neutral", "mixed"], "description": "Overall sentiment"},
+			"mood": {
+				"type": "object",
+				"additionalProperties": {"type": "number", "minimum": 0, "maximum": 1},
+				"description": "Emotions present in the entry (e.g. joy, anxiety, gratitude) with intensities from 0 to 1"
+			},
 			"urls_to_fetch": {
 				"type": "array",
 				"items": {
@@ -60,59 +285,33 @@ func (p *Processor) ProcessJournalEntry(content string) (*models.ProcessedData,
 		"required": ["summary", "entities", "topics", "sentiment", "urls_to_fetch", "metadata"]
 	}`)
 
-	prompt := fmt.Sprintf(`Analyze the following journal entry and extract structured information according to the provided schema.
-
-Example Analysis:
-Journal Entry: "Had an amazing meeting with Sarah Chen from TechCorp today at their Seattle office. We discussed the new AI project and she seemed really excited about our proposal. Check out their recent blog post about ML trends: https://techcorp.com/blog/ml-2024. Feeling optimistic about this partnership!"
-
-Expected Output:
-- Summary: "Successful meeting with TechCorp representative about AI project proposal, positive reception"
-- Entities: ["Sarah Chen" (person), "TechCorp" (organization), "Seattle" (place)]
-- Topics: ["business meeting", "AI project", "partnership", "machine learning"]
-- Sentiment: "positive"
-- URLs: ["https://techcorp.com/blog/ml-2024"]
-
-Now analyze this journal entry:
-%s
-
-Extract:
-1. A concise summary (2-3 sentences max)
-2. Named entities (people, places, organizations, products) - be specific
-3. Main topics or themes (3-5 most relevant)
-4. Overall sentiment (positive, negative, neutral, or mixed)
-5. Any URLs mentioned that would provide valuable context
-6. Additional metadata that might be useful for search and organization
+	// One pass, so placeholders inside the content or examples stay as typed
+	prompt := strings.NewReplacer(
+		ContentPlaceholder, content,
+		ExamplesPlaceholder, renderExamples(p.examples),
+	).Replace(p.promptTemplate)
 
-Important:
-- Keep summaries factual and concise
-- Extract full names when mentioned
-- Identify specific locations, not just general areas
-- For sentiment, consider the overall emotional tone
-- Only extract complete, valid URLs`, content)
-
-	request := ChatRequest{
-		Model: "qwen3:8b",
+	return ChatRequest{
+		Model: ChatModel,
 		Messages: []Message{
 			{Role: "user", Content: prompt},
 		},
 		Format: schema,
 		Stream: false,
 		Options: Options{
-			Temperature: 0.3,
+			Temperature: temperature(p.temperature),
 		},
 	}
+}
 
-	response, err := p.client.Chat(request)
-	if err != nil {
-		return nil, fmt.Errorf("failed to process with Qwen: %w", err)
-	}
-
+// parseAnalysis converts the model's JSON answer into ProcessedData
+func parseAnalysis(raw string) (*models.ProcessedData, error) {
 	// Log raw response for debugging
-	log.Printf("Raw Qwen response: %s", response.Message.Content)
+	log.Printf("Raw Qwen response: %s", raw)
 
 	var analysis JournalAnalysis
-	if err := json.Unmarshal([]byte(response.Message.Content), &analysis); err != nil {
-		log.Printf("Failed to parse JSON response: %v\nResponse was: %s", err, response.Message.Content)
+	if err := json.Unmarshal([]byte(raw), &analysis); err != nil {
+		log.Printf("Failed to parse JSON response: %v\nResponse was: %s", err, raw)
 		return nil, fmt.Errorf("failed to parse Qwen response: %w", err)
 	}
 
@@ -132,6 +331,7 @@ Important:
 		Entities:      analysis.Entities,
 		Topics:        analysis.Topics,
 		Sentiment:     analysis.Sentiment,
+		Mood:          normalizeMood(analysis.Mood),
 		Metadata:      analysis.Metadata,
 		ExtractedURLs: make([]models.ExtractedURL, 0, len(analysis.URLs)),
 	}
@@ -160,23 +360,30 @@ Important:
 	return processedData, nil
 }
 
-// CreateEmbedding generates embeddings for journal entry with metadata
-func (p *Processor) CreateEmbedding(entry models.JournalEntry) ([]float32, error) {
-	// Combine content with metadata for richer embeddings
-	embeddingText := fmt.Sprintf("%s\n\nSummary: %s\nTopics: %s\nEntities: %s\nSentiment: %s",
-		entry.Content,
-		entry.ProcessedData.Summary,
-		strings.Join(entry.ProcessedData.Topics, ", "),
-		strings.Join(entry.ProcessedData.Entities, ", "),
-		entry.ProcessedData.Sentiment,
-	)
+// normalizeMood lowercases emotion names and clamps intensities to [0, 1],
+// since models do not always respect the schema bounds
+func normalizeMood(mood map[string]float64) map[string]float64 {
+	if len(mood) == 0 {
+		return nil
+	}
 
-	// Add extracted URL content if available
-	for _, url := range entry.ProcessedData.ExtractedURLs {
-		embeddingText += fmt.Sprintf("\n\nFrom %s: %s", url.URL, url.Title)
+	normalized := make(map[string]float64, len(mood))
+	for emotion, intensity := range mood {
+		emotion = strings.ToLower(strings.TrimSpace(emotion))
+		if emotion == "" {
+			continue
+		}
+		normalized[emotion] = math.Max(0, math.Min(1, intensity))
 	}
+	return normalized
+}
+
+// CreateEmbedding generates embeddings for journal entry with metadata
+func (p *Processor) CreateEmbedding(ctx context.Context, entry models.JournalEntry) ([]float32, error) {
+	// Combine content with metadata for richer embeddings
+	embeddingText := p.embeddingFields.embeddingText(entry)
 
-	embeddings, err := p.client.CreateEmbedding("nomic-embed-text", embeddingText)
+	embeddings, err := p.client.CreateEmbedding(ctx, EmbeddingModel, embeddingText)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create embedding: %w", err)
 	}
@@ -184,27 +391,27 @@ func (p *Processor) CreateEmbedding(entry models.JournalEntry) ([]float32, error
 	return embeddings, nil
 }
 
-// ProcessWithSchema processes a prompt and returns structured JSON according to the provided schema
+// ProcessWithSchema processes a prompt and returns structured JSON according
+// to a JSON Schema generated from the type of schemaExample
 func (p *Processor) ProcessWithSchema(ctx context.Context, prompt string, schemaExample interface{}) (string, error) {
-	// Generate schema from the example struct
-	schemaJSON, err := json.Marshal(schemaExample)
+	schemaJSON, err := GenerateSchema(schemaExample)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal schema: %w", err)
+		return "", fmt.Errorf("failed to generate schema: %w", err)
 	}
 
 	request := ChatRequest{
-		Model: "qwen3:8b",
+		Model: ChatModel,
 		Messages: []Message{
 			{Role: "user", Content: prompt},
 		},
-		Format: json.RawMessage(schemaJSON),
+		Format: schemaJSON,
 		Stream: false,
 		Options: Options{
-			Temperature: 0.3,
+			Temperature: temperature(DefaultTemperature),
 		},
 	}
 
-	response, err := p.client.Chat(request)
+	response, err := p.client.Chat(ctx, request)
 	if err != nil {
 		return "", fmt.Errorf("failed to process with Qwen: %w", err)
 	}
@@ -0,0 +1,253 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/journal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithPromptTemplate(t *testing.T) {
+	p := NewProcessor(nil)
+
+	_, err := p.WithPromptTemplate("Summarize this entry.")
+	assert.Error(t, err, "template without placeholder")
+
+	_, err = p.WithPromptTemplate("{{content}} and again {{content}}")
+	assert.Error(t, err, "template with two placeholders")
+
+	_, err = p.WithPromptTemplate("Extract recipes from: {{content}}")
+	require.NoError(t, err)
+
+	request := p.analysisRequest("pancakes with blueberries")
+	assert.Equal(t, "Extract recipes from: pancakes with blueberries", request.Messages[0].Content)
+	assert.NotEmpty(t, request.Format, "schema must stay enforced for custom prompts")
+}
+
+func TestPromptExamples(t *testing.T) {
+	p := NewProcessor(nil)
+	prompt := p.analysisRequest("x").Messages[0].Content
+	assert.Contains(t, prompt, "Example Analysis:\nJournal Entry: \"Had an amazing meeting with Sarah Chen")
+	assert.Contains(t, prompt, `- Mood: {"optimism": 0.8, "excitement": 0.6}`)
+	assert.NotContains(t, prompt, ExamplesPlaceholder)
+
+	examples, err := ParsePromptExamples([]byte(`[
+		{"entry": "BP 150/95 after the new dose; Dr. Patel says \"watch & wait\".",
+		 "output": {"summary": "Blood pressure still high on the new dose", "entities": ["Dr. Patel"], "topics": ["blood pressure", "medication"], "sentiment": "negative", "mood": {"worry": 0.5}, "urls": []}},
+		{"entry": "Filed the motion {{content}} today.",
+		 "output": {"summary": "Filed a motion", "entities": [], "topics": ["litigation"], "sentiment": "neutral"}}
+	]`))
+	require.NoError(t, err)
+	_, err = p.WithPromptExamples(examples)
+	require.NoError(t, err)
+
+	prompt = p.analysisRequest("today's entry").Messages[0].Content
+	assert.NotContains(t, prompt, "Sarah Chen")
+	assert.Contains(t, prompt, `Example Analysis 1:
+Journal Entry: "BP 150/95 after the new dose; Dr. Patel says \"watch & wait\"."
+
+Expected Output:
+- Summary: "Blood pressure still high on the new dose"
+- Entities: ["Dr. Patel"]
+- Topics: ["blood pressure", "medication"]
+- Sentiment: "negative"
+- Mood: {"worry": 0.5}
+- URLs: []`)
+	// Placeholders inside examples are left as written
+	assert.Contains(t, prompt, `Journal Entry: "Filed the motion {{content}} today."`)
+	assert.Contains(t, prompt, "Now analyze this journal entry:\ntoday's entry")
+
+	// No examples leaves the prompt zero-shot
+	_, err = p.WithPromptExamples(nil)
+	require.NoError(t, err)
+	assert.NotContains(t, p.analysisRequest("x").Messages[0].Content, "Example Analysis")
+}
+
+func TestParsePromptExamplesRejectsInvalidExamples(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"not an array", `{"entry": "x"}`},
+		{"null", `null`},
+		{"empty entry", `[{"entry": " ", "output": {"sentiment": "positive"}}]`},
+		{"sentiment outside schema", `[{"entry": "x", "output": {"sentiment": "elated"}}]`},
+		{"mood out of range", `[{"entry": "x", "output": {"sentiment": "positive", "mood": {"joy": 2}}}]`},
+		{"field outside schema", `[{"entry": "x", "output": {"sentiment": "positive", "diagnosis": "flu"}}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParsePromptExamples([]byte(tt.json))
+			assert.Error(t, err)
+		})
+	}
+
+	examples, err := ParsePromptExamples([]byte(`[]`))
+	require.NoError(t, err)
+	assert.Empty(t, examples)
+}
+
+func TestWithTemperature(t *testing.T) {
+	p := NewProcessor(nil)
+	assert.Equal(t, float32(DefaultTemperature), *p.analysisRequest("x").Options.Temperature)
+
+	_, err := p.WithTemperature(0)
+	require.NoError(t, err)
+	assert.Equal(t, float32(0), *p.analysisRequest("x").Options.Temperature)
+
+	_, err = p.WithTemperature(3)
+	assert.Error(t, err)
+}
+
+func TestParseAnalysisMood(t *testing.T) {
+	data, err := parseAnalysis(`{"summary":"s","entities":[],"topics":[],"sentiment":"mixed","urls_to_fetch":[],"metadata":{},
+		"mood":{"Joy":0.7,"anxiety":1.4,"gratitude":-0.2,"  ":0.5}}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"joy": 0.7, "anxiety": 1, "gratitude": 0}, data.Mood)
+
+	data, err = parseAnalysis(`{"summary":"s","entities":[],"topics":[],"sentiment":"neutral","urls_to_fetch":[],"metadata":{}}`)
+	require.NoError(t, err)
+	assert.Nil(t, data.Mood, "older analyses without mood stay empty")
+}
+
+func TestGuardInput(t *testing.T) {
+	p := NewProcessor(nil).WithMaxInputLength(20)
+
+	tests := []struct {
+		name      string
+		content   string
+		want      string
+		truncated bool
+	}{
+		{"under the limit", "short", "short", false},
+		{"exactly the limit", "01234567890123456789", "01234567890123456789", false},
+		{"one over the limit", "01234567890123456789x", "01234567890123456789", true},
+		{"cuts at whitespace near the limit", "abcdefghijklmnopqrs tuvwxyz", "abcdefghijklmnopqrs", true},
+		{"ignores whitespace far from the limit", "ab cdefghijklmnopqrstuvwxyz", "ab cdefghijklmnopqrs", true},
+		// The limit counts characters, so multi-byte runes are never split
+		{"multi-byte runes at the cut", strings.Repeat("é", 21), strings.Repeat("é", 20), true},
+		{"emoji at the cut", "1234567890123456789🌞🌞", "1234567890123456789🌞", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, truncated := p.guardInput(tt.content)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.truncated, truncated)
+			assert.True(t, utf8.ValidString(got))
+		})
+	}
+
+	unlimited := NewProcessor(nil).WithMaxInputLength(0)
+	got, truncated := unlimited.guardInput("0123456789x")
+	assert.Equal(t, "0123456789x", got)
+	assert.False(t, truncated)
+}
+
+func TestMarkTruncated(t *testing.T) {
+	data := &models.ProcessedData{Metadata: map[string]any{}}
+	markTruncated(data, false, "ééé")
+	assert.NotContains(t, data.Metadata, "truncated")
+
+	// The original length is reported in characters, not bytes
+	markTruncated(data, true, "ééé")
+	assert.Equal(t, true, data.Metadata["truncated"])
+	assert.Equal(t, 3, data.Metadata["original_length"])
+}
+
+func TestProcessJournalEntryStreaming(t *testing.T) {
+	analysis := `{"summary":"A calm day","entities":["Alice"],"topics":["walks"],"sentiment":"positive","urls_to_fetch":[],"metadata":{}}`
+	// One character per chunk, so progress is reported several times
+	pieces := make([]string, 0, len(analysis))
+	for _, r := range analysis {
+		pieces = append(pieces, string(r))
+	}
+	server := streamServer(pieces)
+	defer server.Close()
+
+	var progress []AnalysisProgress
+	data, usage, err := NewProcessor(NewClient(server.URL)).ProcessJournalEntryStreaming(context.Background(), "Walked with Alice.", func(p AnalysisProgress) {
+		progress = append(progress, p)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "A calm day", data.Summary)
+	assert.Equal(t, []string{"walks"}, data.Topics)
+	assert.Equal(t, len(pieces), usage.GenerationTokens)
+
+	require.NotEmpty(t, progress)
+	assert.Equal(t, progressEvery, progress[0].Tokens)
+	assert.Equal(t, "A calm day", progress[len(progress)-1].PartialSummary)
+}
+
+func TestProcessJournalEntryStreamingFallback(t *testing.T) {
+	analysis := `{"summary":"s","entities":[],"topics":[],"sentiment":"neutral","urls_to_fetch":[],"metadata":{}}`
+
+	tests := []struct {
+		name         string
+		streamStatus int
+		wantErr      bool
+		wantRequests int
+	}{
+		{name: "streaming unsupported falls back", streamStatus: http.StatusNotImplemented, wantRequests: 2},
+		{name: "overloaded server is not retried", streamStatus: http.StatusServiceUnavailable, wantErr: true, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				var request ChatRequest
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+				if request.Stream {
+					http.Error(w, "no", tt.streamStatus)
+					return
+				}
+				json.NewEncoder(w).Encode(ChatResponse{Done: true, Message: Message{Content: analysis}})
+			}))
+			defer server.Close()
+
+			data, _, err := NewProcessor(NewClient(server.URL)).ProcessJournalEntryStreaming(context.Background(), "entry", nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "s", data.Summary)
+			}
+			assert.Equal(t, tt.wantRequests, requests)
+		})
+	}
+}
+
+func TestPartialSummary(t *testing.T) {
+	tests := []struct {
+		name    string
+		partial string
+		want    string
+	}{
+		{"no summary yet", `{"entities":["A"]`, ""},
+		{"key without value", `{"summary":`, ""},
+		{"unterminated", `{"summary":"Went hiking wi`, "Went hiking wi"},
+		{"complete", `{"summary":"Went hiking.","topics":["x"]}`, "Went hiking."},
+		{"escaped quote", `{"summary":"She said \"hi\" back"`, `She said "hi" back`},
+		{"newlines become spaces", `{"summary":"Line one\nLine two\ttab"`, "Line one Line two tab"},
+		{"unicode escape", `{"summary":"Café visit"`, "Café visit"},
+		{"surrogate pair", `{"summary":"Sunny 🌞 day"}`, "Sunny 🌞 day"},
+		{"cut inside unicode escape", `{"summary":"Caf\u00`, "Caf"},
+		{"cut after backslash", `{"summary":"Caf\`, "Caf"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, partialSummary(tt.partial))
+		})
+	}
+}
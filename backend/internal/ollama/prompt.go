@@ -0,0 +1,177 @@
+package ollama
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ContentPlaceholder marks where the entry content goes in a prompt template
+const ContentPlaceholder = "{{content}}"
+
+// ExamplesPlaceholder marks where the few-shot examples go in a prompt
+// template. Templates without it are sent without examples.
+const ExamplesPlaceholder = "{{examples}}"
+
+// DefaultPromptTemplate is the built-in journal analysis prompt
+const DefaultPromptTemplate = `Analyze the following journal entry and extract structured information according to the provided schema.
+
+{{examples}}
+
+Now analyze this journal entry:
+{{content}}
+
+Extract:
+1. A concise summary (2-3 sentences max)
+2. Named entities (people, places, organizations, products) - be specific
+3. Main topics or themes (3-5 most relevant)
+4. Overall sentiment (positive, negative, neutral, or mixed)
+5. The emotions expressed (joy, anxiety, gratitude, frustration, etc.) with intensities from 0 to 1
+6. Any URLs mentioned that would provide valuable context
+7. Additional metadata that might be useful for search and organization
+
+Important:
+- Keep summaries factual and concise
+- Extract full names when mentioned
+- Identify specific locations, not just general areas
+- For sentiment, consider the overall emotional tone
+- For mood, only include emotions actually expressed in the entry
+- Only extract complete, valid URLs`
+
+// Sentiments are the sentiment classes the analysis schema allows
+var Sentiments = []string{"positive", "negative", "neutral", "mixed"}
+
+// PromptExample is a few-shot example for the analysis prompt: an entry and
+// the analysis expected for it
+type PromptExample struct {
+	Entry  string        `json:"entry"`
+	Output ExampleOutput `json:"output"`
+}
+
+// ExampleOutput is the expected analysis of a PromptExample. It has the
+// fields of the analysis schema, which examples cannot change.
+type ExampleOutput struct {
+	Summary   string             `json:"summary"`
+	Entities  []string           `json:"entities"`
+	Topics    []string           `json:"topics"`
+	Sentiment string             `json:"sentiment"`
+	Mood      map[string]float64 `json:"mood"`
+	URLs      []string           `json:"urls"`
+}
+
+// DefaultPromptExamples are the examples used unless others are configured
+var DefaultPromptExamples = []PromptExample{{
+	Entry: "Had an amazing meeting with Sarah Chen from TechCorp today at their Seattle office. We discussed the new AI project and she seemed really excited about our proposal. Check out their recent blog post about ML trends: https://techcorp.com/blog/ml-2024. Feeling optimistic about this partnership!",
+	Output: ExampleOutput{
+		Summary:   "Successful meeting with TechCorp representative about AI project proposal, positive reception",
+		Entities:  []string{"Sarah Chen", "TechCorp", "Seattle"},
+		Topics:    []string{"business meeting", "AI project", "partnership", "machine learning"},
+		Sentiment: "positive",
+		Mood:      map[string]float64{"optimism": 0.8, "excitement": 0.6},
+		URLs:      []string{"https://techcorp.com/blog/ml-2024"},
+	},
+}}
+
+// ParsePromptExamples reads a JSON array of examples, each with an "entry"
+// and an "output" holding summary, entities, topics, sentiment, mood and
+// urls. An empty array turns examples off.
+func ParsePromptExamples(data []byte) ([]PromptExample, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var examples []PromptExample
+	if err := decoder.Decode(&examples); err != nil {
+		return nil, fmt.Errorf("invalid prompt examples: %w", err)
+	}
+	if examples == nil {
+		return nil, fmt.Errorf("invalid prompt examples: expected a JSON array")
+	}
+	for i, example := range examples {
+		if err := example.validate(); err != nil {
+			return nil, fmt.Errorf("invalid prompt example %d: %w", i+1, err)
+		}
+	}
+	return examples, nil
+}
+
+// validate checks that the example's output is one the schema allows
+func (e PromptExample) validate() error {
+	if strings.TrimSpace(e.Entry) == "" {
+		return fmt.Errorf("entry is empty")
+	}
+	if !slices.Contains(Sentiments, e.Output.Sentiment) {
+		return fmt.Errorf("sentiment %q is not one of %s", e.Output.Sentiment, strings.Join(Sentiments, ", "))
+	}
+	for emotion, intensity := range e.Output.Mood {
+		if intensity < 0 || intensity > 1 {
+			return fmt.Errorf("mood %q intensity %v is outside 0 to 1", emotion, intensity)
+		}
+	}
+	return nil
+}
+
+// renderExamples formats examples for the prompt, numbering them when there
+// are several
+func renderExamples(examples []PromptExample) string {
+	blocks := make([]string, 0, len(examples))
+	for i, example := range examples {
+		heading := "Example Analysis:"
+		if len(examples) > 1 {
+			heading = fmt.Sprintf("Example Analysis %d:", i+1)
+		}
+
+		var b strings.Builder
+		b.WriteString(heading + "\n")
+		fmt.Fprintf(&b, "Journal Entry: %s\n\n", quote(example.Entry))
+		b.WriteString("Expected Output:\n")
+		fmt.Fprintf(&b, "- Summary: %s\n", quote(example.Output.Summary))
+		fmt.Fprintf(&b, "- Entities: %s\n", quoteList(example.Output.Entities))
+		fmt.Fprintf(&b, "- Topics: %s\n", quoteList(example.Output.Topics))
+		fmt.Fprintf(&b, "- Sentiment: %s\n", quote(example.Output.Sentiment))
+		fmt.Fprintf(&b, "- Mood: %s\n", formatMood(example.Output.Mood))
+		fmt.Fprintf(&b, "- URLs: %s", quoteList(example.Output.URLs))
+		blocks = append(blocks, b.String())
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// quote writes s as a JSON string, which keeps quotes and line breaks in
+// example entries from breaking the prompt layout
+func quote(s string) string {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func quoteList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = quote(item)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// formatMood lists emotions strongest first, ties in name order
+func formatMood(mood map[string]float64) string {
+	emotions := make([]string, 0, len(mood))
+	for emotion := range mood {
+		emotions = append(emotions, emotion)
+	}
+	sort.Slice(emotions, func(i, j int) bool {
+		if mood[emotions[i]] != mood[emotions[j]] {
+			return mood[emotions[i]] > mood[emotions[j]]
+		}
+		return emotions[i] < emotions[j]
+	})
+
+	parts := make([]string, len(emotions))
+	for i, emotion := range emotions {
+		parts[i] = fmt.Sprintf("%s: %v", quote(emotion), mood[emotion])
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
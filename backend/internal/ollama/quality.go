@@ -0,0 +1,59 @@
+package ollama
+
+import (
+	"math"
+	"strings"
+
+	"github.com/journal/internal/models"
+)
+
+// AnalysisQualityKey is the metadata key holding an analysis's quality score
+const AnalysisQualityKey = "analysis_quality"
+
+// AnalysisQuality scores from 0 to 1 how complete an analysis looks for the
+// content it was made from. The summary, entities and topics are each
+// compared with what content of that length usually yields, so a thin
+// summary or missing entities for a long entry score low while a one-line
+// note with no entities does not. It is a heuristic for spotting analyses
+// worth retrying, not a measure of accuracy.
+func AnalysisQuality(data *models.ProcessedData, content string) float64 {
+	words := float64(len(strings.Fields(content)))
+
+	// Expected amounts grow with the entry and level off for long ones
+	summaryWords := math.Min(math.Max(words/8, 3), 15)
+	entities := math.Min(words/50, 3)
+	topics := math.Min(math.Max(words/30, 1), 3)
+
+	score := 0.4*ratio(float64(len(strings.Fields(data.Summary))), summaryWords) +
+		0.25*ratio(float64(len(nonBlank(data.Entities))), entities) +
+		0.25*ratio(float64(len(nonBlank(data.Topics))), topics)
+	if strings.TrimSpace(data.Sentiment) != "" {
+		score += 0.1
+	}
+
+	return math.Round(score*100) / 100
+}
+
+// ratio is how much of want got covers, capped at 1; nothing wanted is
+// fully covered
+func ratio(got, want float64) float64 {
+	if want < 1 {
+		return 1
+	}
+	return math.Min(got/want, 1)
+}
+
+func nonBlank(values []string) []string {
+	var kept []string
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// markQuality records the analysis's quality score in its metadata
+func markQuality(processedData *models.ProcessedData, content string) {
+	processedData.Metadata[AnalysisQualityKey] = AnalysisQuality(processedData, content)
+}
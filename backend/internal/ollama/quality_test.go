@@ -0,0 +1,28 @@
+package ollama
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/journal/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalysisQuality(t *testing.T) {
+	rich := strings.Repeat("Met Priya at the Lisbon office to plan the product launch and budget. ", 20)
+
+	full := &models.ProcessedData{
+		Summary:   "Planned the product launch and its budget with Priya at the Lisbon office over several meetings",
+		Entities:  []string{"Priya", "Lisbon"},
+		Topics:    []string{"product launch", "budget", "planning"},
+		Sentiment: "neutral",
+	}
+	assert.Greater(t, AnalysisQuality(full, rich), 0.9)
+
+	thin := &models.ProcessedData{Summary: "Meeting", Entities: []string{}, Topics: []string{""}, Sentiment: "neutral"}
+	assert.Less(t, AnalysisQuality(thin, rich), 0.2)
+
+	// A short note is not expected to name anyone
+	note := &models.ProcessedData{Summary: "A quiet day at home", Topics: []string{"rest"}, Sentiment: "positive"}
+	assert.Equal(t, 1.0, AnalysisQuality(note, "Quiet day at home, read a book."))
+}
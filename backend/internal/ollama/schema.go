@@ -0,0 +1,147 @@
+package ollama
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// GenerateSchema builds a JSON Schema for the type of v, following its json
+// struct tags. Fields are required unless tagged omitempty, and an optional
+// `description` struct tag is copied into the schema to guide the model.
+func GenerateSchema(v interface{}) (json.RawMessage, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("cannot generate a schema for nil")
+	}
+
+	schema, err := schemaForType(t, map[reflect.Type]bool{})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(schema)
+}
+
+func schemaForType(t reflect.Type, visiting map[reflect.Type]bool) (map[string]interface{}, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	case t == rawMessageType:
+		return map[string]interface{}{}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.Interface:
+		return map[string]interface{}{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json writes byte slices as base64 strings
+			return map[string]interface{}{"type": "string"}, nil
+		}
+		items, err := schemaForType(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type %s", t.Key())
+		}
+		values, err := schemaForType(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		return schemaForStruct(t, visiting)
+	default:
+		return nil, fmt.Errorf("unsupported type %s", t)
+	}
+}
+
+func schemaForStruct(t reflect.Type, visiting map[reflect.Type]bool) (map[string]interface{}, error) {
+	if visiting[t] {
+		return nil, fmt.Errorf("recursive type %s", t)
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	properties := map[string]interface{}{}
+	required := []string{}
+
+	if err := addStructFields(t, properties, &required, visiting); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}, nil
+}
+
+// addStructFields adds the JSON-visible fields of t, flattening embedded
+// structs the way encoding/json does
+func addStructFields(t reflect.Type, properties map[string]interface{}, required *[]string, visiting map[reflect.Type]bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := addStructFields(embedded, properties, required, visiting); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property, err := schemaForType(field.Type, visiting)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if description := field.Tag.Get("description"); description != "" {
+			property["description"] = description
+		}
+		properties[name] = property
+
+		if !strings.Contains(options, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+	return nil
+}
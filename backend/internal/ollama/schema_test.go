@@ -0,0 +1,60 @@
+package ollama
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateSchema(t *testing.T) {
+	type cause struct {
+		Cause       string  `json:"cause" description:"Short name of the cause"`
+		Probability float64 `json:"probability"`
+		Solution    string  `json:"solution,omitempty"`
+	}
+	type response struct {
+		Causes    []cause            `json:"causes"`
+		Scores    map[string]float64 `json:"scores"`
+		CreatedAt *time.Time         `json:"created_at,omitempty"`
+		Internal  string             `json:"-"`
+	}
+
+	raw, err := GenerateSchema(response{})
+	require.NoError(t, err)
+
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &schema))
+
+	assert.Equal(t, "object", schema["type"])
+	assert.ElementsMatch(t, []interface{}{"causes", "scores"}, schema["required"])
+
+	properties := schema["properties"].(map[string]interface{})
+	assert.NotContains(t, properties, "Internal")
+	assert.Equal(t, map[string]interface{}{"type": "string", "format": "date-time"}, properties["created_at"])
+	assert.Equal(t, map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": map[string]interface{}{"type": "number"},
+	}, properties["scores"])
+
+	causes := properties["causes"].(map[string]interface{})
+	assert.Equal(t, "array", causes["type"])
+	item := causes["items"].(map[string]interface{})
+	assert.Equal(t, "object", item["type"])
+	assert.ElementsMatch(t, []interface{}{"cause", "probability"}, item["required"])
+	itemProperties := item["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string", "description": "Short name of the cause"}, itemProperties["cause"])
+	assert.Equal(t, map[string]interface{}{"type": "number"}, itemProperties["probability"])
+}
+
+func TestGenerateSchemaRejectsUnsupportedTypes(t *testing.T) {
+	_, err := GenerateSchema(struct {
+		Callback func() `json:"callback"`
+	}{})
+	assert.Error(t, err)
+
+	_, err = GenerateSchema(nil)
+	assert.Error(t, err)
+}
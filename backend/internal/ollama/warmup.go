@@ -0,0 +1,31 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultWarmupTimeout bounds each warmup request. Loading a model from disk
+// on a cold start can take far longer than a normal request is allowed.
+const DefaultWarmupTimeout = 5 * time.Minute
+
+// Warmup loads ChatModel and EmbeddingModel into memory, so the first real
+// analysis does not pay the load time and trip the normal timeouts. The chat
+// request has no messages, which Ollama treats as load-only; the embedding
+// request embeds a single word. Both models are tried even if one fails.
+func (c *Client) Warmup(timeout time.Duration) error {
+	warm := *c
+	warm.chatTimeout = timeout
+	warm.embeddingTimeout = timeout
+
+	var errs []error
+	if _, err := warm.Chat(context.Background(), ChatRequest{Model: ChatModel, Messages: []Message{}}); err != nil {
+		errs = append(errs, fmt.Errorf("failed to load %s: %w", ChatModel, err))
+	}
+	if _, err := warm.CreateEmbedding(context.Background(), EmbeddingModel, "warmup"); err != nil {
+		errs = append(errs, fmt.Errorf("failed to load %s: %w", EmbeddingModel, err))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,67 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmupLoadsBothModels(t *testing.T) {
+	var mu sync.Mutex
+	loaded := map[string]bool{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Slower than the client's normal timeouts, as a cold load would be
+		time.Sleep(30 * time.Millisecond)
+
+		var req struct {
+			Model    string          `json:"model"`
+			Messages json.RawMessage `json:"messages"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		mu.Lock()
+		loaded[req.Model] = true
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/api/chat":
+			assert.JSONEq(t, `[]`, string(req.Messages), "chat warmup should be load-only")
+			w.Write([]byte(`{"model":"qwen3:8b","message":{"role":"assistant","content":""},"done":true}`))
+		case "/api/embed":
+			w.Write([]byte(`{"model":"nomic-embed-text","embeddings":[[0.1,0.2]]}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL).
+		WithTimeout(10 * time.Millisecond).
+		WithEmbeddingTimeout(10 * time.Millisecond)
+
+	require.NoError(t, client.Warmup(time.Second))
+	assert.True(t, loaded[ChatModel])
+	assert.True(t, loaded[EmbeddingModel])
+
+	// The client's own timeouts are unchanged
+	_, err := client.CreateEmbedding(context.Background(), EmbeddingModel, "hello")
+	assert.Error(t, err)
+}
+
+func TestWarmupReportsEachFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"model not found, try pulling it first"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	err := NewClient(server.URL).Warmup(time.Second)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrModelNotFound)
+	assert.Contains(t, err.Error(), ChatModel)
+	assert.Contains(t, err.Error(), EmbeddingModel)
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// topAnalyticsTopics is how many topics GetAnalytics reports per month
+const topAnalyticsTopics = 10
+
+// AnalyticsParams selects the date range and bucket size for GetAnalytics
+type AnalyticsParams struct {
+	StartDate   *time.Time `json:"start_date"`
+	EndDate     *time.Time `json:"end_date"`
+	Granularity string     `json:"granularity"` // day, week or month (default day)
+}
+
+// PeriodCount is the number of entries created in one time bucket
+type PeriodCount struct {
+	Period time.Time `json:"period"`
+	Count  int       `json:"count"`
+}
+
+// SentimentCount is the number of entries with a sentiment in one time bucket
+type SentimentCount struct {
+	Period    time.Time `json:"period"`
+	Sentiment string    `json:"sentiment"`
+	Count     int       `json:"count"`
+}
+
+// MoodIntensity is the average intensity of an emotion across the entries
+// of one time bucket that expressed it
+type MoodIntensity struct {
+	Period    time.Time `json:"period"`
+	Emotion   string    `json:"emotion"`
+	Intensity float64   `json:"intensity"`
+	Entries   int       `json:"entries"`
+}
+
+// TopicCount is how often a topic appeared in one month
+type TopicCount struct {
+	Month time.Time `json:"month"`
+	Topic string    `json:"topic"`
+	Count int       `json:"count"`
+}
+
+// TopicTrend compares a topic's frequency in the latest month of the range
+// with the month before
+type TopicTrend struct {
+	Topic    string `json:"topic"`
+	Current  int    `json:"current"`
+	Previous int    `json:"previous"`
+	Change   int    `json:"change"`
+}
+
+// Analytics is the chart-ready summary returned by GetAnalytics
+type Analytics struct {
+	Granularity       string           `json:"granularity"`
+	EntriesPerPeriod  []PeriodCount    `json:"entries_per_period"`
+	SentimentByPeriod []SentimentCount `json:"sentiment_by_period"`
+	SentimentTotals   map[string]int   `json:"sentiment_totals"`
+	MoodByPeriod      []MoodIntensity  `json:"mood_by_period"`
+	TopicsByMonth     []TopicCount     `json:"topics_by_month"`
+	TrendingTopics    []TopicTrend     `json:"trending_topics"`
+}
+
+// GetAnalytics aggregates entry counts, sentiment and topic frequency over
+// time for dashboard charts
+func (s *JournalService) GetAnalytics(params AnalyticsParams) (*Analytics, error) {
+	granularity, err := parseGranularity(params.Granularity)
+	if err != nil {
+		return nil, err
+	}
+	params.Granularity = granularity
+
+	analytics := &Analytics{
+		Granularity:       params.Granularity,
+		EntriesPerPeriod:  []PeriodCount{},
+		SentimentByPeriod: []SentimentCount{},
+		SentimentTotals:   map[string]int{},
+		MoodByPeriod:      []MoodIntensity{},
+		TopicsByMonth:     []TopicCount{},
+		TrendingTopics:    []TopicTrend{},
+	}
+
+	// Entries per period
+	filter, filterArgs := s.analyticsFilter(params, 2)
+	rows, err := s.db.Query(`
+		SELECT date_trunc($1, created_at) AS period, COUNT(*)
+		FROM journal_entries
+		WHERE 1=1`+filter+`
+		GROUP BY period
+		ORDER BY period`,
+		append([]interface{}{params.Granularity}, filterArgs...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c PeriodCount
+		if err := rows.Scan(&c.Period, &c.Count); err != nil {
+			return nil, err
+		}
+		analytics.EntriesPerPeriod = append(analytics.EntriesPerPeriod, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count entries: %w", err)
+	}
+
+	// Sentiment distribution per period
+	sentimentRows, err := s.db.Query(`
+		SELECT date_trunc($1, created_at) AS period,
+			COALESCE(NULLIF(processed_data->>'sentiment', ''), 'unknown') AS sentiment,
+			COUNT(*)
+		FROM journal_entries
+		WHERE processing_stage IN ('completed', 'completed_no_embedding')`+filter+`
+		GROUP BY period, sentiment
+		ORDER BY period, sentiment`,
+		append([]interface{}{params.Granularity}, filterArgs...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sentiment: %w", err)
+	}
+	defer sentimentRows.Close()
+
+	for sentimentRows.Next() {
+		var c SentimentCount
+		if err := sentimentRows.Scan(&c.Period, &c.Sentiment, &c.Count); err != nil {
+			return nil, err
+		}
+		analytics.SentimentByPeriod = append(analytics.SentimentByPeriod, c)
+		analytics.SentimentTotals[c.Sentiment] += c.Count
+	}
+	if err := sentimentRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count sentiment: %w", err)
+	}
+
+	// Mood intensity per period; entries analyzed before mood scoring have no
+	// mood object and are skipped
+	moodRows, err := s.db.Query(`
+		SELECT date_trunc($1, created_at) AS period, mood.key, AVG(mood.value::float), COUNT(*)
+		FROM journal_entries,
+		LATERAL jsonb_each_text(CASE WHEN jsonb_typeof(processed_data->'mood') = 'object'
+			THEN processed_data->'mood' ELSE '{}'::jsonb END) AS mood
+		WHERE processing_stage IN ('completed', 'completed_no_embedding')`+filter+`
+		GROUP BY period, mood.key
+		ORDER BY period, mood.key`,
+		append([]interface{}{params.Granularity}, filterArgs...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate mood: %w", err)
+	}
+	defer moodRows.Close()
+
+	for moodRows.Next() {
+		var m MoodIntensity
+		if err := moodRows.Scan(&m.Period, &m.Emotion, &m.Intensity, &m.Entries); err != nil {
+			return nil, err
+		}
+		analytics.MoodByPeriod = append(analytics.MoodByPeriod, m)
+	}
+	if err := moodRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to aggregate mood: %w", err)
+	}
+
+	// Topic frequency by month
+	topicFilter, topicArgs := s.analyticsFilter(params, 1)
+	topicRows, err := s.db.Query(`
+		SELECT date_trunc('month', created_at) AS month, topic, COUNT(*) as count
+		FROM journal_entries,
+		LATERAL jsonb_array_elements_text(processed_data->'topics') as topic
+		WHERE processing_stage IN ('completed', 'completed_no_embedding')`+topicFilter+`
+		GROUP BY month, topic
+		ORDER BY month, count DESC`,
+		topicArgs...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count topics: %w", err)
+	}
+	defer topicRows.Close()
+
+	topicCounts := []TopicCount{}
+	for topicRows.Next() {
+		var c TopicCount
+		if err := topicRows.Scan(&c.Month, &c.Topic, &c.Count); err != nil {
+			return nil, err
+		}
+		topicCounts = append(topicCounts, c)
+	}
+	if err := topicRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count topics: %w", err)
+	}
+
+	analytics.TopicsByMonth = topTopicsPerMonth(topicCounts, topAnalyticsTopics)
+	analytics.TrendingTopics = trendingTopics(topicCounts)
+
+	return analytics, nil
+}
+
+// parseGranularity checks a date_trunc bucket size; empty selects day
+func parseGranularity(granularity string) (string, error) {
+	switch granularity {
+	case "":
+		return "day", nil
+	case "day", "week", "month":
+		return granularity, nil
+	default:
+		return "", Invalidf("invalid granularity: %s (expected day, week or month)", granularity)
+	}
+}
+
+// analyticsFilter restricts aggregates to the requested date range and the
+// current user's live entries, numbering placeholders from firstArg
+func (s *JournalService) analyticsFilter(params AnalyticsParams, firstArg int) (string, []interface{}) {
+	clause := " AND deleted_at IS NULL"
+	args := []interface{}{}
+
+	if scope, scopeArgs := s.scopeClause("user_id", firstArg+len(args)); scope != "" {
+		clause += scope
+		args = append(args, scopeArgs...)
+	}
+	if params.StartDate != nil {
+		clause += fmt.Sprintf(" AND created_at >= $%d", firstArg+len(args))
+		args = append(args, *params.StartDate)
+	}
+	if params.EndDate != nil {
+		clause += fmt.Sprintf(" AND created_at <= $%d", firstArg+len(args))
+		args = append(args, *params.EndDate)
+	}
+
+	return clause, args
+}
+
+// topTopicsPerMonth keeps the limit most frequent topics of each month.
+// counts must be ordered by month, then count descending.
+func topTopicsPerMonth(counts []TopicCount, limit int) []TopicCount {
+	top := []TopicCount{}
+	perMonth := map[time.Time]int{}
+	for _, c := range counts {
+		if perMonth[c.Month] >= limit {
+			continue
+		}
+		perMonth[c.Month]++
+		top = append(top, c)
+	}
+	return top
+}
+
+// trendingTopics returns topics that appeared more often in the latest month
+// than in the month before, biggest increase first
+func trendingTopics(counts []TopicCount) []TopicTrend {
+	if len(counts) == 0 {
+		return []TopicTrend{}
+	}
+
+	latest := counts[0].Month
+	for _, c := range counts {
+		if c.Month.After(latest) {
+			latest = c.Month
+		}
+	}
+	previous := latest.AddDate(0, -1, 0)
+
+	current := map[string]int{}
+	before := map[string]int{}
+	for _, c := range counts {
+		switch {
+		case c.Month.Equal(latest):
+			current[c.Topic] += c.Count
+		case c.Month.Equal(previous):
+			before[c.Topic] += c.Count
+		}
+	}
+
+	trends := []TopicTrend{}
+	for topic, n := range current {
+		if n > before[topic] {
+			trends = append(trends, TopicTrend{
+				Topic:    topic,
+				Current:  n,
+				Previous: before[topic],
+				Change:   n - before[topic],
+			})
+		}
+	}
+
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].Change != trends[j].Change {
+			return trends[i].Change > trends[j].Change
+		}
+		return trends[i].Topic < trends[j].Topic
+	})
+	if len(trends) > topAnalyticsTopics {
+		trends = trends[:topAnalyticsTopics]
+	}
+
+	return trends
+}
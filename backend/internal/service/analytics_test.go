@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrendingTopics(t *testing.T) {
+	june := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	july := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	trends := trendingTopics([]TopicCount{
+		{Month: june, Topic: "work", Count: 5},
+		{Month: june, Topic: "travel", Count: 1},
+		{Month: july, Topic: "travel", Count: 4},
+		{Month: july, Topic: "work", Count: 2},
+		{Month: july, Topic: "cooking", Count: 1},
+	})
+
+	assert.Equal(t, []TopicTrend{
+		{Topic: "travel", Current: 4, Previous: 1, Change: 3},
+		{Topic: "cooking", Current: 1, Previous: 0, Change: 1},
+	}, trends)
+}
+
+func TestGetAnalyticsRejectsUnknownGranularity(t *testing.T) {
+	service := &JournalService{}
+
+	_, err := service.GetAnalytics(AnalyticsParams{Granularity: "hour"})
+	assert.Error(t, err)
+}
+
+func TestGetAnalyticsReportsRowErrors(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	now := time.Now()
+
+	// The connection drops after the first row; that must not pass as a
+	// complete (but short) result
+	mock.ExpectQuery(`SELECT date_trunc\(\$1, created_at\) AS period, COUNT\(\*\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"period", "count"}).
+			AddRow(now, 3).
+			AddRow(now, 4).
+			RowError(1, errors.New("connection reset")))
+
+	_, err := service.GetAnalytics(AnalyticsParams{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection reset")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/models"
+)
+
+// maxAttachments bounds the attachments stored on one entry
+const maxAttachments = 50
+
+// validateAttachment checks the metadata supplied for an attachment
+func validateAttachment(a models.Attachment) error {
+	if strings.TrimSpace(a.Filename) == "" {
+		return Invalidf("attachment filename is required")
+	}
+	if a.Size < 0 {
+		return Invalidf("attachment size must not be negative")
+	}
+	if a.URL != "" {
+		if u, err := url.Parse(a.URL); err != nil || u.Scheme == "" {
+			return Invalidf("attachment url %q is not an absolute URL", a.URL)
+		}
+	}
+	return nil
+}
+
+// ValidateAttachments checks a full set of attachments before it is stored
+func ValidateAttachments(attachments models.Attachments) error {
+	if len(attachments) > maxAttachments {
+		return Invalidf("an entry can have at most %d attachments", maxAttachments)
+	}
+	for _, a := range attachments {
+		if err := validateAttachment(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetAttachments replaces an entry's attachments. Attachments are metadata
+// only and are not part of the AI analysis, so the entry is not reprocessed.
+func (s *JournalService) SetAttachments(entryID string, attachments models.Attachments) error {
+	if err := ValidateAttachments(attachments); err != nil {
+		return err
+	}
+
+	scope, scopeArgs := s.scopeClause("user_id", 4)
+	result, err := s.db.Exec(
+		"UPDATE journal_entries SET attachments = $1, updated_at = $2 WHERE id = $3"+scope,
+		append([]interface{}{attachments, time.Now(), entryID}, scopeArgs...)...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set attachments: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return NotFoundf("entry not found")
+	}
+	return nil
+}
+
+// AddAttachment appends an attachment to an entry and returns the updated
+// entry
+func (s *JournalService) AddAttachment(entryID string, attachment models.Attachment) (*models.JournalEntry, error) {
+	if err := validateAttachment(attachment); err != nil {
+		return nil, err
+	}
+
+	entry, err := s.GetEntry(entryID)
+	if err != nil {
+		return nil, err
+	}
+	if len(entry.Attachments) >= maxAttachments {
+		return nil, Invalidf("an entry can have at most %d attachments", maxAttachments)
+	}
+
+	now := time.Now()
+	_, err = s.db.Exec(
+		"UPDATE journal_entries SET attachments = attachments || $1::jsonb, updated_at = $2 WHERE id = $3",
+		models.Attachments{attachment}, now, entryID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add attachment: %w", err)
+	}
+
+	entry.Attachments = append(entry.Attachments, attachment)
+	entry.UpdatedAt = now
+	s.sendEvent(events.EventEntryUpdated, entry.ID, map[string]interface{}{
+		"entry":       entry,
+		"original_id": entry.ID,
+	})
+
+	return entry, nil
+}
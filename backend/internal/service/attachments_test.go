@@ -0,0 +1,102 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateAttachments(t *testing.T) {
+	assert.NoError(t, ValidateAttachments(nil))
+	assert.NoError(t, ValidateAttachments(models.Attachments{
+		{Filename: "receipt.pdf", MIME: "application/pdf", Size: 2048, URL: "https://files.example.com/receipt.pdf"},
+		{Filename: "photo.jpg"},
+	}))
+
+	for name, a := range map[string]models.Attachment{
+		"no filename":   {Filename: "  "},
+		"negative size": {Filename: "a.txt", Size: -1},
+		"relative url":  {Filename: "a.txt", URL: "files/a.txt"},
+	} {
+		assert.ErrorIs(t, ValidateAttachments(models.Attachments{a}), ErrValidation, name)
+	}
+
+	tooMany := make(models.Attachments, maxAttachments+1)
+	for i := range tooMany {
+		tooMany[i] = models.Attachment{Filename: "a.txt"}
+	}
+	assert.ErrorIs(t, ValidateAttachments(tooMany), ErrValidation)
+}
+
+func TestSetAttachments(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	attachments := models.Attachments{{Filename: "notes.txt", MIME: "text/plain", Size: 12}}
+
+	mock.ExpectExec(`UPDATE journal_entries SET attachments = \$1, updated_at = \$2 WHERE id = \$3`).
+		WithArgs(attachments, sqlmock.AnyArg(), "e1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, service.SetAttachments("e1", attachments))
+
+	mock.ExpectExec(`UPDATE journal_entries SET attachments`).
+		WithArgs(attachments, sqlmock.AnyArg(), "missing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, service.SetAttachments("missing", attachments), ErrNotFound)
+
+	assert.ErrorIs(t, service.SetAttachments("e1", models.Attachments{{}}), ErrValidation)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddAttachment(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	broadcaster := events.NewBroadcaster()
+	var sent []*events.Event
+	broadcaster.AddHook(func(e *events.Event) { sent = append(sent, e) })
+
+	service := &JournalService{db: database, broadcaster: broadcaster}
+	created := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+	existing := []byte(`[{"filename":"a.txt","mime":"text/plain","size":3,"url":""}]`)
+
+	mock.ExpectQuery(`WHERE je.id = \$1`).
+		WithArgs("e1").
+		WillReturnRows(entryRows(mockEntry{ID: "e1", Content: "hello", CreatedAt: created, Attachments: existing}))
+
+	added := models.Attachment{Filename: "b.png", MIME: "image/png", Size: 10, URL: "https://files.example.com/b.png"}
+	mock.ExpectExec(`UPDATE journal_entries SET attachments = attachments \|\| \$1::jsonb, updated_at = \$2 WHERE id = \$3`).
+		WithArgs(models.Attachments{added}, sqlmock.AnyArg(), "e1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	entry, err := service.AddAttachment("e1", added)
+	require.NoError(t, err)
+	require.Len(t, entry.Attachments, 2)
+	assert.Equal(t, "a.txt", entry.Attachments[0].Filename)
+	assert.Equal(t, added, entry.Attachments[1])
+
+	require.Len(t, sent, 1)
+	assert.Equal(t, string(events.EventEntryUpdated), sent[0].Type)
+
+	_, err = service.AddAttachment("e1", models.Attachment{Filename: ""})
+	assert.ErrorIs(t, err, ErrValidation)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHasAttachmentsFilter(t *testing.T) {
+	with, without := true, false
+
+	clause, args := buildFilterClause(SearchParams{HasAttachments: &with}, 1)
+	assert.Equal(t, " AND je.attachments <> '[]'::jsonb", clause)
+	assert.Empty(t, args)
+
+	clause, _ = buildFilterClause(SearchParams{HasAttachments: &without}, 1)
+	assert.Equal(t, " AND je.attachments = '[]'::jsonb", clause)
+}
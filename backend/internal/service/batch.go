@@ -0,0 +1,192 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/journal/internal/events"
+	"github.com/lib/pq"
+)
+
+// BatchSetFavorite sets or clears the favorite flag of every entry in ids in
+// a single transaction. Nothing changes unless every entry exists. It
+// returns how many entries were updated.
+func (s *JournalService) BatchSetFavorite(ids []string, favorite bool) (int, error) {
+	ids, err := s.batchIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.inBatch(ids, func(tx *sql.Tx) error {
+		_, err := tx.Exec("UPDATE journal_entries SET is_favorite = $2 WHERE id = ANY($1)", pq.Array(ids), favorite)
+		if err != nil {
+			return fmt.Errorf("failed to update favorites: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.sendBatchUpdates(ids, map[string]interface{}{"is_favorite": favorite})
+	return len(ids), nil
+}
+
+// BatchAddToCollection adds every entry in ids to a manual collection in a
+// single transaction, skipping entries already in it. Nothing changes unless
+// every entry exists. It returns how many entries were named.
+func (s *JournalService) BatchAddToCollection(collectionID string, ids []string) (int, error) {
+	ids, err := s.batchIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.ensureOwnership("", collectionID); err != nil {
+		return 0, err
+	}
+	if err := s.ensureManualCollection(collectionID); err != nil {
+		return 0, err
+	}
+
+	err = s.inBatch(ids, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO journal_collection (journal_id, collection_id)
+			SELECT id, $2 FROM UNNEST($1::uuid[]) AS id
+			ON CONFLICT DO NOTHING`,
+			pq.Array(ids), collectionID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add entries to collection: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.sendBatchUpdates(ids, map[string]interface{}{
+		"collection_action": "added",
+		"collection_id":     collectionID,
+	})
+	return len(ids), nil
+}
+
+// BatchRemoveFromCollection removes every entry in ids from a manual
+// collection in a single transaction. Nothing changes unless every entry
+// exists. It returns how many entries were named.
+func (s *JournalService) BatchRemoveFromCollection(collectionID string, ids []string) (int, error) {
+	ids, err := s.batchIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.ensureOwnership("", collectionID); err != nil {
+		return 0, err
+	}
+	if err := s.ensureManualCollection(collectionID); err != nil {
+		return 0, err
+	}
+
+	err = s.inBatch(ids, func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			"DELETE FROM journal_collection WHERE journal_id = ANY($1) AND collection_id = $2",
+			pq.Array(ids), collectionID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to remove entries from collection: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.sendBatchUpdates(ids, map[string]interface{}{
+		"collection_action": "removed",
+		"collection_id":     collectionID,
+	})
+	return len(ids), nil
+}
+
+// batchIDs deduplicates the IDs of a batch operation and checks there are
+// some, but no more than a result page
+func (s *JournalService) batchIDs(ids []string) ([]string, error) {
+	ids = uniqueIDs(ids)
+	if len(ids) == 0 {
+		return nil, Invalidf("ids must name at least one entry")
+	}
+	if limit := s.maxResultLimit(); len(ids) > limit {
+		return nil, Invalidf("at most %d entries can be changed at once", limit)
+	}
+	return ids, nil
+}
+
+// inBatch runs mutate in a transaction after locking every entry in ids. It
+// fails with ErrNotFound, before mutating, when any entry is missing, deleted
+// or owned by another user.
+func (s *JournalService) inBatch(ids []string, mutate func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	scope, scopeArgs := s.scopeClause("user_id", 2)
+	rows, err := tx.Query(
+		"SELECT id FROM journal_entries WHERE id = ANY($1) AND deleted_at IS NULL"+scope+" FOR UPDATE",
+		append([]interface{}{pq.Array(ids)}, scopeArgs...)...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to check entries: %w", err)
+	}
+	found := make(map[string]bool, len(ids))
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to check entries: %w", err)
+		}
+		found[id] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to check entries: %w", err)
+	}
+
+	var missing []string
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return NotFoundf("entries not found: %s", strings.Join(missing, ", "))
+	}
+
+	if err := mutate(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit batch: %w", err)
+	}
+	return nil
+}
+
+// sendBatchUpdates broadcasts an entry.updated event with the full entry for
+// every entry a batch changed, each carrying the batch's details
+func (s *JournalService) sendBatchUpdates(ids []string, details map[string]interface{}) {
+	entries, err := s.GetEntries(ids)
+	if err != nil {
+		// The batch is committed; clients catch up on their next fetch
+		slog.Error("Failed to get entries after batch update", "count", len(ids), "error", err)
+		return
+	}
+
+	for i := range entries {
+		data := map[string]interface{}{"entry": &entries[i]}
+		for k, v := range details {
+			data[k] = v
+		}
+		s.sendEvent(events.EventEntryUpdated, entries[i].ID, data)
+	}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchSetFavorite(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	service := &JournalService{db: database, broadcaster: broadcaster}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM journal_entries WHERE id = ANY\(\$1\) AND deleted_at IS NULL FOR UPDATE`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("e1").AddRow("e2"))
+	mock.ExpectExec(`UPDATE journal_entries SET is_favorite = \$2 WHERE id = ANY\(\$1\)`).
+		WithArgs(sqlmock.AnyArg(), true).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`WHERE je.id = ANY\(\$1\) AND je.deleted_at IS NULL`).
+		WillReturnRows(entryRows(
+			mockEntry{ID: "e1", Content: "first", IsFavorite: true},
+			mockEntry{ID: "e2", Content: "second", IsFavorite: true},
+		))
+
+	updated, err := service.BatchSetFavorite([]string{"e1", "e2", "e1"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated)
+
+	sent := recorded()
+	require.Len(t, sent, 2)
+	for _, e := range sent {
+		assert.Equal(t, string(events.EventEntryUpdated), e.Type)
+		assert.Equal(t, true, e.Data.(map[string]interface{})["is_favorite"])
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBatchSetFavoriteRejectsMissingEntries(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database, broadcaster: events.NewBroadcaster()}
+
+	// Nothing is updated when one of the entries does not exist
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM journal_entries WHERE id = ANY\(\$1\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("e1"))
+	mock.ExpectRollback()
+
+	_, err := service.BatchSetFavorite([]string{"e1", "gone"}, false)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Contains(t, err.Error(), "gone")
+
+	_, err = service.BatchSetFavorite(nil, false)
+	assert.ErrorIs(t, err, ErrValidation)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBatchAddToCollectionScopedToUser(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{db: database, broadcaster: events.NewBroadcaster()}).
+		WithConfig(Config{MultiTenant: true}).
+		ForUser("alice")
+
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM collections WHERE id = \$1 AND user_id = \$2\)`).
+		WithArgs("c1", "alice").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`SELECT is_smart FROM collections WHERE id = \$1`).
+		WithArgs("c1").
+		WillReturnRows(sqlmock.NewRows([]string{"is_smart"}).AddRow(false))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM journal_entries WHERE id = ANY\(\$1\) AND deleted_at IS NULL AND user_id = \$2 FOR UPDATE`).
+		WithArgs(sqlmock.AnyArg(), "alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("e1").AddRow("e2"))
+	mock.ExpectExec(`INSERT INTO journal_collection \(journal_id, collection_id\) SELECT id, \$2 FROM UNNEST\(\$1::uuid\[\]\) AS id ON CONFLICT DO NOTHING`).
+		WithArgs(sqlmock.AnyArg(), "c1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`WHERE je.id = ANY\(\$1\) AND je.deleted_at IS NULL AND je.user_id = \$2`).
+		WillReturnRows(entryRows(mockEntry{ID: "e1"}, mockEntry{ID: "e2"}))
+
+	updated, err := service.BatchAddToCollection("c1", []string{"e1", "e2"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/journal/internal/models"
+)
+
+// defaultStageListLimit bounds GetEntriesByStage when no limit is given
+const defaultStageListLimit = 100
+
+// GetEntriesByStage lists entries in one processing stage, longest-running
+// first, for processing queue and needs-attention views. Failed entries carry
+// their processing_error.
+func (s *JournalService) GetEntriesByStage(stage models.ProcessingStage, limit int) ([]models.JournalEntry, error) {
+	if !stage.Valid() {
+		return nil, Invalidf("invalid stage: %s", stage)
+	}
+	if limit <= 0 {
+		limit = defaultStageListLimit
+	}
+	limit = s.clampLimit(limit)
+
+	args := []interface{}{stage, limit}
+	scope, scopeArgs := s.scopeClause("je.user_id", len(args)+1)
+	args = append(args, scopeArgs...)
+
+	query := `
+		SELECT` + entryColumns + `
+		FROM journal_entries je
+		LEFT JOIN journal_collection jc ON je.id = jc.journal_id
+		WHERE je.processing_stage = $1 AND je.deleted_at IS NULL` + scope + `
+		GROUP BY je.id
+		ORDER BY je.processing_started_at ASC NULLS LAST, je.created_at ASC
+		LIMIT $2`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entries by stage: %w", err)
+	}
+	defer rows.Close()
+
+	return s.scanEntries(rows)
+}
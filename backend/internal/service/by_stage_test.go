@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/journal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEntriesByStage(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	now := time.Now()
+	reason := "connection refused"
+
+	mock.ExpectQuery(`WHERE je.processing_stage = \$1 AND je.deleted_at IS NULL GROUP BY je.id ORDER BY je.processing_started_at ASC NULLS LAST, je.created_at ASC LIMIT \$2`).
+		WithArgs(models.StageFailed, defaultStageListLimit).
+		WillReturnRows(entryRows(mockEntry{ID: "e1", Content: "broken", CreatedAt: now, Stage: models.StageFailed, StartedAt: now, CompletedAt: now, Error: reason}))
+
+	entries, err := service.GetEntriesByStage(models.StageFailed, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.NotNil(t, entries[0].ProcessingError)
+	assert.Equal(t, reason, *entries[0].ProcessingError)
+
+	_, err = service.GetEntriesByStage("stuck", 10)
+	assert.ErrorIs(t, err, ErrValidation)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
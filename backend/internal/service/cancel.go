@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/models"
+)
+
+// ErrProcessingCancelled is the cause of a pipeline context stopped by
+// CancelProcessing
+var ErrProcessingCancelled = errors.New("processing cancelled")
+
+// ErrProcessingSuperseded is the cause of a pipeline context stopped because
+// a newer run of the same entry started, e.g. a reprocess while it was
+// still analyzing
+var ErrProcessingSuperseded = errors.New("processing superseded by a newer run")
+
+// processingRuns tracks the running pipeline of each entry so
+// CancelProcessing can stop it and a newer run can replace it. It is shared
+// by the copies ForUser makes; a nil *processingRuns tracks nothing.
+type processingRuns struct {
+	mu   sync.Mutex
+	runs map[string]*processingRun
+}
+
+// processingRun is one pipeline run; a retry of the same entry registers a
+// new one
+type processingRun struct {
+	cancel context.CancelCauseFunc
+}
+
+func newProcessingRuns() *processingRuns {
+	return &processingRuns{runs: make(map[string]*processingRun)}
+}
+
+// add registers run as the entry's pipeline, stopping the run it replaces so
+// two pipelines never race to store their results
+func (r *processingRuns) add(entryID string, run *processingRun) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if previous, running := r.runs[entryID]; running {
+		previous.cancel(ErrProcessingSuperseded)
+	}
+	r.runs[entryID] = run
+}
+
+// remove forgets run, unless a newer run of the entry replaced it
+func (r *processingRuns) remove(entryID string, run *processingRun) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.runs[entryID] == run {
+		delete(r.runs, entryID)
+	}
+}
+
+// cancel stops the entry's running pipeline, reporting whether there was one
+func (r *processingRuns) cancel(entryID string) bool {
+	if r == nil {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	run, running := r.runs[entryID]
+	if running {
+		run.cancel(ErrProcessingCancelled)
+	}
+	return running
+}
+
+// startProcessing returns the context an entry's pipeline runs under,
+// registered so CancelProcessing can stop it. done releases the context and
+// must be called when the pipeline returns.
+func (s *JournalService) startProcessing(entryID string) (ctx context.Context, done func()) {
+	ctx, cancelTimeout := s.processingContext()
+	ctx, cancel := context.WithCancelCause(ctx)
+	run := &processingRun{cancel: cancel}
+	s.processing.add(entryID, run)
+
+	return ctx, func() {
+		s.processing.remove(entryID, run)
+		cancel(nil)
+		cancelTimeout()
+	}
+}
+
+// processingCancelled reports whether the pipeline running under ctx was
+// stopped by CancelProcessing or superseded by a newer run. Either way the
+// entry's stage is no longer this pipeline's to set, so it should return
+// without recording a failure or storing its results.
+func processingCancelled(ctx context.Context, entryID string) bool {
+	switch cause := context.Cause(ctx); {
+	case errors.Is(cause, ErrProcessingCancelled):
+		slog.Info("Processing stopped after cancellation", "entry_id", entryID)
+	case errors.Is(cause, ErrProcessingSuperseded):
+		slog.Info("Processing stopped; a newer run of the entry replaced it", "entry_id", entryID)
+	default:
+		return false
+	}
+	return true
+}
+
+// CancelProcessing stops the background processing of an entry and marks it
+// StageCancelled. Whatever the pipeline had not stored yet is discarded;
+// RetryProcessing starts it over. The stage changes in a single conditional
+// UPDATE, so when the pipeline finishes at the same moment exactly one of
+// them wins: either the entry is cancelled and the pipeline's final write is
+// skipped, or it completed first and cancelling fails with ErrConflict.
+func (s *JournalService) CancelProcessing(entryID string) error {
+	scope, scopeArgs := s.scopeClause("user_id", 8)
+	result, err := s.db.Exec(`
+		UPDATE journal_entries
+		SET processing_stage = $1, processing_error = NULL, processing_completed_at = $2
+		WHERE id = $3 AND deleted_at IS NULL
+			AND processing_stage NOT IN ($4, $5, $6, $7)`+scope,
+		append([]interface{}{
+			models.StageCancelled, time.Now(), entryID,
+			models.StageCompleted, models.StageCompletedNoEmbedding, models.StageFailed, models.StageCancelled,
+		}, scopeArgs...)...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to cancel processing: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to cancel processing: %w", err)
+	}
+	if rows == 0 {
+		entry, err := s.GetEntry(entryID)
+		if err != nil {
+			return err
+		}
+		return Conflictf("entry %s is not being processed (stage: %s)", entryID, entry.ProcessingStage)
+	}
+
+	// The entry may have no pipeline here, e.g. one orphaned by a restart;
+	// marking it cancelled is all there is to do then
+	running := s.processing.cancel(entryID)
+
+	s.logger.LogInfo(entryID, models.StageCancelled, "Processing cancelled", map[string]interface{}{
+		"running": running,
+	})
+	slog.Info("Cancelled processing", "entry_id", entryID, "running", running)
+
+	s.sendEvent(events.EventEntryCancelled, entryID, map[string]interface{}{
+		"stage": models.StageCancelled,
+	})
+	return nil
+}
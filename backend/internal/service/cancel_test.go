@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/logger"
+	"github.com/journal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCancelProcessingStopsRunningPipeline(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	service := &JournalService{
+		db:          database,
+		broadcaster: broadcaster,
+		logger:      logger.NewProcessingLogger(database.DB),
+		processing:  newProcessingRuns(),
+	}
+
+	ctx, done := service.startProcessing("e1")
+	defer done()
+
+	mock.ExpectExec(`UPDATE journal_entries SET processing_stage = \$1, processing_error = NULL, processing_completed_at = \$2 WHERE id = \$3 AND deleted_at IS NULL AND processing_stage NOT IN \(\$4, \$5, \$6, \$7\)`).
+		WithArgs(models.StageCancelled, sqlmock.AnyArg(), "e1",
+			models.StageCompleted, models.StageCompletedNoEmbedding, models.StageFailed, models.StageCancelled).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, service.CancelProcessing("e1"))
+
+	assert.ErrorIs(t, context.Cause(ctx), ErrProcessingCancelled)
+	assert.True(t, processingCancelled(ctx, "e1"))
+
+	sent := recorded()
+	require.Len(t, sent, 1)
+	assert.Equal(t, string(events.EventEntryCancelled), sent[0].Type)
+	assert.Equal(t, "e1", sent[0].EntryID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCancelProcessingFinishedEntryConflicts(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	service := &JournalService{
+		db:          database,
+		broadcaster: broadcaster,
+		logger:      logger.NewProcessingLogger(database.DB),
+		processing:  newProcessingRuns(),
+	}
+
+	// The pipeline completed just before the cancel arrived
+	mock.ExpectExec(`SET processing_stage = \$1, processing_error = NULL`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`WHERE je.id = \$1`).
+		WithArgs("e1").
+		WillReturnRows(entryRows(mockEntry{ID: "e1", Content: "Done", Stage: models.StageCompleted}))
+
+	err := service.CancelProcessing("e1")
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Empty(t, recorded())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCancelProcessingRejectsForeignEntry(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{db: database, broadcaster: events.NewBroadcaster()}).
+		WithConfig(Config{MultiTenant: true}).
+		ForUser("alice")
+
+	mock.ExpectExec(`processing_stage NOT IN \(\$4, \$5, \$6, \$7\) AND user_id = \$8`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`WHERE je.id = \$1`).
+		WillReturnRows(entryRows())
+
+	err := service.CancelProcessing("entry-of-bob")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStoreAnalysisSkipsCancelledEntry(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	service := &JournalService{
+		db:          database,
+		broadcaster: broadcaster,
+		logger:      logger.NewProcessingLogger(database.DB),
+		processing:  newProcessingRuns(),
+	}
+
+	ctx, done := service.startProcessing("e1")
+	defer done()
+	service.processing.cancel("e1")
+
+	// Nothing is written and no event is sent
+	service.storeAnalysis(ctx, analyzedEntry())
+
+	assert.Empty(t, recorded())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/journal/internal/models"
+	"github.com/pgvector/pgvector-go"
+)
+
+// storeChunkEmbeddings replaces the chunk embeddings of a long entry. Short
+// entries simply have their old chunks removed. Failures are logged as
+// warnings because the whole-entry embedding is still usable on its own.
+func (s *JournalService) storeChunkEmbeddings(ctx context.Context, entryID, content string) {
+	if err := s.replaceChunkEmbeddings(ctx, entryID, content); err != nil {
+		s.logger.LogWarn(entryID, models.StageGeneratingEmbeddings, "Failed to store chunk embeddings", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+func (s *JournalService) replaceChunkEmbeddings(ctx context.Context, entryID, content string) error {
+	chunks, err := s.processor.CreateChunkEmbeddings(ctx, content)
+	if err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		if err := checkEmbeddingDimensions(len(chunk.Embedding)); err != nil {
+			return err
+		}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM entry_chunks WHERE entry_id = $1", entryID); err != nil {
+		return fmt.Errorf("failed to clear chunks: %w", err)
+	}
+
+	for _, chunk := range chunks {
+		_, err := tx.Exec(
+			"INSERT INTO entry_chunks (entry_id, chunk_index, content, embedding) VALUES ($1, $2, $3, $4)",
+			entryID, chunk.Index, chunk.Content, pgvector.NewVector(chunk.Embedding),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert chunk %d: %w", chunk.Index, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit chunks: %w", err)
+	}
+
+	if len(chunks) > 0 {
+		s.logger.LogInfo(entryID, models.StageGeneratingEmbeddings, "Chunk embeddings generated", map[string]interface{}{
+			"chunks": len(chunks),
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxExportEntries bounds how many entries a single export returns
+const maxExportEntries = 1000
+
+// ExportCollection exports the entries of one collection, manual or smart.
+// The markdown title and the returned filename include the collection name.
+// A positive maxContentLength truncates content as in StreamExport.
+func (s *JournalService) ExportCollection(collectionID, format string, maxContentLength int) ([]byte, string, string, error) {
+	scope, scopeArgs := s.scopeClause("user_id", 2)
+
+	var name string
+	err := s.db.QueryRow(
+		"SELECT name FROM collections WHERE id = $1"+scope,
+		append([]interface{}{collectionID}, scopeArgs...)...,
+	).Scan(&name)
+	if err == sql.ErrNoRows {
+		return nil, "", "", NotFoundf("collection not found")
+	}
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	entries, err := s.GetCollectionEntries(collectionID, maxExportEntries, 0)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	data, contentType, err := renderExport(entries, format, name, maxContentLength)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	return data, contentType, ExportFilename(name, format), nil
+}
+
+// ExportFilename names an export file, including a slug of the collection
+// name when one is given
+func ExportFilename(collectionName, format string) string {
+	date := time.Now().Format("2006-01-02")
+	if slug := filenameSlug(collectionName); slug != "" {
+		return fmt.Sprintf("journal-export-%s-%s.%s", slug, date, format)
+	}
+	return fmt.Sprintf("journal-export-%s.%s", date, format)
+}
+
+// filenameSlug lowercases name and replaces every run of characters other
+// than letters and digits with a single dash
+func filenameSlug(name string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			slug.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && slug.Len() > 0 {
+			slug.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(slug.String(), "-")
+}
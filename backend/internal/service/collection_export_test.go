@@ -0,0 +1,43 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCollection(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT name FROM collections WHERE id = \$1`).
+		WithArgs("c1").
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Work Journal"))
+	mock.ExpectQuery(`SELECT is_smart, query_params FROM collections WHERE id = \$1`).
+		WithArgs("c1").
+		WillReturnRows(sqlmock.NewRows([]string{"is_smart", "query_params"}).AddRow(false, nil))
+	mock.ExpectQuery(`SELECT DISTINCT`).
+		WillReturnRows(entryRows(mockEntry{ID: "e1", Content: "shipped the release", ProcessedData: `{"summary":"release day"}`, CreatedAt: now, CollectionIDs: []string{"c1"}}))
+
+	data, contentType, filename, err := service.ExportCollection("c1", "markdown", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "text/markdown", contentType)
+	assert.True(t, strings.HasPrefix(string(data), "# Work Journal\n"))
+	assert.Contains(t, string(data), "shipped the release")
+	assert.Equal(t, "journal-export-work-journal-"+now.Format("2006-01-02")+".markdown", filename)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFilenameSlug(t *testing.T) {
+	assert.Equal(t, "work-journal", filenameSlug("Work Journal"))
+	assert.Equal(t, "q3-plans-2024", filenameSlug("  Q3 plans / 2024!"))
+	assert.Equal(t, "", filenameSlug("!!!"))
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/journal/internal/models"
+	"github.com/lib/pq"
+)
+
+// DefaultSuggestionThreshold is the minimum score a collection needs to be
+// suggested when the caller does not pick one
+const DefaultSuggestionThreshold = 0.25
+
+// Weights of the three signals combined by scoreCollection. When the entry or
+// the collection has no embeddings the similarity weight is dropped and the
+// remaining score is rescaled.
+const (
+	nameMatchWeight   = 0.4
+	memberTermsWeight = 0.3
+	similarityWeight  = 0.3
+)
+
+// CollectionSuggestion is a collection the entry probably belongs in
+type CollectionSuggestion struct {
+	Collection   models.Collection `json:"collection"`
+	Score        float64           `json:"score"`
+	MatchedTerms []string          `json:"matched_terms"`
+}
+
+// collectionSignals holds what is known about a collection's existing members
+type collectionSignals struct {
+	memberTerms   map[string]bool
+	similarity    float64
+	hasSimilarity bool
+}
+
+// SuggestCollections ranks the manual collections an entry is not yet in by
+// how well they match its AI topics and entities. Each collection is scored
+// on its name and description, the topics/entities of its current members,
+// and the average embedding similarity to those members. Only suggestions
+// scoring at least threshold are returned; threshold <= 0 uses the default.
+func (s *JournalService) SuggestCollections(entryID string, threshold float64) ([]CollectionSuggestion, error) {
+	if threshold <= 0 {
+		threshold = DefaultSuggestionThreshold
+	}
+
+	entry, err := s.GetEntry(entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := entryTerms(entry.ProcessedData)
+	if len(terms) == 0 {
+		return []CollectionSuggestion{}, nil
+	}
+
+	collections, err := s.GetCollections()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collections: %w", err)
+	}
+
+	member := make(map[string]bool, len(entry.CollectionIDs))
+	for _, id := range entry.CollectionIDs {
+		member[id] = true
+	}
+
+	candidates := []models.Collection{}
+	candidateIDs := []string{}
+	for _, c := range collections {
+		if c.IsSmart || member[c.ID] {
+			continue
+		}
+		candidates = append(candidates, c)
+		candidateIDs = append(candidateIDs, c.ID)
+	}
+	if len(candidates) == 0 {
+		return []CollectionSuggestion{}, nil
+	}
+
+	signals, err := s.collectionSignals(entryID, candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []CollectionSuggestion{}
+	for _, c := range candidates {
+		score, matched := scoreCollection(terms, c, signals[c.ID])
+		if score < threshold {
+			continue
+		}
+		suggestions = append(suggestions, CollectionSuggestion{
+			Collection:   c,
+			Score:        score,
+			MatchedTerms: matched,
+		})
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Score > suggestions[j].Score
+	})
+
+	return suggestions, nil
+}
+
+// collectionSignals loads member topics/entities and embedding similarity for
+// the given collections, excluding the entry being classified and any
+// soft-deleted members
+func (s *JournalService) collectionSignals(entryID string, collectionIDs []string) (map[string]*collectionSignals, error) {
+	signals := make(map[string]*collectionSignals, len(collectionIDs))
+	for _, id := range collectionIDs {
+		signals[id] = &collectionSignals{memberTerms: map[string]bool{}}
+	}
+
+	termRows, err := s.db.Query(`
+		SELECT DISTINCT jc.collection_id, LOWER(term)
+		FROM journal_collection jc
+		JOIN journal_entries je ON je.id = jc.journal_id,
+		LATERAL jsonb_array_elements_text(
+			COALESCE(je.processed_data->'topics', '[]'::jsonb) || COALESCE(je.processed_data->'entities', '[]'::jsonb)
+		) AS term
+		WHERE jc.collection_id = ANY($1) AND je.id <> $2 AND je.deleted_at IS NULL`,
+		pq.Array(collectionIDs), entryID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection topics: %w", err)
+	}
+	defer termRows.Close()
+
+	for termRows.Next() {
+		var collectionID, term string
+		if err := termRows.Scan(&collectionID, &term); err != nil {
+			return nil, err
+		}
+		if sig, ok := signals[collectionID]; ok {
+			sig.memberTerms[term] = true
+		}
+	}
+	if err := termRows.Err(); err != nil {
+		return nil, err
+	}
+
+	simRows, err := s.db.Query(`
+		SELECT jc.collection_id, AVG(`+s.metric().similarity("je.embedding", "target.embedding")+`)
+		FROM journal_collection jc
+		JOIN journal_entries je ON je.id = jc.journal_id
+		CROSS JOIN (SELECT embedding FROM journal_entries WHERE id = $2 AND deleted_at IS NULL) target
+		WHERE jc.collection_id = ANY($1) AND je.id <> $2 AND je.deleted_at IS NULL
+			AND je.embedding IS NOT NULL AND target.embedding IS NOT NULL
+		GROUP BY jc.collection_id`,
+		pq.Array(collectionIDs), entryID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection similarity: %w", err)
+	}
+	defer simRows.Close()
+
+	for simRows.Next() {
+		var collectionID string
+		var similarity float64
+		if err := simRows.Scan(&collectionID, &similarity); err != nil {
+			return nil, err
+		}
+		if sig, ok := signals[collectionID]; ok {
+			sig.similarity = similarity
+			sig.hasSimilarity = true
+		}
+	}
+
+	return signals, simRows.Err()
+}
+
+// entryTerms returns the entry's topics and entities, lowercased and deduplicated
+func entryTerms(data models.ProcessedData) []string {
+	seen := map[string]bool{}
+	terms := []string{}
+	for _, t := range append(append([]string{}, data.Topics...), data.Entities...) {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		terms = append(terms, t)
+	}
+	return terms
+}
+
+// scoreCollection combines the name, member-term and similarity signals into
+// a score between 0 and 1, returning the entry terms that matched
+func scoreCollection(terms []string, c models.Collection, sig *collectionSignals) (float64, []string) {
+	label := strings.ToLower(c.Name + " " + c.Description)
+	name := strings.ToLower(strings.TrimSpace(c.Name))
+
+	matched := []string{}
+	nameHits, memberHits := 0, 0
+	for _, term := range terms {
+		hit := false
+		if strings.Contains(label, term) || (name != "" && strings.Contains(term, name)) {
+			nameHits++
+			hit = true
+		}
+		if sig != nil && sig.memberTerms[term] {
+			memberHits++
+			hit = true
+		}
+		if hit {
+			matched = append(matched, term)
+		}
+	}
+
+	total := float64(len(terms))
+	score := nameMatchWeight*float64(nameHits)/total + memberTermsWeight*float64(memberHits)/total
+	if sig != nil && sig.hasSimilarity {
+		score += similarityWeight * sig.similarity
+	} else {
+		score /= nameMatchWeight + memberTermsWeight
+	}
+
+	return score, matched
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoreCollection(t *testing.T) {
+	terms := entryTerms(models.ProcessedData{
+		Topics:   []string{"Travel", "food"},
+		Entities: []string{"Lisbon", "travel"},
+	})
+	assert.Equal(t, []string{"travel", "food", "lisbon"}, terms)
+
+	t.Run("name match without embeddings", func(t *testing.T) {
+		score, matched := scoreCollection(terms, models.Collection{Name: "Travel"}, nil)
+		assert.InDelta(t, (0.4/3)/0.7, score, 1e-9)
+		assert.Equal(t, []string{"travel"}, matched)
+	})
+
+	t.Run("member terms and similarity", func(t *testing.T) {
+		sig := &collectionSignals{
+			memberTerms:   map[string]bool{"lisbon": true, "food": true},
+			similarity:    0.8,
+			hasSimilarity: true,
+		}
+		score, matched := scoreCollection(terms, models.Collection{Name: "Trips", Description: "places I travel to"}, sig)
+		assert.InDelta(t, 0.4/3+0.3*2/3+0.3*0.8, score, 1e-9)
+		assert.Equal(t, []string{"travel", "food", "lisbon"}, matched)
+	})
+
+	t.Run("unrelated collection", func(t *testing.T) {
+		score, matched := scoreCollection(terms, models.Collection{Name: "Work"}, &collectionSignals{memberTerms: map[string]bool{}})
+		assert.Zero(t, score)
+		assert.Empty(t, matched)
+	})
+}
+
+func TestCollectionSignalsIgnoreDeletedEntries(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+
+	mock.ExpectQuery(`WHERE jc.collection_id = ANY\(\$1\) AND je.id <> \$2 AND je.deleted_at IS NULL$`).
+		WithArgs(sqlmock.AnyArg(), "e1").
+		WillReturnRows(sqlmock.NewRows([]string{"collection_id", "term"}).AddRow("c1", "travel"))
+	mock.ExpectQuery(`FROM journal_entries WHERE id = \$2 AND deleted_at IS NULL\) target WHERE jc.collection_id = ANY\(\$1\) AND je.id <> \$2 AND je.deleted_at IS NULL AND`).
+		WithArgs(sqlmock.AnyArg(), "e1").
+		WillReturnRows(sqlmock.NewRows([]string{"collection_id", "avg"}).AddRow("c1", 0.8))
+
+	signals, err := service.collectionSignals("e1", []string{"c1"})
+	require.NoError(t, err)
+	assert.True(t, signals["c1"].memberTerms["travel"])
+	assert.InDelta(t, 0.8, signals["c1"].similarity, 1e-9)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
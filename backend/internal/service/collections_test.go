@@ -0,0 +1,179 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collectionColumns() []string {
+	return []string{"id", "name", "description", "created_at", "updated_at", "is_smart", "query_params"}
+}
+
+func TestUpdateCollectionRenames(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	service := &JournalService{db: database, broadcaster: broadcaster}
+	now := time.Now()
+
+	mock.ExpectQuery(`UPDATE collections SET name = \$2, description = \$3 WHERE id = \$1 RETURNING`).
+		WithArgs("c1", "Travel", "Trips abroad").
+		WillReturnRows(sqlmock.NewRows(collectionColumns()).
+			AddRow("c1", "Travel", "Trips abroad", now, now, false, nil))
+
+	collection, err := service.UpdateCollection("c1", "Travel", "Trips abroad")
+	require.NoError(t, err)
+	assert.Equal(t, "Travel", collection.Name)
+	assert.Equal(t, "Trips abroad", collection.Description)
+
+	sent := recorded()
+	require.Len(t, sent, 1)
+	assert.Equal(t, string(events.EventCollectionUpdated), sent[0].Type)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateCollectionNotFound(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database, broadcaster: events.NewBroadcaster()}
+
+	mock.ExpectQuery(`UPDATE collections SET name = \$2, description = \$3 WHERE id = \$1 RETURNING`).
+		WithArgs("missing", "Travel", "").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := service.UpdateCollection("missing", "Travel", "")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateCollectionRejectsForeignCollection(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{db: database, broadcaster: events.NewBroadcaster()}).
+		WithConfig(Config{MultiTenant: true}).
+		ForUser("alice")
+
+	// Bob's collection does not match Alice's scope, so nothing is returned
+	mock.ExpectQuery(`UPDATE collections SET name = \$2, description = \$3 WHERE id = \$1 AND user_id = \$4 RETURNING`).
+		WithArgs("collection-of-bob", "Mine now", "", "alice").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := service.UpdateCollection("collection-of-bob", "Mine now", "")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteCollection(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	service := &JournalService{db: database, broadcaster: broadcaster}
+
+	mock.ExpectExec(`DELETE FROM collections WHERE id = \$1`).
+		WithArgs("c1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, service.DeleteCollection("c1"))
+
+	sent := recorded()
+	require.Len(t, sent, 1)
+	assert.Equal(t, string(events.EventCollectionDeleted), sent[0].Type)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteCollectionRejectsForeignCollection(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{db: database, broadcaster: events.NewBroadcaster()}).
+		WithConfig(Config{MultiTenant: true}).
+		ForUser("alice")
+
+	mock.ExpectExec(`DELETE FROM collections WHERE id = \$1 AND user_id = \$2`).
+		WithArgs("collection-of-bob", "alice").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := service.DeleteCollection("collection-of-bob")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetCollectionsCountsOnlyLiveEntries(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+
+	// Memberships of soft-deleted entries join no entry, so COUNT skips them
+	mock.ExpectQuery(`COUNT\(je.id\), c.is_smart, c.query_params FROM collections c LEFT JOIN journal_collection jc ON jc.collection_id = c.id LEFT JOIN journal_entries je ON je.id = jc.journal_id AND je.deleted_at IS NULL`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at", "count", "is_smart", "query_params"}).
+			AddRow("c1", "Travel", "", time.Now(), time.Now(), 2, false, nil))
+
+	collections, err := service.GetCollections()
+	require.NoError(t, err)
+	require.Len(t, collections, 1)
+	assert.Equal(t, 2, collections[0].EntryCount)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetCollectionEntriesPagesHybridSmartCollection(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	now := time.Now()
+
+	// Hybrid search ranks its matches as a whole, so the second page of two
+	// is cut from the first four matches
+	mock.ExpectQuery(`SELECT is_smart, query_params FROM collections WHERE id = \$1`).
+		WithArgs("c1").
+		WillReturnRows(sqlmock.NewRows([]string{"is_smart", "query_params"}).
+			AddRow(true, []byte(`{"query":"hiking","hybrid_mode":"balanced"}`)))
+	mock.ExpectQuery(`websearch_to_tsquery(.*)LIMIT \$2$`).
+		WithArgs("hiking", 4).
+		WillReturnRows(entryRows(
+			mockEntry{ID: "e1", Content: "first", CreatedAt: now},
+			mockEntry{ID: "e2", Content: "second", CreatedAt: now},
+			mockEntry{ID: "e3", Content: "third", CreatedAt: now},
+			mockEntry{ID: "e4", Content: "fourth", CreatedAt: now},
+		))
+
+	entries, err := service.GetCollectionEntries("c1", 2, 2)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "e3", entries[0].ID)
+	assert.Equal(t, "e4", entries[1].ID)
+
+	// A page past the last match is empty
+	mock.ExpectQuery(`SELECT is_smart, query_params FROM collections WHERE id = \$1`).
+		WithArgs("c1").
+		WillReturnRows(sqlmock.NewRows([]string{"is_smart", "query_params"}).
+			AddRow(true, []byte(`{"query":"hiking","hybrid_mode":"balanced"}`)))
+	mock.ExpectQuery(`websearch_to_tsquery(.*)LIMIT \$2$`).
+		WithArgs("hiking", 6).
+		WillReturnRows(entryRows(mockEntry{ID: "e1", Content: "first", CreatedAt: now}))
+
+	entries, err = service.GetCollectionEntries("c1", 2, 4)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,68 @@
+package service
+
+import "time"
+
+// Config holds optional behaviour switches for the journal service. The zero
+// value reproduces the original single-tenant behaviour, so services built as
+// struct literals (as in tests) keep working unchanged.
+type Config struct {
+	// MultiTenant scopes every query to the user bound with ForUser
+	MultiTenant bool
+
+	// StreamAnalysis streams the AI analysis and broadcasts partial progress
+	// as entry.analyzing.progress events
+	StreamAnalysis bool
+
+	// FetchCacheTTL reuses stored MCP fetch results younger than this instead
+	// of fetching the URL again; 0 always refetches
+	FetchCacheTTL time.Duration
+
+	// MaxContentBytes rejects new entries whose content is larger than this;
+	// 0 uses DefaultMaxContentBytes
+	MaxContentBytes int
+
+	// VectorProbes is the default ivfflat.probes for vector searches that do
+	// not set SearchParams.Probes; higher finds more true neighbours at the
+	// cost of latency. 0 keeps the Postgres default of 1.
+	VectorProbes int
+
+	// MaxFetchURLs is how many of an entry's links are fetched; the rest are
+	// skipped. 0 uses DefaultMaxFetchURLs.
+	MaxFetchURLs int
+
+	// MaxResultLimit caps the limit a search or list request may ask for;
+	// larger limits are clamped. 0 uses DefaultMaxResultLimit.
+	MaxResultLimit int
+
+	// DeleteGracePeriod is how long a deleted entry can be restored before it
+	// is purged; 0 uses DefaultDeleteGracePeriod
+	DeleteGracePeriod time.Duration
+
+	// ProcessingTimeout bounds an entry's whole processing pipeline; an entry
+	// still processing when it passes is marked failed. 0 uses
+	// DefaultProcessingTimeout.
+	ProcessingTimeout time.Duration
+
+	// SimilarityMetric is the distance vector searches rank by. The embedding
+	// indexes must use its operator class. Empty uses MetricCosine.
+	SimilarityMetric SimilarityMetric
+
+	// StrictEmbeddings fails an entry whose embedding cannot be generated.
+	// By default its analysis is kept and it completes as
+	// StageCompletedNoEmbedding, which RetryProcessing can finish later.
+	StrictEmbeddings bool
+
+	// SanitizeContent cleans new and edited entries with SanitizeContent
+	// before they are stored and analyzed
+	SanitizeContent bool
+
+	// KeepOriginalContent stores the content as submitted in
+	// original_content whenever sanitizing changed it
+	KeepOriginalContent bool
+}
+
+// WithConfig applies cfg to the service and returns it for chaining
+func (s *JournalService) WithConfig(cfg Config) *JournalService {
+	s.config = cfg
+	return s
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/journal/internal/models"
+)
+
+// SearchPage is one page of classic search results. NextCursor is empty on
+// the last page.
+type SearchPage struct {
+	Entries    []models.JournalEntry `json:"entries"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+// ClassicSearchPage runs a classic search and returns the cursor for the
+// following page. Unlike offsets, cursors do not skip or repeat entries when
+// new ones are created while the user scrolls. Pages are strictly newest
+// first: cursors follow (created_at, id), so pinned entries are not lifted.
+func (s *JournalService) ClassicSearchPage(params SearchParams) (*SearchPage, error) {
+	params.Sort = "newest"
+	params.Limit = s.clampLimit(params.Limit)
+	entries, err := s.ClassicSearch(params)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &SearchPage{Entries: entries}
+	if params.Limit > 0 && len(entries) == params.Limit {
+		last := entries[len(entries)-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+
+	return page, nil
+}
+
+// encodeCursor packs an entry's position in created_at, id order into an
+// opaque token
+func encodeCursor(createdAt time.Time, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(createdAt.Format(time.RFC3339Nano) + "|" + id))
+}
+
+func decodeCursor(cursor string) (time.Time, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, "", Invalidf("invalid after_cursor")
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return time.Time{}, "", Invalidf("invalid after_cursor")
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return time.Time{}, "", Invalidf("invalid after_cursor")
+	}
+
+	return t, id, nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassicSearchPageCursor(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	first := time.Date(2024, 5, 2, 9, 30, 0, 123456000, time.UTC)
+	second := first.Add(-time.Hour)
+
+	mock.ExpectQuery(`GROUP BY je.id ORDER BY je.created_at DESC, je.id DESC LIMIT \$1$`).
+		WithArgs(2).
+		WillReturnRows(entryRows(
+			mockEntry{ID: "e1", Content: "one", CreatedAt: first},
+			mockEntry{ID: "e2", Content: "two", CreatedAt: second},
+		))
+
+	page, err := service.ClassicSearchPage(SearchParams{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 2)
+	require.NotEmpty(t, page.NextCursor)
+
+	// The cursor resumes strictly after the last entry and ignores offset
+	mock.ExpectQuery(`AND \(je.created_at, je.id\) < \(\$1, \$2\) GROUP BY je.id ORDER BY je.created_at DESC, je.id DESC LIMIT \$3$`).
+		WithArgs(second, "e2", 2).
+		WillReturnRows(entryRows(mockEntry{ID: "e3", Content: "three", CreatedAt: second}))
+
+	page, err = service.ClassicSearchPage(SearchParams{Limit: 2, Offset: 40, AfterCursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 1)
+	assert.Empty(t, page.NextCursor)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	for _, cursor := range []string{"not base64!", "bm8tc2VwYXJhdG9y", encodeCursor(time.Now(), "")} {
+		_, _, err := decodeCursor(cursor)
+		assert.ErrorIs(t, err, ErrValidation, cursor)
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ApplyDateRange fills StartDate and EndDate from the DateRange preset,
+// relative to now. Presets are "<n>d" (the last n days), "this_month",
+// "last_month" and "ytd" (also "this_year"). Explicitly set dates take
+// precedence over the preset, and an empty DateRange leaves params unchanged.
+func (p *SearchParams) ApplyDateRange(now time.Time) error {
+	if p.DateRange == "" {
+		return nil
+	}
+
+	start, end, err := dateRangeBounds(p.DateRange, now)
+	if err != nil {
+		return err
+	}
+
+	if p.StartDate == nil {
+		p.StartDate = &start
+	}
+	if p.EndDate == nil {
+		p.EndDate = &end
+	}
+	return nil
+}
+
+// dateRangeBounds resolves a date preset to the [start, end] it covers
+func dateRangeBounds(preset string, now time.Time) (time.Time, time.Time, error) {
+	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	switch preset {
+	case "this_month":
+		return startOfMonth, now, nil
+	case "last_month":
+		return startOfMonth.AddDate(0, -1, 0), startOfMonth.Add(-time.Nanosecond), nil
+	case "ytd", "this_year":
+		return time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location()), now, nil
+	}
+
+	if days, ok := strings.CutSuffix(preset, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err == nil && n > 0 {
+			return now.AddDate(0, 0, -n), now, nil
+		}
+	}
+
+	return time.Time{}, time.Time{}, Invalidf("invalid date_range %q: use <n>d, this_month, last_month or ytd", preset)
+}
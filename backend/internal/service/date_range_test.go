@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyDateRange(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		preset string
+		start  time.Time
+		end    time.Time
+	}{
+		{"7d", time.Date(2024, 3, 8, 12, 0, 0, 0, time.UTC), now},
+		{"30d", time.Date(2024, 2, 14, 12, 0, 0, 0, time.UTC), now},
+		{"this_month", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), now},
+		{"last_month", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 29, 23, 59, 59, 999999999, time.UTC)},
+		{"ytd", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			params := SearchParams{DateRange: tt.preset}
+			require.NoError(t, params.ApplyDateRange(now))
+			assert.Equal(t, tt.start, *params.StartDate)
+			assert.Equal(t, tt.end, *params.EndDate)
+		})
+	}
+}
+
+func TestApplyDateRangeExplicitDatesWin(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	explicit := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	params := SearchParams{DateRange: "30d", StartDate: &explicit}
+	require.NoError(t, params.ApplyDateRange(now))
+	assert.Equal(t, explicit, *params.StartDate)
+	assert.Equal(t, now, *params.EndDate)
+}
+
+func TestApplyDateRangeInvalid(t *testing.T) {
+	for _, preset := range []string{"0d", "-3d", "d", "fortnight"} {
+		params := SearchParams{DateRange: preset}
+		assert.Error(t, params.ApplyDateRange(time.Now()), preset)
+	}
+}
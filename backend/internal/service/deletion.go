@@ -0,0 +1,125 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/models"
+)
+
+// DefaultDeleteGracePeriod is how long a deleted entry can be restored when
+// Config.DeleteGracePeriod is unset
+const DefaultDeleteGracePeriod = 7 * 24 * time.Hour
+
+// purgeInterval is how often StartDeletedEntryPurger looks for entries whose
+// grace period has ended
+const purgeInterval = time.Hour
+
+// DeletedEntry reports a soft delete and when it becomes permanent
+type DeletedEntry struct {
+	ID         string    `json:"id"`
+	DeletedAt  time.Time `json:"deleted_at"`
+	PurgeAfter time.Time `json:"purge_after"`
+}
+
+func (s *JournalService) deleteGracePeriod() time.Duration {
+	if s.config.DeleteGracePeriod > 0 {
+		return s.config.DeleteGracePeriod
+	}
+	return DefaultDeleteGracePeriod
+}
+
+// DeleteEntry hides an entry and every version in its chain. The rows are
+// kept until the grace period ends, so RestoreEntry can undo the delete;
+// PurgeDeletedEntries removes them after that.
+func (s *JournalService) DeleteEntry(id string) (*DeletedEntry, error) {
+	now := time.Now()
+	scope, scopeArgs := s.scopeClause("user_id", 3)
+	result, err := s.db.Exec(versionChainCTE+`
+		UPDATE journal_entries SET deleted_at = $2
+		WHERE id IN (SELECT id FROM versions) AND deleted_at IS NULL`+scope,
+		append([]interface{}{id, now}, scopeArgs...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete entry: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, NotFoundf("entry not found")
+	}
+
+	deleted := &DeletedEntry{ID: id, DeletedAt: now, PurgeAfter: now.Add(s.deleteGracePeriod())}
+	s.sendEvent(events.EventEntryDeleted, id, map[string]interface{}{
+		"deleted_at":  deleted.DeletedAt,
+		"purge_after": deleted.PurgeAfter,
+	})
+
+	return deleted, nil
+}
+
+// RestoreEntry undoes DeleteEntry for an entry that has not been purged yet
+func (s *JournalService) RestoreEntry(id string) (*models.JournalEntry, error) {
+	scope, scopeArgs := s.scopeClause("user_id", 2)
+	result, err := s.db.Exec(versionChainCTE+`
+		UPDATE journal_entries SET deleted_at = NULL
+		WHERE id IN (SELECT id FROM versions) AND deleted_at IS NOT NULL`+scope,
+		append([]interface{}{id}, scopeArgs...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore entry: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, NotFoundf("no deleted entry %s to restore", id)
+	}
+
+	entry, err := s.GetEntry(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Clients add the entry back to their lists as if it were new
+	s.sendEvent(events.EventEntryCreated, entry.ID, map[string]interface{}{
+		"entry":    entry,
+		"restored": true,
+	})
+
+	return entry, nil
+}
+
+// PurgeDeletedEntries permanently removes entries deleted longer ago than
+// the grace period, for every user. A version chain is deleted with one
+// timestamp, so each chain is purged in a single statement and the
+// original_entry_id references between its rows never dangle.
+func (s *JournalService) PurgeDeletedEntries() (int, error) {
+	result, err := s.db.Exec(
+		"DELETE FROM journal_entries WHERE deleted_at <= $1",
+		time.Now().Add(-s.deleteGracePeriod()),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted entries: %w", err)
+	}
+
+	n, _ := result.RowsAffected()
+	return int(n), nil
+}
+
+// StartDeletedEntryPurger runs PurgeDeletedEntries every purgeInterval in the
+// background
+func (s *JournalService) StartDeletedEntryPurger() {
+	go func() {
+		ticker := time.NewTicker(purgeInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			n, err := s.PurgeDeletedEntries()
+			if err != nil {
+				slog.Error("Deleted entry purge failed", "error", err)
+				continue
+			}
+			if n > 0 {
+				slog.Info("Purged deleted entries", "count", n)
+			}
+		}
+	}()
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteEntrySoftDeletesVersionChain(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	broadcaster := events.NewBroadcaster()
+	var sent []*events.Event
+	broadcaster.AddHook(func(e *events.Event) { sent = append(sent, e) })
+
+	service := (&JournalService{db: database, broadcaster: broadcaster}).
+		WithConfig(Config{MultiTenant: true, DeleteGracePeriod: time.Hour}).
+		ForUser("alice")
+
+	mock.ExpectExec(`WITH RECURSIVE ancestors AS .*UPDATE journal_entries SET deleted_at = \$2 WHERE id IN \(SELECT id FROM versions\) AND deleted_at IS NULL AND user_id = \$3`).
+		WithArgs("e1", sqlmock.AnyArg(), "alice").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	deleted, err := service.DeleteEntry("e1")
+	require.NoError(t, err)
+	assert.Equal(t, "e1", deleted.ID)
+	assert.Equal(t, time.Hour, deleted.PurgeAfter.Sub(deleted.DeletedAt))
+
+	require.Len(t, sent, 1)
+	assert.Equal(t, string(events.EventEntryDeleted), sent[0].Type)
+	assert.Equal(t, "e1", sent[0].EntryID)
+
+	mock.ExpectExec(`UPDATE journal_entries SET deleted_at = \$2`).
+		WithArgs("e1", sqlmock.AnyArg(), "alice").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, err = service.DeleteEntry("e1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreEntry(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	broadcaster := events.NewBroadcaster()
+	var sent []*events.Event
+	broadcaster.AddHook(func(e *events.Event) { sent = append(sent, e) })
+
+	service := &JournalService{db: database, broadcaster: broadcaster}
+	now := time.Now()
+
+	mock.ExpectExec(`UPDATE journal_entries SET deleted_at = NULL WHERE id IN \(SELECT id FROM versions\) AND deleted_at IS NOT NULL`).
+		WithArgs("e1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`WHERE je.id = \$1 AND je.deleted_at IS NULL`).
+		WithArgs("e1").
+		WillReturnRows(entryRows(mockEntry{ID: "e1", Content: "back again", CreatedAt: now}))
+
+	entry, err := service.RestoreEntry("e1")
+	require.NoError(t, err)
+	assert.Equal(t, "back again", entry.Content)
+
+	require.Len(t, sent, 1)
+	assert.Equal(t, string(events.EventEntryCreated), sent[0].Type)
+
+	mock.ExpectExec(`UPDATE journal_entries SET deleted_at = NULL`).
+		WithArgs("e2").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, err = service.RestoreEntry("e2")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeDeletedEntries(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+
+	mock.ExpectExec(`DELETE FROM journal_entries WHERE deleted_at <= \$1`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	n, err := service.PurgeDeletedEntries()
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"time"
+
+	"github.com/journal/internal/models"
+)
+
+// draftMetadataKey marks the processed data of a draft entry. Processing the
+// draft replaces its processed data, which clears the mark.
+const draftMetadataKey = "draft"
+
+// CreateDraftAt stores a scratchpad entry without running AI analysis or
+// creating embeddings. The draft is completed right away with empty processed
+// data, so it is listed and found by classic search but, having no
+// embedding, never by vector search. ProcessDraft analyzes it later on
+// demand. createdAt is handled as in CreateEntryAt.
+func (s *JournalService) CreateDraftAt(content string, createdAt time.Time) (*models.JournalEntry, error) {
+	createdAt, err := s.validateNewEntry(content, createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	entry := models.JournalEntry{
+		Content: content,
+		ProcessedData: models.ProcessedData{
+			Entities:      []string{},
+			Topics:        []string{},
+			Metadata:      map[string]any{draftMetadataKey: true},
+			ExtractedURLs: []models.ExtractedURL{},
+		},
+		CreatedAt:             createdAt,
+		UpdatedAt:             now,
+		ProcessingStage:       models.StageCompleted,
+		ProcessingCompletedAt: &now,
+	}
+
+	if err := s.storeNewEntry(&entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// IsDraft reports whether entry was created as a draft and not processed since
+func IsDraft(entry *models.JournalEntry) bool {
+	draft, _ := entry.ProcessedData.Metadata[draftMetadataKey].(bool)
+	return draft
+}
+
+// ProcessDraft runs the full pipeline (analysis, URL fetching and
+// embeddings) on a draft in the background, after which it is an ordinary
+// entry. Entries that are not drafts are rejected; RetryProcessing and
+// ReprocessEntry cover those.
+func (s *JournalService) ProcessDraft(entryID string) error {
+	entry, err := s.GetEntry(entryID)
+	if err != nil {
+		return err
+	}
+	if !IsDraft(entry) {
+		return Conflictf("entry %s is not a draft", entryID)
+	}
+	if s.processor == nil {
+		return Unavailablef("processing is unavailable: no AI processor configured")
+	}
+
+	return s.restartProcessing(entry, "Processing draft")
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/logger"
+	"github.com/journal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateDraftSkipsProcessing(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	// No processor: running the pipeline would fail the test
+	service := &JournalService{
+		db:          database,
+		broadcaster: broadcaster,
+		logger:      logger.NewProcessingLogger(database.DB),
+	}
+
+	mock.ExpectQuery(`INSERT INTO journal_entries \(content, processed_data, created_at, updated_at, is_favorite, processing_stage, processing_started_at, processing_completed_at, user_id, ts_config, original_content\)`).
+		WithArgs("quick note", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), false, models.StageCompleted, nil, sqlmock.AnyArg(), nil, "english", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("d1"))
+
+	entry, err := service.CreateDraftAt("quick note", time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "d1", entry.ID)
+	assert.Equal(t, models.StageCompleted, entry.ProcessingStage)
+	assert.True(t, IsDraft(entry))
+
+	sent := recorded()
+	require.Len(t, sent, 1)
+	assert.Equal(t, string(events.EventEntryCreated), sent[0].Type)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProcessDraftRejectsProcessedEntry(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+
+	mock.ExpectQuery(`WHERE je.id = \$1`).
+		WithArgs("e1").
+		WillReturnRows(entryRows(mockEntry{ID: "e1", Content: "analyzed", ProcessedData: `{"summary":"done"}`}))
+
+	err := service.ProcessDraft("e1")
+	assert.ErrorIs(t, err, ErrConflict)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
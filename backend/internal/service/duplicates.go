@@ -0,0 +1,84 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultDuplicateThreshold is the cosine similarity above which two entries
+// are reported as likely duplicates when the caller does not pick a threshold
+const DefaultDuplicateThreshold = 0.95
+
+// maxDuplicatePairs caps how many candidate pairs FindDuplicates returns
+const maxDuplicatePairs = 100
+
+// DuplicatePair is two distinct entries whose embeddings are nearly identical
+type DuplicatePair struct {
+	EntryID            string    `json:"entry_id"`
+	DuplicateID        string    `json:"duplicate_id"`
+	Similarity         float32   `json:"similarity"`
+	EntryCreatedAt     time.Time `json:"entry_created_at"`
+	DuplicateCreatedAt time.Time `json:"duplicate_created_at"`
+	EntryPreview       string    `json:"entry_preview"`
+	DuplicatePreview   string    `json:"duplicate_preview"`
+}
+
+// FindDuplicates returns pairs of entries whose embedding similarity exceeds
+// threshold, most similar first. Versions of the same entry (linked through
+// original_entry_id) are never paired with each other.
+func (s *JournalService) FindDuplicates(threshold float32) ([]DuplicatePair, error) {
+	if threshold <= 0 {
+		threshold = DefaultDuplicateThreshold
+	}
+	if threshold > 1 {
+		return nil, Invalidf("threshold must be between 0 and 1")
+	}
+
+	scopeA, scopeArgs := s.scopeClause("a.user_id", 2)
+	scopeB, _ := s.scopeClause("b.user_id", 2)
+	similarity := s.metric().similarity("a.embedding", "b.embedding")
+
+	// chains maps every entry to the root of its version chain
+	query := `
+		WITH RECURSIVE chains AS (
+			SELECT id, id AS root FROM journal_entries WHERE original_entry_id IS NULL
+			UNION ALL
+			SELECT je.id, c.root
+			FROM journal_entries je
+			JOIN chains c ON je.original_entry_id = c.id
+		)
+		SELECT
+			a.id, b.id, ` + similarity + ` AS similarity,
+			a.created_at, b.created_at, LEFT(a.content, 200), LEFT(b.content, 200)
+		FROM journal_entries a
+		JOIN journal_entries b ON a.id < b.id
+		JOIN chains ca ON ca.id = a.id
+		JOIN chains cb ON cb.id = b.id
+		WHERE a.embedding IS NOT NULL AND b.embedding IS NOT NULL
+			AND a.deleted_at IS NULL AND b.deleted_at IS NULL
+			AND ca.root <> cb.root
+			AND ` + similarity + ` > $1` + scopeA + scopeB + fmt.Sprintf(`
+		ORDER BY similarity DESC
+		LIMIT %d`, maxDuplicatePairs)
+
+	rows, err := s.db.Query(query, append([]interface{}{threshold}, scopeArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+	defer rows.Close()
+
+	pairs := []DuplicatePair{}
+	for rows.Next() {
+		var p DuplicatePair
+		if err := rows.Scan(
+			&p.EntryID, &p.DuplicateID, &p.Similarity,
+			&p.EntryCreatedAt, &p.DuplicateCreatedAt,
+			&p.EntryPreview, &p.DuplicatePreview,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan duplicate pair: %w", err)
+		}
+		pairs = append(pairs, p)
+	}
+
+	return pairs, rows.Err()
+}
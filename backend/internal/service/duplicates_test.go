@@ -0,0 +1,31 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDuplicatesExcludesVersionChains(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	now := time.Now()
+
+	mock.ExpectQuery(`WITH RECURSIVE chains AS .* AND ca.root <> cb.root AND 1 - \(a.embedding <=> b.embedding\) > \$1 ORDER BY similarity DESC`).
+		WithArgs(float32(DefaultDuplicateThreshold)).
+		WillReturnRows(sqlmock.NewRows([]string{"a", "b", "similarity", "a_created", "b_created", "a_preview", "b_preview"}).
+			AddRow("e1", "e2", 0.99, now, now, "same text", "same text"))
+
+	pairs, err := service.FindDuplicates(0)
+	require.NoError(t, err)
+	require.Len(t, pairs, 1)
+	assert.Equal(t, "e1", pairs[0].EntryID)
+	assert.Equal(t, "e2", pairs[0].DuplicateID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
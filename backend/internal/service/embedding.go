@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/journal/internal/models"
+)
+
+// embeddingDimensions is the width of the embedding vector(768) columns in
+// journal_entries and entry_chunks
+const embeddingDimensions = 768
+
+// createEmbedding embeds an entry and checks the vector fits the schema, so a
+// swapped embedding model fails with a clear message rather than a pgvector
+// error from the UPDATE
+func (s *JournalService) createEmbedding(ctx context.Context, entry models.JournalEntry) ([]float32, error) {
+	embedding, err := s.processor.CreateEmbedding(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEmbeddingDimensions(len(embedding)); err != nil {
+		return nil, err
+	}
+	return embedding, nil
+}
+
+// checkEmbeddingDimensions rejects embeddings whose width does not match the
+// schema
+func checkEmbeddingDimensions(dims int) error {
+	if dims != embeddingDimensions {
+		return fmt.Errorf("embedding model returned %d dims, schema expects %d; use a %d-dimension embedding model such as nomic-embed-text",
+			dims, embeddingDimensions, embeddingDimensions)
+	}
+	return nil
+}
+
+// retryEmbedding generates the missing embedding of an analyzed entry in the
+// background, keeping its analysis and fetched URLs
+func (s *JournalService) retryEmbedding(entry *models.JournalEntry) error {
+	if s.processor == nil {
+		return Unavailablef("embedding is unavailable: no AI processor configured")
+	}
+
+	s.logger.LogInfo(entry.ID, models.StageGeneratingEmbeddings, "Retrying embedding", nil)
+	go func() {
+		ctx, done := s.startProcessing(entry.ID)
+		defer done()
+		s.storeAnalysis(ctx, *entry)
+	}()
+	return nil
+}
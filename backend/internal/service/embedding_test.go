@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/logger"
+	"github.com/journal/internal/models"
+	"github.com/journal/internal/ollama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// analyzedEntry is an entry whose analysis succeeded
+func analyzedEntry() models.JournalEntry {
+	return models.JournalEntry{
+		ID:      "e1",
+		Content: "A long walk by the river",
+		ProcessedData: models.ProcessedData{
+			Summary: "A walk",
+			Topics:  []string{"walking"},
+		},
+	}
+}
+
+func TestStoreAnalysisKeepsAnalysisWhenEmbeddingFails(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusInternalServerError)
+	}))
+	defer down.Close()
+
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	service := &JournalService{
+		db:          database,
+		processor:   ollama.NewProcessor(ollama.NewClient(down.URL)),
+		broadcaster: broadcaster,
+		logger:      logger.NewProcessingLogger(database.DB),
+	}
+
+	mock.ExpectExec(`UPDATE journal_entries SET processed_data = \$1, embedding = \$2`).
+		WithArgs(sqlmock.AnyArg(), nil, sqlmock.AnyArg(), models.StageCompletedNoEmbedding, sqlmock.AnyArg(), "e1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	service.storeAnalysis(context.Background(), analyzedEntry())
+
+	var processed *events.Event
+	for _, e := range recorded() {
+		assert.NotEqual(t, string(events.EventEntryFailed), e.Type)
+		if e.Type == string(events.EventEntryProcessed) {
+			processed = e
+		}
+	}
+	require.NotNil(t, processed)
+	assert.Equal(t, models.StageCompletedNoEmbedding, processed.Data.(map[string]interface{})["stage"])
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStoreAnalysisStrictEmbeddingsFails(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusInternalServerError)
+	}))
+	defer down.Close()
+
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	service := (&JournalService{
+		db:          database,
+		processor:   ollama.NewProcessor(ollama.NewClient(down.URL)),
+		broadcaster: broadcaster,
+		logger:      logger.NewProcessingLogger(database.DB),
+	}).WithConfig(Config{StrictEmbeddings: true})
+
+	// The entry is marked failed; its analysis is not stored
+	mock.ExpectExec(`SET processing_stage = \$1, processing_error = \$2`).
+		WithArgs(models.StageFailed, sqlmock.AnyArg(), sqlmock.AnyArg(), "e1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	service.storeAnalysis(context.Background(), analyzedEntry())
+
+	var failed *events.Event
+	for _, e := range recorded() {
+		if e.Type == string(events.EventEntryFailed) {
+			failed = e
+		}
+	}
+	require.NotNil(t, failed)
+	assert.Equal(t, models.StageGeneratingEmbeddings, failed.Data.(map[string]interface{})["stage"])
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRetryProcessingOnlyRegeneratesMissingEmbedding(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	// Answers embeddings only; re-running the analysis would fail
+	embeddings := embeddingServer(embeddingDimensions)
+	defer embeddings.Close()
+
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	service := &JournalService{
+		db:          database,
+		processor:   ollama.NewProcessor(ollama.NewClient(embeddings.URL)),
+		broadcaster: broadcaster,
+		logger:      logger.NewProcessingLogger(database.DB),
+	}
+
+	mock.ExpectQuery(`WHERE je.id = \$1`).
+		WithArgs("e1").
+		WillReturnRows(entryRows(mockEntry{
+			ID:            "e1",
+			Content:       "A long walk by the river",
+			ProcessedData: `{"summary":"A walk","topics":["walking"]}`,
+			Stage:         models.StageCompletedNoEmbedding,
+		}))
+	mock.ExpectExec(`UPDATE journal_entries SET processed_data = \$1, embedding = \$2`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), models.StageCompleted, sqlmock.AnyArg(), "e1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, service.RetryProcessing("e1"))
+
+	require.Eventually(t, func() bool {
+		for _, e := range recorded() {
+			if e.Type == string(events.EventEntryProcessed) {
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+
+	for _, e := range recorded() {
+		if e.Type == string(events.EventEntryProcessing) {
+			assert.Equal(t, models.StageGeneratingEmbeddings, e.Data.(map[string]interface{})["stage"])
+		}
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
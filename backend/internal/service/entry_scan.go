@@ -0,0 +1,99 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/journal/internal/models"
+	"github.com/lib/pq"
+)
+
+// entryColumns is the select list of every query returning full entries, in
+// the order scanEntry reads them. The query must select FROM journal_entries
+// je LEFT JOIN journal_collection jc and GROUP BY je.id.
+const entryColumns = `
+			je.id, je.content, je.processed_data, je.created_at, je.updated_at,
+			je.is_favorite, je.pinned_at, je.original_entry_id,
+			je.processing_stage, je.processing_started_at, je.processing_completed_at, je.processing_error, je.attachments,
+			je.original_content,
+			COALESCE(array_agg(jc.collection_id) FILTER (WHERE jc.collection_id IS NOT NULL), '{}') as collection_ids`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanEntry scans a row selecting entryColumns, followed by any extra
+// columns into extra
+func scanEntry(row rowScanner, extra ...interface{}) (models.JournalEntry, error) {
+	var entry models.JournalEntry
+	var processedJSON []byte
+
+	dest := []interface{}{
+		&entry.ID,
+		&entry.Content,
+		&processedJSON,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+		&entry.IsFavorite,
+		&entry.PinnedAt,
+		&entry.OriginalEntryID,
+		&entry.ProcessingStage,
+		&entry.ProcessingStartedAt,
+		&entry.ProcessingCompletedAt,
+		&entry.ProcessingError,
+		&entry.Attachments,
+		&entry.OriginalContent,
+		pq.Array(&entry.CollectionIDs),
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return entry, fmt.Errorf("failed to scan entry: %w", err)
+	}
+
+	if err := json.Unmarshal(processedJSON, &entry.ProcessedData); err != nil {
+		return entry, fmt.Errorf("failed to unmarshal processed data: %w", err)
+	}
+
+	return entry, nil
+}
+
+// scanEntries scans every row of an entry query
+func (s *JournalService) scanEntries(rows *sql.Rows) ([]models.JournalEntry, error) {
+	entries := []models.JournalEntry{}
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read entries: %w", err)
+	}
+	return entries, nil
+}
+
+// scanEntriesWithSimilarity scans entry rows followed by a similarity column,
+// recording the score as metadata["similarity_score"]
+func (s *JournalService) scanEntriesWithSimilarity(rows *sql.Rows) ([]models.JournalEntry, error) {
+	entries := []models.JournalEntry{}
+	for rows.Next() {
+		var similarity float32
+		entry, err := scanEntry(rows, &similarity)
+		if err != nil {
+			return nil, err
+		}
+
+		if entry.ProcessedData.Metadata == nil {
+			entry.ProcessedData.Metadata = make(map[string]any)
+		}
+		entry.ProcessedData.Metadata["similarity_score"] = similarity
+
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read entries: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error classes returned by the service layer. Match them with errors.Is;
+// the JSON-RPC server maps each class to its own error code.
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrValidation         = errors.New("invalid request")
+	ErrConflict           = errors.New("conflict")
+	ErrServiceUnavailable = errors.New("service unavailable")
+)
+
+// Error is a service error tagged with one of the error classes above
+type Error struct {
+	Kind    error
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// NotFoundf reports a missing entry, collection or other record
+func NotFoundf(format string, args ...interface{}) error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// Invalidf reports a request the caller must correct before retrying
+func Invalidf(format string, args ...interface{}) error {
+	return &Error{Kind: ErrValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// Conflictf reports a request that clashes with the current state, such as
+// a duplicate name or an entry that is still processing
+func Conflictf(format string, args ...interface{}) error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+// Unavailablef reports that a dependency such as Ollama cannot be reached
+func Unavailablef(format string, args ...interface{}) error {
+	return &Error{Kind: ErrServiceUnavailable, Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/journal/internal/models"
+)
+
+// maxMatchReasons caps the reasons attached to each explained result
+const maxMatchReasons = 5
+
+var queryWord = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// explainMatches records in metadata["match_reasons"] the topics and entities
+// each result shares with the query. The query is analyzed with the same
+// model as entries, which costs one extra model call per search, so this only
+// runs when SearchParams.Explain is set. If the analysis fails, reasons fall
+// back to the stored topics and entities the query text mentions.
+func (s *JournalService) explainMatches(query string, entries []models.JournalEntry) {
+	if len(entries) == 0 {
+		return
+	}
+
+	analysis, err := s.processor.ProcessJournalEntry(context.Background(), query)
+	if err != nil {
+		slog.Warn("Failed to analyze query for match reasons, using query words only", "error", err)
+		analysis = nil
+	}
+
+	for i := range entries {
+		if entries[i].ProcessedData.Metadata == nil {
+			entries[i].ProcessedData.Metadata = make(map[string]any)
+		}
+		entries[i].ProcessedData.Metadata["match_reasons"] = matchReasons(query, analysis, entries[i].ProcessedData)
+	}
+}
+
+// matchReasons lists, as "topic: x" and "entity: x", the entry's topics and
+// entities that also appear in the query's analysis, then those the query
+// text names directly. Comparisons ignore case. The result is never nil so
+// an explained result with no overlap is distinguishable from an unexplained
+// one.
+func matchReasons(query string, analysis *models.ProcessedData, entry models.ProcessedData) []string {
+	reasons := []string{}
+	seen := make(map[string]bool)
+	add := func(kind, value string) {
+		reason := kind + ": " + value
+		key := strings.ToLower(reason)
+		if len(reasons) < maxMatchReasons && !seen[key] {
+			seen[key] = true
+			reasons = append(reasons, reason)
+		}
+	}
+
+	if analysis != nil {
+		for _, topic := range overlap(entry.Topics, analysis.Topics) {
+			add("topic", topic)
+		}
+		for _, entity := range overlap(entry.Entities, analysis.Entities) {
+			add("entity", entity)
+		}
+	}
+
+	words := " " + strings.Join(queryWord.FindAllString(strings.ToLower(query), -1), " ") + " "
+	mentioned := func(value string) bool {
+		phrase := strings.Join(queryWord.FindAllString(strings.ToLower(value), -1), " ")
+		return phrase != "" && strings.Contains(words, " "+phrase+" ")
+	}
+	for _, topic := range entry.Topics {
+		if mentioned(topic) {
+			add("topic", topic)
+		}
+	}
+	for _, entity := range entry.Entities {
+		if mentioned(entity) {
+			add("entity", entity)
+		}
+	}
+
+	return reasons
+}
+
+// overlap returns the values of a that also appear in b, ignoring case
+func overlap(a, b []string) []string {
+	inB := make(map[string]bool, len(b))
+	for _, v := range b {
+		inB[strings.ToLower(strings.TrimSpace(v))] = true
+	}
+
+	var shared []string
+	for _, v := range a {
+		if inB[strings.ToLower(strings.TrimSpace(v))] {
+			shared = append(shared, v)
+		}
+	}
+	return shared
+}
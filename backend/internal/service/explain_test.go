@@ -0,0 +1,66 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/journal/internal/models"
+	"github.com/journal/internal/ollama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchReasons(t *testing.T) {
+	entry := models.ProcessedData{
+		Topics:   []string{"Hiking", "Work", "Mountains"},
+		Entities: []string{"Alice", "New York"},
+	}
+	analysis := &models.ProcessedData{
+		Topics:   []string{"hiking", "outdoors"},
+		Entities: []string{"alice"},
+	}
+
+	assert.Equal(t, []string{"topic: Hiking", "entity: Alice", "entity: New York"},
+		matchReasons("weekend trips from new york", analysis, entry))
+
+	// Without an analysis only names in the query text count
+	assert.Equal(t, []string{"topic: Mountains"}, matchReasons("mountains!", nil, entry))
+
+	// Substrings of a word are not mentions
+	assert.Equal(t, []string{}, matchReasons("homework", nil, entry))
+}
+
+func TestVectorSearchExplainFallsBackWhenAnalysisFails(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	vector := embeddingServer(embeddingDimensions)
+	defer vector.Close()
+	// Embeddings succeed but the chat endpoint used for analysis fails
+	ollamaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/chat" {
+			http.Error(w, "model not loaded", http.StatusInternalServerError)
+			return
+		}
+		vector.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer ollamaServer.Close()
+
+	service := &JournalService{
+		db:        database,
+		processor: ollama.NewProcessor(ollama.NewClient(ollamaServer.URL)),
+	}
+	now := time.Now()
+
+	mock.ExpectQuery(`ORDER BY similarity DESC`).
+		WillReturnRows(similarityRows(mockEntry{ID: "e1", Content: "c", ProcessedData: `{"topics":["gardening"],"entities":[]}`, CreatedAt: now, Similarity: 0.8}))
+
+	entries, err := service.VectorSearch(SearchParams{Query: "gardening tips", Explain: true})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []string{"topic: gardening"}, entries[0].ProcessedData.Metadata["match_reasons"])
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
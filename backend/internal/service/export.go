@@ -0,0 +1,295 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/journal/internal/models"
+)
+
+// exportFlushInterval is how many entries a streamed export writes between
+// flushes to the client
+const exportFlushInterval = 100
+
+// maxStreamedExportEntries bounds a streamed export. Streaming keeps memory
+// flat, so it can be far larger than maxExportEntries.
+const maxStreamedExportEntries = 50000
+
+// contentEllipsis marks entry content cut short by an export's
+// maxContentLength
+const contentEllipsis = "…"
+
+// ExportContentType returns the content type of an export format, or an error
+// for unsupported formats
+func ExportContentType(format string) (string, error) {
+	switch format {
+	case "json":
+		return "application/json", nil
+	case "markdown":
+		return "text/markdown", nil
+	case "csv":
+		return "text/csv", nil
+	default:
+		return "", Invalidf("unsupported export format: %s", format)
+	}
+}
+
+// StreamExport writes the entries matching params to w as they are read from
+// the database, so large exports are never held in memory. When w is an
+// http.Flusher the output is flushed every exportFlushInterval entries.
+// Errors after the first write leave a truncated document, so callers should
+// validate the format with ExportContentType before writing headers.
+// A positive maxContentLength truncates each entry's content in markdown and
+// csv output to that many characters.
+func (s *JournalService) StreamExport(w io.Writer, params SearchParams, format string, maxContentLength int) error {
+	if _, err := ExportContentType(format); err != nil {
+		return err
+	}
+	if params.Limit <= 0 || params.Limit > maxStreamedExportEntries {
+		params.Limit = maxStreamedExportEntries
+	}
+
+	query, args, err := s.classicSearchQuery(params)
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to search entries: %w", err)
+	}
+	defer rows.Close()
+
+	flusher, _ := w.(http.Flusher)
+	exp := newExporter(w, format, "Journal Export")
+	exp.maxContentLength = maxContentLength
+	if err := exp.begin(); err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return err
+		}
+		if err := exp.write(entry); err != nil {
+			return err
+		}
+
+		if flusher != nil && exp.count%exportFlushInterval == 0 {
+			if err := exp.buf.Flush(); err != nil {
+				return err
+			}
+			flusher.Flush()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read entries: %w", err)
+	}
+
+	return exp.end()
+}
+
+// ExportEntry renders a single entry as markdown, including the title and
+// fetched content of every URL it links to, and returns it with its filename
+func (s *JournalService) ExportEntry(id string) ([]byte, string, error) {
+	entry, err := s.GetEntry(id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	exp := newExporter(&buf, "markdown", "Journal Entry")
+	exp.urls = true
+	if err := exp.begin(); err != nil {
+		return nil, "", err
+	}
+	if err := exp.write(*entry); err != nil {
+		return nil, "", err
+	}
+	if err := exp.end(); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), ExportFilename("entry", "markdown"), nil
+}
+
+// renderExport formats entries as json, markdown or csv; title heads the
+// markdown document. A positive maxContentLength truncates content as in
+// StreamExport.
+func renderExport(entries []models.JournalEntry, format, title string, maxContentLength int) ([]byte, string, error) {
+	contentType, err := ExportContentType(format)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var buf bytes.Buffer
+	exp := newExporter(&buf, format, title)
+	exp.maxContentLength = maxContentLength
+	if err := exp.begin(); err != nil {
+		return nil, "", err
+	}
+	for _, entry := range entries {
+		if err := exp.write(entry); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := exp.end(); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), contentType, nil
+}
+
+// exporter writes one export document entry by entry. Its output matches
+// rendering the whole entry list at once: the JSON form is byte-identical to
+// json.MarshalIndent of the slice.
+type exporter struct {
+	buf    *bufio.Writer
+	format string
+	title  string
+	count  int
+
+	// urls adds each entry's fetched URL titles and content to markdown
+	urls bool
+
+	// maxContentLength, when positive, truncates entry content in markdown
+	// and csv to that many characters
+	maxContentLength int
+}
+
+func newExporter(w io.Writer, format, title string) *exporter {
+	return &exporter{buf: bufio.NewWriter(w), format: format, title: title}
+}
+
+func (e *exporter) begin() error {
+	switch e.format {
+	case "json":
+		e.buf.WriteString("[")
+	case "markdown":
+		e.buf.WriteString(fmt.Sprintf("# %s\n\n", e.title))
+		e.buf.WriteString(fmt.Sprintf("*Exported on %s*\n\n", time.Now().Format("January 2, 2006")))
+	case "csv":
+		e.buf.WriteString("Date,Time,Summary,Content,Topics,Entities,Sentiment,Is Favorite,Attachments\n")
+	}
+	return nil
+}
+
+func (e *exporter) write(entry models.JournalEntry) error {
+	e.count++
+
+	switch e.format {
+	case "json":
+		data, err := json.MarshalIndent(entry, "  ", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		if e.count > 1 {
+			e.buf.WriteString(",")
+		}
+		e.buf.WriteString("\n  ")
+		e.buf.Write(data)
+
+	case "markdown":
+		e.buf.WriteString(fmt.Sprintf("## %s\n\n", entry.CreatedAt.Format("January 2, 2006 - 3:04 PM")))
+
+		if entry.ProcessedData.Summary != "" {
+			e.buf.WriteString(fmt.Sprintf("**Summary:** %s\n\n", entry.ProcessedData.Summary))
+		}
+
+		e.buf.WriteString(e.content(entry) + "\n\n")
+
+		if len(entry.ProcessedData.Topics) > 0 {
+			e.buf.WriteString("**Topics:** " + strings.Join(entry.ProcessedData.Topics, ", ") + "\n\n")
+		}
+
+		if len(entry.ProcessedData.Entities) > 0 {
+			e.buf.WriteString("**Entities:** " + strings.Join(entry.ProcessedData.Entities, ", ") + "\n\n")
+		}
+
+		if entry.ProcessedData.Sentiment != "" {
+			e.buf.WriteString(fmt.Sprintf("**Sentiment:** %s\n\n", entry.ProcessedData.Sentiment))
+		}
+
+		if len(entry.Attachments) > 0 {
+			e.buf.WriteString("**Attachments:**\n\n")
+			for _, a := range entry.Attachments {
+				if a.URL != "" {
+					e.buf.WriteString(fmt.Sprintf("- [%s](%s)\n", a.Filename, a.URL))
+				} else {
+					e.buf.WriteString("- " + a.Filename + "\n")
+				}
+			}
+			e.buf.WriteString("\n")
+		}
+
+		if e.urls && len(entry.ProcessedData.ExtractedURLs) > 0 {
+			e.buf.WriteString("### Links\n\n")
+			for _, u := range entry.ProcessedData.ExtractedURLs {
+				title := u.Title
+				if title == "" {
+					title = u.URL
+				}
+				e.buf.WriteString(fmt.Sprintf("#### [%s](%s)\n\n", title, u.URL))
+				if content := strings.TrimSpace(u.Content); content != "" {
+					e.buf.WriteString(content + "\n\n")
+				}
+			}
+		}
+
+		e.buf.WriteString("---\n\n")
+
+	case "csv":
+		e.buf.WriteString(fmt.Sprintf("%s,%s,%s,%s,%s,%s,%s,%v,%s\n",
+			entry.CreatedAt.Format("2006-01-02"),
+			entry.CreatedAt.Format("15:04:05"),
+			escapeCSV(entry.ProcessedData.Summary),
+			escapeCSV(e.content(entry)),
+			escapeCSV(strings.Join(entry.ProcessedData.Topics, "; ")),
+			escapeCSV(strings.Join(entry.ProcessedData.Entities, "; ")),
+			entry.ProcessedData.Sentiment,
+			entry.IsFavorite,
+			escapeCSV(attachmentNames(entry.Attachments)),
+		))
+	}
+	return nil
+}
+
+// content returns entry's content, cut to maxContentLength characters with
+// an ellipsis appended when it is longer
+func (e *exporter) content(entry models.JournalEntry) string {
+	if e.maxContentLength <= 0 || utf8.RuneCountInString(entry.Content) <= e.maxContentLength {
+		return entry.Content
+	}
+	runes := []rune(entry.Content)
+	return string(runes[:e.maxContentLength]) + contentEllipsis
+}
+
+func (e *exporter) end() error {
+	if e.format == "json" {
+		if e.count > 0 {
+			e.buf.WriteString("\n")
+		}
+		e.buf.WriteString("]")
+	}
+	return e.buf.Flush()
+}
+
+// attachmentNames lists attachments for a CSV cell, with their URLs when set
+func attachmentNames(attachments models.Attachments) string {
+	names := make([]string, len(attachments))
+	for i, a := range attachments {
+		names[i] = a.Filename
+		if a.URL != "" {
+			names[i] += " (" + a.URL + ")"
+		}
+	}
+	return strings.Join(names, "; ")
+}
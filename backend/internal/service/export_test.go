@@ -0,0 +1,139 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/journal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderExportJSONMatchesMarshalIndent(t *testing.T) {
+	created := time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC)
+	entries := []models.JournalEntry{
+		{ID: "e1", Content: "first <b>", CreatedAt: created, ProcessedData: models.ProcessedData{Topics: []string{"work"}}},
+		{ID: "e2", Content: "second", CreatedAt: created, IsFavorite: true},
+	}
+
+	for n := 0; n <= len(entries); n++ {
+		want, err := json.MarshalIndent(entries[:n], "", "  ")
+		require.NoError(t, err)
+
+		got, contentType, err := renderExport(entries[:n], "json", "", 0)
+		require.NoError(t, err)
+		assert.Equal(t, "application/json", contentType)
+		assert.Equal(t, string(want), string(got))
+	}
+}
+
+func TestStreamExportCSV(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	created := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`GROUP BY je.id ORDER BY je.pinned_at DESC NULLS LAST, je.created_at DESC, je.id DESC LIMIT \$1`).
+		WithArgs(maxStreamedExportEntries).
+		WillReturnRows(entryRows(mockEntry{ID: "e1", Content: "hello, world", ProcessedData: `{"summary":"greeting","sentiment":"positive"}`, CreatedAt: created, IsFavorite: true}))
+
+	var buf bytes.Buffer
+	require.NoError(t, service.StreamExport(&buf, SearchParams{}, "csv", 0))
+
+	assert.Equal(t, "Date,Time,Summary,Content,Topics,Entities,Sentiment,Is Favorite,Attachments\n"+
+		"2024-03-01,08:30:00,greeting,\"hello, world\",,,positive,true,\n", buf.String())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStreamExportTruncatesContent(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	created := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`GROUP BY je.id ORDER BY je.pinned_at DESC NULLS LAST, je.created_at DESC, je.id DESC LIMIT \$1`).
+		WithArgs(maxStreamedExportEntries).
+		WillReturnRows(entryRows(
+			mockEntry{ID: "e1", Content: "naïve café visit", CreatedAt: created},
+			mockEntry{ID: "e2", Content: "short", CreatedAt: created},
+		))
+
+	var buf bytes.Buffer
+	require.NoError(t, service.StreamExport(&buf, SearchParams{}, "csv", 10))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, "naïve café…", records[1][3])
+	assert.Equal(t, "short", records[2][3])
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRenderExportTruncatesMarkdownContent(t *testing.T) {
+	entries := []models.JournalEntry{{ID: "e1", Content: "a very long entry", CreatedAt: time.Now()}}
+
+	data, _, err := renderExport(entries, "markdown", "Journal", 6)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "\n\na very…\n\n")
+	assert.NotContains(t, string(data), "long entry")
+
+	// JSON keeps the full entry
+	data, _, err = renderExport(entries, "json", "", 6)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "a very long entry")
+}
+
+func TestEscapeCSVEmbeddedLineBreaks(t *testing.T) {
+	row := escapeCSV("first line\r\nsecond, \"quoted\"\rthird") + "," + escapeCSV("next") + "\n"
+	assert.Equal(t, "\"first line\nsecond, \"\"quoted\"\"\nthird\",next\n", row)
+
+	records, err := csv.NewReader(strings.NewReader(row)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, []string{"first line\nsecond, \"quoted\"\nthird", "next"}, records[0])
+}
+
+func TestStreamExportRejectsUnknownFormat(t *testing.T) {
+	service := &JournalService{}
+
+	err := service.StreamExport(&bytes.Buffer{}, SearchParams{}, "xml", 0)
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+func TestExportEntryIncludesFetchedURLs(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	created := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+	processed := `{"summary":"reading","topics":["go"],"sentiment":"positive","extracted_urls":[` +
+		`{"url":"https://go.dev/blog","title":"The Go Blog","content":"Posts about Go."},` +
+		`{"url":"https://example.com"}]}`
+
+	mock.ExpectQuery(`FROM journal_entries je`).
+		WithArgs("e1").
+		WillReturnRows(entryRows(mockEntry{ID: "e1", Content: "Read https://go.dev/blog", ProcessedData: processed, CreatedAt: created}))
+
+	data, filename, err := service.ExportEntry("e1")
+	require.NoError(t, err)
+	assert.Contains(t, filename, "journal-export-entry-")
+
+	markdown := string(data)
+	assert.Contains(t, markdown, "# Journal Entry\n\n")
+	assert.Contains(t, markdown, "**Topics:** go\n\n")
+	assert.Contains(t, markdown, "**Sentiment:** positive\n\n"+
+		"### Links\n\n"+
+		"#### [The Go Blog](https://go.dev/blog)\n\nPosts about Go.\n\n"+
+		"#### [https://example.com](https://example.com)\n\n"+
+		"---\n\n")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CollectionFacet is the number of completed entries in a collection
+type CollectionFacet struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// SentimentFacet is the number of completed entries with a sentiment
+type SentimentFacet struct {
+	Sentiment string `json:"sentiment"`
+	Count     int    `json:"count"`
+}
+
+// FacetValue is a distinct topic or entity and the number of completed
+// entries naming it
+type FacetValue struct {
+	Text  string `json:"text"`
+	Count int    `json:"count"`
+}
+
+// facetFields maps the kinds GetFacet accepts to their processed_data array
+var facetFields = map[string]string{
+	"topic":    "topics",
+	"topics":   "topics",
+	"entity":   "entities",
+	"entities": "entities",
+}
+
+// likeEscaper escapes LIKE wildcards so a prefix matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// GetFacet lists the distinct topics or entities (kind "topics" or
+// "entities") of completed entries with how many entries name each, most
+// common first. prefix keeps only values starting with it, ignoring case.
+// Unlike GetSearchSuggestions, which shows the top ten, it covers every
+// value; a limit of 0 returns them all.
+func (s *JournalService) GetFacet(kind, prefix string, limit int) ([]FacetValue, error) {
+	field, ok := facetFields[strings.ToLower(kind)]
+	if !ok {
+		return nil, Invalidf("invalid facet kind: %s (expected topics or entities)", kind)
+	}
+	if limit < 0 {
+		return nil, Invalidf("limit must not be negative")
+	}
+	limit = s.clampLimit(limit)
+
+	scope, args := s.scopeClause("user_id", 1)
+	query := `
+		SELECT value, COUNT(*) AS count
+		FROM journal_entries,
+		LATERAL jsonb_array_elements_text(processed_data->'` + field + `') AS value
+		WHERE processing_stage IN ('completed', 'completed_no_embedding') AND deleted_at IS NULL` + scope
+	if prefix != "" {
+		args = append(args, likeEscaper.Replace(prefix))
+		query += fmt.Sprintf(" AND value ILIKE $%d || '%%'", len(args))
+	}
+	query += " GROUP BY value ORDER BY count DESC, value"
+	if limit > 0 {
+		args = append(args, limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", field, err)
+	}
+	defer rows.Close()
+
+	values := []FacetValue{}
+	for rows.Next() {
+		var v FacetValue
+		if err := rows.Scan(&v.Text, &v.Count); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+
+	return values, rows.Err()
+}
+
+// favoriteCount counts completed favorite entries
+func (s *JournalService) favoriteCount() (int, error) {
+	scope, scopeArgs := s.scopeClause("user_id", 1)
+
+	var count int
+	err := s.db.QueryRow(
+		"SELECT COUNT(*) FROM journal_entries WHERE processing_stage IN ('completed', 'completed_no_embedding') AND is_favorite AND deleted_at IS NULL"+scope,
+		scopeArgs...,
+	).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count favorites: %w", err)
+	}
+
+	return count, nil
+}
+
+// collectionCounts counts the completed entries in each manual collection.
+// Smart collections have no stored membership, so they are left out.
+func (s *JournalService) collectionCounts() ([]CollectionFacet, error) {
+	scope, scopeArgs := s.scopeClause("c.user_id", 1)
+
+	rows, err := s.db.Query(`
+		SELECT c.id, c.name, COUNT(je.id)
+		FROM collections c
+		LEFT JOIN journal_collection jc ON jc.collection_id = c.id
+		LEFT JOIN journal_entries je ON je.id = jc.journal_id AND je.processing_stage IN ('completed', 'completed_no_embedding') AND je.deleted_at IS NULL
+		WHERE NOT c.is_smart`+scope+`
+		GROUP BY c.id, c.name
+		ORDER BY c.name`,
+		scopeArgs...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count collection entries: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []CollectionFacet{}
+	for rows.Next() {
+		var c CollectionFacet
+		if err := rows.Scan(&c.ID, &c.Name, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, rows.Err()
+}
+
+// sentimentCounts counts completed entries per sentiment, most common first
+func (s *JournalService) sentimentCounts() ([]SentimentFacet, error) {
+	scope, scopeArgs := s.scopeClause("user_id", 1)
+
+	rows, err := s.db.Query(`
+		SELECT processed_data->>'sentiment' AS sentiment, COUNT(*) AS count
+		FROM journal_entries
+		WHERE processing_stage IN ('completed', 'completed_no_embedding') AND deleted_at IS NULL
+		AND COALESCE(processed_data->>'sentiment', '') <> ''`+scope+`
+		GROUP BY sentiment
+		ORDER BY count DESC, sentiment`,
+		scopeArgs...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sentiments: %w", err)
+	}
+	defer rows.Close()
+
+	counts := []SentimentFacet{}
+	for rows.Next() {
+		var c SentimentFacet
+		if err := rows.Scan(&c.Sentiment, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, rows.Err()
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFacetFiltersByPrefix(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+
+	// Wildcards in the prefix are escaped so they match literally
+	mock.ExpectQuery(`jsonb_array_elements_text\(processed_data->'topics'\) AS value WHERE processing_stage IN \('completed', 'completed_no_embedding'\) AND deleted_at IS NULL AND value ILIKE \$1 \|\| '%' GROUP BY value ORDER BY count DESC, value LIMIT \$2`).
+		WithArgs(`tra\_`, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"value", "count"}).
+			AddRow("tra_vel", 4).
+			AddRow("Tra_ins", 1))
+
+	values, err := service.GetFacet("topics", "tra_", 20)
+	require.NoError(t, err)
+	assert.Equal(t, []FacetValue{{Text: "tra_vel", Count: 4}, {Text: "Tra_ins", Count: 1}}, values)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFacetListsEveryValueWithoutLimit(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{db: database}).WithConfig(Config{MultiTenant: true}).ForUser("alice")
+
+	mock.ExpectQuery(`processed_data->'entities'\) AS value WHERE (.+) AND user_id = \$1 GROUP BY value ORDER BY count DESC, value$`).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"value", "count"}))
+
+	values, err := service.GetFacet("entity", "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []FacetValue{}, values)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFacetRejectsUnknownKind(t *testing.T) {
+	_, err := (&JournalService{}).GetFacet("moods", "", 0)
+	assert.ErrorIs(t, err, ErrValidation)
+}
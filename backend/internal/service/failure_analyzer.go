@@ -45,6 +45,12 @@ type FailureAnalysis struct {
 
 // AnalyzeFailure analyzes why a journal entry processing failed
 func (fa *FailureAnalyzer) AnalyzeFailure(ctx context.Context, entryID string, entry *models.JournalEntry) (*FailureAnalysis, error) {
+	return fa.analyzeFailure(ctx, entryID, entry, true)
+}
+
+// analyzeFailure ranks likely causes from the processing logs, asking the
+// model to refine them when useAI is set
+func (fa *FailureAnalyzer) analyzeFailure(ctx context.Context, entryID string, entry *models.JournalEntry, useAI bool) (*FailureAnalysis, error) {
 	// Get all logs for the entry
 	logs, err := fa.logger.GetLogs(entryID)
 	if err != nil {
@@ -74,7 +80,7 @@ func (fa *FailureAnalyzer) AnalyzeFailure(ctx context.Context, entryID string, e
 				Cause:       "Content too large",
 				Description: "The journal entry content exceeds the model's context window",
 				Probability: 0.2,
-				Solution:    "Consider splitting very long entries or increasing model context size",
+				Solution:    "Consider splitting very long entries, increasing model context size, or lowering OLLAMA_MAX_INPUT_CHARS",
 			},
 			{
 				Cause:       "Invalid JSON response",
@@ -128,8 +134,18 @@ func (fa *FailureAnalyzer) AnalyzeFailure(ctx context.Context, entryID string, e
 		likelyCauses = causes
 	}
 
+	// A recorded timeout outweighs the generic guesses for the stage
+	if timedOut(errorLogs) {
+		likelyCauses = append([]FailureCause{timeoutCause(lastStage)}, likelyCauses...)
+	}
+
+	// A missing model is detected exactly, so it replaces the guesses
+	if model, ok := missingModel(errorLogs); ok {
+		likelyCauses = []FailureCause{modelNotFoundCause(model)}
+	}
+
 	// If we have error logs, use AI to refine the analysis
-	if len(errorLogs) > 0 && entry != nil {
+	if useAI && len(errorLogs) > 0 && entry != nil {
 		refinedAnalysis, err := fa.aiAnalyzeError(ctx, entry.Content, logsContext, errorLogs)
 		if err == nil && refinedAnalysis != nil {
 			// Merge AI insights with common patterns
@@ -165,6 +181,64 @@ func (fa *FailureAnalyzer) AnalyzeFailure(ctx context.Context, entryID string, e
 	}, nil
 }
 
+// timedOut reports whether any error was logged as a timeout
+func timedOut(errorLogs []models.ProcessingLog) bool {
+	for _, errLog := range errorLogs {
+		if errLog.Details["error_type"] == "timeout" {
+			return true
+		}
+	}
+	return false
+}
+
+// missingModel returns the model named by an error logged as model_not_found
+func missingModel(errorLogs []models.ProcessingLog) (string, bool) {
+	for _, errLog := range errorLogs {
+		if errLog.Details["error_type"] == "model_not_found" {
+			model, _ := errLog.Details["model"].(string)
+			return model, true
+		}
+	}
+	return "", false
+}
+
+// modelNotFoundCause describes a request for a model Ollama has not pulled
+func modelNotFoundCause(model string) FailureCause {
+	return FailureCause{
+		Cause:       "Model not installed",
+		Description: fmt.Sprintf("Ollama does not have the %s model", model),
+		Probability: 1.0,
+		Solution:    fmt.Sprintf("Install the model with 'ollama pull %s'", model),
+	}
+}
+
+// timeoutCause describes a timeout in the given stage
+func timeoutCause(stage models.ProcessingStage) FailureCause {
+	switch stage {
+	case models.StageAnalyzing:
+		return FailureCause{
+			Cause:       "Analysis timed out",
+			Description: "Ollama was reachable but did not finish analyzing the entry within the chat timeout",
+			Probability: 0.9,
+			Solution:    "Increase OLLAMA_TIMEOUT, lower OLLAMA_MAX_INPUT_CHARS, or run Ollama on faster hardware",
+		}
+	case models.StageGeneratingEmbeddings:
+		return FailureCause{
+			Cause:       "Embedding timed out",
+			Description: "Ollama did not return the embedding within the embedding timeout",
+			Probability: 0.9,
+			Solution:    "Increase OLLAMA_EMBED_TIMEOUT or check whether Ollama is busy with other requests",
+		}
+	default:
+		return FailureCause{
+			Cause:       "Request timed out",
+			Description: "A request made during this stage did not complete in time",
+			Probability: 0.9,
+			Solution:    "Retry the operation; if it keeps timing out, check the responsiveness of the service involved",
+		}
+	}
+}
+
 // buildLogsContext creates a summary of logs for AI analysis
 func (fa *FailureAnalyzer) buildLogsContext(logs []models.ProcessingLog, errorLogs []models.ProcessingLog) string {
 	var context strings.Builder
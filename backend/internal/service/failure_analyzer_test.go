@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeFailureDetectsMissingModel(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	analyzer := NewFailureAnalyzer(nil, logger.NewProcessingLogger(database.DB))
+
+	mock.ExpectQuery(`SELECT id, entry_id, stage, level, message, details, created_at\s+FROM processing_logs`).
+		WithArgs("e1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "entry_id", "stage", "level", "message", "details", "created_at"}).
+			AddRow("l1", "e1", "analyzing", "error", "Processing failed",
+				`{"error":"ollama model \"qwen3:8b\" is not installed","error_type":"model_not_found","model":"qwen3:8b"}`, time.Now()))
+
+	analysis, err := analyzer.analyzeFailure(context.Background(), "e1", nil, false)
+	require.NoError(t, err)
+	require.Len(t, analysis.LikelyCauses, 1)
+	assert.Equal(t, "Model not installed", analysis.LikelyCauses[0].Cause)
+	assert.Equal(t, "Install the model with 'ollama pull qwen3:8b'", analysis.LikelyCauses[0].Solution)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// maxSummaryErrorLength truncates error messages before grouping, so errors
+// that differ only in trailing detail (response bodies, IDs) group together
+const maxSummaryErrorLength = 120
+
+// FailureCauseGroup counts the failed entries whose most likely cause is the
+// same
+type FailureCauseGroup struct {
+	Cause       string   `json:"cause"`
+	Solution    string   `json:"solution"`
+	Entries     int      `json:"entries"`
+	EntryIDs    []string `json:"entry_ids"`
+	SampleError string   `json:"sample_error"`
+}
+
+// FailureErrorCount counts failed entries with the same error message
+type FailureErrorCount struct {
+	Error   string `json:"error"`
+	Entries int    `json:"entries"`
+}
+
+// FailureSummary is the aggregate picture of every failed entry
+type FailureSummary struct {
+	TotalFailed int                 `json:"total_failed"`
+	ByStage     map[string]int      `json:"by_stage"`
+	Causes      []FailureCauseGroup `json:"causes"`
+	Errors      []FailureErrorCount `json:"errors"`
+}
+
+// AnalyzeAllFailures analyzes every failed entry and groups them by most
+// likely cause and by error message. The heuristic analysis only reads the
+// processing logs; useAI additionally asks the model to refine each entry's
+// causes, which costs one model call per failed entry.
+func (s *JournalService) AnalyzeAllFailures(useAI bool) (*FailureSummary, error) {
+	scope, scopeArgs := s.scopeClause("user_id", 1)
+	rows, err := s.db.Query(
+		"SELECT id FROM journal_entries WHERE processing_stage = 'failed' AND deleted_at IS NULL"+scope+" ORDER BY processing_completed_at DESC",
+		scopeArgs...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list failed entries: %w", err)
+	}
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+
+	analyses := make([]*FailureAnalysis, 0, len(ids))
+	for _, id := range ids {
+		entry, err := s.GetEntry(id)
+		if err != nil {
+			slog.Warn("Skipping failed entry in failure summary", "entry_id", id, "error", err)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		analysis, err := s.failureAnalyzer.analyzeFailure(ctx, id, entry, useAI)
+		cancel()
+		if err != nil {
+			slog.Warn("Skipping failed entry in failure summary", "entry_id", id, "error", err)
+			continue
+		}
+		analyses = append(analyses, analysis)
+	}
+
+	return summarizeFailures(analyses), nil
+}
+
+// summarizeFailures groups analyses by their top cause and by error
+// message, largest groups first
+func summarizeFailures(analyses []*FailureAnalysis) *FailureSummary {
+	summary := &FailureSummary{
+		TotalFailed: len(analyses),
+		ByStage:     map[string]int{},
+		Causes:      []FailureCauseGroup{},
+		Errors:      []FailureErrorCount{},
+	}
+
+	causes := map[string]*FailureCauseGroup{}
+	errorCounts := map[string]int{}
+
+	for _, analysis := range analyses {
+		summary.ByStage[analysis.FailedStage]++
+
+		errorMsg := analysis.Error
+		if len(errorMsg) > maxSummaryErrorLength {
+			errorMsg = errorMsg[:maxSummaryErrorLength] + "..."
+		}
+		if errorMsg != "" {
+			errorCounts[errorMsg]++
+		}
+
+		cause, solution := "Unknown", ""
+		if len(analysis.LikelyCauses) > 0 {
+			cause, solution = analysis.LikelyCauses[0].Cause, analysis.LikelyCauses[0].Solution
+		}
+
+		group, ok := causes[cause]
+		if !ok {
+			group = &FailureCauseGroup{Cause: cause, Solution: solution, EntryIDs: []string{}, SampleError: errorMsg}
+			causes[cause] = group
+		}
+		group.Entries++
+		group.EntryIDs = append(group.EntryIDs, analysis.EntryID)
+	}
+
+	for _, group := range causes {
+		summary.Causes = append(summary.Causes, *group)
+	}
+	sort.Slice(summary.Causes, func(i, j int) bool {
+		if summary.Causes[i].Entries != summary.Causes[j].Entries {
+			return summary.Causes[i].Entries > summary.Causes[j].Entries
+		}
+		return summary.Causes[i].Cause < summary.Causes[j].Cause
+	})
+
+	for errorMsg, n := range errorCounts {
+		summary.Errors = append(summary.Errors, FailureErrorCount{Error: errorMsg, Entries: n})
+	}
+	sort.Slice(summary.Errors, func(i, j int) bool {
+		if summary.Errors[i].Entries != summary.Errors[j].Entries {
+			return summary.Errors[i].Entries > summary.Errors[j].Entries
+		}
+		return summary.Errors[i].Error < summary.Errors[j].Error
+	})
+
+	return summary
+}
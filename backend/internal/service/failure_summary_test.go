@@ -0,0 +1,33 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeFailures(t *testing.T) {
+	unavailable := FailureCause{Cause: "Ollama service unavailable", Solution: "Start Ollama"}
+	timeout := FailureCause{Cause: "Analysis timed out", Solution: "Increase OLLAMA_TIMEOUT"}
+
+	summary := summarizeFailures([]*FailureAnalysis{
+		{EntryID: "e1", FailedStage: "analyzing", Error: "connection refused", LikelyCauses: []FailureCause{unavailable}},
+		{EntryID: "e2", FailedStage: "analyzing", Error: "connection refused", LikelyCauses: []FailureCause{unavailable}},
+		{EntryID: "e3", FailedStage: "analyzing", Error: "deadline exceeded", LikelyCauses: []FailureCause{timeout, unavailable}},
+		{EntryID: "e4", FailedStage: "generating_embeddings", Error: "connection refused"},
+	})
+
+	assert.Equal(t, 4, summary.TotalFailed)
+	assert.Equal(t, map[string]int{"analyzing": 3, "generating_embeddings": 1}, summary.ByStage)
+
+	assert.Equal(t, []FailureCauseGroup{
+		{Cause: "Ollama service unavailable", Solution: "Start Ollama", Entries: 2, EntryIDs: []string{"e1", "e2"}, SampleError: "connection refused"},
+		{Cause: "Analysis timed out", Solution: "Increase OLLAMA_TIMEOUT", Entries: 1, EntryIDs: []string{"e3"}, SampleError: "deadline exceeded"},
+		{Cause: "Unknown", Entries: 1, EntryIDs: []string{"e4"}, SampleError: "connection refused"},
+	}, summary.Causes)
+
+	assert.Equal(t, []FailureErrorCount{
+		{Error: "connection refused", Entries: 3},
+		{Error: "deadline exceeded", Entries: 1},
+	}, summary.Errors)
+}
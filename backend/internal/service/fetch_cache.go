@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/journal/internal/models"
+)
+
+// fetchURL fetches a URL through the MCP agent, persisting the result in
+// fetched_urls. A stored result younger than FetchCacheTTL is returned
+// without contacting the agent; the returned bool reports a cache hit.
+func (s *JournalService) fetchURL(ctx context.Context, url, reason string) (*models.ExtractedURL, bool, error) {
+	if s.config.FetchCacheTTL > 0 {
+		if cached, err := s.cachedFetch(url, s.config.FetchCacheTTL); err != nil {
+			slog.Warn("Failed to read fetch cache", "url", url, "error", err)
+		} else if cached != nil {
+			return cached, true, nil
+		}
+	}
+
+	fetched, err := s.mcpClient.FetchURL(ctx, url, reason)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := s.storeFetch(url, reason, fetched); err != nil {
+		slog.Warn("Failed to persist fetch result", "url", url, "error", err)
+	}
+
+	return fetched, false, nil
+}
+
+// cachedFetch returns the stored result for url if it is younger than ttl
+func (s *JournalService) cachedFetch(url string, ttl time.Duration) (*models.ExtractedURL, error) {
+	var result models.ExtractedURL
+	err := s.db.QueryRow(
+		"SELECT url, title, content, source, fetched_at FROM fetched_urls WHERE url = $1 AND fetched_at > $2",
+		url, time.Now().Add(-ttl),
+	).Scan(&result.URL, &result.Title, &result.Content, &result.Source, &result.ExtractedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// storeFetch records a fetch result, replacing any older copy of the URL
+func (s *JournalService) storeFetch(url, reason string, fetched *models.ExtractedURL) error {
+	metadata, err := json.Marshal(map[string]string{"reason": reason})
+	if err != nil {
+		return err
+	}
+
+	fetchedAt := fetched.ExtractedAt
+	if fetchedAt.IsZero() {
+		fetchedAt = time.Now()
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO fetched_urls (url, title, content, source, metadata, fetched_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (url) DO UPDATE SET
+			title = EXCLUDED.title,
+			content = EXCLUDED.content,
+			source = EXCLUDED.source,
+			metadata = EXCLUDED.metadata,
+			fetched_at = EXCLUDED.fetched_at`,
+		url, fetched.Title, fetched.Content, fetched.Source, metadata, fetchedAt,
+	)
+	return err
+}
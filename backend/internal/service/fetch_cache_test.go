@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchURLUsesFreshCache(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	// No MCP client: a cache miss would panic
+	service := &JournalService{db: database, config: Config{FetchCacheTTL: time.Hour}}
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT url, title, content, source, fetched_at FROM fetched_urls WHERE url = \$1 AND fetched_at > \$2`).
+		WithArgs("https://example.com", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"url", "title", "content", "source", "fetched_at"}).
+			AddRow("https://example.com", "Example", "cached body", "example.com", now))
+
+	result, cached, err := service.fetchURL(context.Background(), "https://example.com", "reference")
+	require.NoError(t, err)
+	assert.True(t, cached)
+	assert.Equal(t, "cached body", result.Content)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/db"
+	"github.com/journal/internal/ollama"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildFilterClause(t *testing.T) {
+	favorite := true
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+
+	clause, args := buildFilterClause(SearchParams{
+		IsFavorite:    &favorite,
+		CollectionIDs: []string{"c1", "c2"},
+		StartDate:     &start,
+		EndDate:       &end,
+	}, 3)
+
+	assert.Equal(t, " AND je.is_favorite = $3"+
+		" AND je.id IN (SELECT journal_id FROM journal_collection WHERE collection_id = ANY($4))"+
+		" AND je.created_at >= $5 AND je.created_at <= $6", clause)
+	assert.Equal(t, []interface{}{true, pq.Array([]string{"c1", "c2"}), start, end}, args)
+
+	clause, args = buildFilterClause(SearchParams{}, 1)
+	assert.Empty(t, clause)
+	assert.Empty(t, args)
+}
+
+// TestFilterClauseSharedAcrossSearchModes checks classic and vector search
+// apply the same filters for the same params, differing only in where their
+// placeholders start
+func TestFilterClauseSharedAcrossSearchModes(t *testing.T) {
+	var queries []string
+	recordQueries := sqlmock.QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+		queries = append(queries, actualSQL)
+		return nil
+	})
+	mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(recordQueries))
+	require.NoError(t, err)
+	defer mockDB.Close()
+
+	embeddings := embeddingServer(embeddingDimensions)
+	defer embeddings.Close()
+
+	service := &JournalService{
+		db:        &db.DB{DB: mockDB},
+		processor: ollama.NewProcessor(ollama.NewClient(embeddings.URL)),
+	}
+
+	favorite := false
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	params := SearchParams{Query: "rain", IsFavorite: &favorite, CollectionIDs: []string{"c1"}, StartDate: &start, SemanticMode: "similar", Limit: 5}
+
+	mock.ExpectQuery("classic").WillReturnRows(entryRows())
+	mock.ExpectQuery("vector").WillReturnRows(similarityRows())
+
+	_, err = service.ClassicSearch(params)
+	require.NoError(t, err)
+	_, err = service.VectorSearch(params)
+	require.NoError(t, err)
+	require.Len(t, queries, 2)
+
+	// Both number the filters from $2: classic search after the text query,
+	// vector search after the query embedding
+	filters, _ := buildFilterClause(params, 2)
+	assert.True(t, strings.Contains(queries[0], filters), queries[0])
+	assert.True(t, strings.Contains(queries[1], filters), queries[1])
+	assert.NotContains(t, queries[1], "websearch_to_tsquery")
+}
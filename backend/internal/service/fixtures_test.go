@@ -0,0 +1,91 @@
+package service
+
+import (
+	"database/sql/driver"
+	"strings"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/models"
+)
+
+// entryColumnNames are the result columns of a query selecting entryColumns
+var entryColumnNames = []string{
+	"id", "content", "processed_data", "created_at", "updated_at",
+	"is_favorite", "pinned_at", "original_entry_id", "processing_stage",
+	"processing_started_at", "processing_completed_at", "processing_error",
+	"attachments", "original_content", "collection_ids",
+}
+
+// mockEntry is one row of an entry query result. Zero fields take the values
+// of a processed entry created now; UpdatedAt defaults to CreatedAt.
+type mockEntry struct {
+	ID              string
+	Content         string
+	ProcessedData   string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	IsFavorite      bool
+	PinnedAt        time.Time
+	OriginalEntryID string
+	Stage           models.ProcessingStage
+	StartedAt       time.Time
+	CompletedAt     time.Time
+	Error           string
+	Attachments     []byte
+	OriginalContent string
+	CollectionIDs   []string
+	Similarity      float64
+}
+
+func (e mockEntry) values() []driver.Value {
+	orNil := func(v interface{}, zero bool) driver.Value {
+		if zero {
+			return nil
+		}
+		return v
+	}
+
+	processed := e.ProcessedData
+	if processed == "" {
+		processed = `{}`
+	}
+	created := e.CreatedAt
+	if created.IsZero() {
+		created = time.Now()
+	}
+	updated := e.UpdatedAt
+	if updated.IsZero() {
+		updated = created
+	}
+	stage := e.Stage
+	if stage == "" {
+		stage = models.StageCompleted
+	}
+
+	return []driver.Value{
+		e.ID, e.Content, []byte(processed), created, updated,
+		e.IsFavorite, orNil(e.PinnedAt, e.PinnedAt.IsZero()), orNil(e.OriginalEntryID, e.OriginalEntryID == ""), string(stage),
+		orNil(e.StartedAt, e.StartedAt.IsZero()), orNil(e.CompletedAt, e.CompletedAt.IsZero()), orNil(e.Error, e.Error == ""),
+		orNil(e.Attachments, e.Attachments == nil), orNil(e.OriginalContent, e.OriginalContent == ""), "{" + strings.Join(e.CollectionIDs, ",") + "}",
+	}
+}
+
+// entryRows returns the result of an entry query holding entries
+func entryRows(entries ...mockEntry) *sqlmock.Rows {
+	rows := sqlmock.NewRows(entryColumnNames)
+	for _, e := range entries {
+		rows.AddRow(e.values()...)
+	}
+	return rows
+}
+
+// similarityRows returns the result of a vector query, whose rows carry a
+// similarity column after the entry columns
+func similarityRows(entries ...mockEntry) *sqlmock.Rows {
+	rows := sqlmock.NewRows(append(append([]string{}, entryColumnNames...), "similarity"))
+	for _, e := range entries {
+		rows.AddRow(append(e.values(), e.Similarity)...)
+	}
+	return rows
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/journal/internal/models"
+	"github.com/lib/pq"
+)
+
+// GetEntries fetches several entries in one query, returned in the order of
+// ids. IDs that do not exist, are deleted or belong to another user are left
+// out rather than failing the call, and repeated IDs are returned once.
+func (s *JournalService) GetEntries(ids []string) ([]models.JournalEntry, error) {
+	ids = uniqueIDs(ids)
+	if len(ids) == 0 {
+		return []models.JournalEntry{}, nil
+	}
+	if limit := s.maxResultLimit(); len(ids) > limit {
+		return nil, Invalidf("at most %d ids can be fetched at once", limit)
+	}
+
+	scope, scopeArgs := s.scopeClause("je.user_id", 2)
+	query := `
+		SELECT` + entryColumns + `
+		FROM journal_entries je
+		LEFT JOIN journal_collection jc ON je.id = jc.journal_id
+		WHERE je.id = ANY($1) AND je.deleted_at IS NULL` + scope + `
+		GROUP BY je.id`
+
+	rows, err := s.db.Query(query, append([]interface{}{pq.Array(ids)}, scopeArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entries: %w", err)
+	}
+	defer rows.Close()
+
+	found, err := s.scanEntries(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]models.JournalEntry, len(found))
+	for _, entry := range found {
+		byID[entry.ID] = entry
+	}
+	entries := make([]models.JournalEntry, 0, len(found))
+	for _, id := range ids {
+		if entry, ok := byID[id]; ok {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
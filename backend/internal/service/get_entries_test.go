@@ -0,0 +1,43 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEntriesKeepsRequestedOrder(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{db: database}).WithConfig(Config{MultiTenant: true}).ForUser("alice")
+	now := time.Now()
+
+	mock.ExpectQuery(`WHERE je.id = ANY\(\$1\) AND je.deleted_at IS NULL AND je.user_id = \$2 GROUP BY je.id`).
+		WithArgs(pq.Array([]string{"e3", "missing", "e1"}), "alice").
+		WillReturnRows(entryRows(
+			mockEntry{ID: "e1", Content: "first", CreatedAt: now},
+			mockEntry{ID: "e3", Content: "third", CreatedAt: now},
+		))
+
+	entries, err := service.GetEntries([]string{"e3", "missing", "e1", "e3"})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "e3", entries[0].ID)
+	assert.Equal(t, "e1", entries[1].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetEntriesLimitsIDs(t *testing.T) {
+	service := (&JournalService{}).WithConfig(Config{MaxResultLimit: 2})
+
+	_, err := service.GetEntries([]string{"a", "b", "c"})
+	assert.ErrorIs(t, err, ErrValidation)
+
+	entries, err := service.GetEntries(nil)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
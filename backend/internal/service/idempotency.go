@@ -0,0 +1,109 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/journal/internal/models"
+)
+
+// IdempotencyWindow is how long an idempotency key keeps returning the entry
+// it created; after that the key may be reused for a new entry
+const IdempotencyWindow = 24 * time.Hour
+
+// maxIdempotencyKeyLength bounds client-supplied keys
+const maxIdempotencyKeyLength = 255
+
+// CreateEntryIdempotent creates an entry like CreateEntryAt, or a draft like
+// CreateDraftAt when process is false, except that a repeated key seen within
+// IdempotencyWindow returns the entry created by the first request instead of
+// inserting a duplicate. An empty key behaves exactly like the plain create.
+//
+// The key is recorded after the entry is inserted, so two requests racing
+// with the same key can still both create an entry; the window protects
+// against sequential client retries, which is the case it exists for.
+func (s *JournalService) CreateEntryIdempotent(content, key string, createdAt time.Time, process bool) (*models.JournalEntry, error) {
+	create := s.CreateEntryAt
+	if !process {
+		create = s.CreateDraftAt
+	}
+	if key == "" {
+		return create(content, createdAt)
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, Invalidf("idempotency_key is longer than %d characters", maxIdempotencyKeyLength)
+	}
+
+	existingID, err := s.lookupIdempotencyKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if existingID != "" {
+		slog.Info("Idempotency key replayed, returning existing entry", "entry_id", existingID)
+		return s.GetEntry(existingID)
+	}
+
+	entry, err := create(content, createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.recordIdempotencyKey(key, entry.ID); err != nil {
+		// The entry exists; a lost key only means a retry could duplicate it
+		slog.Error("Failed to record idempotency key", "entry_id", entry.ID, "error", err)
+	}
+
+	return entry, nil
+}
+
+// idempotencyOwner is the owner column value for the current user
+func (s *JournalService) idempotencyOwner() string {
+	if owner, ok := s.ownerValue().(string); ok {
+		return owner
+	}
+	return ""
+}
+
+// lookupIdempotencyKey returns the entry created with key within the
+// window, or "" when there is none
+func (s *JournalService) lookupIdempotencyKey(key string) (string, error) {
+	var entryID string
+	err := s.db.QueryRow(`
+		SELECT entry_id FROM entry_idempotency_keys
+		WHERE owner = $1 AND key = $2 AND created_at > $3`,
+		s.idempotencyOwner(), key, time.Now().Add(-IdempotencyWindow),
+	).Scan(&entryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up idempotency key: %w", err)
+	}
+	return entryID, nil
+}
+
+// recordIdempotencyKey binds key to entryID, replacing an expired binding,
+// and drops keys that have aged out of the window
+func (s *JournalService) recordIdempotencyKey(key, entryID string) error {
+	cutoff := time.Now().Add(-IdempotencyWindow)
+
+	_, err := s.db.Exec(`
+		INSERT INTO entry_idempotency_keys (owner, key, entry_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (owner, key) DO UPDATE
+		SET entry_id = EXCLUDED.entry_id, created_at = CURRENT_TIMESTAMP
+		WHERE entry_idempotency_keys.created_at <= $4`,
+		s.idempotencyOwner(), key, entryID, cutoff,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record idempotency key: %w", err)
+	}
+
+	if _, err := s.db.Exec("DELETE FROM entry_idempotency_keys WHERE created_at <= $1", cutoff); err != nil {
+		return fmt.Errorf("failed to expire idempotency keys: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateEntryIdempotentReplaysKnownKey(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT entry_id FROM entry_idempotency_keys`).
+		WithArgs("", "key-1", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"entry_id"}).AddRow("e1"))
+	mock.ExpectQuery(`FROM journal_entries je`).
+		WithArgs("e1").
+		WillReturnRows(entryRows(mockEntry{ID: "e1", Content: "first attempt", CreatedAt: now}))
+
+	entry, err := service.CreateEntryIdempotent("first attempt", "key-1", time.Time{}, true)
+	require.NoError(t, err)
+	assert.Equal(t, "e1", entry.ID)
+	assert.Equal(t, "first attempt", entry.Content)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordIdempotencyKeyScopesToOwner(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{db: database}).WithConfig(Config{MultiTenant: true}).ForUser("alice")
+
+	mock.ExpectExec(`INSERT INTO entry_idempotency_keys \(owner, key, entry_id\).*ON CONFLICT \(owner, key\) DO UPDATE`).
+		WithArgs("alice", "key-1", "e1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM entry_idempotency_keys WHERE created_at <= \$1`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, service.recordIdempotencyKey("key-1", "e1"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateEntryIdempotentRejectsLongKey(t *testing.T) {
+	service := &JournalService{}
+
+	_, err := service.CreateEntryIdempotent("content", string(make([]byte, maxIdempotencyKeyLength+1)), time.Time{}, true)
+	assert.ErrorIs(t, err, ErrValidation)
+}
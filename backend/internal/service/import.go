@@ -0,0 +1,361 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/journal/internal/models"
+)
+
+// ImportedEntry is a journal entry parsed from an external export. Items
+// that could not be parsed are kept, with Err saying why, so the import can
+// report on every item of the upload.
+type ImportedEntry struct {
+	Content    string
+	CreatedAt  time.Time
+	IsFavorite bool
+	Tags       []string
+	// File names the zip member the item came from
+	File string
+	// Line is the 1-based line of the upload the item starts on
+	Line int
+	// Err is set when the item cannot be imported. Errors wrapping
+	// errNothingToImport mark items that are skipped rather than failed.
+	Err error
+}
+
+// errNothingToImport marks items that parsed but hold nothing to import,
+// such as an entry with an empty body
+var errNothingToImport = errors.New("nothing to import")
+
+// ImportStatus is the outcome of importing one item
+type ImportStatus string
+
+const (
+	ImportStatusImported ImportStatus = "imported"
+	ImportStatusSkipped  ImportStatus = "skipped"
+	ImportStatusFailed   ImportStatus = "failed"
+)
+
+// ImportItemResult reports the outcome of one item of an import. Index is
+// the item's 0-based position in the upload.
+type ImportItemResult struct {
+	Index   int          `json:"index"`
+	Status  ImportStatus `json:"status"`
+	Error   string       `json:"error,omitempty"`
+	File    string       `json:"file,omitempty"`
+	Line    int          `json:"line,omitempty"`
+	EntryID string       `json:"entry_id,omitempty"`
+}
+
+// ImportResult reports the outcome of a bulk import: Parsed counts the items
+// that parsed, and the other counts split every item by status
+type ImportResult struct {
+	Parsed   int                `json:"parsed"`
+	Imported int                `json:"imported"`
+	Skipped  int                `json:"skipped"`
+	Failed   int                `json:"failed"`
+	Items    []ImportItemResult `json:"items"`
+}
+
+// frontMatterDelimiter opens and closes a front-matter block
+const frontMatterDelimiter = "---"
+
+// frontMatterLine matches a "key: value" front-matter line
+var frontMatterLine = regexp.MustCompile(`^([A-Za-z_][\w-]*):\s*(.*)$`)
+
+// importDateLayouts are the front-matter date formats accepted on import,
+// covering Day One and common Obsidian templates
+var importDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseMarkdownImport splits concatenated Markdown into entries. Every entry
+// starts with a front-matter block delimited by "---" lines that must carry a
+// date and may set favorite and tags. A "---" line only starts a new entry
+// when the next line looks like front matter, so horizontal rules in entry
+// bodies survive. Entries with malformed front matter or an empty body are
+// reported with the reason they cannot be imported.
+func ParseMarkdownImport(r io.Reader) ([]ImportedEntry, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read import: %w", err)
+	}
+
+	var entries []ImportedEntry
+	i := 0
+	for i < len(lines) {
+		if !startsFrontMatter(lines, i) {
+			i++
+			continue
+		}
+
+		end := i + 1
+		for end < len(lines) && strings.TrimSpace(lines[end]) != frontMatterDelimiter {
+			end++
+		}
+		if end == len(lines) {
+			// Unterminated front matter swallows the rest of the input
+			entries = append(entries, ImportedEntry{Line: i + 1, Err: fmt.Errorf("front matter is never closed")})
+			break
+		}
+
+		bodyEnd := end + 1
+		for bodyEnd < len(lines) && !startsFrontMatter(lines, bodyEnd) {
+			bodyEnd++
+		}
+
+		entry, err := parseFrontMatter(lines[i+1 : end])
+		entry.Content = strings.TrimSpace(strings.Join(lines[end+1:bodyEnd], "\n"))
+		entry.Line = i + 1
+		switch {
+		case err != nil:
+			entry.Err = err
+		case entry.Content == "":
+			entry.Err = fmt.Errorf("%w: the entry body is empty", errNothingToImport)
+		}
+		entries = append(entries, entry)
+
+		i = bodyEnd
+	}
+
+	return entries, nil
+}
+
+// ParseMarkdownZip parses every .md file in a zip archive, such as an
+// Obsidian vault or a Day One Markdown export. Items are tagged with the
+// file they came from, and a file that cannot be read is reported as one
+// failed item.
+func ParseMarkdownZip(data []byte) ([]ImportedEntry, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+
+	var entries []ImportedEntry
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || !strings.EqualFold(path.Ext(file.Name), ".md") {
+			continue
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			entries = append(entries, ImportedEntry{File: file.Name, Err: err})
+			continue
+		}
+		parsed, err := ParseMarkdownImport(f)
+		f.Close()
+		if err != nil {
+			entries = append(entries, ImportedEntry{File: file.Name, Err: err})
+			continue
+		}
+
+		if len(parsed) == 0 {
+			// A note without front matter has no date to import it under
+			parsed = []ImportedEntry{{Err: fmt.Errorf("%w: the note has no front matter with a date", errNothingToImport)}}
+		}
+		for i := range parsed {
+			parsed[i].File = file.Name
+		}
+		entries = append(entries, parsed...)
+	}
+
+	return entries, nil
+}
+
+// startsFrontMatter reports whether lines[i] opens a front-matter block
+func startsFrontMatter(lines []string, i int) bool {
+	return strings.TrimSpace(lines[i]) == frontMatterDelimiter &&
+		i+1 < len(lines) && frontMatterLine.MatchString(lines[i+1])
+}
+
+// parseFrontMatter reads the date, favorite and tags keys; other keys are
+// ignored so exports from different tools can be imported unchanged
+func parseFrontMatter(lines []string) (ImportedEntry, error) {
+	var entry ImportedEntry
+	hasDate := false
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		match := frontMatterLine.FindStringSubmatch(line)
+		if match == nil {
+			return entry, fmt.Errorf("malformed front matter line %q", line)
+		}
+		key, value := strings.ToLower(match[1]), unquote(strings.TrimSpace(match[2]))
+
+		switch key {
+		case "date", "created", "created_at":
+			createdAt, err := parseImportDate(value)
+			if err != nil {
+				return entry, err
+			}
+			entry.CreatedAt = createdAt
+			hasDate = true
+		case "favorite", "starred":
+			favorite, err := strconv.ParseBool(value)
+			if err != nil {
+				return entry, fmt.Errorf("invalid %s value %q", key, value)
+			}
+			entry.IsFavorite = favorite
+		case "tags":
+			entry.Tags = parseTags(value)
+		}
+	}
+
+	if !hasDate {
+		return entry, fmt.Errorf("front matter has no date")
+	}
+	return entry, nil
+}
+
+func parseImportDate(value string) (time.Time, error) {
+	for _, layout := range importDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date %q", value)
+}
+
+// parseTags accepts both "[a, b]" and "a, b" tag lists
+func parseTags(value string) []string {
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+
+	var tags []string
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimPrefix(unquote(strings.TrimSpace(tag)), "#")
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// ImportEntries stores parsed entries with their original dates, files them
+// into a manual collection per tag (created when missing), and processes
+// them one at a time in the background so a large import does not flood the
+// model with concurrent requests. Every item gets a result; one that fails
+// does not stop the others.
+func (s *JournalService) ImportEntries(entries []ImportedEntry) (*ImportResult, error) {
+	result := &ImportResult{Items: make([]ImportItemResult, 0, len(entries))}
+
+	collectionIDs, err := s.collectionIDsByName()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var created []*models.JournalEntry
+	for i, imported := range entries {
+		item := ImportItemResult{Index: i, File: imported.File, Line: imported.Line}
+		err := imported.Err
+		if err == nil {
+			result.Parsed++
+			err = validateCreatedAt(imported.CreatedAt, now)
+		}
+
+		var entry *models.JournalEntry
+		if err == nil {
+			entry, err = s.insertEntry(imported.Content, imported.CreatedAt, imported.IsFavorite)
+			if err != nil {
+				slog.Error("Failed to import entry", "created_at", imported.CreatedAt.Format(time.RFC3339), "error", err)
+			}
+		}
+
+		switch {
+		case errors.Is(err, errNothingToImport):
+			item.Status = ImportStatusSkipped
+			item.Error = err.Error()
+			result.Skipped++
+		case err != nil:
+			item.Status = ImportStatusFailed
+			item.Error = err.Error()
+			result.Failed++
+		default:
+			item.Status = ImportStatusImported
+			item.EntryID = entry.ID
+			result.Imported++
+			created = append(created, entry)
+
+			for _, tag := range imported.Tags {
+				if err := s.fileUnderTag(entry.ID, tag, collectionIDs); err != nil {
+					slog.Error("Failed to tag imported entry", "entry_id", entry.ID, "tag", tag, "error", err)
+				}
+			}
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	go func() {
+		for _, entry := range created {
+			s.processEntry(entry.ID, entry.Content)
+		}
+	}()
+
+	return result, nil
+}
+
+// collectionIDsByName maps the names of the user's manual collections to
+// their IDs
+func (s *JournalService) collectionIDsByName() (map[string]string, error) {
+	collections, err := s.GetCollections()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]string, len(collections))
+	for _, collection := range collections {
+		if !collection.IsSmart {
+			ids[collection.Name] = collection.ID
+		}
+	}
+	return ids, nil
+}
+
+// fileUnderTag adds an entry to the manual collection named after tag,
+// creating the collection on first use
+func (s *JournalService) fileUnderTag(entryID, tag string, collectionIDs map[string]string) error {
+	collectionID, ok := collectionIDs[tag]
+	if !ok {
+		collection, err := s.CreateCollection(tag, "Imported tag")
+		if err != nil {
+			return err
+		}
+		collectionID = collection.ID
+		collectionIDs[tag] = collectionID
+	}
+
+	_, err := s.db.Exec(
+		"INSERT INTO journal_collection (journal_id, collection_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+		entryID, collectionID,
+	)
+	return err
+}
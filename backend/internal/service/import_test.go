@@ -0,0 +1,162 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/logger"
+	"github.com/journal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMarkdownImport(t *testing.T) {
+	input := `---
+date: 2023-05-01
+favorite: true
+tags: [work, "travel"]
+---
+Flew to Lisbon for the conference.
+
+---
+
+Notes after the horizontal rule stay in the entry.
+---
+title: No date here
+---
+This one is skipped.
+---
+date: 2023-05-03 21:30
+---
+Quiet evening at home.
+---
+date: not-a-date
+---
+Skipped too.
+`
+
+	entries, err := ParseMarkdownImport(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	assert.NoError(t, entries[0].Err)
+	assert.Equal(t, 1, entries[0].Line)
+	assert.Equal(t, time.Date(2023, 5, 1, 0, 0, 0, 0, time.Local), entries[0].CreatedAt)
+	assert.True(t, entries[0].IsFavorite)
+	assert.Equal(t, []string{"work", "travel"}, entries[0].Tags)
+	assert.Contains(t, entries[0].Content, "Notes after the horizontal rule")
+
+	assert.Equal(t, 11, entries[1].Line)
+	assert.EqualError(t, entries[1].Err, "front matter has no date")
+
+	assert.NoError(t, entries[2].Err)
+	assert.Equal(t, time.Date(2023, 5, 3, 21, 30, 0, 0, time.Local), entries[2].CreatedAt)
+	assert.False(t, entries[2].IsFavorite)
+	assert.Equal(t, "Quiet evening at home.", entries[2].Content)
+
+	assert.Equal(t, 19, entries[3].Line)
+	assert.EqualError(t, entries[3].Err, `unrecognized date "not-a-date"`)
+}
+
+func TestParseMarkdownImportReportsEmptyAndUnterminatedEntries(t *testing.T) {
+	input := `---
+date: 2023-05-01
+---
+
+---
+date: 2023-05-02
+Never closed.
+`
+
+	entries, err := ParseMarkdownImport(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	assert.ErrorIs(t, entries[0].Err, errNothingToImport)
+	assert.Equal(t, 5, entries[1].Line)
+	assert.EqualError(t, entries[1].Err, "front matter is never closed")
+}
+
+func TestParseMarkdownZip(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	files := map[string]string{
+		"2023/first.md":  "---\ndate: 2023-01-01\n---\nNew year.\n",
+		"2023/notes.md":  "Just a note without front matter.\n",
+		"attachment.png": "not markdown",
+	}
+	for name, content := range files {
+		w, err := archive.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+
+	entries, err := ParseMarkdownZip(buf.Bytes())
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	byFile := map[string]ImportedEntry{}
+	for _, entry := range entries {
+		byFile[entry.File] = entry
+	}
+	assert.NoError(t, byFile["2023/first.md"].Err)
+	assert.Equal(t, "New year.", byFile["2023/first.md"].Content)
+	assert.ErrorIs(t, byFile["2023/notes.md"].Err, errNothingToImport)
+}
+
+func TestImportEntriesReportsEveryItem(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	mock.MatchExpectationsInOrder(false)
+
+	service := &JournalService{
+		db:          database,
+		broadcaster: events.NewBroadcaster(),
+		logger:      logger.NewProcessingLogger(database.DB),
+	}
+	created := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`FROM collections c`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at", "count", "is_smart", "query_params"}))
+	mock.ExpectQuery(`INSERT INTO journal_entries`).
+		WithArgs("kept", sqlmock.AnyArg(), created, sqlmock.AnyArg(), false, models.StageCreated, sqlmock.AnyArg(), nil, nil, "english", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("e1"))
+	mock.ExpectQuery(`INSERT INTO journal_entries`).
+		WithArgs("storage fails", sqlmock.AnyArg(), created, sqlmock.AnyArg(), false, models.StageCreated, sqlmock.AnyArg(), nil, nil, "english", nil).
+		WillReturnError(errors.New("connection reset"))
+
+	result, err := service.ImportEntries([]ImportedEntry{
+		{Content: "kept", CreatedAt: created, Line: 1},
+		{Line: 5, Err: errors.New("front matter has no date")},
+		{Line: 9, Err: fmt.Errorf("%w: the entry body is empty", errNothingToImport)},
+		{Content: "storage fails", CreatedAt: created, Line: 12},
+		{Content: "from the future", CreatedAt: time.Now().Add(48 * time.Hour), Line: 16},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, result.Parsed)
+	assert.Equal(t, 1, result.Imported)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Equal(t, 3, result.Failed)
+	require.Len(t, result.Items, 5)
+
+	assert.Equal(t, ImportItemResult{Index: 0, Status: ImportStatusImported, Line: 1, EntryID: "e1"}, result.Items[0])
+	assert.Equal(t, ImportItemResult{Index: 1, Status: ImportStatusFailed, Line: 5, Error: "front matter has no date"}, result.Items[1])
+	assert.Equal(t, ImportStatusSkipped, result.Items[2].Status)
+	assert.Equal(t, ImportStatusFailed, result.Items[3].Status)
+	assert.Contains(t, result.Items[3].Error, "connection reset")
+	assert.Equal(t, 4, result.Items[4].Index)
+	assert.Contains(t, result.Items[4].Error, "is in the future")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
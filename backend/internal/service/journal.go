@@ -4,8 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"math"
+	"net/url"
 	"strings"
 	"time"
 
@@ -26,6 +29,10 @@ type JournalService struct {
 	broadcaster     *events.Broadcaster
 	logger          *logger.ProcessingLogger
 	failureAnalyzer *FailureAnalyzer
+	reprocess       *reprocessRuns
+	processing      *processingRuns
+	config          Config
+	userID          string // Set by ForUser; only used when config.MultiTenant
 }
 
 func NewJournalService(database *db.DB, processor *ollama.Processor, mcpClient *mcp.Client, broadcaster *events.Broadcaster, logger *logger.ProcessingLogger) *JournalService {
@@ -38,41 +45,99 @@ func NewJournalService(database *db.DB, processor *ollama.Processor, mcpClient *
 		broadcaster:     broadcaster,
 		logger:          logger,
 		failureAnalyzer: failureAnalyzer,
+		reprocess:       newReprocessRuns(),
+		processing:      newProcessingRuns(),
 	}
 }
 
 // CreateEntry creates a new journal entry with processing and embedding
 func (s *JournalService) CreateEntry(content string) (*models.JournalEntry, error) {
-	log.Printf("Creating new journal entry, content length: %d", len(content))
+	return s.CreateEntryAt(content, time.Time{})
+}
+
+// CreateEntryAt creates an entry dated createdAt instead of now, so entries
+// written elsewhere keep their place in the timeline. updated_at is still
+// the real time of writing. A zero createdAt means now; a future one is
+// rejected.
+func (s *JournalService) CreateEntryAt(content string, createdAt time.Time) (*models.JournalEntry, error) {
+	createdAt, err := s.validateNewEntry(content, createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.insertEntry(content, createdAt, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Process asynchronously in background, then point out earlier entries
+	// on the same subject
+	go func() {
+		s.processEntry(entry.ID, entry.Content)
+		s.suggestRelatedEntries(entry.ID)
+	}()
+
+	return entry, nil
+}
+
+// validateNewEntry checks the content and date of a new entry, returning the
+// date to store: createdAt, or now when it is zero
+func (s *JournalService) validateNewEntry(content string, createdAt time.Time) (time.Time, error) {
+	if err := s.validateContent(content); err != nil {
+		return time.Time{}, err
+	}
 
+	now := time.Now()
+	if createdAt.IsZero() {
+		return now, nil
+	}
+	if err := validateCreatedAt(createdAt, now); err != nil {
+		return time.Time{}, err
+	}
+	return createdAt, nil
+}
+
+// insertEntry stores a new unprocessed entry and announces it to clients.
+// The caller is responsible for starting processing.
+func (s *JournalService) insertEntry(content string, createdAt time.Time, isFavorite bool) (*models.JournalEntry, error) {
 	// Create initial entry with minimal processing
 	now := time.Now()
 	entry := models.JournalEntry{
-		Content: content,
-		ProcessedData: models.ProcessedData{
-			Summary:       "Processing...",
-			Entities:      []string{},
-			Topics:        []string{},
-			Sentiment:     "neutral",
-			Metadata:      make(map[string]any),
-			ExtractedURLs: []models.ExtractedURL{},
-		},
-		CreatedAt:           now,
+		Content:             content,
+		ProcessedData:       placeholderProcessedData(),
+		CreatedAt:           createdAt,
 		UpdatedAt:           now,
+		IsFavorite:          isFavorite,
 		ProcessingStage:     models.StageCreated,
 		ProcessingStartedAt: &now,
 	}
 
+	if err := s.storeNewEntry(&entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// storeNewEntry sanitizes the content of entry, inserts it, sets its ID,
+// and announces it to clients
+func (s *JournalService) storeNewEntry(entry *models.JournalEntry) error {
+	entry.Content, entry.OriginalContent = s.sanitizeContent(entry.Content)
+	if entry.Content == "" {
+		return Invalidf("content is empty once control and invisible characters are removed")
+	}
+
+	slog.Info("Creating new journal entry", "content_length", len(entry.Content))
+
 	// Convert minimal processed data to JSON
 	processedJSON, err := json.Marshal(entry.ProcessedData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal processed data: %w", err)
+		return fmt.Errorf("failed to marshal processed data: %w", err)
 	}
 
 	// Insert into database immediately
 	query := `
-		INSERT INTO journal_entries (content, processed_data, created_at, updated_at, processing_stage, processing_started_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO journal_entries (content, processed_data, created_at, updated_at, is_favorite, processing_stage, processing_started_at, processing_completed_at, user_id, ts_config, original_content)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id`
 
 	err = s.db.QueryRow(query,
@@ -80,211 +145,352 @@ func (s *JournalService) CreateEntry(content string) (*models.JournalEntry, erro
 		processedJSON,
 		entry.CreatedAt,
 		entry.UpdatedAt,
+		entry.IsFavorite,
 		entry.ProcessingStage,
 		entry.ProcessingStartedAt,
+		entry.ProcessingCompletedAt,
+		s.ownerValue(),
+		detectTSConfig(entry.Content),
+		entry.OriginalContent,
 	).Scan(&entry.ID)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to insert entry: %w", err)
+		return fmt.Errorf("failed to insert entry: %w", err)
 	}
 
-	log.Printf("Created journal entry with ID: %s", entry.ID)
+	slog.Info("Created journal entry", "entry_id", entry.ID)
 
 	// Log initial creation
-	s.logger.LogInfo(entry.ID, models.StageCreated, "Journal entry created", map[string]interface{}{
-		"content_length": len(content),
+	s.logger.LogInfo(entry.ID, entry.ProcessingStage, "Journal entry created", map[string]interface{}{
+		"content_length": len(entry.Content),
 	})
 
 	// Send created event to all connected clients
-	s.broadcaster.SendEvent(events.EventEntryCreated, entry.ID, map[string]interface{}{
+	s.sendEvent(events.EventEntryCreated, entry.ID, map[string]interface{}{
 		"entry": entry,
 	})
 
-	// Process asynchronously in background
-	go func(entryID string, content string) {
-		// Recover from panics in goroutine
-		defer func() {
-			if r := recover(); r != nil {
-				log.Printf("PANIC in background processing for entry %s: %v", entryID, r)
-				s.logger.SetError(entryID, models.StageAnalyzing, fmt.Errorf("panic: %v", r))
-				// Send failure event
-				s.broadcaster.SendEvent(events.EventEntryFailed, entryID, map[string]interface{}{
-					"error": fmt.Sprintf("%v", r),
-					"stage": models.StageFailed,
-				})
-			}
-		}()
-
-		log.Printf("Starting background processing for entry %s", entryID)
+	return nil
+}
 
-		// Transition to analyzing stage
-		s.logger.UpdateStage(entryID, models.StageAnalyzing)
-		s.broadcaster.SendEvent(events.EventEntryProcessing, entryID, map[string]interface{}{
-			"stage":   models.StageAnalyzing,
-			"message": "Analyzing content with AI",
+// analyzeContent runs the AI analysis for an entry, streaming partial
+// progress to clients when StreamAnalysis is enabled, and logs the model's
+// token usage and timing
+func (s *JournalService) analyzeContent(ctx context.Context, entryID, content string) (*models.ProcessedData, error) {
+	var processedData *models.ProcessedData
+	var usage ollama.Usage
+	var err error
+
+	if s.config.StreamAnalysis {
+		processedData, usage, err = s.processor.ProcessJournalEntryStreaming(ctx, content, func(progress ollama.AnalysisProgress) {
+			s.sendEvent(events.EventEntryAnalyzingProgress, entryID, map[string]interface{}{
+				"stage":           models.StageAnalyzing,
+				"tokens":          progress.Tokens,
+				"partial_summary": progress.PartialSummary,
+			})
 		})
+	} else {
+		processedData, usage, err = s.processor.ProcessJournalEntryWithUsage(ctx, content)
+	}
+
+	if usage.TotalDuration > 0 {
+		s.logger.LogInfo(entryID, models.StageAnalyzing, "Model usage", map[string]interface{}{
+			"prompt_tokens":     usage.PromptTokens,
+			"generation_tokens": usage.GenerationTokens,
+			"load_ms":           usage.LoadDuration.Milliseconds(),
+			"prompt_eval_ms":    usage.PromptDuration.Milliseconds(),
+			"generation_ms":     usage.GenerationDuration.Milliseconds(),
+			"total_ms":          usage.TotalDuration.Milliseconds(),
+			"tokens_per_second": math.Round(usage.TokensPerSecond()*10) / 10,
+		})
+	}
 
-		// Process content with Qwen
-		s.logger.LogInfo(entryID, models.StageAnalyzing, "Starting AI analysis", nil)
-		processedData, err := s.processor.ProcessJournalEntry(content)
-		if err != nil {
-			log.Printf("Failed to process entry %s: %v", entryID, err)
-			s.logger.SetError(entryID, models.StageAnalyzing, err)
+	return processedData, err
+}
+
+// placeholderProcessedData is stored on new entries until processing finishes
+func placeholderProcessedData() models.ProcessedData {
+	return models.ProcessedData{
+		Summary:       "Processing...",
+		Entities:      []string{},
+		Topics:        []string{},
+		Sentiment:     "neutral",
+		Metadata:      make(map[string]any),
+		ExtractedURLs: []models.ExtractedURL{},
+	}
+}
+
+// processEntry runs the AI analysis, URL fetching and embedding pipeline for
+// an entry that has already been inserted, broadcasting progress as it goes.
+// It is meant to be run in its own goroutine.
+func (s *JournalService) processEntry(entryID string, content string) {
+	// Recover from panics in goroutine
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("PANIC in background processing", "entry_id", entryID, "panic", r)
+			s.logger.SetError(entryID, models.StageAnalyzing, fmt.Errorf("panic: %v", r))
 			// Send failure event
-			s.broadcaster.SendEvent(events.EventEntryFailed, entryID, map[string]interface{}{
-				"error": err.Error(),
-				"stage": models.StageAnalyzing,
+			s.sendEvent(events.EventEntryFailed, entryID, map[string]interface{}{
+				"error": fmt.Sprintf("%v", r),
+				"stage": models.StageFailed,
 			})
+		}
+	}()
+
+	ctx, done := s.startProcessing(entryID)
+	defer done()
+
+	slog.Info("Starting background processing", "entry_id", entryID)
+
+	// Transition to analyzing stage
+	s.logger.UpdateStage(entryID, models.StageAnalyzing)
+	s.sendEvent(events.EventEntryProcessing, entryID, map[string]interface{}{
+		"stage":   models.StageAnalyzing,
+		"message": "Analyzing content with AI",
+	})
+
+	// Process content with Qwen
+	s.logger.LogInfo(entryID, models.StageAnalyzing, "Starting AI analysis", nil)
+	processedData, err := s.analyzeContent(ctx, entryID, content)
+	if err != nil {
+		if processingCancelled(ctx, entryID) {
 			return
 		}
+		err = s.processingError(ctx, err)
+		slog.Error("Failed to process entry", "entry_id", entryID, "error", err)
+		s.logger.SetError(entryID, models.StageAnalyzing, err)
+		// Send failure event
+		s.sendEvent(events.EventEntryFailed, entryID, map[string]interface{}{
+			"error": err.Error(),
+			"stage": models.StageAnalyzing,
+		})
+		return
+	}
+
+	if truncated, _ := processedData.Metadata["truncated"].(bool); truncated {
+		s.logger.LogWarn(entryID, models.StageAnalyzing, "Entry exceeds the analysis input limit; only the beginning was analyzed", map[string]interface{}{
+			"original_length": processedData.Metadata["original_length"],
+		})
+	}
+
+	s.logger.LogInfo(entryID, models.StageAnalyzing, "AI analysis completed", map[string]interface{}{
+		"entities_count": len(processedData.Entities),
+		"topics_count":   len(processedData.Topics),
+		"sentiment":      processedData.Sentiment,
+	})
 
-		s.logger.LogInfo(entryID, models.StageAnalyzing, "AI analysis completed", map[string]interface{}{
-			"entities_count": len(processedData.Entities),
-			"topics_count":   len(processedData.Topics),
-			"sentiment":      processedData.Sentiment,
+	// Create temporary entry for embedding generation
+	tempEntry := models.JournalEntry{
+		ID:            entryID,
+		Content:       content,
+		ProcessedData: *processedData,
+	}
+
+	// Fetch URLs if any
+	if s.mcpClient != nil && len(processedData.ExtractedURLs) > 0 {
+		// Transition to fetching URLs stage
+		s.logger.UpdateStage(entryID, models.StageFetchingURLs)
+		fetchCount := s.urlsToFetch(entryID, &tempEntry.ProcessedData)
+		s.sendEvent(events.EventEntryProcessing, entryID, map[string]interface{}{
+			"stage":   models.StageFetchingURLs,
+			"message": fmt.Sprintf("Fetching %d URLs", fetchCount),
 		})
 
-		// Create temporary entry for embedding generation
-		tempEntry := models.JournalEntry{
-			ID:            entryID,
-			Content:       content,
-			ProcessedData: *processedData,
-		}
+		s.logger.LogInfo(entryID, models.StageFetchingURLs, "Starting URL fetching", map[string]interface{}{
+			"urls_count": fetchCount,
+		})
 
-		// Fetch URLs if any
-		if s.mcpClient != nil && len(processedData.ExtractedURLs) > 0 {
-			// Transition to fetching URLs stage
-			s.logger.UpdateStage(entryID, models.StageFetchingURLs)
-			s.broadcaster.SendEvent(events.EventEntryProcessing, entryID, map[string]interface{}{
-				"stage":   models.StageFetchingURLs,
-				"message": fmt.Sprintf("Fetching %d URLs", len(processedData.ExtractedURLs)),
+		// Fetch each URL
+		for i, urlInfo := range processedData.ExtractedURLs[:fetchCount] {
+			s.logger.LogInfo(entryID, models.StageFetchingURLs, fmt.Sprintf("Fetching URL %d/%d", i+1, fetchCount), map[string]interface{}{
+				"url": urlInfo.URL,
 			})
 
-			s.logger.LogInfo(entryID, models.StageFetchingURLs, "Starting URL fetching", map[string]interface{}{
-				"urls_count": len(processedData.ExtractedURLs),
-			})
+			fetchCtx, cancel := context.WithTimeout(ctx, urlFetchTimeout)
+			fetchedContent, cached, err := s.fetchURL(fetchCtx, urlInfo.URL, urlInfo.Title) // Using Title as reason
+			cancel()
+			if ctx.Err() != nil {
+				break
+			}
 
-			// Fetch each URL
-			for i, urlInfo := range processedData.ExtractedURLs {
-				s.logger.LogInfo(entryID, models.StageFetchingURLs, fmt.Sprintf("Fetching URL %d/%d", i+1, len(processedData.ExtractedURLs)), map[string]interface{}{
+			if err != nil {
+				slog.Warn("Failed to fetch URL", "entry_id", entryID, "url", urlInfo.URL, "error", err)
+				s.logger.LogInfo(entryID, models.StageFetchingURLs, fmt.Sprintf("Failed to fetch URL: %v", err), map[string]interface{}{
+					"url": urlInfo.URL,
+				})
+				continue
+			}
+			if cached {
+				s.logger.LogDebug(entryID, models.StageFetchingURLs, "Using cached fetch result", map[string]interface{}{
 					"url": urlInfo.URL,
 				})
-
-				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-				fetchedContent, err := s.mcpClient.FetchURL(ctx, urlInfo.URL, urlInfo.Title) // Using Title as reason
-				cancel()
-
-				if err != nil {
-					log.Printf("Failed to fetch URL %s: %v", urlInfo.URL, err)
-					s.logger.LogInfo(entryID, models.StageFetchingURLs, fmt.Sprintf("Failed to fetch URL: %v", err), map[string]interface{}{
-						"url": urlInfo.URL,
-					})
-					continue
-				}
-
-				// Update the entry with fetched content
-				tempEntry.ProcessedData.ExtractedURLs[i].Title = fetchedContent.Title
-				tempEntry.ProcessedData.ExtractedURLs[i].Content = fetchedContent.Content
 			}
 
-			s.logger.LogInfo(entryID, models.StageFetchingURLs, "URL fetching completed", map[string]interface{}{
-				"fetched_count": len(tempEntry.ProcessedData.ExtractedURLs),
-			})
+			// Update the entry with fetched content
+			tempEntry.ProcessedData.ExtractedURLs[i].Title = fetchedContent.Title
+			tempEntry.ProcessedData.ExtractedURLs[i].Content = fetchedContent.Content
+			tempEntry.ProcessedData.ExtractedURLs[i].Metadata = fetchedContent.Metadata
+			if fetchedContent.IsLowContent() {
+				s.logger.LogInfo(entryID, models.StageFetchingURLs, "URL has too little content to embed", map[string]interface{}{
+					"url":    urlInfo.URL,
+					"reason": fetchedContent.Metadata["low_content_reason"],
+				})
+			}
 		}
 
-		// Transition to embedding generation stage
-		s.logger.UpdateStage(entryID, models.StageGeneratingEmbeddings)
-		s.broadcaster.SendEvent(events.EventEntryProcessing, entryID, map[string]interface{}{
-			"stage":   models.StageGeneratingEmbeddings,
-			"message": "Generating semantic embeddings",
-		})
-
-		// Generate embedding
-		s.logger.LogInfo(entryID, models.StageGeneratingEmbeddings, "Starting embedding generation", nil)
-		embedding, err := s.processor.CreateEmbedding(tempEntry)
-		if err != nil {
-			log.Printf("Failed to create embedding for entry %s: %v", entryID, err)
-			s.logger.SetError(entryID, models.StageGeneratingEmbeddings, err)
+		if ctx.Err() != nil {
+			if processingCancelled(ctx, entryID) {
+				return
+			}
+			err := s.processingError(ctx, ctx.Err())
+			slog.Error("Processing stopped while fetching URLs", "entry_id", entryID, "error", err)
+			s.logger.SetError(entryID, models.StageFetchingURLs, err)
 			// Send failure event
-			s.broadcaster.SendEvent(events.EventEntryFailed, entryID, map[string]interface{}{
+			s.sendEvent(events.EventEntryFailed, entryID, map[string]interface{}{
 				"error": err.Error(),
-				"stage": models.StageGeneratingEmbeddings,
+				"stage": models.StageFetchingURLs,
 			})
 			return
 		}
 
-		s.logger.LogInfo(entryID, models.StageGeneratingEmbeddings, "Embedding generated", map[string]interface{}{
-			"embedding_dims": len(embedding),
+		s.logger.LogInfo(entryID, models.StageFetchingURLs, "URL fetching completed", map[string]interface{}{
+			"fetched_count": len(tempEntry.ProcessedData.ExtractedURLs),
 		})
+	}
 
-		// Update processed data JSON
-		processedJSON, err := json.Marshal(tempEntry.ProcessedData)
-		if err != nil {
-			log.Printf("Failed to marshal processed data for entry %s: %v", entryID, err)
-			return
-		}
+	s.storeAnalysis(ctx, tempEntry)
+}
 
-		// Update the entry with processed data and embedding
-		updateQuery := `
-			UPDATE journal_entries 
-			SET processed_data = $1, embedding = $2, updated_at = $3, 
-			    processing_stage = $4, processing_completed_at = $5
-			WHERE id = $6`
-
-		_, err = s.db.Exec(updateQuery,
-			processedJSON,
-			pgvector.NewVector(embedding),
-			time.Now(),
-			models.StageCompleted,
-			time.Now(),
-			entryID,
-		)
+// storeAnalysis generates the embeddings of an analyzed entry and saves them
+// with its processed data, completing the entry. When the embedding fails the
+// analysis is still saved and the entry completes as
+// StageCompletedNoEmbedding, unless Config.StrictEmbeddings fails it instead.
+// Nothing is saved once the entry is cancelled or a newer run replaced this
+// one.
+func (s *JournalService) storeAnalysis(ctx context.Context, entry models.JournalEntry) {
+	entryID := entry.ID
+	if processingCancelled(ctx, entryID) {
+		return
+	}
+
+	// Transition to embedding generation stage
+	s.logger.UpdateStage(entryID, models.StageGeneratingEmbeddings)
+	s.sendEvent(events.EventEntryProcessing, entryID, map[string]interface{}{
+		"stage":   models.StageGeneratingEmbeddings,
+		"message": "Generating semantic embeddings",
+	})
 
-		if err != nil {
-			log.Printf("Failed to update entry %s with processed data: %v", entryID, err)
+	// Generate embedding
+	stage := models.StageCompleted
+	var embedding interface{}
+	s.logger.LogInfo(entryID, models.StageGeneratingEmbeddings, "Starting embedding generation", nil)
+	vector, err := s.createEmbedding(ctx, entry)
+	if err != nil {
+		if processingCancelled(ctx, entryID) {
+			return
+		}
+		err = s.processingError(ctx, err)
+		if s.config.StrictEmbeddings {
+			slog.Error("Failed to create embedding", "entry_id", entryID, "error", err)
 			s.logger.SetError(entryID, models.StageGeneratingEmbeddings, err)
 			// Send failure event
-			s.broadcaster.SendEvent(events.EventEntryFailed, entryID, map[string]interface{}{
+			s.sendEvent(events.EventEntryFailed, entryID, map[string]interface{}{
 				"error": err.Error(),
 				"stage": models.StageGeneratingEmbeddings,
 			})
 			return
 		}
 
-		// Update to completed stage
-		s.logger.UpdateStage(entryID, models.StageCompleted)
-		s.logger.LogInfo(entryID, models.StageCompleted, "Processing completed successfully", map[string]interface{}{
-			"total_entities": len(tempEntry.ProcessedData.Entities),
-			"total_topics":   len(tempEntry.ProcessedData.Topics),
-			"total_urls":     len(tempEntry.ProcessedData.ExtractedURLs),
+		// Keep the analysis; a retry fills in the embedding later
+		slog.Warn("Failed to create embedding, saving analysis without it", "entry_id", entryID, "error", err)
+		s.logger.LogWarn(entryID, models.StageGeneratingEmbeddings, "Embedding failed; entry saved without one and can be retried", map[string]interface{}{
+			"error": err.Error(),
+		})
+		stage = models.StageCompletedNoEmbedding
+	} else {
+		s.logger.LogInfo(entryID, models.StageGeneratingEmbeddings, "Embedding generated", map[string]interface{}{
+			"embedding_dims": len(vector),
 		})
+		s.storeChunkEmbeddings(ctx, entryID, entry.Content)
+		embedding = pgvector.NewVector(vector)
+	}
 
-		log.Printf("Successfully processed entry %s", entryID)
+	// Update processed data JSON
+	processedJSON, err := json.Marshal(entry.ProcessedData)
+	if err != nil {
+		slog.Error("Failed to marshal processed data", "entry_id", entryID, "error", err)
+		return
+	}
 
-		// Fetch the complete updated entry to send in the event
-		updatedEntry, err := s.GetEntry(entryID)
-		if err != nil {
-			log.Printf("Failed to fetch updated entry for event: %v", err)
-			// Create a complete entry structure even if fetch fails
-			tempEntry.UpdatedAt = time.Now()
-			tempEntry.ProcessingStage = models.StageCompleted
-			tempEntry.ProcessingCompletedAt = &tempEntry.UpdatedAt
-
-			// Send event with reconstructed entry data
-			s.broadcaster.SendEvent(events.EventEntryProcessed, entryID, map[string]interface{}{
-				"entry": &tempEntry,
-				"stage": models.StageCompleted,
-			})
-		} else {
-			// Send success event with full updated entry
-			s.broadcaster.SendEvent(events.EventEntryProcessed, entryID, map[string]interface{}{
-				"entry": updatedEntry,
-				"stage": models.StageCompleted,
-			})
-		}
-	}(entry.ID, content)
+	// A newer run may have replaced this one while the embedding was made;
+	// its results are the ones to keep
+	if processingCancelled(ctx, entryID) {
+		return
+	}
 
-	return &entry, nil
+	// Update the entry with processed data and embedding, unless it was
+	// cancelled in the meantime
+	updateQuery := `
+		UPDATE journal_entries 
+		SET processed_data = $1, embedding = $2, updated_at = $3, 
+		    processing_stage = $4, processing_completed_at = $5
+		WHERE id = $6 AND processing_stage <> 'cancelled'`
+
+	result, err := s.db.Exec(updateQuery,
+		processedJSON,
+		embedding,
+		time.Now(),
+		stage,
+		time.Now(),
+		entryID,
+	)
+
+	if err != nil {
+		slog.Error("Failed to update entry with processed data", "entry_id", entryID, "error", err)
+		s.logger.SetError(entryID, models.StageGeneratingEmbeddings, err)
+		// Send failure event
+		s.sendEvent(events.EventEntryFailed, entryID, map[string]interface{}{
+			"error": err.Error(),
+			"stage": models.StageGeneratingEmbeddings,
+		})
+		return
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		slog.Info("Entry was cancelled before its analysis was stored", "entry_id", entryID)
+		return
+	}
+
+	// Update to completed stage
+	s.logger.UpdateStage(entryID, stage)
+	s.logger.LogInfo(entryID, stage, "Processing completed successfully", map[string]interface{}{
+		"total_entities": len(entry.ProcessedData.Entities),
+		"total_topics":   len(entry.ProcessedData.Topics),
+		"total_urls":     len(entry.ProcessedData.ExtractedURLs),
+	})
+
+	slog.Info("Successfully processed entry", "entry_id", entryID, "stage", stage)
+
+	// Fetch the complete updated entry to send in the event
+	updatedEntry, err := s.GetEntry(entryID)
+	if err != nil {
+		slog.Error("Failed to fetch updated entry for event", "entry_id", entryID, "error", err)
+		// Create a complete entry structure even if fetch fails
+		entry.UpdatedAt = time.Now()
+		entry.ProcessingStage = stage
+		entry.ProcessingCompletedAt = &entry.UpdatedAt
+
+		// Send event with reconstructed entry data
+		s.sendEvent(events.EventEntryProcessed, entryID, map[string]interface{}{
+			"entry": &entry,
+			"stage": stage,
+		})
+	} else {
+		// Send success event with full updated entry
+		s.sendEvent(events.EventEntryProcessed, entryID, map[string]interface{}{
+			"entry": updatedEntry,
+			"stage": stage,
+		})
+	}
 }
 
 // UpdateEntry updates an existing entry and preserves the original
@@ -295,8 +501,13 @@ func (s *JournalService) UpdateEntry(id string, content string) (*models.Journal
 		return nil, fmt.Errorf("failed to get original entry: %w", err)
 	}
 
+	content, originalContent := s.sanitizeContent(content)
+	if content == "" {
+		return nil, Invalidf("content is empty once control and invisible characters are removed")
+	}
+
 	// Process new content
-	processedData, err := s.processor.ProcessJournalEntry(content)
+	processedData, err := s.processor.ProcessJournalEntry(context.Background(), content)
 	if err != nil {
 		return nil, fmt.Errorf("failed to process updated entry: %w", err)
 	}
@@ -308,12 +519,15 @@ func (s *JournalService) UpdateEntry(id string, content string) (*models.Journal
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 		IsFavorite:      original.IsFavorite,
+		PinnedAt:        original.PinnedAt,
 		CollectionIDs:   original.CollectionIDs,
 		OriginalEntryID: &id,
+		Attachments:     original.Attachments,
+		OriginalContent: originalContent,
 	}
 
 	// Generate new embedding
-	embedding, err := s.processor.CreateEmbedding(newEntry)
+	embedding, err := s.createEmbedding(context.Background(), newEntry)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create embedding: %w", err)
 	}
@@ -327,8 +541,8 @@ func (s *JournalService) UpdateEntry(id string, content string) (*models.Journal
 
 	// Insert new version
 	query := `
-		INSERT INTO journal_entries (content, processed_data, embedding, created_at, updated_at, is_favorite, original_entry_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO journal_entries (content, processed_data, embedding, created_at, updated_at, is_favorite, pinned_at, original_entry_id, user_id, ts_config, attachments, original_content)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		RETURNING id`
 
 	err = s.db.QueryRow(query,
@@ -338,12 +552,18 @@ func (s *JournalService) UpdateEntry(id string, content string) (*models.Journal
 		newEntry.CreatedAt,
 		newEntry.UpdatedAt,
 		newEntry.IsFavorite,
+		newEntry.PinnedAt,
 		newEntry.OriginalEntryID,
+		s.ownerValue(),
+		detectTSConfig(newEntry.Content),
+		newEntry.Attachments,
+		newEntry.OriginalContent,
 	).Scan(&newEntry.ID)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert updated entry: %w", err)
 	}
+	s.storeChunkEmbeddings(context.Background(), newEntry.ID, content)
 
 	// Copy collection associations
 	if len(original.CollectionIDs) > 0 {
@@ -353,13 +573,13 @@ func (s *JournalService) UpdateEntry(id string, content string) (*models.Journal
 				newEntry.ID, collID,
 			)
 			if err != nil {
-				log.Printf("Failed to copy collection association: %v", err)
+				slog.Error("Failed to copy collection association", "error", err)
 			}
 		}
 	}
 
 	// Send updated event
-	s.broadcaster.SendEvent(events.EventEntryUpdated, newEntry.ID, map[string]interface{}{
+	s.sendEvent(events.EventEntryUpdated, newEntry.ID, map[string]interface{}{
 		"entry":       &newEntry,
 		"original_id": id,
 	})
@@ -369,113 +589,213 @@ func (s *JournalService) UpdateEntry(id string, content string) (*models.Journal
 
 // GetEntry retrieves a single journal entry
 func (s *JournalService) GetEntry(id string) (*models.JournalEntry, error) {
+	scope, scopeArgs := s.scopeClause("je.user_id", 2)
 	query := `
-		SELECT 
-			je.id, je.content, je.processed_data, je.created_at, je.updated_at,
-			je.is_favorite, je.original_entry_id,
-			je.processing_stage, je.processing_started_at, je.processing_completed_at, je.processing_error,
-			COALESCE(array_agg(jc.collection_id) FILTER (WHERE jc.collection_id IS NOT NULL), '{}') as collection_ids
+		SELECT` + entryColumns + `
 		FROM journal_entries je
 		LEFT JOIN journal_collection jc ON je.id = jc.journal_id
-		WHERE je.id = $1
+		WHERE je.id = $1 AND je.deleted_at IS NULL` + scope + `
 		GROUP BY je.id`
 
-	var entry models.JournalEntry
-	var processedJSON []byte
-
-	err := s.db.QueryRow(query, id).Scan(
-		&entry.ID,
-		&entry.Content,
-		&processedJSON,
-		&entry.CreatedAt,
-		&entry.UpdatedAt,
-		&entry.IsFavorite,
-		&entry.OriginalEntryID,
-		&entry.ProcessingStage,
-		&entry.ProcessingStartedAt,
-		&entry.ProcessingCompletedAt,
-		&entry.ProcessingError,
-		pq.Array(&entry.CollectionIDs),
-	)
-
-	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("entry not found")
+	entry, err := scanEntry(s.db.QueryRow(query, append([]interface{}{id}, scopeArgs...)...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, NotFoundf("entry not found")
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get entry: %w", err)
 	}
 
-	if err := json.Unmarshal(processedJSON, &entry.ProcessedData); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal processed data: %w", err)
-	}
-
 	return &entry, nil
 }
 
 // SearchParams holds search parameters
 type SearchParams struct {
-	Query         string     `json:"query"`
-	IsFavorite    *bool      `json:"is_favorite"`
-	CollectionIDs []string   `json:"collection_ids"`
-	StartDate     *time.Time `json:"start_date"`
-	EndDate       *time.Time `json:"end_date"`
-	Limit         int        `json:"limit"`
-	Offset        int        `json:"offset"`
-	SemanticMode  string     `json:"semantic_mode"` // similar, explore, contrast
-	HybridMode    string     `json:"hybrid_mode"`   // balanced, semantic_boost, precision, discovery
+	Query          string     `json:"query"`
+	IsFavorite     *bool      `json:"is_favorite"`
+	CollectionIDs  []string   `json:"collection_ids"`
+	StartDate      *time.Time `json:"start_date"`
+	EndDate        *time.Time `json:"end_date"`
+	DateRange      string     `json:"date_range,omitempty"` // 7d, 30d, this_month, last_month, ytd
+	Limit          int        `json:"limit"`
+	Offset         int        `json:"offset"`
+	AfterCursor    string     `json:"after_cursor,omitempty"`   // classic search only; replaces offset
+	Sort           string     `json:"sort,omitempty"`           // classic search: "" pins first, "newest" ignores pins
+	SemanticMode   string     `json:"semantic_mode"`            // similar, explore, contrast
+	MinSimilarity  float32    `json:"min_similarity,omitempty"` // vector search only; 0 disables
+	Probes         int        `json:"probes,omitempty"`         // vector search only; ivfflat lists scanned, 0 uses the configured default
+	Explain        bool       `json:"explain,omitempty"`        // vector search only; adds metadata["match_reasons"] at the cost of a model call
+	HasAttachments *bool      `json:"has_attachments,omitempty"`
+	HybridMode     string     `json:"hybrid_mode"`      // balanced, semantic_boost, precision, discovery
+	Expand         bool       `json:"expand,omitempty"` // classic search only; ORs related terms into the query and adds metadata["expanded_terms"] at the cost of a model call
+
+	// expandedTerms are ORed into the text query by buildFilters
+	expandedTerms []string
 }
 
 // ClassicSearch performs traditional keyword and filter based search
 func (s *JournalService) ClassicSearch(params SearchParams) ([]models.JournalEntry, error) {
-	query := `
-		SELECT DISTINCT
-			je.id, je.content, je.processed_data, je.created_at, je.updated_at,
-			je.is_favorite, je.original_entry_id,
-			je.processing_stage, je.processing_started_at, je.processing_completed_at, je.processing_error,
-			COALESCE(array_agg(jc.collection_id) FILTER (WHERE jc.collection_id IS NOT NULL), '{}') as collection_ids
-		FROM journal_entries je
-		LEFT JOIN journal_collection jc ON je.id = jc.journal_id
-		WHERE 1=1`
+	params.Limit = s.clampLimit(params.Limit)
+	expand := params.Expand && params.Query != ""
+	if expand {
+		params.expandedTerms = s.expandQuery(params.Query)
+	}
+
+	query, args, err := s.classicSearchQuery(params)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries, err := s.scanEntries(rows)
+	if err != nil {
+		return nil, err
+	}
+	if expand {
+		markExpansion(entries, params.expandedTerms)
+	}
+	return entries, nil
+}
+
+// CountEntries counts the entries matching the classic search filters
+// without reading any rows. Limit, offset, cursor and sort are ignored.
+func (s *JournalService) CountEntries(params SearchParams) (int, error) {
+	filters, args := s.buildFilters(params, 1)
 
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM journal_entries je WHERE je.deleted_at IS NULL"+filters, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count entries: %w", err)
+	}
+
+	return count, nil
+}
+
+// buildFilters builds the " AND ..." conditions on journal_entries je for
+// classic search: the user scope, the text query and buildFilterClause,
+// numbering placeholders from startArgIndex
+func (s *JournalService) buildFilters(params SearchParams, startArgIndex int) (string, []interface{}) {
+	clause := ""
 	args := []interface{}{}
-	argCount := 0
+	argCount := startArgIndex - 1
+
+	// Restrict to the current user in multi-tenant mode
+	if scope, scopeArgs := s.scopeClause("je.user_id", argCount+1); scope != "" {
+		argCount++
+		clause += scope
+		args = append(args, scopeArgs...)
+	}
 
 	// Add text search
 	if params.Query != "" {
 		argCount++
-		query += fmt.Sprintf(" AND je.tsv @@ plainto_tsquery('english', $%d)", argCount)
+		// Parse the query with each entry's own configuration so stemming
+		// matches how that entry was indexed. websearch_to_tsquery reads
+		// the query like a web search box: "quoted phrases" match as
+		// phrases, -word excludes, and OR alternates.
+		tsquery := fmt.Sprintf("websearch_to_tsquery(je.ts_config, $%d)", argCount)
 		args = append(args, params.Query)
+		if len(params.expandedTerms) > 0 {
+			// An entry matching the query or any related term is a hit.
+			// Terms come from the model, so they are taken as plain words
+			// rather than search syntax.
+			for _, term := range params.expandedTerms {
+				argCount++
+				tsquery += fmt.Sprintf(" || plainto_tsquery(je.ts_config, $%d)", argCount)
+				args = append(args, term)
+			}
+			tsquery = "(" + tsquery + ")"
+		}
+		clause += " AND je.tsv @@ " + tsquery
 	}
 
-	// Add favorite filter
+	filters, filterArgs := buildFilterClause(params, argCount+1)
+	clause += filters
+	args = append(args, filterArgs...)
+
+	return clause, args
+}
+
+// buildFilterClause builds the " AND ..." conditions on journal_entries je
+// for the favorite, collection and date filters shared by every search mode,
+// numbering placeholders from startArgIndex. New filters belong here so each
+// mode picks them up.
+func buildFilterClause(params SearchParams, startArgIndex int) (string, []interface{}) {
+	clause := ""
+	args := []interface{}{}
+	argCount := startArgIndex - 1
+
 	if params.IsFavorite != nil {
 		argCount++
-		query += fmt.Sprintf(" AND je.is_favorite = $%d", argCount)
+		clause += fmt.Sprintf(" AND je.is_favorite = $%d", argCount)
 		args = append(args, *params.IsFavorite)
 	}
 
-	// Add collection filter
+	if params.HasAttachments != nil {
+		if *params.HasAttachments {
+			clause += " AND je.attachments <> '[]'::jsonb"
+		} else {
+			clause += " AND je.attachments = '[]'::jsonb"
+		}
+	}
+
 	if len(params.CollectionIDs) > 0 {
 		argCount++
-		query += fmt.Sprintf(" AND je.id IN (SELECT journal_id FROM journal_collection WHERE collection_id = ANY($%d))", argCount)
+		clause += fmt.Sprintf(" AND je.id IN (SELECT journal_id FROM journal_collection WHERE collection_id = ANY($%d))", argCount)
 		args = append(args, pq.Array(params.CollectionIDs))
 	}
 
-	// Add date filters
 	if params.StartDate != nil {
 		argCount++
-		query += fmt.Sprintf(" AND je.created_at >= $%d", argCount)
+		clause += fmt.Sprintf(" AND je.created_at >= $%d", argCount)
 		args = append(args, *params.StartDate)
 	}
 
 	if params.EndDate != nil {
 		argCount++
-		query += fmt.Sprintf(" AND je.created_at <= $%d", argCount)
+		clause += fmt.Sprintf(" AND je.created_at <= $%d", argCount)
 		args = append(args, *params.EndDate)
 	}
 
-	// Add grouping and ordering
-	query += " GROUP BY je.id ORDER BY je.created_at DESC"
+	return clause, args
+}
+
+// classicSearchQuery builds the SQL and arguments for a classic search
+func (s *JournalService) classicSearchQuery(params SearchParams) (string, []interface{}, error) {
+	query := `
+		SELECT DISTINCT` + entryColumns + `
+		FROM journal_entries je
+		LEFT JOIN journal_collection jc ON je.id = jc.journal_id
+		WHERE je.deleted_at IS NULL`
+
+	filters, args := s.buildFilters(params, 1)
+	query += filters
+	argCount := len(args)
+
+	// Resume after the last entry of the previous page
+	if params.AfterCursor != "" {
+		createdAt, id, err := decodeCursor(params.AfterCursor)
+		if err != nil {
+			return "", nil, err
+		}
+		query += fmt.Sprintf(" AND (je.created_at, je.id) < ($%d, $%d)", argCount+1, argCount+2)
+		argCount += 2
+		args = append(args, createdAt, id)
+	}
+
+	// Add grouping and ordering; id breaks ties so cursors are stable.
+	// Pinned entries lead the default order, most recently pinned first.
+	switch params.Sort {
+	case "":
+		query += " GROUP BY je.id ORDER BY je.pinned_at DESC NULLS LAST, je.created_at DESC, je.id DESC"
+	case "newest":
+		query += " GROUP BY je.id ORDER BY je.created_at DESC, je.id DESC"
+	default:
+		return "", nil, Invalidf("invalid sort: %s (expected newest)", params.Sort)
+	}
 
 	// Add pagination
 	if params.Limit > 0 {
@@ -484,37 +804,38 @@ func (s *JournalService) ClassicSearch(params SearchParams) ([]models.JournalEnt
 		args = append(args, params.Limit)
 	}
 
-	if params.Offset > 0 {
+	if params.Offset > 0 && params.AfterCursor == "" {
 		argCount++
 		query += fmt.Sprintf(" OFFSET $%d", argCount)
 		args = append(args, params.Offset)
 	}
 
-	rows, err := s.db.Query(query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to search entries: %w", err)
-	}
-	defer rows.Close()
-
-	return s.scanEntries(rows)
+	return query, args, nil
 }
 
 // VectorSearch performs semantic search using embeddings with different modes
 func (s *JournalService) VectorSearch(params SearchParams) ([]models.JournalEntry, error) {
 	// Validate query is not empty
 	if params.Query == "" {
-		return nil, fmt.Errorf("query cannot be empty for vector search")
+		return nil, Invalidf("query cannot be empty for vector search")
+	}
+
+	if s.processor == nil {
+		return nil, Unavailablef("vector search is unavailable: no embedding processor configured")
 	}
 
 	// Generate embedding for query
-	embedding, err := s.processor.CreateEmbedding(models.JournalEntry{
+	embedding, err := s.createEmbedding(context.Background(), models.JournalEntry{
 		Content:       params.Query,
 		ProcessedData: models.ProcessedData{},
 	})
 	if err != nil {
 		// Check if Ollama is running
 		if strings.Contains(err.Error(), "connection refused") {
-			return nil, fmt.Errorf("Ollama service is not running. Please start it with 'ollama serve'")
+			return nil, Unavailablef("Ollama service is not running. Please start it with 'ollama serve'")
+		}
+		if errors.Is(err, ollama.ErrModelNotFound) {
+			return nil, Unavailablef("%v", err)
 		}
 		return nil, fmt.Errorf("failed to create query embedding: %w", err)
 	}
@@ -528,64 +849,105 @@ func (s *JournalService) VectorSearch(params SearchParams) ([]models.JournalEntr
 	if params.Limit == 0 {
 		params.Limit = 20
 	}
+	params.Limit = s.clampLimit(params.Limit)
 
-	// Build base query
-	baseQuery := `
-		SELECT 
-			je.id, je.content, je.processed_data, je.created_at, je.updated_at,
-			je.is_favorite, je.original_entry_id,
-			je.processing_stage, je.processing_started_at, je.processing_completed_at, je.processing_error,
-			COALESCE(array_agg(jc.collection_id) FILTER (WHERE jc.collection_id IS NOT NULL), '{}') as collection_ids,
-			1 - (je.embedding <=> $1) as similarity
-		FROM journal_entries je
-		LEFT JOIN journal_collection jc ON je.id = jc.journal_id
-		WHERE je.embedding IS NOT NULL`
+	if params.MinSimilarity < 0 || params.MinSimilarity > 1 {
+		return nil, Invalidf("min_similarity must be between 0 and 1")
+	}
+
+	probes, err := s.vectorProbes(params)
+	if err != nil {
+		return nil, err
+	}
 
-	// Add filters
+	// Filters apply both when picking candidates and to the final rows, so
+	// their placeholders are shared by the two parts of the query
 	args := []interface{}{pgvector.NewVector(embedding)}
 	argCount := 1
 
-	// Collection filter
-	if len(params.CollectionIDs) > 0 {
-		argCount++
-		baseQuery += fmt.Sprintf(" AND je.id IN (SELECT journal_id FROM journal_collection WHERE collection_id = ANY($%d))", argCount)
-		args = append(args, pq.Array(params.CollectionIDs))
-	}
+	// Restrict to the current user in multi-tenant mode
+	scope, scopeArgs := s.scopeClause("je.user_id", argCount+1)
+	argCount += len(scopeArgs)
+	args = append(args, scopeArgs...)
 
-	// Favorite filter
-	if params.IsFavorite != nil {
-		argCount++
-		baseQuery += fmt.Sprintf(" AND je.is_favorite = $%d", argCount)
-		args = append(args, *params.IsFavorite)
-	}
+	filters, filterArgs := buildFilterClause(params, argCount+1)
+	argCount += len(filterArgs)
+	args = append(args, filterArgs...)
 
-	// Date filters
-	if params.StartDate != nil {
-		argCount++
-		baseQuery += fmt.Sprintf(" AND je.created_at >= $%d", argCount)
-		args = append(args, *params.StartDate)
+	argCount++
+	candidateLimit := argCount
+	args = append(args, params.Limit*candidateFactor(params.SemanticMode))
+
+	metric := s.metric()
+	similarity := s.entrySimilaritySQL()
+
+	// Candidates come from the ivfflat indexes: the entries, and the entries
+	// of the chunks, nearest the query. Only these few rows are then scored
+	// with the entry similarity, which also checks every chunk of the entry.
+	// Both parts apply the scope and filters, or chunks of entries the final
+	// WHERE drops would use up the candidate limit. Contrast mode wants the
+	// farthest entries instead, which no index can serve, so it ranks
+	// whole-entry vectors only.
+	candidates := fmt.Sprintf(`
+		SELECT je.id FROM journal_entries je
+		WHERE je.embedding IS NOT NULL AND je.deleted_at IS NULL`+scope+filters+`
+		ORDER BY `+metric.distance("je.embedding", "$1")+` DESC
+		LIMIT $%d`, candidateLimit)
+	if params.SemanticMode != "contrast" {
+		candidates = fmt.Sprintf(`
+		(SELECT je.id FROM journal_entries je
+		WHERE je.embedding IS NOT NULL AND je.deleted_at IS NULL`+scope+filters+`
+		ORDER BY `+metric.distance("je.embedding", "$1")+`
+		LIMIT $%d)
+		UNION
+		(SELECT ec.entry_id FROM entry_chunks ec
+		JOIN journal_entries je ON je.id = ec.entry_id
+		WHERE je.deleted_at IS NULL`+scope+filters+`
+		ORDER BY `+metric.distance("ec.embedding", "$1")+`
+		LIMIT $%d)`, candidateLimit, candidateLimit)
 	}
 
-	if params.EndDate != nil {
+	baseQuery := `
+		WITH candidates AS (` + candidates + `
+		)
+		SELECT` + entryColumns + `,
+			` + similarity + ` as similarity
+		FROM candidates c
+		JOIN journal_entries je ON je.id = c.id
+		LEFT JOIN journal_collection jc ON je.id = jc.journal_id
+		WHERE je.embedding IS NOT NULL AND je.deleted_at IS NULL` + scope + filters + `
+		GROUP BY je.id`
+
+	// Drop weak matches so callers can tell when nothing matches well. Contrast
+	// mode looks for dissimilar entries, so the threshold does not apply.
+	minSimilarity := ""
+	if params.MinSimilarity > 0 && params.SemanticMode != "contrast" {
 		argCount++
-		baseQuery += fmt.Sprintf(" AND je.created_at <= $%d", argCount)
-		args = append(args, *params.EndDate)
+		minSimilarity = fmt.Sprintf(similarity+" >= $%d", argCount)
+		args = append(args, params.MinSimilarity)
 	}
 
-	baseQuery += " GROUP BY je.id"
-
-	// Apply semantic mode
+	// Apply semantic mode. Similarity is the best match across the entry's
+	// whole-entry and chunk embeddings.
 	var searchQuery string
 	switch params.SemanticMode {
 	case "contrast":
 		// Find contrasting/opposite entries by using inverse similarity
-		searchQuery = baseQuery + " ORDER BY je.embedding <=> $1 DESC"
+		searchQuery = baseQuery + " ORDER BY similarity ASC"
 	case "explore":
 		// Find conceptually related entries with medium similarity
-		searchQuery = baseQuery + " HAVING 1 - (je.embedding <=> $1) BETWEEN 0.3 AND 0.7 ORDER BY RANDOM()"
+		searchQuery = baseQuery + " HAVING " + similarity + " BETWEEN 0.3 AND 0.7"
+		if minSimilarity != "" {
+			searchQuery += " AND " + minSimilarity
+		}
+		searchQuery += " ORDER BY RANDOM()"
 	default: // "similar"
 		// Standard similarity search
-		searchQuery = baseQuery + " ORDER BY je.embedding <=> $1"
+		searchQuery = baseQuery
+		if minSimilarity != "" {
+			searchQuery += " HAVING " + minSimilarity
+		}
+		searchQuery += " ORDER BY similarity DESC"
 	}
 
 	// Add limit
@@ -593,55 +955,74 @@ func (s *JournalService) VectorSearch(params SearchParams) ([]models.JournalEntr
 	searchQuery += fmt.Sprintf(" LIMIT $%d", argCount)
 	args = append(args, params.Limit)
 
-	rows, err := s.db.Query(searchQuery, args...)
+	entries, err := s.queryWithSimilarity(probes, searchQuery, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to perform vector search: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
 
-	entries := []models.JournalEntry{}
-	for rows.Next() {
-		var entry models.JournalEntry
-		var processedJSON []byte
-		var similarity float32
-
-		err := rows.Scan(
-			&entry.ID,
-			&entry.Content,
-			&processedJSON,
-			&entry.CreatedAt,
-			&entry.UpdatedAt,
-			&entry.IsFavorite,
-			&entry.OriginalEntryID,
-			&entry.ProcessingStage,
-			&entry.ProcessingStartedAt,
-			&entry.ProcessingCompletedAt,
-			&entry.ProcessingError,
-			pq.Array(&entry.CollectionIDs),
-			&similarity,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan entry: %w", err)
-		}
+	if params.SemanticMode == "contrast" {
+		markContrast(entries)
+	} else if params.Explain {
+		s.explainMatches(params.Query, entries)
+	}
+	return entries, nil
+}
 
-		if err := json.Unmarshal(processedJSON, &entry.ProcessedData); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal processed data: %w", err)
+// markContrast replaces the similarity score of contrast results with a
+// contrast_score (1 - similarity), so the farthest entries score highest and
+// are never mistaken for close matches downstream
+func markContrast(entries []models.JournalEntry) {
+	for i := range entries {
+		metadata := entries[i].ProcessedData.Metadata
+		if similarity, ok := metadata["similarity_score"].(float32); ok {
+			metadata["contrast_score"] = 1 - similarity
+			delete(metadata, "similarity_score")
 		}
+	}
+}
 
-		// Add similarity score to metadata
-		if entry.ProcessedData.Metadata == nil {
-			entry.ProcessedData.Metadata = make(map[string]any)
-		}
-		entry.ProcessedData.Metadata["similarity_score"] = similarity
+// ReasonSemanticUnavailable explains a degraded hybrid search whose vector
+// leg failed
+const ReasonSemanticUnavailable = "semantic search unavailable"
 
-		entries = append(entries, entry)
-	}
+// HybridResult is a hybrid search's entries. Degraded is set when the vector
+// leg failed and the entries come from classic search alone.
+type HybridResult struct {
+	Entries  []models.JournalEntry `json:"entries"`
+	Degraded bool                  `json:"degraded"`
+	Reason   string                `json:"reason,omitempty"`
+}
 
-	return entries, nil
+// semanticUnavailable reports whether a VectorSearch error means semantic
+// search could not run at all: it is unavailable, or the request to Ollama
+// failed in transport or timed out
+func semanticUnavailable(err error) bool {
+	if errors.Is(err, ErrServiceUnavailable) {
+		return true
+	}
+	var timeout interface{ Timeout() bool }
+	if errors.As(err, &timeout) && timeout.Timeout() {
+		return true
+	}
+	var transport *url.Error
+	return errors.As(err, &transport)
 }
 
 // HybridSearch combines vector and traditional search
 func (s *JournalService) HybridSearch(params SearchParams) ([]models.JournalEntry, error) {
+	result, err := s.HybridSearchResult(params)
+	if err != nil {
+		return nil, err
+	}
+	return result.Entries, nil
+}
+
+// HybridSearchResult runs HybridSearch and reports whether semantic search
+// was skipped. When the vector leg cannot reach the embedding model
+// (typically because Ollama is down) the classic results are still returned,
+// marked as degraded; any other vector search error, such as invalid
+// parameters, is returned.
+func (s *JournalService) HybridSearchResult(params SearchParams) (*HybridResult, error) {
 	// Default hybrid mode
 	if params.HybridMode == "" {
 		params.HybridMode = "balanced"
@@ -649,6 +1030,7 @@ func (s *JournalService) HybridSearch(params SearchParams) ([]models.JournalEntr
 
 	// If there's a query, perform vector search first
 	vectorResults := []models.JournalEntry{}
+	result := &HybridResult{}
 	if params.Query != "" {
 		// Create vector params with a higher limit for hybrid
 		vectorParams := params
@@ -657,7 +1039,12 @@ func (s *JournalService) HybridSearch(params SearchParams) ([]models.JournalEntr
 		var err error
 		vectorResults, err = s.VectorSearch(vectorParams)
 		if err != nil {
-			log.Printf("Vector search failed, falling back to classic: %v", err)
+			if !semanticUnavailable(err) {
+				return nil, err
+			}
+			slog.Warn("Vector search failed, falling back to classic", "error", err)
+			result.Degraded = true
+			result.Reason = ReasonSemanticUnavailable
 		}
 	}
 
@@ -688,11 +1075,14 @@ func (s *JournalService) HybridSearch(params SearchParams) ([]models.JournalEntr
 		vectorWeight, classicWeight = 0.5, 0.5
 	}
 
-	// Add vector results with their similarity scores
+	// Add vector results with their similarity scores. In contrast mode the
+	// relevant score is how far the entry is from the query.
 	for i, entry := range vectorResults {
 		similarity := float32(0.0)
 		if score, ok := entry.ProcessedData.Metadata["similarity_score"].(float32); ok {
 			similarity = score
+		} else if score, ok := entry.ProcessedData.Metadata["contrast_score"].(float32); ok {
+			similarity = score
 		}
 		// Normalize rank to score (higher rank = lower score)
 		rankScore := 1.0 - (float32(i) / float32(len(vectorResults)))
@@ -752,7 +1142,7 @@ func (s *JournalService) HybridSearch(params SearchParams) ([]models.JournalEntr
 
 	// Convert back to entries slice
 	results := make([]models.JournalEntry, 0, len(scored))
-	limit := params.Limit
+	limit := s.clampLimit(params.Limit)
 	if limit == 0 {
 		limit = 20
 	}
@@ -769,54 +1159,44 @@ func (s *JournalService) HybridSearch(params SearchParams) ([]models.JournalEntr
 		results = append(results, se.entry)
 	}
 
-	return results, nil
-}
-
-// Helper function to scan multiple entries
-func (s *JournalService) scanEntries(rows *sql.Rows) ([]models.JournalEntry, error) {
-	entries := []models.JournalEntry{}
-
-	for rows.Next() {
-		var entry models.JournalEntry
-		var processedJSON []byte
-
-		err := rows.Scan(
-			&entry.ID,
-			&entry.Content,
-			&processedJSON,
-			&entry.CreatedAt,
-			&entry.UpdatedAt,
-			&entry.IsFavorite,
-			&entry.OriginalEntryID,
-			&entry.ProcessingStage,
-			&entry.ProcessingStartedAt,
-			&entry.ProcessingCompletedAt,
-			&entry.ProcessingError,
-			pq.Array(&entry.CollectionIDs),
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan entry: %w", err)
-		}
-
-		if err := json.Unmarshal(processedJSON, &entry.ProcessedData); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal processed data: %w", err)
-		}
-
-		entries = append(entries, entry)
-	}
-
-	return entries, nil
+	result.Entries = results
+	return result, nil
 }
 
 // ToggleFavorite toggles the favorite status of an entry
 func (s *JournalService) ToggleFavorite(id string) error {
+	scope, scopeArgs := s.scopeClause("user_id", 2)
 	_, err := s.db.Exec(
-		"UPDATE journal_entries SET is_favorite = NOT is_favorite WHERE id = $1",
-		id,
+		"UPDATE journal_entries SET is_favorite = NOT is_favorite WHERE id = $1"+scope,
+		append([]interface{}{id}, scopeArgs...)...,
 	)
 	return err
 }
 
+// TogglePin pins an entry to the top of the timeline, or unpins it, and
+// returns the new pinned_at (nil when unpinned)
+func (s *JournalService) TogglePin(id string) (*time.Time, error) {
+	scope, scopeArgs := s.scopeClause("user_id", 2)
+
+	var pinnedAt *time.Time
+	err := s.db.QueryRow(
+		"UPDATE journal_entries SET pinned_at = CASE WHEN pinned_at IS NULL THEN NOW() ELSE NULL END WHERE id = $1"+scope+" RETURNING pinned_at",
+		append([]interface{}{id}, scopeArgs...)...,
+	).Scan(&pinnedAt)
+	if err == sql.ErrNoRows {
+		return nil, NotFoundf("entry not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to toggle pin: %w", err)
+	}
+
+	s.sendEvent(events.EventEntryUpdated, id, map[string]interface{}{
+		"pinned_at": pinnedAt,
+	})
+
+	return pinnedAt, nil
+}
+
 // Collection management methods
 func (s *JournalService) CreateCollection(name, description string) (*models.Collection, error) {
 	collection := &models.Collection{
@@ -827,19 +1207,37 @@ func (s *JournalService) CreateCollection(name, description string) (*models.Col
 	}
 
 	err := s.db.QueryRow(
-		"INSERT INTO collections (name, description) VALUES ($1, $2) RETURNING id",
-		name, description,
+		"INSERT INTO collections (name, description, user_id) VALUES ($1, $2, $3) RETURNING id",
+		name, description, s.ownerValue(),
 	).Scan(&collection.ID)
 
+	if isUniqueViolation(err) {
+		return nil, Conflictf("a collection named %q already exists", name)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create collection: %w", err)
 	}
 
+	s.sendEvent(events.EventCollectionCreated, "", map[string]interface{}{
+		"collection": collection,
+	})
+
 	return collection, nil
 }
 
 func (s *JournalService) GetCollections() ([]models.Collection, error) {
-	rows, err := s.db.Query("SELECT id, name, description, created_at, updated_at FROM collections ORDER BY name")
+	scope, scopeArgs := s.scopeClause("c.user_id", 1)
+	rows, err := s.db.Query(`
+		SELECT c.id, c.name, c.description, c.created_at, c.updated_at, COUNT(je.id),
+			c.is_smart, c.query_params
+		FROM collections c
+		LEFT JOIN journal_collection jc ON jc.collection_id = c.id
+		LEFT JOIN journal_entries je ON je.id = jc.journal_id AND je.deleted_at IS NULL
+		WHERE 1=1`+scope+`
+		GROUP BY c.id
+		ORDER BY c.name`,
+		scopeArgs...,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -848,16 +1246,90 @@ func (s *JournalService) GetCollections() ([]models.Collection, error) {
 	collections := []models.Collection{}
 	for rows.Next() {
 		var c models.Collection
-		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		var queryParams []byte
+		if err := rows.Scan(&c.ID, &c.Name, &c.Description, &c.CreatedAt, &c.UpdatedAt, &c.EntryCount, &c.IsSmart, &queryParams); err != nil {
 			return nil, err
 		}
+		c.QueryParams = queryParams
 		collections = append(collections, c)
 	}
 
 	return collections, nil
 }
 
+// UpdateCollection renames a collection and replaces its description
+func (s *JournalService) UpdateCollection(id, name, description string) (*models.Collection, error) {
+	scope, scopeArgs := s.scopeClause("user_id", 4)
+	args := append([]interface{}{id, name, description}, scopeArgs...)
+
+	collection := &models.Collection{}
+	var queryParams []byte
+	err := s.db.QueryRow(
+		"UPDATE collections SET name = $2, description = $3 WHERE id = $1"+scope+
+			" RETURNING id, name, description, created_at, updated_at, is_smart, query_params",
+		args...,
+	).Scan(&collection.ID, &collection.Name, &collection.Description, &collection.CreatedAt, &collection.UpdatedAt,
+		&collection.IsSmart, &queryParams)
+	collection.QueryParams = queryParams
+
+	if err == sql.ErrNoRows {
+		return nil, NotFoundf("collection not found")
+	}
+	if isUniqueViolation(err) {
+		return nil, Conflictf("a collection named %q already exists", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update collection: %w", err)
+	}
+
+	s.sendEvent(events.EventCollectionUpdated, "", map[string]interface{}{
+		"collection": collection,
+	})
+
+	return collection, nil
+}
+
+// DeleteCollection removes a collection. Entry memberships are removed by the
+// journal_collection foreign key cascade; the entries themselves are kept.
+func (s *JournalService) DeleteCollection(id string) error {
+	scope, scopeArgs := s.scopeClause("user_id", 2)
+	result, err := s.db.Exec(
+		"DELETE FROM collections WHERE id = $1"+scope,
+		append([]interface{}{id}, scopeArgs...)...,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+	if affected == 0 {
+		return NotFoundf("collection not found")
+	}
+
+	s.sendEvent(events.EventCollectionDeleted, "", map[string]interface{}{
+		"collection_id": id,
+	})
+
+	return nil
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique constraint error
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
 func (s *JournalService) AddToCollection(entryID, collectionID string) error {
+	if err := s.ensureOwnership(entryID, collectionID); err != nil {
+		return err
+	}
+	if err := s.ensureManualCollection(collectionID); err != nil {
+		return err
+	}
+
 	_, err := s.db.Exec(
 		"INSERT INTO journal_collection (journal_id, collection_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
 		entryID, collectionID,
@@ -869,13 +1341,13 @@ func (s *JournalService) AddToCollection(entryID, collectionID string) error {
 	// Get the updated entry to send in the event
 	entry, err := s.GetEntry(entryID)
 	if err != nil {
-		log.Printf("Failed to get entry after adding to collection: %v", err)
+		slog.Error("Failed to get entry after adding to collection", "entry_id", entryID, "error", err)
 		// Still return success since the collection was added
 		return nil
 	}
 
 	// Send updated event with the full entry data
-	s.broadcaster.SendEvent(events.EventEntryUpdated, entryID, map[string]interface{}{
+	s.sendEvent(events.EventEntryUpdated, entryID, map[string]interface{}{
 		"entry":             entry,
 		"collection_action": "added",
 		"collection_id":     collectionID,
@@ -885,6 +1357,13 @@ func (s *JournalService) AddToCollection(entryID, collectionID string) error {
 }
 
 func (s *JournalService) RemoveFromCollection(entryID, collectionID string) error {
+	if err := s.ensureOwnership(entryID, collectionID); err != nil {
+		return err
+	}
+	if err := s.ensureManualCollection(collectionID); err != nil {
+		return err
+	}
+
 	_, err := s.db.Exec(
 		"DELETE FROM journal_collection WHERE journal_id = $1 AND collection_id = $2",
 		entryID, collectionID,
@@ -896,13 +1375,13 @@ func (s *JournalService) RemoveFromCollection(entryID, collectionID string) erro
 	// Get the updated entry to send in the event
 	entry, err := s.GetEntry(entryID)
 	if err != nil {
-		log.Printf("Failed to get entry after removing from collection: %v", err)
+		slog.Error("Failed to get entry after removing from collection", "entry_id", entryID, "error", err)
 		// Still return success since the collection was removed
 		return nil
 	}
 
 	// Send updated event with the full entry data
-	s.broadcaster.SendEvent(events.EventEntryUpdated, entryID, map[string]interface{}{
+	s.sendEvent(events.EventEntryUpdated, entryID, map[string]interface{}{
 		"entry":             entry,
 		"collection_action": "removed",
 		"collection_id":     collectionID,
@@ -911,9 +1390,26 @@ func (s *JournalService) RemoveFromCollection(entryID, collectionID string) erro
 	return nil
 }
 
-// GetProcessingLogs retrieves processing logs for a specific entry
-func (s *JournalService) GetProcessingLogs(entryID string) ([]models.ProcessingLog, error) {
-	return s.logger.GetLogs(entryID)
+// GetProcessingLogs retrieves processing logs for a specific entry. An empty
+// filter returns every log.
+func (s *JournalService) GetProcessingLogs(entryID string, filter logger.LogFilter) ([]models.ProcessingLog, error) {
+	if err := s.ensureOwnership(entryID, ""); err != nil {
+		return nil, err
+	}
+	return s.logger.GetFilteredLogs(entryID, filter)
+}
+
+// PurgeProcessingLogs deletes processing logs older than the given age. In
+// multi-tenant mode only the current user's entries' logs are deleted.
+func (s *JournalService) PurgeProcessingLogs(olderThan time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-olderThan)
+	if s.config.MultiTenant {
+		if s.userID == "" {
+			return 0, Invalidf("purging logs requires an authenticated user")
+		}
+		return s.logger.PurgeUserLogs(cutoff, s.userID)
+	}
+	return s.logger.PurgeOldLogs(cutoff)
 }
 
 // AnalyzeFailure analyzes why a journal entry processing failed
@@ -926,7 +1422,7 @@ func (s *JournalService) AnalyzeFailure(entryID string) (*FailureAnalysis, error
 
 	// Check if entry actually failed
 	if entry.ProcessingStage != models.StageFailed {
-		return nil, fmt.Errorf("entry %s has not failed (current stage: %s)", entryID, entry.ProcessingStage)
+		return nil, Conflictf("entry %s has not failed (current stage: %s)", entryID, entry.ProcessingStage)
 	}
 
 	// Analyze the failure
@@ -936,7 +1432,9 @@ func (s *JournalService) AnalyzeFailure(entryID string) (*FailureAnalysis, error
 	return s.failureAnalyzer.AnalyzeFailure(ctx, entryID, entry)
 }
 
-// RetryProcessing retries processing for a failed entry
+// RetryProcessing retries processing for a failed or cancelled entry. An
+// entry that completed without an embedding keeps its analysis and only has
+// the embedding generated again.
 func (s *JournalService) RetryProcessing(entryID string) error {
 	// Get the entry
 	entry, err := s.GetEntry(entryID)
@@ -944,20 +1442,33 @@ func (s *JournalService) RetryProcessing(entryID string) error {
 		return fmt.Errorf("failed to get entry: %w", err)
 	}
 
+	// An analyzed entry only lacks its embedding, so only that is retried
+	if entry.ProcessingStage == models.StageCompletedNoEmbedding {
+		return s.retryEmbedding(entry)
+	}
+
 	// Check if entry is in a failed state or stuck in processing
-	if entry.ProcessingStage != models.StageFailed && entry.ProcessingStage != models.StageCompleted {
+	if entry.ProcessingStage != models.StageFailed && entry.ProcessingStage != models.StageCompleted &&
+		entry.ProcessingStage != models.StageCancelled {
 		// Check if it's been stuck for more than 5 minutes
 		if entry.ProcessingStartedAt != nil {
 			elapsed := time.Since(*entry.ProcessingStartedAt)
 			if elapsed < 5*time.Minute {
-				return fmt.Errorf("entry %s is currently being processed (stage: %s)", entryID, entry.ProcessingStage)
+				return Conflictf("entry %s is currently being processed (stage: %s)", entryID, entry.ProcessingStage)
 			}
 		}
 	}
 
+	return s.restartProcessing(entry, "Retrying processing")
+}
+
+// restartProcessing resets an entry to the created stage and runs the same
+// pipeline as a new entry on it in the background. message says why in the
+// processing log and the entry.processing event.
+func (s *JournalService) restartProcessing(entry *models.JournalEntry, message string) error {
 	// Reset processing state
 	now := time.Now()
-	_, err = s.db.Exec(`
+	_, err := s.db.Exec(`
 		UPDATE journal_entries 
 		SET processing_stage = $1, 
 		    processing_started_at = $2,
@@ -966,207 +1477,44 @@ func (s *JournalService) RetryProcessing(entryID string) error {
 		WHERE id = $3`,
 		models.StageCreated,
 		now,
-		entryID,
+		entry.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to reset processing state: %w", err)
 	}
 
-	// Log retry attempt
-	s.logger.LogInfo(entryID, models.StageCreated, "Retrying processing", map[string]interface{}{
+	s.logger.LogInfo(entry.ID, models.StageCreated, message, map[string]interface{}{
 		"previous_stage": entry.ProcessingStage,
 		"previous_error": entry.ProcessingError,
 	})
 
-	// Send processing event
-	s.broadcaster.SendEvent(events.EventEntryProcessing, entryID, map[string]interface{}{
+	s.sendEvent(events.EventEntryProcessing, entry.ID, map[string]interface{}{
 		"stage":   models.StageCreated,
-		"message": "Retrying processing",
+		"message": message,
 	})
 
-	// Process asynchronously in background
-	go func(entryID string, content string) {
-		// Recover from panics in goroutine
-		defer func() {
-			if r := recover(); r != nil {
-				log.Printf("PANIC in retry processing for entry %s: %v", entryID, r)
-				s.logger.SetError(entryID, models.StageAnalyzing, fmt.Errorf("panic: %v", r))
-				// Send failure event
-				s.broadcaster.SendEvent(events.EventEntryFailed, entryID, map[string]interface{}{
-					"error": fmt.Sprintf("%v", r),
-					"stage": models.StageFailed,
-				})
-			}
-		}()
-
-		log.Printf("Starting retry processing for entry %s", entryID)
-
-		// Use the same processing logic as CreateEntry
-		// Transition to analyzing stage
-		s.logger.UpdateStage(entryID, models.StageAnalyzing)
-		s.broadcaster.SendEvent(events.EventEntryProcessing, entryID, map[string]interface{}{
-			"stage":   models.StageAnalyzing,
-			"message": "Analyzing content with AI",
-		})
-
-		// Process content with Qwen
-		s.logger.LogInfo(entryID, models.StageAnalyzing, "Starting AI analysis (retry)", nil)
-		processedData, err := s.processor.ProcessJournalEntry(content)
-		if err != nil {
-			log.Printf("Failed to process entry %s on retry: %v", entryID, err)
-			s.logger.SetError(entryID, models.StageAnalyzing, err)
-			// Send failure event
-			s.broadcaster.SendEvent(events.EventEntryFailed, entryID, map[string]interface{}{
-				"error": err.Error(),
-				"stage": models.StageAnalyzing,
-			})
-			return
-		}
-
-		// Continue with the rest of the processing pipeline...
-		// (URL fetching, embedding generation, etc.)
-
-		// Create temporary entry for embedding generation
-		tempEntry := models.JournalEntry{
-			ID:            entryID,
-			Content:       content,
-			ProcessedData: *processedData,
-		}
-
-		// Transition to fetching URLs stage
-		s.logger.UpdateStage(entryID, models.StageFetchingURLs)
-		s.broadcaster.SendEvent(events.EventEntryProcessing, entryID, map[string]interface{}{
-			"stage":   models.StageFetchingURLs,
-			"message": "Fetching linked content",
-		})
-
-		// Fetch URLs if any
-		if len(processedData.ExtractedURLs) > 0 {
-			s.logger.LogInfo(entryID, models.StageFetchingURLs, "Fetching URLs", map[string]interface{}{
-				"urls_count": len(processedData.ExtractedURLs),
-			})
-
-			for i, urlInfo := range processedData.ExtractedURLs {
-				s.logger.LogInfo(entryID, models.StageFetchingURLs, fmt.Sprintf("Fetching URL %d/%d", i+1, len(processedData.ExtractedURLs)), map[string]interface{}{
-					"url": urlInfo.URL,
-				})
-
-				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-				fetchedContent, err := s.mcpClient.FetchURL(ctx, urlInfo.URL, urlInfo.Title) // Using Title as reason
-				cancel()
-
-				if err != nil {
-					s.logger.LogInfo(entryID, models.StageFetchingURLs, fmt.Sprintf("Failed to fetch URL: %v", err), map[string]interface{}{
-						"url": urlInfo.URL,
-					})
-					continue
-				}
-
-				// Add fetched content to the URL info
-				tempEntry.ProcessedData.ExtractedURLs[i].Title = fetchedContent.Title
-				tempEntry.ProcessedData.ExtractedURLs[i].Content = fetchedContent.Content
-			}
-		}
-
-		// Transition to embedding generation stage
-		s.logger.UpdateStage(entryID, models.StageGeneratingEmbeddings)
-		s.broadcaster.SendEvent(events.EventEntryProcessing, entryID, map[string]interface{}{
-			"stage":   models.StageGeneratingEmbeddings,
-			"message": "Creating semantic embeddings",
-		})
-
-		// Generate embeddings
-		s.logger.LogInfo(entryID, models.StageGeneratingEmbeddings, "Generating embeddings", nil)
-		embedding, err := s.processor.CreateEmbedding(tempEntry)
-		if err != nil {
-			log.Printf("Failed to create embeddings for entry %s: %v", entryID, err)
-			s.logger.SetError(entryID, models.StageGeneratingEmbeddings, err)
-			// Send failure event
-			s.broadcaster.SendEvent(events.EventEntryFailed, entryID, map[string]interface{}{
-				"error": err.Error(),
-				"stage": models.StageGeneratingEmbeddings,
-			})
-			return
-		}
-
-		// Update entry with processed data
-		processedJSON, err := json.Marshal(tempEntry.ProcessedData)
-		if err != nil {
-			s.logger.SetError(entryID, models.StageGeneratingEmbeddings, fmt.Errorf("failed to marshal processed data: %w", err))
-			return
-		}
-
-		completedAt := time.Now()
-		_, err = s.db.Exec(`
-			UPDATE journal_entries 
-			SET processed_data = $1, 
-			    embedding = $2, 
-			    updated_at = $3,
-			    processing_stage = $4,
-			    processing_completed_at = $5,
-			    processing_error = NULL
-			WHERE id = $6`,
-			processedJSON,
-			pgvector.NewVector(embedding),
-			completedAt,
-			models.StageCompleted,
-			completedAt,
-			entryID,
-		)
-
-		if err != nil {
-			log.Printf("Failed to update entry %s with processed data: %v", entryID, err)
-			s.logger.SetError(entryID, models.StageGeneratingEmbeddings, err)
-			return
-		}
-
-		// Mark as completed
-		s.logger.UpdateStage(entryID, models.StageCompleted)
-		s.logger.LogInfo(entryID, models.StageCompleted, "Processing completed successfully", map[string]interface{}{
-			"processing_time": completedAt.Sub(*entry.ProcessingStartedAt).Seconds(),
-		})
-
-		// Get updated entry to send in event
-		updatedEntry, err := s.GetEntry(entryID)
-		if err != nil {
-			log.Printf("Failed to get updated entry %s: %v", entryID, err)
-			// Create a complete entry structure even if fetch fails
-			tempEntry.UpdatedAt = completedAt
-			tempEntry.ProcessingStage = models.StageCompleted
-			tempEntry.ProcessingCompletedAt = &completedAt
-
-			// Send event with reconstructed entry data
-			s.broadcaster.SendEvent(events.EventEntryProcessed, entryID, map[string]interface{}{
-				"entry": &tempEntry,
-				"stage": models.StageCompleted,
-			})
-		} else {
-			// Send processed event with full entry
-			s.broadcaster.SendEvent(events.EventEntryProcessed, entryID, map[string]interface{}{
-				"entry": updatedEntry,
-				"stage": models.StageCompleted,
-			})
-		}
-
-		log.Printf("Successfully completed retry processing for entry %s", entryID)
-	}(entryID, entry.Content)
+	go s.processEntry(entry.ID, entry.Content)
 
 	return nil
 }
 
-// GetSearchSuggestions returns popular topics and entities for search suggestions
+// GetSearchSuggestions returns popular topics and entities, recently run
+// searches, and favorite, collection and sentiment counts for filter chips.
+// Everything except recent searches counts completed entries only.
 func (s *JournalService) GetSearchSuggestions() (map[string]interface{}, error) {
+	scope, scopeArgs := s.scopeClause("user_id", 1)
+
 	// Get top topics
 	topicsQuery := `
 		SELECT topic, COUNT(*) as count
 		FROM journal_entries,
 		LATERAL jsonb_array_elements_text(processed_data->'topics') as topic
-		WHERE processing_stage = 'completed'
+		WHERE processing_stage IN ('completed', 'completed_no_embedding') AND deleted_at IS NULL` + scope + `
 		GROUP BY topic
 		ORDER BY count DESC
 		LIMIT 10`
 
-	topicsRows, err := s.db.Query(topicsQuery)
+	topicsRows, err := s.db.Query(topicsQuery, scopeArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get top topics: %w", err)
 	}
@@ -1190,12 +1538,12 @@ func (s *JournalService) GetSearchSuggestions() (map[string]interface{}, error)
 		SELECT entity, COUNT(*) as count
 		FROM journal_entries,
 		LATERAL jsonb_array_elements_text(processed_data->'entities') as entity
-		WHERE processing_stage = 'completed'
+		WHERE processing_stage IN ('completed', 'completed_no_embedding') AND deleted_at IS NULL` + scope + `
 		GROUP BY entity
 		ORDER BY count DESC
 		LIMIT 10`
 
-	entitiesRows, err := s.db.Query(entitiesQuery)
+	entitiesRows, err := s.db.Query(entitiesQuery, scopeArgs...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get top entities: %w", err)
 	}
@@ -1214,114 +1562,45 @@ func (s *JournalService) GetSearchSuggestions() (map[string]interface{}, error)
 		})
 	}
 
-	// Get recent searches from metadata
-	recentQuery := `
-		SELECT DISTINCT content
-		FROM journal_entries
-		WHERE processing_stage = 'completed'
-		ORDER BY created_at DESC
-		LIMIT 5`
-
-	recentRows, err := s.db.Query(recentQuery)
+	recent, err := s.recentSearches(5)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get recent entries: %w", err)
+		return nil, err
 	}
-	defer recentRows.Close()
 
-	recentPhrases := []string{}
-	for recentRows.Next() {
-		var content string
-		if err := recentRows.Scan(&content); err != nil {
-			continue
-		}
-		// Extract first meaningful phrase (up to 50 chars)
-		if len(content) > 50 {
-			content = content[:50] + "..."
-		}
-		recentPhrases = append(recentPhrases, content)
+	// Facet counts for the filter sidebar
+	favorites, err := s.favoriteCount()
+	if err != nil {
+		return nil, err
 	}
 
-	return map[string]interface{}{
-		"topics":   topics,
-		"entities": entities,
-		"recent":   recentPhrases,
-	}, nil
-}
-
-// ExportEntries exports journal entries in various formats
-func (s *JournalService) ExportEntries(params SearchParams, format string) ([]byte, string, error) {
-	// Get entries using existing search
-	entries, err := s.ClassicSearch(params)
+	collections, err := s.collectionCounts()
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to search entries: %w", err)
+		return nil, err
 	}
 
-	switch format {
-	case "json":
-		data, err := json.MarshalIndent(entries, "", "  ")
-		if err != nil {
-			return nil, "", fmt.Errorf("failed to marshal JSON: %w", err)
-		}
-		return data, "application/json", nil
-
-	case "markdown":
-		var md strings.Builder
-		md.WriteString("# Journal Export\n\n")
-		md.WriteString(fmt.Sprintf("*Exported on %s*\n\n", time.Now().Format("January 2, 2006")))
-
-		for _, entry := range entries {
-			md.WriteString(fmt.Sprintf("## %s\n\n", entry.CreatedAt.Format("January 2, 2006 - 3:04 PM")))
-
-			if entry.ProcessedData.Summary != "" {
-				md.WriteString(fmt.Sprintf("**Summary:** %s\n\n", entry.ProcessedData.Summary))
-			}
-
-			md.WriteString(entry.Content + "\n\n")
-
-			if len(entry.ProcessedData.Topics) > 0 {
-				md.WriteString("**Topics:** " + strings.Join(entry.ProcessedData.Topics, ", ") + "\n\n")
-			}
-
-			if len(entry.ProcessedData.Entities) > 0 {
-				md.WriteString("**Entities:** " + strings.Join(entry.ProcessedData.Entities, ", ") + "\n\n")
-			}
-
-			if entry.ProcessedData.Sentiment != "" {
-				md.WriteString(fmt.Sprintf("**Sentiment:** %s\n\n", entry.ProcessedData.Sentiment))
-			}
-
-			md.WriteString("---\n\n")
-		}
-
-		return []byte(md.String()), "text/markdown", nil
-
-	case "csv":
-		var csv strings.Builder
-		csv.WriteString("Date,Time,Summary,Content,Topics,Entities,Sentiment,Is Favorite\n")
-
-		for _, entry := range entries {
-			csv.WriteString(fmt.Sprintf("%s,%s,%s,%s,%s,%s,%s,%v\n",
-				entry.CreatedAt.Format("2006-01-02"),
-				entry.CreatedAt.Format("15:04:05"),
-				escapeCSV(entry.ProcessedData.Summary),
-				escapeCSV(entry.Content),
-				escapeCSV(strings.Join(entry.ProcessedData.Topics, "; ")),
-				escapeCSV(strings.Join(entry.ProcessedData.Entities, "; ")),
-				entry.ProcessedData.Sentiment,
-				entry.IsFavorite,
-			))
-		}
-
-		return []byte(csv.String()), "text/csv", nil
-
-	default:
-		return nil, "", fmt.Errorf("unsupported export format: %s", format)
+	sentiments, err := s.sentimentCounts()
+	if err != nil {
+		return nil, err
 	}
+
+	return map[string]interface{}{
+		"topics":         topics,
+		"entities":       entities,
+		"recent":         recent,
+		"favorite_count": favorites,
+		"collections":    collections,
+		"sentiments":     sentiments,
+	}, nil
 }
 
+// csvLineBreaks normalizes line breaks inside a CSV field to the \n that ends
+// each row, so readers never see a stray \r within a quoted field
+var csvLineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
 // escapeCSV escapes special characters in CSV fields
 func escapeCSV(s string) string {
 	if strings.ContainsAny(s, ",\"\n\r") {
+		s = csvLineBreaks.Replace(s)
 		s = strings.ReplaceAll(s, `"`, `""`)
 		return `"` + s + `"`
 	}
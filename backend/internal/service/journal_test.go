@@ -1,11 +1,18 @@
 package service
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/journal/internal/db"
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/logger"
+	"github.com/journal/internal/models"
+	"github.com/journal/internal/ollama"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -33,7 +40,7 @@ func TestGetSearchSuggestions(t *testing.T) {
 	mock.ExpectQuery(`SELECT topic, COUNT\(\*\) as count
 		FROM journal_entries,
 		LATERAL jsonb_array_elements_text\(processed_data->'topics'\) as topic
-		WHERE processing_stage = 'completed'
+		WHERE processing_stage IN \('completed', 'completed_no_embedding'\) AND deleted_at IS NULL
 		GROUP BY topic
 		ORDER BY count DESC
 		LIMIT 10`).
@@ -47,24 +54,39 @@ func TestGetSearchSuggestions(t *testing.T) {
 	mock.ExpectQuery(`SELECT entity, COUNT\(\*\) as count
 		FROM journal_entries,
 		LATERAL jsonb_array_elements_text\(processed_data->'entities'\) as entity
-		WHERE processing_stage = 'completed'
+		WHERE processing_stage IN \('completed', 'completed_no_embedding'\) AND deleted_at IS NULL
 		GROUP BY entity
 		ORDER BY count DESC
 		LIMIT 10`).
 		WillReturnRows(entitiesRows)
 
-	// Mock the recent entries query
-	recentRows := sqlmock.NewRows([]string{"content"}).
-		AddRow("Today was a productive day working on the new feature...").
-		AddRow("Had a great meeting with the team about...")
-
-	mock.ExpectQuery(`SELECT DISTINCT content
-		FROM journal_entries
-		WHERE processing_stage = 'completed'
-		ORDER BY created_at DESC
-		LIMIT 5`).
+	// Mock the recent searches query
+	searchedAt := time.Now()
+	recentRows := sqlmock.NewRows([]string{"query", "count", "max"}).
+		AddRow("productive day", 3, searchedAt).
+		AddRow("team meeting", 1, searchedAt.Add(-time.Hour))
+
+	mock.ExpectQuery(`SELECT query, COUNT\(\*\), MAX\(searched_at\)
+		FROM search_history
+		WHERE TRUE
+		GROUP BY query
+		ORDER BY MAX\(searched_at\) DESC
+		LIMIT \$1`).
+		WithArgs(5).
 		WillReturnRows(recentRows)
 
+	// Mock the facet count queries
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM journal_entries WHERE processing_stage IN \('completed', 'completed_no_embedding'\) AND is_favorite`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(6))
+	mock.ExpectQuery(`FROM collections c(.*)WHERE NOT c.is_smart`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "count"}).
+			AddRow("c1", "Travel", 4).
+			AddRow("c2", "Work", 0))
+	mock.ExpectQuery(`SELECT processed_data->>'sentiment' AS sentiment, COUNT\(\*\) AS count`).
+		WillReturnRows(sqlmock.NewRows([]string{"sentiment", "count"}).
+			AddRow("positive", 9).
+			AddRow("neutral", 3))
+
 	// Call the method
 	suggestions, err := service.GetSearchSuggestions()
 	require.NoError(t, err)
@@ -84,10 +106,14 @@ func TestGetSearchSuggestions(t *testing.T) {
 	assert.Equal(t, "John Doe", entities[0]["text"])
 	assert.Equal(t, 4, entities[0]["count"])
 
-	recent, ok := suggestions["recent"].([]string)
+	recent, ok := suggestions["recent"].([]RecentSearch)
 	assert.True(t, ok)
 	assert.Len(t, recent, 2)
-	assert.Equal(t, "Today was a productive day working on the new feat...", recent[0])
+	assert.Equal(t, RecentSearch{Text: "productive day", Count: 3, LastSearchedAt: searchedAt}, recent[0])
+
+	assert.Equal(t, 6, suggestions["favorite_count"])
+	assert.Equal(t, []CollectionFacet{{ID: "c1", Name: "Travel", Count: 4}, {ID: "c2", Name: "Work", Count: 0}}, suggestions["collections"])
+	assert.Equal(t, []SentimentFacet{{Sentiment: "positive", Count: 9}, {Sentiment: "neutral", Count: 3}}, suggestions["sentiments"])
 
 	// Ensure all expectations were met
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -109,15 +135,15 @@ func TestClassicSearch(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{
 		"id", "content", "processed_data", "created_at", "updated_at",
-		"is_favorite", "original_entry_id", "processing_stage",
+		"is_favorite", "pinned_at", "original_entry_id", "processing_stage",
 		"processing_started_at", "processing_completed_at", "processing_error",
-		"collection_ids",
+		"attachments", "original_content", "collection_ids",
 	}).AddRow(
 		"123", "Learning golang today", `{"summary": "test", "topics": [], "entities": [], "sentiment": "positive"}`,
-		time.Now(), time.Now(), false, nil, "completed",
-		time.Now(), time.Now(), nil, "{}")
+		time.Now(), time.Now(), false, nil, nil, "completed",
+		time.Now(), time.Now(), nil, nil, nil, "{}")
 
-	mock.ExpectQuery(`SELECT DISTINCT(.*)FROM journal_entries(.*)WHERE(.*)plainto_tsquery`).
+	mock.ExpectQuery(`SELECT DISTINCT(.*)FROM journal_entries(.*)WHERE(.*)websearch_to_tsquery`).
 		WithArgs("golang", 10).
 		WillReturnRows(rows)
 
@@ -130,6 +156,24 @@ func TestClassicSearch(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestClassicSearchKeepsWebSearchSyntax(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+
+	// Quotes and operators reach websearch_to_tsquery untouched, so the
+	// phrase and the exclusion are honored
+	mock.ExpectQuery(`je.tsv @@ websearch_to_tsquery\(je.ts_config, \$1\)`).
+		WithArgs(`"New York" -work OR vacation`).
+		WillReturnRows(entryRows())
+
+	_, err := service.ClassicSearch(SearchParams{Query: `"New York" -work OR vacation`})
+	require.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestVectorSearch(t *testing.T) {
 	database, mock := setupMockDB(t)
 	defer database.Close()
@@ -154,6 +198,172 @@ func TestVectorSearch(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+// embeddingServer stands in for Ollama's embedding endpoint, returning a
+// vector of the given width for every request
+func embeddingServer(dims int) *httptest.Server {
+	vector := make([]float32, dims)
+	vector[0] = 1
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"model":      "nomic-embed-text",
+			"embeddings": [][]float32{vector},
+		})
+	}))
+}
+
+func TestVectorSearchEmbeddingDimensionMismatch(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	embeddings := embeddingServer(1024)
+	defer embeddings.Close()
+
+	service := &JournalService{
+		db:        database,
+		processor: ollama.NewProcessor(ollama.NewClient(embeddings.URL)),
+	}
+
+	_, err := service.VectorSearch(SearchParams{Query: "hiking", SemanticMode: "similar"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "embedding model returned 1024 dims, schema expects 768")
+
+	// The query never reaches the database
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVectorSearchMinSimilarity(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	embeddings := embeddingServer(embeddingDimensions)
+	defer embeddings.Close()
+
+	service := &JournalService{
+		db:        database,
+		processor: ollama.NewProcessor(ollama.NewClient(embeddings.URL)),
+	}
+
+	mock.ExpectQuery(`GROUP BY je.id HAVING GREATEST\(.*\) >= \$3 ORDER BY similarity DESC LIMIT \$4`).
+		WithArgs(sqlmock.AnyArg(), 10*vectorCandidateFactor, float32(0.6), 10).
+		WillReturnRows(similarityRows())
+
+	entries, err := service.VectorSearch(SearchParams{Query: "gardening", Limit: 10, MinSimilarity: 0.6})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	_, err = service.VectorSearch(SearchParams{Query: "gardening", MinSimilarity: 1.5})
+	assert.Error(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVectorSearchScoresIndexedCandidates(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	embeddings := embeddingServer(embeddingDimensions)
+	defer embeddings.Close()
+
+	service := (&JournalService{
+		db:        database,
+		processor: ollama.NewProcessor(ollama.NewClient(embeddings.URL)),
+	}).WithConfig(Config{MultiTenant: true}).ForUser("alice")
+
+	// Nearest entries and chunks are ordered by raw distance so the ivfflat
+	// indexes serve them; only those candidates get the combined score. The
+	// scope and filters are applied to both parts with shared placeholders.
+	mock.ExpectQuery(`WITH candidates AS \( \(SELECT je.id FROM journal_entries je `+
+		`WHERE je.embedding IS NOT NULL AND je.deleted_at IS NULL AND je.user_id = \$2 AND je.is_favorite = \$3 `+
+		`ORDER BY je.embedding <=> \$1 LIMIT \$4\) UNION `+
+		`\(SELECT ec.entry_id FROM entry_chunks ec JOIN journal_entries je ON je.id = ec.entry_id `+
+		`WHERE je.deleted_at IS NULL AND je.user_id = \$2 AND je.is_favorite = \$3 `+
+		`ORDER BY ec.embedding <=> \$1 LIMIT \$4\) \) `+
+		`SELECT .* FROM candidates c JOIN journal_entries je ON je.id = c.id .* `+
+		`WHERE je.embedding IS NOT NULL AND je.deleted_at IS NULL AND je.user_id = \$2 AND je.is_favorite = \$3 `+
+		`GROUP BY je.id ORDER BY similarity DESC LIMIT \$5`).
+		WithArgs(sqlmock.AnyArg(), "alice", true, 5*vectorCandidateFactor, 5).
+		WillReturnRows(similarityRows())
+
+	favorite := true
+	_, err := service.VectorSearch(SearchParams{Query: "gardening", Limit: 5, IsFavorite: &favorite})
+	require.NoError(t, err)
+
+	// Explore mode samples mid-range matches from a wider pool
+	mock.ExpectQuery(`BETWEEN 0.3 AND 0.7 ORDER BY RANDOM\(\) LIMIT \$4`).
+		WithArgs(sqlmock.AnyArg(), "alice", 5*exploreCandidateFactor, 5).
+		WillReturnRows(similarityRows())
+
+	_, err = service.VectorSearch(SearchParams{Query: "gardening", Limit: 5, SemanticMode: "explore"})
+	require.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVectorSearchChunkCandidatesSkipFilteredEntries(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	embeddings := embeddingServer(embeddingDimensions)
+	defer embeddings.Close()
+
+	service := &JournalService{
+		db:        database,
+		processor: ollama.NewProcessor(ollama.NewClient(embeddings.URL)),
+	}
+
+	// The nearest chunks may belong to deleted entries or entries outside
+	// the collection; they are filtered before the limit so they cannot
+	// crowd out the entries that do match
+	mock.ExpectQuery(`\(SELECT ec.entry_id FROM entry_chunks ec JOIN journal_entries je ON je.id = ec.entry_id `+
+		`WHERE je.deleted_at IS NULL AND je.id IN \(SELECT journal_id FROM journal_collection WHERE collection_id = ANY\(\$2\)\) `+
+		`ORDER BY ec.embedding <=> \$1 LIMIT \$3\) \)`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 2*vectorCandidateFactor, 2).
+		WillReturnRows(similarityRows(
+			mockEntry{ID: "in-collection", Content: "matches", CreatedAt: time.Now(), Similarity: 0.8},
+		))
+
+	entries, err := service.VectorSearch(SearchParams{Query: "gardening", Limit: 2, CollectionIDs: []string{"c1"}})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "in-collection", entries[0].ID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVectorSearchContrast(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	embeddings := embeddingServer(embeddingDimensions)
+	defer embeddings.Close()
+
+	service := &JournalService{
+		db:        database,
+		processor: ollama.NewProcessor(ollama.NewClient(embeddings.URL)),
+	}
+	now := time.Now()
+
+	// Rows as Postgres returns them for the query [1,0,0]: an orthogonal
+	// entry [0,1,0] (similarity 0) before a near-parallel one (0.9)
+	mock.ExpectQuery(`ORDER BY je.embedding <=> \$1 DESC LIMIT \$2 \) SELECT .* GROUP BY je.id ORDER BY similarity ASC LIMIT \$3`).
+		WithArgs(sqlmock.AnyArg(), 10*vectorCandidateFactor, 10).
+		WillReturnRows(similarityRows(
+			mockEntry{ID: "far", Content: "orthogonal", CreatedAt: now, Similarity: 0.0},
+			mockEntry{ID: "near", Content: "parallel", CreatedAt: now, Similarity: 0.9},
+		))
+
+	entries, err := service.VectorSearch(SearchParams{Query: "q", Limit: 10, SemanticMode: "contrast"})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "far", entries[0].ID, "farthest entry comes first")
+	assert.Equal(t, float32(1), entries[0].ProcessedData.Metadata["contrast_score"])
+	assert.NotContains(t, entries[0].ProcessedData.Metadata, "similarity_score")
+	assert.InDelta(t, 0.1, entries[1].ProcessedData.Metadata["contrast_score"], 1e-6)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestHybridSearch(t *testing.T) {
 	database, mock := setupMockDB(t)
 	defer database.Close()
@@ -171,23 +381,141 @@ func TestHybridSearch(t *testing.T) {
 	// Mock classic search results
 	classicRows := sqlmock.NewRows([]string{
 		"id", "content", "processed_data", "created_at", "updated_at",
-		"is_favorite", "original_entry_id", "processing_stage",
+		"is_favorite", "pinned_at", "original_entry_id", "processing_stage",
 		"processing_started_at", "processing_completed_at", "processing_error",
-		"collection_ids",
+		"attachments", "original_content", "collection_ids",
 	}).AddRow(
 		"123", "This is a test entry", `{"summary": "test", "topics": [], "entities": [], "sentiment": "neutral"}`,
-		time.Now(), time.Now(), false, nil, "completed",
-		time.Now(), time.Now(), nil, "{}")
+		time.Now(), time.Now(), false, nil, nil, "completed",
+		time.Now(), time.Now(), nil, nil, nil, "{}")
 
-	mock.ExpectQuery(`SELECT DISTINCT(.*)FROM journal_entries(.*)WHERE(.*)plainto_tsquery`).
+	mock.ExpectQuery(`SELECT DISTINCT(.*)FROM journal_entries(.*)WHERE(.*)websearch_to_tsquery`).
 		WithArgs("test", 5).
 		WillReturnRows(classicRows)
 
-	// Note: Vector search would fail due to nil processor, but hybrid search handles this gracefully
-	entries, err := service.HybridSearch(params)
+	// Vector search fails due to nil processor, so hybrid search falls back
+	// to classic results and reports the degradation
+	result, err := service.HybridSearchResult(params)
 	require.NoError(t, err)
-	assert.Len(t, entries, 1)
-	assert.Equal(t, "123", entries[0].ID)
+	assert.Len(t, result.Entries, 1)
+	assert.Equal(t, "123", result.Entries[0].ID)
+	assert.True(t, result.Degraded)
+	assert.Equal(t, ReasonSemanticUnavailable, result.Reason)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHybridSearchDegradesOnlyWhenSemanticSearchIsDown(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	embeddings := embeddingServer(embeddingDimensions)
+	defer embeddings.Close()
+
+	service := &JournalService{
+		db:        database,
+		processor: ollama.NewProcessor(ollama.NewClient(embeddings.URL)),
+	}
+
+	// Invalid parameters are the caller's mistake, not an outage
+	_, err := service.HybridSearchResult(SearchParams{Query: "test", Limit: 5, MinSimilarity: 1.5})
+	assert.ErrorIs(t, err, ErrValidation)
+
+	_, err = service.HybridSearchResult(SearchParams{Query: "test", Limit: 5, Probes: -1})
+	assert.ErrorIs(t, err, ErrValidation)
+
+	// Ollama cannot be reached, so the classic results are returned alone
+	embeddings.Close()
+	mock.ExpectQuery(`SELECT DISTINCT(.*)FROM journal_entries(.*)websearch_to_tsquery`).
+		WithArgs("test", 5).
+		WillReturnRows(entryRows(mockEntry{ID: "123", Content: "a test entry"}))
+
+	result, err := service.HybridSearchResult(SearchParams{Query: "test", Limit: 5})
+	require.NoError(t, err)
+	assert.Len(t, result.Entries, 1)
+	assert.True(t, result.Degraded)
+	assert.Equal(t, ReasonSemanticUnavailable, result.Reason)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHybridSearchNotDegradedWithoutQuery(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+
+	// Without a query there is no vector leg to fail
+	mock.ExpectQuery(`SELECT DISTINCT(.*)FROM journal_entries`).
+		WithArgs(5).
+		WillReturnRows(entryRows(mockEntry{ID: "123", Content: "an entry"}))
+
+	result, err := service.HybridSearchResult(SearchParams{Limit: 5})
+	require.NoError(t, err)
+	assert.Len(t, result.Entries, 1)
+	assert.False(t, result.Degraded)
+	assert.Empty(t, result.Reason)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCountEntries(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	favorite := true
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM journal_entries je WHERE je.deleted_at IS NULL AND je.is_favorite = \$1 AND je.id IN \(SELECT journal_id FROM journal_collection WHERE collection_id = ANY\(\$2\)\)$`).
+		WithArgs(true, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(23))
+
+	count, err := service.CountEntries(SearchParams{IsFavorite: &favorite, CollectionIDs: []string{"c1"}, Limit: 10, Offset: 20})
+	require.NoError(t, err)
+	assert.Equal(t, 23, count)
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestRetryProcessingRunsPipeline(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	// The model is down, so the retried analysis fails again
+	ollamaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusInternalServerError)
+	}))
+	defer ollamaServer.Close()
+
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	service := &JournalService{
+		db:          database,
+		processor:   ollama.NewProcessor(ollama.NewClient(ollamaServer.URL)),
+		broadcaster: broadcaster,
+		logger:      logger.NewProcessingLogger(database.DB),
+	}
+	now := time.Now()
+
+	mock.ExpectQuery(`WHERE je.id = \$1`).
+		WithArgs("e1").
+		WillReturnRows(entryRows(mockEntry{ID: "e1", Content: "c", CreatedAt: now, Stage: models.StageFailed, StartedAt: now, CompletedAt: now, Error: "boom"}))
+	mock.ExpectExec(`SET processing_stage = \$1,\s+processing_started_at = \$2`).
+		WithArgs(models.StageCreated, sqlmock.AnyArg(), "e1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, service.RetryProcessing("e1"))
+
+	var failed *events.Event
+	require.Eventually(t, func() bool {
+		for _, e := range recorded() {
+			if e.Type == string(events.EventEntryFailed) {
+				failed = e
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, models.StageAnalyzing, failed.Data.(map[string]interface{})["stage"])
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// defaultTSConfig is used when an entry's language cannot be determined,
+// matching the configuration every entry used before detection
+const defaultTSConfig = "english"
+
+// fallbackTSConfig indexes words without stemming, for text in a script no
+// supported configuration covers
+const fallbackTSConfig = "simple"
+
+// languageStopwords lists very common words per Postgres text search
+// configuration. Counting them is enough to tell these languages apart in
+// all but the shortest entries.
+var languageStopwords = map[string][]string{
+	"english":    {"the", "and", "is", "was", "of", "to", "in", "that", "it", "with", "for", "this", "have", "but", "not", "my"},
+	"spanish":    {"el", "la", "de", "que", "y", "en", "los", "las", "por", "con", "una", "para", "es", "pero", "mi", "muy"},
+	"french":     {"le", "la", "les", "de", "et", "est", "un", "une", "que", "pour", "dans", "pas", "avec", "je", "mais", "très"},
+	"german":     {"der", "die", "das", "und", "ist", "nicht", "ich", "mit", "ein", "eine", "zu", "auf", "den", "auch", "war", "sehr"},
+	"italian":    {"il", "la", "di", "che", "e", "è", "un", "una", "per", "non", "con", "sono", "ho", "ma", "gli", "molto"},
+	"portuguese": {"o", "a", "de", "que", "e", "do", "da", "em", "um", "uma", "para", "não", "com", "os", "mas", "muito"},
+	"dutch":      {"de", "het", "een", "en", "van", "ik", "is", "niet", "dat", "op", "te", "met", "was", "voor", "maar", "heel"},
+	"swedish":    {"och", "att", "det", "som", "en", "är", "jag", "på", "för", "med", "inte", "var", "har", "till", "men", "mycket"},
+	"russian":    {"и", "в", "не", "на", "что", "я", "с", "он", "как", "это", "по", "но", "было", "так", "очень", "мне"},
+}
+
+// detectTSConfig guesses the text search configuration for content: the
+// language with the most stopword hits, provided it has at least two. Latin
+// text without a clear winner stays English; other scripts without one get
+// the non-stemming 'simple' configuration.
+func detectTSConfig(content string) string {
+	words := strings.FieldsFunc(strings.ToLower(content), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if len(words) == 0 {
+		return defaultTSConfig
+	}
+
+	latin := 0
+	counts := make(map[string]int, len(words))
+	for _, word := range words {
+		counts[word]++
+		if unicode.Is(unicode.Latin, []rune(word)[0]) {
+			latin++
+		}
+	}
+
+	best := fallbackTSConfig
+	if latin*2 >= len(words) {
+		best = defaultTSConfig
+	}
+	bestHits := 1
+
+	// Sorted so ties resolve the same way every time
+	configs := make([]string, 0, len(languageStopwords))
+	for config := range languageStopwords {
+		configs = append(configs, config)
+	}
+	sort.Strings(configs)
+
+	for _, config := range configs {
+		hits := 0
+		for _, word := range languageStopwords[config] {
+			hits += counts[word]
+		}
+		if hits > bestHits || hits == bestHits && config == defaultTSConfig {
+			best, bestHits = config, hits
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectTSConfig(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"Today I went to the market and it was busy, but the weather was nice.", "english"},
+		{"Hoy fui al mercado con mi hermana y la verdad es que estaba muy lleno.", "spanish"},
+		{"Aujourd'hui je suis allé au marché avec ma sœur, mais il y avait trop de monde.", "french"},
+		{"Heute war ich mit meiner Schwester auf dem Markt und es war sehr voll.", "german"},
+		{"Сегодня я был на рынке, и это было очень интересно.", "russian"},
+		{"今日は友達と市場に行きました。とても楽しかったです。", "simple"},
+		{"Gym. Legs.", "english"},
+		{"", "english"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, detectTSConfig(tt.content), tt.content)
+	}
+}
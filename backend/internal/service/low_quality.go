@@ -0,0 +1,52 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/journal/internal/models"
+	"github.com/journal/internal/ollama"
+)
+
+// DefaultLowQualityThreshold is the analysis quality below which
+// ListLowQuality reports an entry when no threshold is given
+const DefaultLowQualityThreshold = 0.5
+
+// ListLowQuality lists completed entries whose analysis quality score, set
+// by the processor, is below threshold (DefaultLowQualityThreshold when 0),
+// worst first. These are candidates for ReprocessEntry. Entries analyzed
+// before scores were recorded, and drafts, have no score and are left out.
+func (s *JournalService) ListLowQuality(threshold float64, limit int) ([]models.JournalEntry, error) {
+	if threshold == 0 {
+		threshold = DefaultLowQualityThreshold
+	}
+	if threshold < 0 || threshold > 1 {
+		return nil, Invalidf("threshold must be between 0 and 1, got %v", threshold)
+	}
+	if limit <= 0 {
+		limit = defaultStageListLimit
+	}
+	limit = s.clampLimit(limit)
+
+	args := []interface{}{threshold, limit}
+	scope, scopeArgs := s.scopeClause("je.user_id", len(args)+1)
+	args = append(args, scopeArgs...)
+
+	quality := "(je.processed_data->'metadata'->>'" + ollama.AnalysisQualityKey + "')::float"
+	query := `
+		SELECT` + entryColumns + `
+		FROM journal_entries je
+		LEFT JOIN journal_collection jc ON je.id = jc.journal_id
+		WHERE je.processing_stage IN ('completed', 'completed_no_embedding') AND je.deleted_at IS NULL
+		AND ` + quality + ` < $1` + scope + `
+		GROUP BY je.id
+		ORDER BY ` + quality + ` ASC, je.created_at DESC
+		LIMIT $2`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list low quality entries: %w", err)
+	}
+	defer rows.Close()
+
+	return s.scanEntries(rows)
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListLowQuality(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+
+	mock.ExpectQuery(`AND \(je.processed_data->'metadata'->>'analysis_quality'\)::float < \$1 GROUP BY je.id ORDER BY \(je.processed_data->'metadata'->>'analysis_quality'\)::float ASC, je.created_at DESC LIMIT \$2`).
+		WithArgs(DefaultLowQualityThreshold, defaultStageListLimit).
+		WillReturnRows(entryRows(mockEntry{ID: "e1", Content: "a long day", ProcessedData: `{"summary":"day","metadata":{"analysis_quality":0.2}}`, CreatedAt: time.Now()}))
+
+	entries, err := service.ListLowQuality(0, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, 0.2, entries[0].ProcessedData.Metadata["analysis_quality"])
+
+	_, err = service.ListLowQuality(1.5, 10)
+	assert.ErrorIs(t, err, ErrValidation)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
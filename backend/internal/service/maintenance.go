@@ -0,0 +1,31 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// CleanOrphanedAssociations deletes journal_collection rows whose entry or
+// collection no longer exists and returns how many were removed. The foreign
+// keys cascade in normal operation; this is a safety net for data imported
+// or edited directly in the database. Orphans belong to no user, so the
+// cleanup is not scoped.
+func (s *JournalService) CleanOrphanedAssociations() (int64, error) {
+	result, err := s.db.Exec(`
+		DELETE FROM journal_collection jc
+		WHERE NOT EXISTS (SELECT 1 FROM journal_entries je WHERE je.id = jc.journal_id)
+		OR NOT EXISTS (SELECT 1 FROM collections c WHERE c.id = jc.collection_id)`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean orphaned associations: %w", err)
+	}
+
+	cleaned, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count cleaned associations: %w", err)
+	}
+
+	if cleaned > 0 {
+		slog.Info("Removed orphaned collection associations", "count", cleaned)
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,25 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanOrphanedAssociations(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+
+	mock.ExpectExec(`DELETE FROM journal_collection jc WHERE NOT EXISTS \(SELECT 1 FROM journal_entries je WHERE je.id = jc.journal_id\) OR NOT EXISTS \(SELECT 1 FROM collections c WHERE c.id = jc.collection_id\)`).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	cleaned, err := service.CleanOrphanedAssociations()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), cleaned)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/models"
+	"github.com/lib/pq"
+)
+
+// mergeSeparator goes between the contents of merged entries
+const mergeSeparator = "\n\n"
+
+// MergeEntries folds the entries in mergeIDs into keepID. The kept entry's
+// content is followed by the merged entries' content, oldest first; it
+// becomes a favorite if any merged entry was one, gains their collections, and
+// is reprocessed in the background. The merged entries are then deleted with
+// their whole version chains.
+//
+// Every ID must be the current version of its entry: merging an older version
+// would drop the edits made after it. A merged entry may not share a version
+// chain with the kept one.
+func (s *JournalService) MergeEntries(keepID string, mergeIDs []string) (*models.JournalEntry, error) {
+	mergeIDs = uniqueIDs(mergeIDs)
+	if len(mergeIDs) == 0 {
+		return nil, Invalidf("merge_ids must name at least one entry")
+	}
+	for _, id := range mergeIDs {
+		if id == keepID {
+			return nil, Invalidf("an entry cannot be merged into itself")
+		}
+	}
+
+	keep, err := s.GetEntry(keepID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureCurrentVersion(keepID); err != nil {
+		return nil, err
+	}
+
+	merged := make([]*models.JournalEntry, 0, len(mergeIDs))
+	for _, id := range mergeIDs {
+		entry, err := s.GetEntry(id)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.ensureCurrentVersion(id); err != nil {
+			return nil, err
+		}
+		merged = append(merged, entry)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].CreatedAt.Before(merged[j].CreatedAt)
+	})
+
+	parts := []string{keep.Content}
+	isFavorite := keep.IsFavorite
+	for _, entry := range merged {
+		parts = append(parts, entry.Content)
+		isFavorite = isFavorite || entry.IsFavorite
+	}
+	content := strings.Join(parts, mergeSeparator)
+
+	processedJSON, err := json.Marshal(placeholderProcessedData())
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal processed data: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Collect every version of the merged entries, refusing chains that
+	// include the kept entry
+	var deleted []string
+	for _, entry := range merged {
+		rows, err := tx.Query(versionChainCTE+" SELECT id FROM versions", entry.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load version chain: %w", err)
+		}
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if id == keepID {
+				rows.Close()
+				return nil, Conflictf("entry %s is a version of %s and cannot be merged into it", entry.ID, keepID)
+			}
+			deleted = append(deleted, id)
+		}
+		rows.Close()
+	}
+
+	now := time.Now()
+	_, err = tx.Exec(`
+		UPDATE journal_entries
+		SET content = $1,
+		    processed_data = $2,
+		    embedding = NULL,
+		    is_favorite = $3,
+		    updated_at = $4,
+		    processing_stage = $5,
+		    processing_started_at = $4,
+		    processing_completed_at = NULL,
+		    processing_error = NULL,
+		    ts_config = $6
+		WHERE id = $7`,
+		content, processedJSON, isFavorite, now, models.StageCreated, detectTSConfig(content), keepID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update kept entry: %w", err)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO journal_collection (journal_id, collection_id)
+		SELECT DISTINCT $1::uuid, collection_id FROM journal_collection WHERE journal_id = ANY($2)
+		ON CONFLICT DO NOTHING`,
+		keepID, pq.Array(mergeIDs),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move collection associations: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM journal_entries WHERE id = ANY($1)", pq.Array(deleted)); err != nil {
+		return nil, fmt.Errorf("failed to delete merged entries: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit merge: %w", err)
+	}
+
+	s.logger.LogInfo(keepID, models.StageCreated, "Entries merged", map[string]interface{}{
+		"merged_ids":     mergeIDs,
+		"content_length": len(content),
+	})
+
+	result, err := s.GetEntry(keepID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.sendEvent(events.EventEntryUpdated, keepID, map[string]interface{}{
+		"entry":      result,
+		"merged_ids": mergeIDs,
+	})
+	for _, id := range deleted {
+		s.sendEvent(events.EventEntryDeleted, id, map[string]interface{}{
+			"merged_into": keepID,
+		})
+	}
+
+	go s.processEntry(keepID, content)
+
+	return result, nil
+}
+
+// ensureCurrentVersion rejects an entry that has been superseded by an edit
+func (s *JournalService) ensureCurrentVersion(id string) error {
+	var newer string
+	err := s.db.QueryRow(
+		"SELECT COALESCE((SELECT id::text FROM journal_entries WHERE original_entry_id = $1 LIMIT 1), '')",
+		id,
+	).Scan(&newer)
+	if err != nil {
+		return fmt.Errorf("failed to check entry version: %w", err)
+	}
+	if newer != "" {
+		return Conflictf("entry %s has a newer version %s; merge the current version instead", id, newer)
+	}
+	return nil
+}
+
+// uniqueIDs drops empty and repeated IDs, keeping the first occurrence
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
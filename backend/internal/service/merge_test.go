@@ -0,0 +1,47 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeEntriesValidation(t *testing.T) {
+	service := &JournalService{}
+
+	_, err := service.MergeEntries("e1", nil)
+	assert.ErrorIs(t, err, ErrValidation)
+
+	_, err = service.MergeEntries("e1", []string{"", "e1"})
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+func TestMergeEntriesRejectsSupersededVersion(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	now := time.Now()
+
+	mock.ExpectQuery(`WHERE je.id = \$1`).
+		WithArgs("e1").
+		WillReturnRows(entryRows(mockEntry{ID: "e1", Content: "kept", CreatedAt: now}))
+	mock.ExpectQuery(`SELECT COALESCE\(\(SELECT id::text FROM journal_entries WHERE original_entry_id = \$1 LIMIT 1\), ''\)`).
+		WithArgs("e1").
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(""))
+	mock.ExpectQuery(`WHERE je.id = \$1`).
+		WithArgs("e2").
+		WillReturnRows(entryRows(mockEntry{ID: "e2", Content: "old", CreatedAt: now}))
+	mock.ExpectQuery(`original_entry_id = \$1 LIMIT 1`).
+		WithArgs("e2").
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow("e3"))
+
+	_, err := service.MergeEntries("e1", []string{"e2", "e2"})
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Contains(t, err.Error(), "entry e2 has a newer version e3")
+
+	// Nothing was written
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/journal/internal/models"
+)
+
+// GetOnThisDay returns the entries written on the same month and day as date
+// in any year, newest year first. A zero date means today.
+func (s *JournalService) GetOnThisDay(date time.Time) ([]models.JournalEntry, error) {
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	args := []interface{}{int(date.Month()), date.Day()}
+	scope, scopeArgs := s.scopeClause("je.user_id", len(args)+1)
+	args = append(args, scopeArgs...)
+
+	query := `
+		SELECT` + entryColumns + `
+		FROM journal_entries je
+		LEFT JOIN journal_collection jc ON je.id = jc.journal_id
+		WHERE EXTRACT(MONTH FROM je.created_at) = $1
+			AND EXTRACT(DAY FROM je.created_at) = $2
+			AND je.deleted_at IS NULL` + scope + `
+		GROUP BY je.id
+		ORDER BY EXTRACT(YEAR FROM je.created_at) DESC, je.created_at DESC`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entries for this day: %w", err)
+	}
+	defer rows.Close()
+
+	return s.scanEntries(rows)
+}
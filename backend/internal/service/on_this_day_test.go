@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOnThisDay(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	lastYear := time.Date(2023, 7, 4, 9, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`WHERE EXTRACT\(MONTH FROM je.created_at\) = \$1 AND EXTRACT\(DAY FROM je.created_at\) = \$2 AND je.deleted_at IS NULL GROUP BY je.id ORDER BY EXTRACT\(YEAR FROM je.created_at\) DESC`).
+		WithArgs(7, 4).
+		WillReturnRows(entryRows(mockEntry{ID: "e1", Content: "fireworks", CreatedAt: lastYear}))
+
+	entries, err := service.GetOnThisDay(time.Date(2024, 7, 4, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "e1", entries[0].ID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
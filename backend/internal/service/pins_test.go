@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTogglePin(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database, broadcaster: events.NewBroadcaster()}
+	now := time.Now()
+
+	mock.ExpectQuery(`UPDATE journal_entries SET pinned_at = CASE WHEN pinned_at IS NULL THEN NOW\(\) ELSE NULL END WHERE id = \$1 RETURNING pinned_at`).
+		WithArgs("e1").
+		WillReturnRows(sqlmock.NewRows([]string{"pinned_at"}).AddRow(now))
+	mock.ExpectQuery(`UPDATE journal_entries SET pinned_at`).
+		WithArgs("e1").
+		WillReturnRows(sqlmock.NewRows([]string{"pinned_at"}).AddRow(nil))
+
+	pinnedAt, err := service.TogglePin("e1")
+	require.NoError(t, err)
+	require.NotNil(t, pinnedAt)
+	assert.True(t, now.Equal(*pinnedAt))
+
+	pinnedAt, err = service.TogglePin("e1")
+	require.NoError(t, err)
+	assert.Nil(t, pinnedAt)
+
+	mock.ExpectQuery(`UPDATE journal_entries SET pinned_at`).
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"pinned_at"}))
+
+	_, err = service.TogglePin("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClassicSearchSortOrder(t *testing.T) {
+	service := &JournalService{}
+
+	query, _, err := service.classicSearchQuery(SearchParams{})
+	require.NoError(t, err)
+	assert.Contains(t, query, "ORDER BY je.pinned_at DESC NULLS LAST, je.created_at DESC, je.id DESC")
+
+	query, _, err = service.classicSearchQuery(SearchParams{Sort: "newest"})
+	require.NoError(t, err)
+	assert.Contains(t, query, "ORDER BY je.created_at DESC, je.id DESC")
+	assert.NotContains(t, query, "pinned_at DESC")
+
+	_, _, err = service.classicSearchQuery(SearchParams{Sort: "oldest"})
+	assert.ErrorIs(t, err, ErrValidation)
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/journal/internal/models"
+)
+
+// Vector searches pull this many candidates per requested result from the
+// ivfflat indexes before scoring them exactly. The surplus covers candidates
+// that appear in both the entry and chunk lists or that filters drop. Explore
+// mode samples mid-range matches, so it draws on a wider pool.
+const (
+	vectorCandidateFactor  = 4
+	exploreCandidateFactor = 10
+)
+
+// candidateFactor returns the candidate multiplier for a semantic mode
+func candidateFactor(mode string) int {
+	if mode == "explore" {
+		return exploreCandidateFactor
+	}
+	return vectorCandidateFactor
+}
+
+// ivfflatLists is the lists setting of the embedding indexes. Probing that
+// many lists visits every one, which makes the search exact, so larger probe
+// counts only cost time.
+const ivfflatLists = 100
+
+// vectorProbes resolves the ivfflat probe count for a search: the request's
+// value, else the configured default. 0 leaves the Postgres default of 1.
+//
+// Each probe scans one more of the index's lists, so recall and latency both
+// grow with the count: 1 is fastest but can miss close matches that fall in a
+// neighbouring list, around 10 (sqrt of lists) is the usual balance, and
+// ivfflatLists is an exact scan. It applies to the index scans that pick the
+// candidates of VectorSearch and GetRelatedEntries.
+func (s *JournalService) vectorProbes(params SearchParams) (int, error) {
+	probes := params.Probes
+	if probes == 0 {
+		probes = s.config.VectorProbes
+	}
+	if probes < 0 {
+		return 0, Invalidf("probes must not be negative")
+	}
+	if probes > ivfflatLists {
+		probes = ivfflatLists
+	}
+	return probes, nil
+}
+
+// queryWithSimilarity runs a vector search query and scans its rows. With a
+// probe count it runs in a transaction so SET LOCAL ivfflat.probes applies to
+// this query only, not to whichever request next uses the pooled connection.
+func (s *JournalService) queryWithSimilarity(probes int, query string, args ...interface{}) ([]models.JournalEntry, error) {
+	if probes == 0 {
+		rows, err := s.db.Query(query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to perform vector search: %w", err)
+		}
+		defer rows.Close()
+		return s.scanEntriesWithSimilarity(rows)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// SET does not take bind parameters; probes is a validated integer
+	if _, err := tx.Exec(fmt.Sprintf("SET LOCAL ivfflat.probes = %d", probes)); err != nil {
+		return nil, fmt.Errorf("failed to set ivfflat probes: %w", err)
+	}
+
+	entries, err := func() ([]models.JournalEntry, error) {
+		rows, err := tx.Query(query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to perform vector search: %w", err)
+		}
+		defer rows.Close()
+		return s.scanEntriesWithSimilarity(rows)
+	}()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit vector search: %w", err)
+	}
+	return entries, nil
+}
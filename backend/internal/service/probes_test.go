@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/ollama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVectorSearchSetsProbesInTransaction(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	embeddings := embeddingServer(embeddingDimensions)
+	defer embeddings.Close()
+
+	service := (&JournalService{
+		db:        database,
+		processor: ollama.NewProcessor(ollama.NewClient(embeddings.URL)),
+	}).WithConfig(Config{VectorProbes: 5})
+
+	// The request overrides the configured default
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL ivfflat.probes = 20`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`ORDER BY je.embedding <=> \$1 LIMIT \$2\) .* ORDER BY similarity DESC LIMIT \$3`).
+		WithArgs(sqlmock.AnyArg(), 10*vectorCandidateFactor, 10).
+		WillReturnRows(similarityRows())
+	mock.ExpectCommit()
+
+	_, err := service.VectorSearch(SearchParams{Query: "q", Limit: 10, Probes: 20})
+	require.NoError(t, err)
+
+	// Without one the configured default applies
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL ivfflat.probes = 5`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`ORDER BY similarity DESC`).
+		WillReturnRows(similarityRows())
+	mock.ExpectCommit()
+
+	_, err = service.VectorSearch(SearchParams{Query: "q"})
+	require.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVectorProbes(t *testing.T) {
+	service := &JournalService{}
+
+	probes, err := service.vectorProbes(SearchParams{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, probes, "no transaction without a probe count")
+
+	probes, err = service.vectorProbes(SearchParams{Probes: 500})
+	require.NoError(t, err)
+	assert.Equal(t, ivfflatLists, probes)
+
+	_, err = service.vectorProbes(SearchParams{Probes: -1})
+	assert.ErrorIs(t, err, ErrValidation)
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultProcessingTimeout bounds an entry's whole pipeline (analysis, URL
+// fetching and embeddings) when Config.ProcessingTimeout is unset
+const DefaultProcessingTimeout = 5 * time.Minute
+
+// urlFetchTimeout bounds a single MCP fetch within the pipeline
+const urlFetchTimeout = 30 * time.Second
+
+// ErrProcessingTimeout is recorded on an entry whose pipeline ran past the
+// processing deadline
+var ErrProcessingTimeout = errors.New("processing timeout")
+
+func (s *JournalService) processingTimeout() time.Duration {
+	if s.config.ProcessingTimeout > 0 {
+		return s.config.ProcessingTimeout
+	}
+	return DefaultProcessingTimeout
+}
+
+// processingContext returns the context an entry's pipeline runs under. It
+// is cancelled once the processing deadline passes, which aborts in-flight
+// model requests and URL fetches.
+func (s *JournalService) processingContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), s.processingTimeout())
+}
+
+// processingError reports err as ErrProcessingTimeout when the pipeline's
+// deadline caused it, so the entry records why it stopped rather than
+// whichever request happened to be cut off
+func (s *JournalService) processingError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s", ErrProcessingTimeout, s.processingTimeout())
+	}
+	return err
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/logger"
+	"github.com/journal/internal/models"
+	"github.com/journal/internal/ollama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessEntryFailsAfterProcessingTimeout(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	// Accepts the analysis request but never answers it. The handler is
+	// released at the end so the server can shut down.
+	release := make(chan struct{})
+	hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer hung.Close()
+	defer close(release)
+
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	service := (&JournalService{
+		db:          database,
+		processor:   ollama.NewProcessor(ollama.NewClient(hung.URL)),
+		broadcaster: broadcaster,
+		logger:      logger.NewProcessingLogger(database.DB),
+	}).WithConfig(Config{ProcessingTimeout: 50 * time.Millisecond})
+
+	mock.ExpectExec(`SET processing_stage = \$1, processing_error = \$2`).
+		WithArgs(models.StageFailed, "processing timeout after 50ms", sqlmock.AnyArg(), "e1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	done := make(chan struct{})
+	go func() {
+		service.processEntry("e1", "a day that never gets analyzed")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("processing did not stop at the deadline")
+	}
+
+	var failed *events.Event
+	for _, e := range recorded() {
+		if e.Type == string(events.EventEntryFailed) {
+			failed = e
+		}
+	}
+	require.NotNil(t, failed)
+	data := failed.Data.(map[string]interface{})
+	assert.Equal(t, "processing timeout after 50ms", data["error"])
+	assert.Equal(t, models.StageAnalyzing, data["stage"])
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestProcessingTimeoutDefault(t *testing.T) {
+	assert.Equal(t, DefaultProcessingTimeout, (&JournalService{}).processingTimeout())
+	assert.Equal(t, time.Minute, (&JournalService{config: Config{ProcessingTimeout: time.Minute}}).processingTimeout())
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/journal/internal/models"
+)
+
+// maxExpansionTerms caps the related terms ORed into an expanded query
+const maxExpansionTerms = 8
+
+// expandQuery returns related terms for a classic search query: the
+// entities and topics the entry model extracts from it, so "my SF trip" also
+// matches entries that say "San Francisco". It costs one model call, which
+// is why it only runs when SearchParams.Expand is set. When the model is
+// unavailable the query is searched as typed.
+func (s *JournalService) expandQuery(query string) []string {
+	if s.processor == nil {
+		return []string{}
+	}
+
+	analysis, err := s.processor.ProcessJournalEntry(context.Background(), query)
+	if err != nil {
+		slog.Warn("Failed to expand query, searching it unexpanded", "error", err)
+		return []string{}
+	}
+
+	terms := expansionTerms(query, analysis)
+	slog.Debug("Expanded search query", "query", query, "terms", terms)
+	return terms
+}
+
+// expansionTerms lists the entities, then topics, of the query's analysis
+// that the query does not already say, ignoring case. The result is never
+// nil so an expanded search that found no terms is distinguishable from an
+// unexpanded one.
+func expansionTerms(query string, analysis *models.ProcessedData) []string {
+	terms := []string{}
+	words := " " + strings.Join(queryWord.FindAllString(strings.ToLower(query), -1), " ") + " "
+	seen := make(map[string]bool)
+
+	for _, candidate := range append(append([]string{}, analysis.Entities...), analysis.Topics...) {
+		phrase := strings.Join(queryWord.FindAllString(strings.ToLower(candidate), -1), " ")
+		if phrase == "" || seen[phrase] || strings.Contains(words, " "+phrase+" ") {
+			continue
+		}
+		seen[phrase] = true
+		terms = append(terms, strings.TrimSpace(candidate))
+		if len(terms) == maxExpansionTerms {
+			break
+		}
+	}
+	return terms
+}
+
+// markExpansion records in metadata["expanded_terms"] the terms a search
+// was expanded with
+func markExpansion(entries []models.JournalEntry, terms []string) {
+	for i := range entries {
+		if entries[i].ProcessedData.Metadata == nil {
+			entries[i].ProcessedData.Metadata = make(map[string]any)
+		}
+		entries[i].ProcessedData.Metadata["expanded_terms"] = terms
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/journal/internal/models"
+	"github.com/journal/internal/ollama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpansionTerms(t *testing.T) {
+	analysis := &models.ProcessedData{
+		Entities: []string{"San Francisco", "SF", "san francisco"},
+		Topics:   []string{"travel", "Trip", ""},
+	}
+
+	// Terms the query already says, and repeats, add nothing
+	assert.Equal(t, []string{"San Francisco", "travel"}, expansionTerms("my SF trip", analysis))
+	assert.Equal(t, []string{}, expansionTerms("travel", &models.ProcessedData{Topics: []string{"Travel"}}))
+}
+
+func TestClassicSearchExpandsQuery(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	analysis := `{"summary":"","entities":["San Francisco"],"topics":["travel"],"sentiment":"neutral","urls":[]}`
+	ollamaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ollama.ChatResponse{Done: true, Message: ollama.Message{Content: analysis}})
+	}))
+	defer ollamaServer.Close()
+
+	service := &JournalService{
+		db:        database,
+		processor: ollama.NewProcessor(ollama.NewClient(ollamaServer.URL)),
+	}
+
+	mock.ExpectQuery(`AND je.tsv @@ \(websearch_to_tsquery\(je.ts_config, \$1\) \|\| plainto_tsquery\(je.ts_config, \$2\) \|\| plainto_tsquery\(je.ts_config, \$3\)\)`).
+		WithArgs("my SF trip", "San Francisco", "travel").
+		WillReturnRows(entryRows(mockEntry{ID: "e1", Content: "Back from San Francisco", ProcessedData: `{}`, CreatedAt: time.Now()}))
+
+	entries, err := service.ClassicSearch(SearchParams{Query: "my SF trip", Expand: true})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []string{"San Francisco", "travel"}, entries[0].ProcessedData.Metadata["expanded_terms"])
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClassicSearchExpansionFallsBackWhenModelFails(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	ollamaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusInternalServerError)
+	}))
+	defer ollamaServer.Close()
+
+	service := &JournalService{
+		db:        database,
+		processor: ollama.NewProcessor(ollama.NewClient(ollamaServer.URL)),
+	}
+
+	mock.ExpectQuery(`AND je.tsv @@ websearch_to_tsquery\(je.ts_config, \$1\) GROUP BY`).
+		WithArgs("my SF trip").
+		WillReturnRows(entryRows(mockEntry{ID: "e1", Content: "SF trip", ProcessedData: `{}`, CreatedAt: time.Now()}))
+
+	entries, err := service.ClassicSearch(SearchParams{Query: "my SF trip", Expand: true})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []string{}, entries[0].ProcessedData.Metadata["expanded_terms"])
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
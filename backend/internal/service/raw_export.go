@@ -0,0 +1,119 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RawExportContentType is the content type of a raw newline-delimited JSON
+// backup
+const RawExportContentType = "application/x-ndjson"
+
+// RawEntry is one line of a raw backup: the user-written fields of an entry
+// without any processed data, so a backup stays small and re-importable
+type RawEntry struct {
+	ID         string    `json:"id"`
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	IsFavorite bool      `json:"is_favorite"`
+}
+
+// StreamRawExport writes every entry as one JSON object per line, oldest
+// first. Unlike StreamExport there is no entry cap: rows are encoded as they
+// are read, so memory stays flat however large the journal is. When w is an
+// http.Flusher the output is flushed every exportFlushInterval entries.
+func (s *JournalService) StreamRawExport(w io.Writer) error {
+	scope, args := s.scopeClause("user_id", 1)
+	query := `
+		SELECT id, content, created_at, updated_at, is_favorite
+		FROM journal_entries
+		WHERE deleted_at IS NULL` + scope + `
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to read entries: %w", err)
+	}
+	defer rows.Close()
+
+	flusher, _ := w.(http.Flusher)
+	buf := bufio.NewWriter(w)
+	enc := json.NewEncoder(buf)
+
+	count := 0
+	for rows.Next() {
+		var entry RawEntry
+		if err := rows.Scan(&entry.ID, &entry.Content, &entry.CreatedAt, &entry.UpdatedAt, &entry.IsFavorite); err != nil {
+			return fmt.Errorf("failed to scan entry: %w", err)
+		}
+		// Encode terminates each object with a newline
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+		count++
+
+		if flusher != nil && count%exportFlushInterval == 0 {
+			if err := buf.Flush(); err != nil {
+				return err
+			}
+			flusher.Flush()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read entries: %w", err)
+	}
+
+	return buf.Flush()
+}
+
+// IsRawImport reports whether data looks like a raw JSON lines backup rather
+// than Markdown
+func IsRawImport(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("{"))
+}
+
+// ParseRawImport reads a backup written by StreamRawExport. Blank lines are
+// ignored; every other line is one item, reported with the reason it cannot
+// be imported when it does not decode or has no content or date. IDs are not
+// reused, since they may collide with entries already in the journal.
+func ParseRawImport(r io.Reader) ([]ImportedEntry, error) {
+	var entries []ImportedEntry
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		entry := ImportedEntry{Line: lineNumber}
+		var raw RawEntry
+		switch err := json.Unmarshal(line, &raw); {
+		case err != nil:
+			entry.Err = fmt.Errorf("invalid JSON: %w", err)
+		case strings.TrimSpace(raw.Content) == "":
+			entry.Err = fmt.Errorf("%w: content is empty", errNothingToImport)
+		case raw.CreatedAt.IsZero():
+			entry.Err = fmt.Errorf("created_at is missing")
+		default:
+			entry.Content = raw.Content
+			entry.CreatedAt = raw.CreatedAt
+			entry.IsFavorite = raw.IsFavorite
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read import: %w", err)
+	}
+
+	return entries, nil
+}
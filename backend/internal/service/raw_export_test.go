@@ -0,0 +1,82 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamRawExportRoundTripsThroughImport(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	created := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+	updated := created.Add(time.Hour)
+
+	mock.ExpectQuery(`SELECT id, content, created_at, updated_at, is_favorite\s+FROM journal_entries\s+WHERE deleted_at IS NULL\s+ORDER BY created_at ASC, id ASC`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "created_at", "updated_at", "is_favorite"}).
+			AddRow("e1", "first\nline", created, updated, true).
+			AddRow("e2", "second", updated, updated, false))
+
+	var buf bytes.Buffer
+	require.NoError(t, service.StreamRawExport(&buf))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	assert.JSONEq(t, `{"id":"e1","content":"first\nline","created_at":"2024-03-01T08:30:00Z","updated_at":"2024-03-01T09:30:00Z","is_favorite":true}`, lines[0])
+
+	assert.True(t, IsRawImport(buf.Bytes()))
+	entries, err := ParseRawImport(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, []ImportedEntry{
+		{Content: "first\nline", CreatedAt: created, IsFavorite: true, Line: 1},
+		{Content: "second", CreatedAt: updated, Line: 2},
+	}, entries)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStreamRawExportScopesToUser(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{db: database}).WithConfig(Config{MultiTenant: true}).ForUser("alice")
+
+	mock.ExpectQuery(`WHERE deleted_at IS NULL AND user_id = \$1`).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "created_at", "updated_at", "is_favorite"}))
+
+	var buf bytes.Buffer
+	require.NoError(t, service.StreamRawExport(&buf))
+	assert.Empty(t, buf.String())
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestParseRawImportSkipsInvalidLines(t *testing.T) {
+	input := `{"content":"kept","created_at":"2024-01-02T00:00:00Z"}
+
+not json
+{"content":"   ","created_at":"2024-01-02T00:00:00Z"}
+{"content":"no date"}
+`
+	entries, err := ParseRawImport(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	assert.NoError(t, entries[0].Err)
+	assert.Equal(t, "kept", entries[0].Content)
+
+	assert.Equal(t, 3, entries[1].Line)
+	assert.ErrorContains(t, entries[1].Err, "invalid JSON")
+	assert.ErrorIs(t, entries[2].Err, errNothingToImport)
+	assert.Equal(t, 5, entries[3].Line)
+	assert.EqualError(t, entries[3].Err, "created_at is missing")
+
+	assert.False(t, IsRawImport([]byte("---\ndate: 2024-01-02\n---\nhello")))
+}
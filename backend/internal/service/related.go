@@ -0,0 +1,97 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/models"
+)
+
+// maxSuggestedEntries caps the related entries suggested for a new entry
+const maxSuggestedEntries = 3
+
+// GetRelatedEntries returns the entries semantically closest to entryID,
+// using its stored embedding so no new Ollama call is needed. Other versions
+// of the same entry are excluded.
+func (s *JournalService) GetRelatedEntries(entryID string, limit int) ([]models.JournalEntry, error) {
+	if limit <= 0 {
+		limit = 5
+	}
+	limit = s.clampLimit(limit)
+
+	scope, scopeArgs := s.scopeClause("user_id", 2)
+	var hasEmbedding bool
+	err := s.db.QueryRow(
+		"SELECT embedding IS NOT NULL FROM journal_entries WHERE id = $1 AND deleted_at IS NULL"+scope,
+		append([]interface{}{entryID}, scopeArgs...)...,
+	).Scan(&hasEmbedding)
+	if err == sql.ErrNoRows {
+		return nil, NotFoundf("entry not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entry: %w", err)
+	}
+	if !hasEmbedding {
+		return nil, Invalidf("entry has no embedding yet")
+	}
+
+	probes, err := s.vectorProbes(SearchParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	args := []interface{}{entryID}
+	entryScope, entryScopeArgs := s.scopeClause("je.user_id", len(args)+1)
+	args = append(args, entryScopeArgs...)
+	args = append(args, limit)
+
+	// The nearest entries are found by raw distance to the entry's stored
+	// vector, which the ivfflat index serves, before being scored
+	metric := s.metric()
+	query := versionChainCTE + `,
+		target AS (
+			SELECT embedding FROM journal_entries WHERE id = $1
+		),
+		nearest AS (
+			SELECT je.id FROM journal_entries je
+			WHERE je.embedding IS NOT NULL AND je.deleted_at IS NULL
+				AND je.id NOT IN (SELECT id FROM versions)` + entryScope + `
+			ORDER BY ` + metric.distance("je.embedding", "(SELECT embedding FROM target)") + `
+			LIMIT ` + fmt.Sprintf("$%d", len(args)) + `
+		)
+		SELECT` + entryColumns + `,
+			` + metric.similarity("je.embedding", "(SELECT embedding FROM target)") + ` as similarity
+		FROM nearest n
+		JOIN journal_entries je ON je.id = n.id
+		LEFT JOIN journal_collection jc ON je.id = jc.journal_id
+		GROUP BY je.id
+		ORDER BY similarity DESC`
+
+	return s.queryWithSimilarity(probes, query, args...)
+}
+
+// suggestRelatedEntries broadcasts an entry.related event listing the
+// entries closest to a newly processed one, so clients can show what was
+// written about the subject before. Entries left without an embedding
+// (failed, cancelled or completed without one) get no suggestions, and no
+// event is sent when nothing is related.
+func (s *JournalService) suggestRelatedEntries(entryID string) {
+	related, err := s.GetRelatedEntries(entryID, maxSuggestedEntries)
+	if errors.Is(err, ErrValidation) || errors.Is(err, ErrNotFound) {
+		return
+	}
+	if err != nil {
+		slog.Warn("Failed to find related entries", "entry_id", entryID, "error", err)
+		return
+	}
+	if len(related) == 0 {
+		return
+	}
+
+	s.sendEvent(events.EventEntryRelated, entryID, map[string]interface{}{
+		"related": related,
+	})
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRelatedEntries(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT embedding IS NOT NULL FROM journal_entries WHERE id = \$1`).
+		WithArgs("e1").
+		WillReturnRows(sqlmock.NewRows([]string{"has_embedding"}).AddRow(true))
+
+	// Candidates are ordered by raw distance so the ivfflat index serves them
+	mock.ExpectQuery(`AND je.id NOT IN \(SELECT id FROM versions\) ORDER BY je.embedding <=> \(SELECT embedding FROM target\) LIMIT \$2 \) SELECT .* FROM nearest n .* ORDER BY similarity DESC`).
+		WithArgs("e1", 5).
+		WillReturnRows(similarityRows(mockEntry{ID: "e2", Content: "related", CreatedAt: now, Similarity: 0.82}))
+
+	related, err := service.GetRelatedEntries("e1", 0)
+	require.NoError(t, err)
+	require.Len(t, related, 1)
+	assert.Equal(t, "e2", related[0].ID)
+	assert.InDelta(t, 0.82, related[0].ProcessedData.Metadata["similarity_score"], 1e-6)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRelatedEntriesWithoutEmbedding(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+
+	mock.ExpectQuery(`SELECT embedding IS NOT NULL FROM journal_entries WHERE id = \$1`).
+		WithArgs("e1").
+		WillReturnRows(sqlmock.NewRows([]string{"has_embedding"}).AddRow(false))
+
+	_, err := service.GetRelatedEntries("e1", 5)
+	assert.EqualError(t, err, "entry has no embedding yet")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRelatedEntriesSetsProbes(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{db: database}).WithConfig(Config{VectorProbes: 10})
+
+	mock.ExpectQuery(`SELECT embedding IS NOT NULL FROM journal_entries WHERE id = \$1`).
+		WithArgs("e1").
+		WillReturnRows(sqlmock.NewRows([]string{"has_embedding"}).AddRow(true))
+	mock.ExpectBegin()
+	mock.ExpectExec(`SET LOCAL ivfflat.probes = 10`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`FROM nearest n`).
+		WithArgs("e1", 5).
+		WillReturnRows(similarityRows())
+	mock.ExpectCommit()
+
+	_, err := service.GetRelatedEntries("e1", 5)
+	require.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSuggestRelatedEntriesSendsEvent(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	service := &JournalService{db: database, broadcaster: broadcaster}
+
+	mock.ExpectQuery(`SELECT embedding IS NOT NULL FROM journal_entries WHERE id = \$1`).
+		WithArgs("new").
+		WillReturnRows(sqlmock.NewRows([]string{"has_embedding"}).AddRow(true))
+	mock.ExpectQuery(`FROM nearest n`).
+		WithArgs("new", maxSuggestedEntries).
+		WillReturnRows(similarityRows(
+			mockEntry{ID: "e2", Content: "the same trip last year", Similarity: 0.9},
+			mockEntry{ID: "e3", Content: "packing list", Similarity: 0.7},
+		))
+
+	service.suggestRelatedEntries("new")
+
+	sent := recorded()
+	require.Len(t, sent, 1)
+	assert.Equal(t, string(events.EventEntryRelated), sent[0].Type)
+	assert.Equal(t, "new", sent[0].EntryID)
+	related := sent[0].Data.(map[string]interface{})["related"].([]models.JournalEntry)
+	require.Len(t, related, 2)
+	assert.Equal(t, "e2", related[0].ID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSuggestRelatedEntriesSkipsEntryWithoutEmbedding(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	service := &JournalService{db: database, broadcaster: broadcaster}
+
+	// A failed entry has no embedding to compare
+	mock.ExpectQuery(`SELECT embedding IS NOT NULL FROM journal_entries WHERE id = \$1`).
+		WithArgs("new").
+		WillReturnRows(sqlmock.NewRows([]string{"has_embedding"}).AddRow(false))
+
+	service.suggestRelatedEntries("new")
+
+	assert.Empty(t, recorded())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
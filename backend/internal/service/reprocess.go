@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/models"
+)
+
+// reprocessWorkers bounds how many entries ReprocessAll runs through the
+// pipeline at once, so a full-corpus run does not flood the model
+const reprocessWorkers = 2
+
+// ReprocessFilter selects the entries ReprocessAll re-runs. The zero value
+// selects every entry.
+type ReprocessFilter struct {
+	StartDate *time.Time             `json:"start_date,omitempty"`
+	EndDate   *time.Time             `json:"end_date,omitempty"`
+	Stage     models.ProcessingStage `json:"stage,omitempty"`
+
+	// ResumeFrom is the StartedAt of an interrupted or cancelled run. Entries
+	// that run already started are skipped, so passing it continues where the
+	// run stopped, even across a server restart.
+	ResumeFrom *time.Time `json:"resume_from,omitempty"`
+}
+
+// ReprocessJob describes a started ReprocessAll run
+type ReprocessJob struct {
+	StartedAt time.Time `json:"started_at"`
+	Total     int       `json:"total"`
+}
+
+// reprocessTarget is an entry queued for reprocessing
+type reprocessTarget struct {
+	id      string
+	content string
+}
+
+// reprocessRuns tracks the running ReprocessAll job of each user so it can be
+// cancelled and a second run refused. It is shared by the copies ForUser
+// makes.
+type reprocessRuns struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+func newReprocessRuns() *reprocessRuns {
+	return &reprocessRuns{cancels: make(map[string]context.CancelFunc)}
+}
+
+// ReprocessEntry re-runs the full pipeline (analysis, URL fetching and
+// embeddings) on one entry whatever its stage, replacing its analysis, for
+// example after the analysis prompt changed. Unlike RetryProcessing it is not
+// limited to failed or stuck entries, and it is logged as a reprocess rather
+// than a retry.
+func (s *JournalService) ReprocessEntry(entryID string) error {
+	if s.processor == nil {
+		return Unavailablef("reprocessing is unavailable: no AI processor configured")
+	}
+
+	entry, err := s.GetEntry(entryID)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Reprocessing entry on request", "entry_id", entryID, "previous_stage", entry.ProcessingStage)
+	return s.restartProcessing(entry, "Reprocessing entry")
+}
+
+// ReprocessAll re-runs the full pipeline (analysis, URL fetching and
+// embeddings) over every entry matching filter, for example after changing
+// the analysis prompt or model. Entries are processed oldest first by a
+// bounded pool of workers in the background; each finished entry broadcasts
+// a reprocess.progress event with current and total, and the run ends with
+// reprocess.completed. Only one run per user may be active at a time.
+func (s *JournalService) ReprocessAll(filter ReprocessFilter) (*ReprocessJob, error) {
+	if filter.Stage != "" && !filter.Stage.Valid() {
+		return nil, Invalidf("unknown processing stage %q", filter.Stage)
+	}
+	if s.processor == nil {
+		return nil, Unavailablef("reprocessing is unavailable: no AI processor configured")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if !s.reprocess.start(s.userID, cancel) {
+		cancel()
+		return nil, Conflictf("a reprocess run is already in progress")
+	}
+
+	job := &ReprocessJob{StartedAt: time.Now()}
+	targets, err := s.reprocessTargets(filter)
+	if err != nil {
+		s.reprocess.finish(s.userID)
+		cancel()
+		return nil, err
+	}
+	job.Total = len(targets)
+
+	slog.Info("Reprocessing entries", "total", job.Total)
+	go func() {
+		defer s.reprocess.finish(s.userID)
+		defer cancel()
+		s.runReprocess(ctx, job, targets, s.reprocessEntry)
+	}()
+
+	return job, nil
+}
+
+// CancelReprocess stops the current user's ReprocessAll run. Entries already
+// being processed finish; the rest are left for a resumed run.
+func (s *JournalService) CancelReprocess() error {
+	if !s.reprocess.cancel(s.userID) {
+		return NotFoundf("no reprocess run is in progress")
+	}
+	return nil
+}
+
+// reprocessTargets lists the entries matching filter, oldest first
+func (s *JournalService) reprocessTargets(filter ReprocessFilter) ([]reprocessTarget, error) {
+	query := "SELECT id, content FROM journal_entries WHERE deleted_at IS NULL"
+	args := []interface{}{}
+
+	if filter.StartDate != nil {
+		args = append(args, *filter.StartDate)
+		query += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if filter.EndDate != nil {
+		args = append(args, *filter.EndDate)
+		query += fmt.Sprintf(" AND created_at <= $%d", len(args))
+	}
+	if filter.Stage != "" {
+		args = append(args, filter.Stage)
+		query += fmt.Sprintf(" AND processing_stage = $%d", len(args))
+	}
+	if filter.ResumeFrom != nil {
+		args = append(args, *filter.ResumeFrom)
+		query += fmt.Sprintf(" AND (processing_started_at IS NULL OR processing_started_at < $%d)", len(args))
+	}
+
+	scope, scopeArgs := s.scopeClause("user_id", len(args)+1)
+	query += scope + " ORDER BY created_at ASC, id ASC"
+	args = append(args, scopeArgs...)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list entries to reprocess: %w", err)
+	}
+	defer rows.Close()
+
+	targets := []reprocessTarget{}
+	for rows.Next() {
+		var t reprocessTarget
+		if err := rows.Scan(&t.id, &t.content); err != nil {
+			return nil, fmt.Errorf("failed to scan entry: %w", err)
+		}
+		targets = append(targets, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read entries: %w", err)
+	}
+	return targets, nil
+}
+
+// runReprocess feeds targets to reprocessWorkers workers running process,
+// broadcasting progress after each entry, until all are done or ctx is
+// cancelled
+func (s *JournalService) runReprocess(ctx context.Context, job *ReprocessJob, targets []reprocessTarget, process func(reprocessTarget)) {
+	queue := make(chan reprocessTarget)
+	var mu sync.Mutex
+	current := 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < reprocessWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range queue {
+				process(t)
+
+				mu.Lock()
+				current++
+				s.sendEvent(events.EventReprocessProgress, t.id, map[string]interface{}{
+					"current":    current,
+					"total":      job.Total,
+					"started_at": job.StartedAt,
+				})
+				mu.Unlock()
+			}
+		}()
+	}
+
+	cancelled := false
+feed:
+	for _, t := range targets {
+		select {
+		case queue <- t:
+		case <-ctx.Done():
+			cancelled = true
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	slog.Info("Reprocess run finished", "current", current, "total", job.Total, "cancelled", cancelled)
+	s.sendEvent(events.EventReprocessCompleted, "", map[string]interface{}{
+		"current":    current,
+		"total":      job.Total,
+		"started_at": job.StartedAt,
+		"cancelled":  cancelled,
+	})
+}
+
+// reprocessEntry resets an entry's processing state and runs the pipeline on
+// it synchronously
+func (s *JournalService) reprocessEntry(t reprocessTarget) {
+	_, err := s.db.Exec(`
+		UPDATE journal_entries
+		SET processing_stage = $1,
+		    processing_started_at = $2,
+		    processing_completed_at = NULL,
+		    processing_error = NULL
+		WHERE id = $3`,
+		models.StageCreated, time.Now(), t.id,
+	)
+	if err != nil {
+		slog.Error("Failed to reset entry for reprocessing", "entry_id", t.id, "error", err)
+		return
+	}
+
+	s.logger.LogInfo(t.id, models.StageCreated, "Reprocessing entry", nil)
+	s.processEntry(t.id, t.content)
+}
+
+// start records cancel as userID's running job, reporting false when one is
+// already running
+func (r *reprocessRuns) start(userID string, cancel context.CancelFunc) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, running := r.cancels[userID]; running {
+		return false
+	}
+	r.cancels[userID] = cancel
+	return true
+}
+
+func (r *reprocessRuns) finish(userID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.cancels, userID)
+}
+
+func (r *reprocessRuns) cancel(userID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cancel, running := r.cancels[userID]
+	if running {
+		cancel()
+	}
+	return running
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/logger"
+	"github.com/journal/internal/models"
+	"github.com/journal/internal/ollama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReprocessTargetsAppliesFilter(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	resume := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT id, content FROM journal_entries WHERE deleted_at IS NULL AND created_at >= \$1 AND processing_stage = \$2 AND \(processing_started_at IS NULL OR processing_started_at < \$3\) ORDER BY created_at ASC, id ASC`).
+		WithArgs(start, models.StageFailed, resume).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content"}).
+			AddRow("e1", "first").
+			AddRow("e2", "second"))
+
+	targets, err := service.reprocessTargets(ReprocessFilter{StartDate: &start, Stage: models.StageFailed, ResumeFrom: &resume})
+	require.NoError(t, err)
+	assert.Equal(t, []reprocessTarget{{id: "e1", content: "first"}, {id: "e2", content: "second"}}, targets)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// recordEvents collects the events sent through b
+func recordEvents(b *events.Broadcaster) func() []*events.Event {
+	var mu sync.Mutex
+	var recorded []*events.Event
+	b.AddHook(func(e *events.Event) {
+		mu.Lock()
+		recorded = append(recorded, e)
+		mu.Unlock()
+	})
+	return func() []*events.Event {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]*events.Event(nil), recorded...)
+	}
+}
+
+func TestRunReprocessBroadcastsProgress(t *testing.T) {
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	service := &JournalService{broadcaster: broadcaster}
+
+	targets := []reprocessTarget{{id: "e1"}, {id: "e2"}, {id: "e3"}}
+	job := &ReprocessJob{StartedAt: time.Now(), Total: len(targets)}
+
+	var mu sync.Mutex
+	processed := map[string]bool{}
+	service.runReprocess(context.Background(), job, targets, func(t reprocessTarget) {
+		mu.Lock()
+		processed[t.id] = true
+		mu.Unlock()
+	})
+
+	assert.Len(t, processed, 3)
+
+	sent := recorded()
+	require.Len(t, sent, 4)
+	for i, event := range sent[:3] {
+		assert.Equal(t, string(events.EventReprocessProgress), event.Type)
+		assert.Equal(t, i+1, event.Data.(map[string]interface{})["current"])
+		assert.Equal(t, 3, event.Data.(map[string]interface{})["total"])
+	}
+	assert.Equal(t, string(events.EventReprocessCompleted), sent[3].Type)
+	assert.Equal(t, false, sent[3].Data.(map[string]interface{})["cancelled"])
+}
+
+func TestRunReprocessStopsWhenCancelled(t *testing.T) {
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	service := &JournalService{broadcaster: broadcaster}
+
+	targets := make([]reprocessTarget, 20)
+	job := &ReprocessJob{StartedAt: time.Now(), Total: len(targets)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	service.runReprocess(ctx, job, targets, func(reprocessTarget) { cancel() })
+
+	sent := recorded()
+	completed := sent[len(sent)-1]
+	require.Equal(t, string(events.EventReprocessCompleted), completed.Type)
+	assert.Equal(t, true, completed.Data.(map[string]interface{})["cancelled"])
+	assert.Less(t, completed.Data.(map[string]interface{})["current"], len(targets))
+}
+
+func TestReprocessAllAllowsOneRunPerUser(t *testing.T) {
+	service := &JournalService{
+		processor: ollama.NewProcessor(ollama.NewClient("http://localhost:0")),
+		reprocess: newReprocessRuns(),
+	}
+
+	// A run already in progress for this user
+	cancelled := false
+	require.True(t, service.reprocess.start("", func() { cancelled = true }))
+
+	_, err := service.ReprocessAll(ReprocessFilter{})
+	assert.ErrorIs(t, err, ErrConflict)
+
+	require.NoError(t, service.CancelReprocess())
+	assert.True(t, cancelled)
+
+	service.reprocess.finish("")
+	assert.ErrorIs(t, service.CancelReprocess(), ErrNotFound)
+
+	_, err = service.ReprocessAll(ReprocessFilter{Stage: "bogus"})
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+func TestReprocessEntryRunsWhateverTheStage(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	// The model is down, so the reprocessed analysis fails
+	ollamaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusInternalServerError)
+	}))
+	defer ollamaServer.Close()
+
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	service := &JournalService{
+		db:          database,
+		processor:   ollama.NewProcessor(ollama.NewClient(ollamaServer.URL)),
+		broadcaster: broadcaster,
+		logger:      logger.NewProcessingLogger(database.DB),
+	}
+	now := time.Now()
+
+	// RetryProcessing would refuse an entry that only just started analyzing
+	mock.ExpectQuery(`WHERE je.id = \$1`).
+		WithArgs("e1").
+		WillReturnRows(entryRows(mockEntry{ID: "e1", Content: "c", CreatedAt: now, Stage: models.StageAnalyzing, StartedAt: now}))
+	mock.ExpectExec(`SET processing_stage = \$1,\s+processing_started_at = \$2`).
+		WithArgs(models.StageCreated, sqlmock.AnyArg(), "e1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, service.ReprocessEntry("e1"))
+
+	require.Eventually(t, func() bool {
+		for _, e := range recorded() {
+			if e.Type == string(events.EventEntryFailed) {
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+
+	sent := recorded()
+	assert.Equal(t, string(events.EventEntryProcessing), sent[0].Type)
+	assert.Equal(t, "Reprocessing entry", sent[0].Data.(map[string]interface{})["message"])
+}
+
+func TestReprocessEntrySupersedesRunningPipeline(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	ollamaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusInternalServerError)
+	}))
+	defer ollamaServer.Close()
+
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	service := &JournalService{
+		db:          database,
+		processor:   ollama.NewProcessor(ollama.NewClient(ollamaServer.URL)),
+		broadcaster: broadcaster,
+		logger:      logger.NewProcessingLogger(database.DB),
+		processing:  newProcessingRuns(),
+	}
+	now := time.Now()
+
+	// The first pipeline is still analyzing when the reprocess arrives
+	running, done := service.startProcessing("e1")
+	defer done()
+
+	mock.ExpectQuery(`WHERE je.id = \$1`).
+		WithArgs("e1").
+		WillReturnRows(entryRows(mockEntry{ID: "e1", Content: "c", CreatedAt: now, Stage: models.StageAnalyzing, StartedAt: now}))
+	mock.ExpectExec(`SET processing_stage = \$1,\s+processing_started_at = \$2`).
+		WithArgs(models.StageCreated, sqlmock.AnyArg(), "e1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, service.ReprocessEntry("e1"))
+
+	require.Eventually(t, func() bool {
+		return context.Cause(running) != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.ErrorIs(t, context.Cause(running), ErrProcessingSuperseded)
+
+	require.Eventually(t, func() bool {
+		for _, e := range recorded() {
+			if e.Type == string(events.EventEntryFailed) {
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+	before := len(recorded())
+
+	// The replaced pipeline finishing its analysis stores nothing
+	service.storeAnalysis(running, analyzedEntry())
+
+	assert.Len(t, recorded(), before)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,27 @@
+package service
+
+import "log/slog"
+
+// DefaultMaxResultLimit is the most results a single list or search request
+// returns when Config.MaxResultLimit is unset
+const DefaultMaxResultLimit = 200
+
+// clampLimit caps a requested result limit at the configured maximum, so a
+// client cannot force a huge scan by asking for limit: 1000000. A limit of 0
+// is returned unchanged, leaving each caller's own default in place.
+func (s *JournalService) clampLimit(limit int) int {
+	max := s.maxResultLimit()
+	if limit <= max {
+		return limit
+	}
+
+	slog.Warn("Clamped result limit", "requested", limit, "limit", max)
+	return max
+}
+
+func (s *JournalService) maxResultLimit() int {
+	if s.config.MaxResultLimit > 0 {
+		return s.config.MaxResultLimit
+	}
+	return DefaultMaxResultLimit
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClampLimit(t *testing.T) {
+	service := &JournalService{}
+	assert.Equal(t, 0, service.clampLimit(0))
+	assert.Equal(t, 50, service.clampLimit(50))
+	assert.Equal(t, DefaultMaxResultLimit, service.clampLimit(1000000))
+
+	service.WithConfig(Config{MaxResultLimit: 25})
+	assert.Equal(t, 25, service.clampLimit(50))
+	assert.Equal(t, 10, service.clampLimit(10))
+}
+
+func TestClassicSearchClampsLimit(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+
+	mock.ExpectQuery(`LIMIT \$1`).
+		WithArgs(DefaultMaxResultLimit).
+		WillReturnRows(entryRows())
+
+	_, err := service.ClassicSearch(SearchParams{Limit: 1000000})
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
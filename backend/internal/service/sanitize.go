@@ -0,0 +1,85 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// SanitizeContent cleans text pasted from rich sources before it is stored
+// and analyzed. It drops invalid UTF-8, control characters other than
+// newlines and tabs, and invisible format characters such as zero-width
+// spaces and byte order marks, then normalizes to NFC. Line endings become
+// \n and exotic spaces plain spaces. Runs of spaces and tabs inside a line
+// collapse to one space, trailing whitespace is dropped, blank lines collapse
+// to a single one and the text is trimmed; indentation is kept.
+func SanitizeContent(content string) string {
+	content = strings.ToValidUTF8(content, "")
+	content = strings.NewReplacer("\r\n", "\n", "\r", "\n", "\u2028", "\n", "\u2029", "\n").Replace(content)
+	content = strings.Map(sanitizeRune, content)
+	content = norm.NFC.String(content)
+
+	lines := strings.Split(content, "\n")
+	kept := make([]string, 0, len(lines))
+	blank := false
+	for _, line := range lines {
+		line = collapseSpaces(line)
+		if line == "" {
+			if !blank && len(kept) > 0 {
+				kept = append(kept, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		kept = append(kept, line)
+	}
+
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+// sanitizeRune maps a rune to its sanitized form, or -1 to drop it
+func sanitizeRune(r rune) rune {
+	switch {
+	case r == '\n' || r == '\t':
+		return r
+	case unicode.IsControl(r):
+		return -1
+	// Joiners shape emoji sequences and some scripts, and tag characters
+	// spell out subdivision flags, so those format characters stay
+	case r == '\u200c' || r == '\u200d' || (r >= 0xe0020 && r <= 0xe007f):
+		return r
+	case unicode.Is(unicode.Cf, r):
+		return -1
+	case unicode.Is(unicode.Zs, r):
+		return ' '
+	}
+	return r
+}
+
+// collapseSpaces keeps a line's indentation, collapses the other runs of
+// spaces and tabs to one space and drops trailing whitespace
+func collapseSpaces(line string) string {
+	body := strings.TrimLeft(line, " \t")
+	indent := line[:len(line)-len(body)]
+	body = strings.Join(strings.Fields(body), " ")
+	if body == "" {
+		return ""
+	}
+	return indent + body
+}
+
+// sanitizeContent applies SanitizeContent when Config.SanitizeContent is
+// set. original is the submitted content when Config.KeepOriginalContent is
+// set and sanitizing changed it, and nil otherwise.
+func (s *JournalService) sanitizeContent(content string) (sanitized string, original *string) {
+	if !s.config.SanitizeContent {
+		return content, nil
+	}
+	sanitized = SanitizeContent(content)
+	if s.config.KeepOriginalContent && sanitized != content {
+		original = &content
+	}
+	return sanitized, original
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/logger"
+	"github.com/journal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"clean text is unchanged", "Walked to the park.\n\nIt rained.", "Walked to the park.\n\nIt rained."},
+		{"control characters", "Bell\x07 and\x00 null\x1b[31m escape", "Bell and null[31m escape"},
+		{"zero-width and byte order marks", "\ufeffzero\u200bwidth\u2060joined", "zerowidthjoined"},
+		{"bidi overrides", "safe \u202eetirw\u202c text", "safe etirw text"},
+		{"soft hyphens", "hy\u00adphen", "hyphen"},
+		{"decomposed accents become NFC", "cafe\u0301 nai\u0308ve", "café naïve"},
+		{"exotic spaces", "non\u00a0breaking\u2003em\u3000wide", "non breaking em wide"},
+		{"windows and old mac line endings", "one\r\ntwo\rthree", "one\ntwo\nthree"},
+		{"unicode line separators", "one\u2028two\u2029three", "one\ntwo\nthree"},
+		{"runs of spaces and tabs", "too    many \t\t spaces   ", "too many spaces"},
+		{"blank lines collapse", "\n\n\nfirst\n\n\n\n\nsecond\n  \n\t\nthird\n\n", "first\n\nsecond\n\nthird"},
+		{"indentation is kept", "list:\n  - one\n\t- two", "list:\n  - one\n\t- two"},
+		{"emoji sequences survive", "family \U0001F468\u200d\U0001F469\u200d\U0001F467 flag \U0001F3F4\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F",
+			"family \U0001F468\u200d\U0001F469\u200d\U0001F467 flag \U0001F3F4\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F"},
+		{"invalid utf-8", "bad \xff\xfe bytes", "bad bytes"},
+		{"nothing visible", "\u200b\ufeff\x00 \r\n\t", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SanitizeContent(tt.content))
+		})
+	}
+}
+
+func TestSanitizeContentIsIdempotent(t *testing.T) {
+	pathological := strings.Repeat("a\u200b\x00  b\r\n\n\n\u00a0c\u0301\xff\t", 1000)
+	once := SanitizeContent(pathological)
+	assert.Equal(t, once, SanitizeContent(once))
+	assert.NotContains(t, once, "\u200b")
+	assert.NotContains(t, once, "\x00")
+	assert.NotContains(t, once, "\n\n\n")
+}
+
+func TestCreateDraftStoresSanitizedContentAndOriginal(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	service := (&JournalService{
+		db:          database,
+		broadcaster: events.NewBroadcaster(),
+		logger:      logger.NewProcessingLogger(database.DB),
+	}).WithConfig(Config{SanitizeContent: true, KeepOriginalContent: true})
+
+	pasted := "\ufeffMeeting\u200b notes\x00\r\n\r\n\r\nAll  good"
+	mock.ExpectQuery(`INSERT INTO journal_entries`).
+		WithArgs("Meeting notes\n\nAll good", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), false, models.StageCompleted, nil, sqlmock.AnyArg(), nil, "english", pasted).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("d1"))
+
+	entry, err := service.CreateDraftAt(pasted, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, "Meeting notes\n\nAll good", entry.Content)
+	require.NotNil(t, entry.OriginalContent)
+	assert.Equal(t, pasted, *entry.OriginalContent)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateDraftKeepsNoOriginalWhenClean(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	service := (&JournalService{
+		db:          database,
+		broadcaster: events.NewBroadcaster(),
+		logger:      logger.NewProcessingLogger(database.DB),
+	}).WithConfig(Config{SanitizeContent: true, KeepOriginalContent: true})
+
+	mock.ExpectQuery(`INSERT INTO journal_entries`).
+		WithArgs("quick note", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), false, models.StageCompleted, nil, sqlmock.AnyArg(), nil, "english", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("d1"))
+
+	entry, err := service.CreateDraftAt("quick note", time.Time{})
+	require.NoError(t, err)
+	assert.Nil(t, entry.OriginalContent)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateEntryRejectsInvisibleContent(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{db: database}).WithConfig(Config{SanitizeContent: true})
+
+	// Passes the blank check but is empty once sanitized; nothing is inserted
+	_, err := service.CreateEntry("\u200b\ufeff\u2060")
+	assert.ErrorIs(t, err, ErrValidation)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// RecentSearch is a distinct past query with how often it was run
+type RecentSearch struct {
+	Text           string    `json:"text"`
+	Count          int       `json:"count"`
+	LastSearchedAt time.Time `json:"last_searched_at"`
+}
+
+// RecordSearch logs a search for recent-search suggestions. Empty queries,
+// such as filter-only browsing, are not recorded, and failures are only
+// logged so they never fail the search itself.
+func (s *JournalService) RecordSearch(query, mode string, resultCount int) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return
+	}
+
+	_, err := s.db.Exec(
+		"INSERT INTO search_history (user_id, query, mode, result_count) VALUES ($1, $2, $3, $4)",
+		s.ownerValue(), query, mode, resultCount,
+	)
+	if err != nil {
+		slog.Warn("Failed to record search history", "error", err)
+	}
+}
+
+// recentSearches returns the most recently run distinct queries
+func (s *JournalService) recentSearches(limit int) ([]RecentSearch, error) {
+	scope, scopeArgs := s.scopeClause("user_id", 2)
+
+	rows, err := s.db.Query(`
+		SELECT query, COUNT(*), MAX(searched_at)
+		FROM search_history
+		WHERE TRUE`+scope+`
+		GROUP BY query
+		ORDER BY MAX(searched_at) DESC
+		LIMIT $1`,
+		append([]interface{}{limit}, scopeArgs...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent searches: %w", err)
+	}
+	defer rows.Close()
+
+	searches := []RecentSearch{}
+	for rows.Next() {
+		var search RecentSearch
+		if err := rows.Scan(&search.Text, &search.Count, &search.LastSearchedAt); err != nil {
+			return nil, err
+		}
+		searches = append(searches, search)
+	}
+
+	return searches, rows.Err()
+}
+
+// ClearSearchHistory deletes the recorded searches and returns how many were
+// removed
+func (s *JournalService) ClearSearchHistory() (int64, error) {
+	scope, scopeArgs := s.scopeClause("user_id", 1)
+
+	result, err := s.db.Exec("DELETE FROM search_history WHERE TRUE"+scope, scopeArgs...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear search history: %w", err)
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordSearch(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+
+	mock.ExpectExec(`INSERT INTO search_history \(user_id, query, mode, result_count\) VALUES \(\$1, \$2, \$3, \$4\)`).
+		WithArgs(nil, "morning run", "hybrid", 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	service.RecordSearch("  morning run ", "hybrid", 4)
+
+	// Filter-only searches have no query to suggest later
+	service.RecordSearch("", "classic", 12)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClearSearchHistoryScopesToUser(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{db: database, config: Config{MultiTenant: true}}).ForUser("alice")
+
+	mock.ExpectExec(`DELETE FROM search_history WHERE TRUE AND user_id = \$1`).
+		WithArgs("alice").
+		WillReturnResult(sqlmock.NewResult(0, 7))
+
+	deleted, err := service.ClearSearchHistory()
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), deleted)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/journal/internal/ollama"
+)
+
+// SentimentBucket counts the sentiment classes of the analyzed entries
+// created in one time bucket. Counts always holds every class the analysis
+// can produce, zero when absent, plus "unknown" when any entry lacks one.
+type SentimentBucket struct {
+	Period time.Time      `json:"period"`
+	Counts map[string]int `json:"counts"`
+	Total  int            `json:"total"`
+}
+
+// SentimentTrend is the mood chart series returned by GetSentimentTrend
+type SentimentTrend struct {
+	Granularity string            `json:"granularity"`
+	Sentiments  []string          `json:"sentiments"`
+	Buckets     []SentimentBucket `json:"buckets"`
+}
+
+// GetSentimentTrend counts the sentiment of analyzed entries per day, week
+// or month (granularity, default day), oldest bucket first. Buckets without
+// entries are omitted. Zero start or end leaves that side of the range open.
+func (s *JournalService) GetSentimentTrend(start, end time.Time, granularity string) (*SentimentTrend, error) {
+	granularity, err := parseGranularity(granularity)
+	if err != nil {
+		return nil, err
+	}
+	if !start.IsZero() && !end.IsZero() && start.After(end) {
+		return nil, Invalidf("start %s is after end %s", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+
+	var params AnalyticsParams
+	if !start.IsZero() {
+		params.StartDate = &start
+	}
+	if !end.IsZero() {
+		params.EndDate = &end
+	}
+	filter, filterArgs := s.analyticsFilter(params, 2)
+
+	rows, err := s.db.Query(`
+		SELECT date_trunc($1, created_at) AS period,
+			COALESCE(NULLIF(processed_data->>'sentiment', ''), 'unknown') AS sentiment,
+			COUNT(*)
+		FROM journal_entries
+		WHERE processing_stage IN ('completed', 'completed_no_embedding')`+filter+`
+		GROUP BY period, sentiment
+		ORDER BY period, sentiment`,
+		append([]interface{}{granularity}, filterArgs...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate sentiment: %w", err)
+	}
+	defer rows.Close()
+
+	trend := &SentimentTrend{
+		Granularity: granularity,
+		Sentiments:  ollama.Sentiments,
+		Buckets:     []SentimentBucket{},
+	}
+	for rows.Next() {
+		var period time.Time
+		var sentiment string
+		var count int
+		if err := rows.Scan(&period, &sentiment, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan sentiment: %w", err)
+		}
+
+		// Rows are ordered by period, so a new period starts a new bucket
+		if n := len(trend.Buckets); n == 0 || !trend.Buckets[n-1].Period.Equal(period) {
+			counts := make(map[string]int, len(ollama.Sentiments)+1)
+			for _, class := range ollama.Sentiments {
+				counts[class] = 0
+			}
+			trend.Buckets = append(trend.Buckets, SentimentBucket{Period: period, Counts: counts})
+		}
+		bucket := &trend.Buckets[len(trend.Buckets)-1]
+		bucket.Counts[sentiment] += count
+		bucket.Total += count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to aggregate sentiment: %w", err)
+	}
+
+	return trend, nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSentimentTrendBucketsCounts(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	week1 := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	week2 := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT date_trunc\(\$1, created_at\) AS period, COALESCE\(NULLIF\(processed_data->>'sentiment', ''\), 'unknown'\) AS sentiment, COUNT\(\*\) FROM journal_entries WHERE processing_stage IN \('completed', 'completed_no_embedding'\) AND deleted_at IS NULL AND created_at >= \$2 AND created_at <= \$3 GROUP BY period, sentiment ORDER BY period, sentiment`).
+		WithArgs("week", start, end).
+		WillReturnRows(sqlmock.NewRows([]string{"period", "sentiment", "count"}).
+			AddRow(week1, "negative", 1).
+			AddRow(week1, "positive", 3).
+			AddRow(week2, "neutral", 2).
+			AddRow(week2, "unknown", 1))
+
+	trend, err := service.GetSentimentTrend(start, end, "week")
+	require.NoError(t, err)
+	assert.Equal(t, "week", trend.Granularity)
+	require.Len(t, trend.Buckets, 2)
+
+	assert.Equal(t, SentimentBucket{
+		Period: week1,
+		Counts: map[string]int{"positive": 3, "negative": 1, "neutral": 0, "mixed": 0},
+		Total:  4,
+	}, trend.Buckets[0])
+	assert.Equal(t, SentimentBucket{
+		Period: week2,
+		Counts: map[string]int{"positive": 0, "negative": 0, "neutral": 2, "mixed": 0, "unknown": 1},
+		Total:  3,
+	}, trend.Buckets[1])
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSentimentTrendOpenRangeIsScoped(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{db: database}).
+		WithConfig(Config{MultiTenant: true}).
+		ForUser("alice")
+
+	mock.ExpectQuery(`AND deleted_at IS NULL AND user_id = \$2 GROUP BY period, sentiment`).
+		WithArgs("day", "alice").
+		WillReturnRows(sqlmock.NewRows([]string{"period", "sentiment", "count"}))
+
+	trend, err := service.GetSentimentTrend(time.Time{}, time.Time{}, "")
+	require.NoError(t, err)
+	assert.Equal(t, "day", trend.Granularity)
+	assert.Empty(t, trend.Buckets)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSentimentTrendRejectsInvalidParams(t *testing.T) {
+	service := &JournalService{}
+	now := time.Now()
+
+	_, err := service.GetSentimentTrend(time.Time{}, time.Time{}, "hour")
+	assert.ErrorIs(t, err, ErrValidation)
+
+	_, err = service.GetSentimentTrend(now, now.Add(-time.Hour), "day")
+	assert.ErrorIs(t, err, ErrValidation)
+}
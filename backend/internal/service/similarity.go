@@ -0,0 +1,124 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// SimilarityMetric selects the pgvector distance operator that vector
+// searches rank by. The ivfflat indexes only serve the operator their
+// operator class was built for, so the metric and the indexes must agree.
+type SimilarityMetric string
+
+const (
+	// MetricCosine ranks by cosine distance (<=>), the default
+	MetricCosine SimilarityMetric = "cosine"
+	// MetricL2 ranks by Euclidean distance (<->)
+	MetricL2 SimilarityMetric = "l2"
+	// MetricInnerProduct ranks by negative inner product (<#>)
+	MetricInnerProduct SimilarityMetric = "inner_product"
+)
+
+// vectorIndexes are the ivfflat embedding indexes vector searches rely on
+var vectorIndexes = []string{"idx_journal_entries_embedding", "idx_entry_chunks_embedding"}
+
+// ParseSimilarityMetric parses a metric name; empty selects MetricCosine
+func ParseSimilarityMetric(name string) (SimilarityMetric, error) {
+	switch m := SimilarityMetric(strings.ToLower(strings.TrimSpace(name))); m {
+	case "":
+		return MetricCosine, nil
+	case MetricCosine, MetricL2, MetricInnerProduct:
+		return m, nil
+	default:
+		return "", Invalidf("unknown similarity metric %q (want cosine, l2 or inner_product)", name)
+	}
+}
+
+// operator returns the pgvector distance operator of the metric
+func (m SimilarityMetric) operator() string {
+	switch m {
+	case MetricL2:
+		return "<->"
+	case MetricInnerProduct:
+		return "<#>"
+	default:
+		return "<=>"
+	}
+}
+
+// OperatorClass returns the index operator class that serves the metric
+func (m SimilarityMetric) OperatorClass() string {
+	switch m {
+	case MetricL2:
+		return "vector_l2_ops"
+	case MetricInnerProduct:
+		return "vector_ip_ops"
+	default:
+		return "vector_cosine_ops"
+	}
+}
+
+// distance returns SQL for the distance between two vectors; nearer vectors
+// sort first in ascending order
+func (m SimilarityMetric) distance(a, b string) string {
+	return a + " " + m.operator() + " " + b
+}
+
+// similarity returns SQL for a score where higher means closer: 1 - cosine
+// distance, the inner product itself, or 1 / (1 + L2 distance). Similarity
+// thresholds are on this scale, so they need retuning when the metric changes.
+func (m SimilarityMetric) similarity(a, b string) string {
+	switch m {
+	case MetricL2:
+		return "1 / (1 + (" + m.distance(a, b) + "))"
+	case MetricInnerProduct:
+		return "(" + m.distance(a, b) + ") * -1"
+	default:
+		return "1 - (" + m.distance(a, b) + ")"
+	}
+}
+
+// metric returns the configured similarity metric
+func (s *JournalService) metric() SimilarityMetric {
+	if s.config.SimilarityMetric != "" {
+		return s.config.SimilarityMetric
+	}
+	return MetricCosine
+}
+
+// entrySimilaritySQL scores an entry against the query embedding in $1 as
+// the best of its whole-entry vector and its chunk vectors. GREATEST ignores
+// the NULL of an entry without chunks.
+func (s *JournalService) entrySimilaritySQL() string {
+	m := s.metric()
+	return `GREATEST(
+			` + m.similarity("je.embedding", "$1") + `,
+			(SELECT MAX(` + m.similarity("ec.embedding", "$1") + `) FROM entry_chunks ec WHERE ec.entry_id = je.id)
+		)`
+}
+
+// VectorIndexMismatches checks the embedding indexes against the configured
+// metric and describes every index whose operator class does not match it.
+// Such an index is ignored by the planner, so searches fall back to slow
+// sequential scans. Missing indexes are reported too.
+func (s *JournalService) VectorIndexMismatches() ([]string, error) {
+	want := s.metric().OperatorClass()
+	mismatches := []string{}
+	for _, name := range vectorIndexes {
+		var def string
+		err := s.db.QueryRow("SELECT indexdef FROM pg_indexes WHERE indexname = $1", name).Scan(&def)
+		if err == sql.ErrNoRows {
+			mismatches = append(mismatches, fmt.Sprintf("index %s is missing", name))
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read index %s: %w", name, err)
+		}
+		if !strings.Contains(def, want) {
+			mismatches = append(mismatches, fmt.Sprintf("index %s does not use %s for the %s metric; recreate it with USING ivfflat (embedding %s)",
+				name, want, s.metric(), want))
+		}
+	}
+	return mismatches, nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/ollama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSimilarityMetric(t *testing.T) {
+	for name, want := range map[string]SimilarityMetric{
+		"":              MetricCosine,
+		"cosine":        MetricCosine,
+		"L2":            MetricL2,
+		"inner_product": MetricInnerProduct,
+	} {
+		got, err := ParseSimilarityMetric(name)
+		require.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+
+	_, err := ParseSimilarityMetric("manhattan")
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+func TestSimilarityMetricSQL(t *testing.T) {
+	assert.Equal(t, "1 - (a <=> b)", MetricCosine.similarity("a", "b"))
+	assert.Equal(t, "1 / (1 + (a <-> b))", MetricL2.similarity("a", "b"))
+	assert.Equal(t, "(a <#> b) * -1", MetricInnerProduct.similarity("a", "b"))
+
+	assert.Equal(t, "vector_cosine_ops", MetricCosine.OperatorClass())
+	assert.Equal(t, "vector_l2_ops", MetricL2.OperatorClass())
+	assert.Equal(t, "vector_ip_ops", MetricInnerProduct.OperatorClass())
+}
+
+func TestVectorSearchUsesConfiguredMetric(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	embeddings := embeddingServer(embeddingDimensions)
+	defer embeddings.Close()
+
+	service := (&JournalService{
+		db:        database,
+		processor: ollama.NewProcessor(ollama.NewClient(embeddings.URL)),
+	}).WithConfig(Config{SimilarityMetric: MetricL2})
+
+	mock.ExpectQuery(`ORDER BY je.embedding <-> \$1 LIMIT \$2\) UNION \(SELECT ec.entry_id FROM entry_chunks ec JOIN journal_entries je ON je.id = ec.entry_id WHERE je.deleted_at IS NULL ORDER BY ec.embedding <-> \$1 LIMIT \$2\) \) `+
+		`SELECT .* GREATEST\( 1 / \(1 \+ \(je.embedding <-> \$1\)\), \(SELECT MAX\(1 / \(1 \+ \(ec.embedding <-> \$1\)\)\)`).
+		WithArgs(sqlmock.AnyArg(), 10*vectorCandidateFactor, 10).
+		WillReturnRows(similarityRows())
+
+	_, err := service.VectorSearch(SearchParams{Query: "q", Limit: 10})
+	require.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVectorIndexMismatches(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{db: database}).WithConfig(Config{SimilarityMetric: MetricInnerProduct})
+
+	mock.ExpectQuery(`SELECT indexdef FROM pg_indexes WHERE indexname = \$1`).
+		WithArgs("idx_journal_entries_embedding").
+		WillReturnRows(sqlmock.NewRows([]string{"indexdef"}).
+			AddRow("CREATE INDEX idx_journal_entries_embedding ON public.journal_entries USING ivfflat (embedding vector_ip_ops) WITH (lists='100')"))
+	mock.ExpectQuery(`SELECT indexdef FROM pg_indexes WHERE indexname = \$1`).
+		WithArgs("idx_entry_chunks_embedding").
+		WillReturnRows(sqlmock.NewRows([]string{"indexdef"}).
+			AddRow("CREATE INDEX idx_entry_chunks_embedding ON public.entry_chunks USING ivfflat (embedding vector_cosine_ops) WITH (lists='100')"))
+
+	mismatches, err := service.VectorIndexMismatches()
+	require.NoError(t, err)
+	require.Len(t, mismatches, 1)
+	assert.Contains(t, mismatches[0], "idx_entry_chunks_embedding does not use vector_ip_ops")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/models"
+)
+
+// CreateSmartCollection creates a collection whose entries are the results of
+// a stored search rather than manual membership
+func (s *JournalService) CreateSmartCollection(name, description string, params SearchParams) (*models.Collection, error) {
+	// Presets are resolved when the collection is read, so validate on a copy
+	// and keep the relative range in the stored query
+	check := params
+	if err := check.ApplyDateRange(time.Now()); err != nil {
+		return nil, err
+	}
+
+	queryParams, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode search parameters: %w", err)
+	}
+
+	collection := &models.Collection{
+		Name:        name,
+		Description: description,
+		IsSmart:     true,
+		QueryParams: queryParams,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	err = s.db.QueryRow(
+		"INSERT INTO collections (name, description, user_id, is_smart, query_params) VALUES ($1, $2, $3, TRUE, $4) RETURNING id",
+		name, description, s.ownerValue(), queryParams,
+	).Scan(&collection.ID)
+
+	if isUniqueViolation(err) {
+		return nil, Conflictf("a collection named %q already exists", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create collection: %w", err)
+	}
+
+	s.sendEvent(events.EventCollectionCreated, "", map[string]interface{}{
+		"collection": collection,
+	})
+
+	return collection, nil
+}
+
+// GetCollectionEntries lists the entries in a collection. Manual collections
+// read journal_collection; smart collections re-run their stored search, using
+// hybrid search when a hybrid_mode is stored, vector search when a
+// semantic_mode is stored, and classic search otherwise. Pages of hybrid and
+// vector searches stop at the maximum result limit.
+func (s *JournalService) GetCollectionEntries(collectionID string, limit, offset int) ([]models.JournalEntry, error) {
+	scope, scopeArgs := s.scopeClause("user_id", 2)
+
+	var isSmart bool
+	var queryParams []byte
+	err := s.db.QueryRow(
+		"SELECT is_smart, query_params FROM collections WHERE id = $1"+scope,
+		append([]interface{}{collectionID}, scopeArgs...)...,
+	).Scan(&isSmart, &queryParams)
+
+	if err == sql.ErrNoRows {
+		return nil, NotFoundf("collection not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	if !isSmart {
+		return s.ClassicSearch(SearchParams{
+			CollectionIDs: []string{collectionID},
+			Limit:         limit,
+			Offset:        offset,
+		})
+	}
+
+	var params SearchParams
+	if err := json.Unmarshal(queryParams, &params); err != nil {
+		return nil, fmt.Errorf("invalid smart collection query: %w", err)
+	}
+	if limit > 0 {
+		params.Limit = limit
+	}
+	if offset > 0 {
+		params.Offset = offset
+	}
+	if err := params.ApplyDateRange(time.Now()); err != nil {
+		return nil, fmt.Errorf("invalid smart collection query: %w", err)
+	}
+
+	if params.HybridMode == "" && params.SemanticMode == "" {
+		return s.ClassicSearch(params)
+	}
+
+	// Hybrid and vector search rank their matches in memory and take no
+	// offset, so the page is cut from the first offset+limit matches
+	pageOffset := params.Offset
+	params.Offset = 0
+	if params.Limit == 0 {
+		params.Limit = 20
+	}
+	params.Limit += pageOffset
+
+	var entries []models.JournalEntry
+	if params.HybridMode != "" {
+		entries, err = s.HybridSearch(params)
+	} else {
+		entries, err = s.VectorSearch(params)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if pageOffset >= len(entries) {
+		return []models.JournalEntry{}, nil
+	}
+	return entries[pageOffset:], nil
+}
+
+// ensureManualCollection rejects membership changes on smart collections,
+// whose entries are defined by their stored search
+func (s *JournalService) ensureManualCollection(collectionID string) error {
+	var isSmart bool
+	err := s.db.QueryRow("SELECT is_smart FROM collections WHERE id = $1", collectionID).Scan(&isSmart)
+	if err == sql.ErrNoRows {
+		return NotFoundf("collection not found")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get collection: %w", err)
+	}
+	if isSmart {
+		return Conflictf("entries cannot be added to or removed from a smart collection")
+	}
+	return nil
+}
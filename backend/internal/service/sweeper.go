@@ -0,0 +1,112 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/models"
+)
+
+// maxAutoRetries is how many times the sweeper retries the same entry before
+// giving up and marking it failed
+const maxAutoRetries = 2
+
+// autoRetryMessage is logged for every sweeper retry so attempts can be counted
+const autoRetryMessage = "Stuck entry auto-retried"
+
+// SweepStuckEntries finds entries that have sat in a non-terminal processing
+// stage for longer than threshold, typically because the server restarted
+// mid-pipeline. With retry set they are reprocessed (at most maxAutoRetries
+// times); otherwise, or once retries are exhausted, they are marked failed.
+// Soft-deleted entries are skipped. It returns how many entries were handled.
+func (s *JournalService) SweepStuckEntries(threshold time.Duration, retry bool) (int, error) {
+	rows, err := s.db.Query(`
+		SELECT id, user_id, processing_stage
+		FROM journal_entries
+		WHERE processing_stage IS NOT NULL
+			AND processing_stage NOT IN ($1, $2, $3, $4)
+			AND processing_started_at < $5
+			AND deleted_at IS NULL
+		ORDER BY processing_started_at
+		LIMIT 100`,
+		models.StageCompleted, models.StageFailed, models.StageCompletedNoEmbedding, models.StageCancelled, time.Now().Add(-threshold),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find stuck entries: %w", err)
+	}
+
+	type stuckEntry struct {
+		id     string
+		userID sql.NullString
+		stage  models.ProcessingStage
+	}
+	stuck := []stuckEntry{}
+	for rows.Next() {
+		var e stuckEntry
+		if err := rows.Scan(&e.id, &e.userID, &e.stage); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan stuck entry: %w", err)
+		}
+		stuck = append(stuck, e)
+	}
+	rows.Close()
+
+	for _, e := range stuck {
+		owner := s.ForUser(e.userID.String)
+
+		if retry {
+			attempts, err := s.autoRetryCount(e.id)
+			if err != nil {
+				slog.Error("Failed to count retries for stuck entry", "entry_id", e.id, "error", err)
+			} else if attempts < maxAutoRetries {
+				s.logger.LogWarn(e.id, e.stage, autoRetryMessage, map[string]interface{}{
+					"stuck_stage": e.stage,
+					"attempt":     attempts + 1,
+				})
+				if err := owner.RetryProcessing(e.id); err == nil {
+					slog.Info("Sweeper retried stuck entry", "entry_id", e.id, "stage", e.stage, "attempt", attempts+1)
+					continue
+				} else {
+					slog.Error("Sweeper failed to retry entry", "entry_id", e.id, "error", err)
+				}
+			}
+		}
+
+		stallErr := fmt.Errorf("processing stalled in stage %s for over %s", e.stage, threshold)
+		s.logger.SetError(e.id, e.stage, stallErr)
+		owner.sendEvent(events.EventEntryFailed, e.id, map[string]interface{}{
+			"error": stallErr.Error(),
+			"stage": e.stage,
+		})
+		slog.Warn("Sweeper marked entry as failed", "entry_id", e.id, "error", stallErr)
+	}
+
+	return len(stuck), nil
+}
+
+// autoRetryCount returns how many times the sweeper already retried an entry
+func (s *JournalService) autoRetryCount(entryID string) (int, error) {
+	var count int
+	err := s.db.QueryRow(
+		"SELECT COUNT(*) FROM processing_logs WHERE entry_id = $1 AND message = $2",
+		entryID, autoRetryMessage,
+	).Scan(&count)
+	return count, err
+}
+
+// StartStuckEntrySweeper runs SweepStuckEntries every interval in the background
+func (s *JournalService) StartStuckEntrySweeper(threshold, interval time.Duration, retry bool) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := s.SweepStuckEntries(threshold, retry); err != nil {
+				slog.Error("Stuck entry sweep failed", "error", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSweepStuckEntriesMarksFailed(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{
+		db:          database,
+		logger:      logger.NewProcessingLogger(database.DB),
+		broadcaster: events.NewBroadcaster(),
+	}
+
+	mock.ExpectQuery(`SELECT id, user_id, processing_stage FROM journal_entries WHERE processing_stage IS NOT NULL AND processing_stage NOT IN`).
+		WithArgs("completed", "failed", "completed_no_embedding", "cancelled", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "processing_stage"}).
+			AddRow("e1", nil, "analyzing"))
+
+	mock.ExpectExec(`UPDATE journal_entries SET processing_stage = \$1, processing_error = \$2`).
+		WithArgs("failed", "processing stalled in stage analyzing for over 10m0s", sqlmock.AnyArg(), "e1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	handled, err := service.SweepStuckEntries(10*time.Minute, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, handled)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSweepStuckEntriesSkipsDeletedEntries(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database, broadcaster: events.NewBroadcaster()}
+
+	mock.ExpectQuery(`AND processing_started_at < \$5 AND deleted_at IS NULL ORDER BY processing_started_at`).
+		WithArgs("completed", "failed", "completed_no_embedding", "cancelled", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "processing_stage"}))
+
+	handled, err := service.SweepStuckEntries(10*time.Minute, true)
+	require.NoError(t, err)
+	assert.Equal(t, 0, handled)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
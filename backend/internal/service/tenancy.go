@@ -0,0 +1,81 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/journal/internal/events"
+)
+
+// ForUser returns a copy of the service whose queries are scoped to userID.
+// In single-tenant mode the user ID is recorded but never used to filter.
+func (s *JournalService) ForUser(userID string) *JournalService {
+	scoped := *s
+	scoped.userID = userID
+	return &scoped
+}
+
+// scopeClause returns an extra WHERE condition restricting column to the
+// current user, along with its argument. It returns an empty clause when
+// multi-tenancy is disabled so single-tenant queries stay unscoped.
+func (s *JournalService) scopeClause(column string, argIndex int) (string, []interface{}) {
+	if !s.config.MultiTenant {
+		return "", nil
+	}
+	return fmt.Sprintf(" AND %s = $%d", column, argIndex), []interface{}{s.userID}
+}
+
+// ownerValue is the user_id stored on newly inserted rows
+func (s *JournalService) ownerValue() interface{} {
+	if !s.config.MultiTenant || s.userID == "" {
+		return nil
+	}
+	return s.userID
+}
+
+// sendEvent broadcasts an entry event visible only to the current user when
+// multi-tenancy is enabled
+func (s *JournalService) sendEvent(eventType events.EventType, entryID string, data interface{}) {
+	if !s.config.MultiTenant {
+		s.broadcaster.SendEvent(eventType, entryID, data)
+		return
+	}
+	s.broadcaster.SendUserEvent(s.userID, eventType, entryID, data)
+}
+
+// ensureOwnership verifies that the entry and collection (either may be
+// empty) belong to the current user. It is a no-op in single-tenant mode.
+func (s *JournalService) ensureOwnership(entryID, collectionID string) error {
+	if !s.config.MultiTenant {
+		return nil
+	}
+
+	if entryID != "" {
+		var owned bool
+		err := s.db.QueryRow(
+			"SELECT EXISTS(SELECT 1 FROM journal_entries WHERE id = $1 AND user_id = $2)",
+			entryID, s.userID,
+		).Scan(&owned)
+		if err != nil {
+			return fmt.Errorf("failed to check entry ownership: %w", err)
+		}
+		if !owned {
+			return NotFoundf("entry not found")
+		}
+	}
+
+	if collectionID != "" {
+		var owned bool
+		err := s.db.QueryRow(
+			"SELECT EXISTS(SELECT 1 FROM collections WHERE id = $1 AND user_id = $2)",
+			collectionID, s.userID,
+		).Scan(&owned)
+		if err != nil {
+			return fmt.Errorf("failed to check collection ownership: %w", err)
+		}
+		if !owned {
+			return NotFoundf("collection not found")
+		}
+	}
+
+	return nil
+}
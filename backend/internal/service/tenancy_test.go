@@ -0,0 +1,114 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEntryScopedToUser(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{db: database}).
+		WithConfig(Config{MultiTenant: true}).
+		ForUser("alice")
+
+	rows := entryRows(mockEntry{ID: "123", Content: "Alice's entry", ProcessedData: `{"summary": "test", "topics": [], "entities": [], "sentiment": "neutral"}`, StartedAt: time.Now(), CompletedAt: time.Now()})
+
+	mock.ExpectQuery(`WHERE je.id = \$1 AND je.deleted_at IS NULL AND je.user_id = \$2`).
+		WithArgs("123", "alice").
+		WillReturnRows(rows)
+
+	entry, err := service.GetEntry("123")
+	require.NoError(t, err)
+	assert.Equal(t, "Alice's entry", entry.Content)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClassicSearchScopedToUser(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{db: database}).
+		WithConfig(Config{MultiTenant: true}).
+		ForUser("bob")
+
+	mock.ExpectQuery(`WHERE je.deleted_at IS NULL AND je.user_id = \$1 AND je.tsv @@ websearch_to_tsquery\(je.ts_config, \$2\)`).
+		WithArgs("bob", "golang", 10).
+		WillReturnRows(entryRows())
+
+	entries, err := service.ClassicSearch(SearchParams{Query: "golang", Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSingleTenantModeIgnoresUser(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	// ForUser without MultiTenant must not add a user filter
+	service := (&JournalService{db: database}).ForUser("alice")
+
+	mock.ExpectQuery(`c.is_smart, c.query_params FROM collections c LEFT JOIN journal_collection jc ON jc.collection_id = c.id LEFT JOIN journal_entries je ON je.id = jc.journal_id AND je.deleted_at IS NULL WHERE 1=1 GROUP BY c.id ORDER BY c.name`).
+		WithoutArgs().
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description", "created_at", "updated_at", "count", "is_smart", "query_params"}).
+			AddRow("c1", "Work", "", time.Now(), time.Now(), 3, false, nil))
+
+	collections, err := service.GetCollections()
+	require.NoError(t, err)
+	require.Len(t, collections, 1)
+	assert.Equal(t, 3, collections[0].EntryCount)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAddToCollectionRejectsForeignEntry(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{db: database}).
+		WithConfig(Config{MultiTenant: true}).
+		ForUser("alice")
+
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM journal_entries WHERE id = \$1 AND user_id = \$2\)`).
+		WithArgs("entry-of-bob", "alice").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	err := service.AddToCollection("entry-of-bob", "collection-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "entry not found")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeProcessingLogsScopedToUser(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{db: database, logger: logger.NewProcessingLogger(database.DB)}).
+		WithConfig(Config{MultiTenant: true}).
+		ForUser("alice")
+
+	// Only logs of Alice's entries are candidates; Bob's are never matched
+	mock.ExpectExec(`DELETE FROM processing_logs(.*)processing_stage <> 'failed'\) AND je.user_id = \$3`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), "alice").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	deleted, err := service.PurgeProcessingLogs(30 * 24 * time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+
+	// Without a user there is nothing to scope the purge to
+	_, err = service.ForUser("").PurgeProcessingLogs(time.Hour)
+	assert.ErrorIs(t, err, ErrValidation)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
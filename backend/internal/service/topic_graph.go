@@ -0,0 +1,107 @@
+package service
+
+import "fmt"
+
+// topicGraphNodes and topicGraphEdges bound the graph GetTopicGraph returns
+// to what a visualization can usefully draw
+const (
+	topicGraphNodes = 100
+	topicGraphEdges = 300
+)
+
+// TopicNode is a topic and the number of entries tagged with it
+type TopicNode struct {
+	Topic string `json:"topic"`
+	Count int    `json:"count"`
+}
+
+// TopicEdge links two topics by the number of entries tagged with both.
+// Source sorts before Target.
+type TopicEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Weight int    `json:"weight"`
+}
+
+// TopicGraph is the co-occurrence graph of the journal's topics
+type TopicGraph struct {
+	Nodes []TopicNode `json:"nodes"`
+	Edges []TopicEdge `json:"edges"`
+}
+
+// entryTopicsCTE lists each processed entry's distinct topics, so a topic the
+// model repeated within one entry is counted once
+const entryTopicsCTE = `
+	WITH entry_topics AS (
+		SELECT DISTINCT id, topic
+		FROM journal_entries,
+		LATERAL jsonb_array_elements_text(processed_data->'topics') AS topic
+		WHERE processing_stage IN ('completed', 'completed_no_embedding') AND deleted_at IS NULL%s
+	)`
+
+// GetTopicGraph counts how often each pair of topics appears on the same
+// entry. Nodes are the most frequent topics; edges join pairs of those
+// topics, heaviest first.
+func (s *JournalService) GetTopicGraph() (*TopicGraph, error) {
+	scope, scopeArgs := s.scopeClause("user_id", 2)
+	rows, err := s.db.Query(fmt.Sprintf(entryTopicsCTE, scope)+`
+		SELECT topic, COUNT(*) AS count
+		FROM entry_topics
+		GROUP BY topic
+		ORDER BY count DESC, topic
+		LIMIT $1`,
+		append([]interface{}{topicGraphNodes}, scopeArgs...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count topics: %w", err)
+	}
+	defer rows.Close()
+
+	graph := &TopicGraph{Nodes: []TopicNode{}, Edges: []TopicEdge{}}
+	nodes := map[string]bool{}
+	for rows.Next() {
+		var n TopicNode
+		if err := rows.Scan(&n.Topic, &n.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan topic: %w", err)
+		}
+		graph.Nodes = append(graph.Nodes, n)
+		nodes[n.Topic] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read topics: %w", err)
+	}
+	if len(graph.Nodes) < 2 {
+		return graph, nil
+	}
+
+	// Self-join each entry's topics into pairs; a.topic < b.topic keeps one
+	// row per unordered pair
+	scope, scopeArgs = s.scopeClause("user_id", 1)
+	edgeRows, err := s.db.Query(fmt.Sprintf(entryTopicsCTE, scope)+`
+		SELECT a.topic, b.topic, COUNT(*) AS weight
+		FROM entry_topics a
+		JOIN entry_topics b ON a.id = b.id AND a.topic < b.topic
+		GROUP BY a.topic, b.topic
+		ORDER BY weight DESC, a.topic, b.topic`,
+		scopeArgs...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count topic pairs: %w", err)
+	}
+	defer edgeRows.Close()
+
+	for edgeRows.Next() && len(graph.Edges) < topicGraphEdges {
+		var e TopicEdge
+		if err := edgeRows.Scan(&e.Source, &e.Target, &e.Weight); err != nil {
+			return nil, fmt.Errorf("failed to scan topic pair: %w", err)
+		}
+		if nodes[e.Source] && nodes[e.Target] {
+			graph.Edges = append(graph.Edges, e)
+		}
+	}
+	if err := edgeRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read topic pairs: %w", err)
+	}
+
+	return graph, nil
+}
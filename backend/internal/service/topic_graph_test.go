@@ -0,0 +1,62 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTopicGraph(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{db: database}).WithConfig(Config{MultiTenant: true}).ForUser("alice")
+
+	mock.ExpectQuery(`WITH entry_topics AS .*AND user_id = \$2.*GROUP BY topic ORDER BY count DESC, topic LIMIT \$1`).
+		WithArgs(topicGraphNodes, "alice").
+		WillReturnRows(sqlmock.NewRows([]string{"topic", "count"}).
+			AddRow("work", 5).
+			AddRow("stress", 3).
+			AddRow("travel", 1))
+	mock.ExpectQuery(`AND user_id = \$1.*JOIN entry_topics b ON a.id = b.id AND a.topic < b.topic`).
+		WithArgs("alice").
+		WillReturnRows(sqlmock.NewRows([]string{"source", "target", "weight"}).
+			AddRow("stress", "work", 3).
+			AddRow("rare", "work", 1).
+			AddRow("travel", "work", 1))
+
+	graph, err := service.GetTopicGraph()
+	require.NoError(t, err)
+
+	assert.Equal(t, []TopicNode{
+		{Topic: "work", Count: 5},
+		{Topic: "stress", Count: 3},
+		{Topic: "travel", Count: 1},
+	}, graph.Nodes)
+	// Pairs with a topic outside the node set are dropped
+	assert.Equal(t, []TopicEdge{
+		{Source: "stress", Target: "work", Weight: 3},
+		{Source: "travel", Target: "work", Weight: 1},
+	}, graph.Edges)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTopicGraphWithoutPairs(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+
+	mock.ExpectQuery(`WITH entry_topics AS`).
+		WithArgs(topicGraphNodes).
+		WillReturnRows(sqlmock.NewRows([]string{"topic", "count"}).AddRow("work", 1))
+
+	graph, err := service.GetTopicGraph()
+	require.NoError(t, err)
+	assert.Len(t, graph.Nodes, 1)
+	assert.Empty(t, graph.Edges)
+	assert.NotNil(t, graph.Edges)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"github.com/journal/internal/models"
+)
+
+// DefaultMaxFetchURLs is how many of an entry's links are fetched when
+// Config.MaxFetchURLs is unset
+const DefaultMaxFetchURLs = 10
+
+// urlsToFetch returns how many of the entry's extracted URLs the pipeline
+// should fetch. The model lists URLs in the order it found them and gives no
+// priority, so the first ones are kept. The rest stay in ExtractedURLs
+// without content, and their count is recorded as metadata["urls_skipped"].
+func (s *JournalService) urlsToFetch(entryID string, data *models.ProcessedData) int {
+	limit := s.config.MaxFetchURLs
+	if limit <= 0 {
+		limit = DefaultMaxFetchURLs
+	}
+	if len(data.ExtractedURLs) <= limit {
+		return len(data.ExtractedURLs)
+	}
+
+	skipped := len(data.ExtractedURLs) - limit
+	if data.Metadata == nil {
+		data.Metadata = make(map[string]any)
+	}
+	data.Metadata["urls_skipped"] = skipped
+
+	s.logger.LogWarn(entryID, models.StageFetchingURLs, "Entry has more URLs than the fetch limit; skipping the rest", map[string]interface{}{
+		"urls_count": len(data.ExtractedURLs),
+		"limit":      limit,
+		"skipped":    skipped,
+	})
+	return limit
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/journal/internal/logger"
+	"github.com/journal/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLsToFetchCapsLinkHeavyEntries(t *testing.T) {
+	database, _ := setupMockDB(t)
+	defer database.Close()
+
+	service := (&JournalService{logger: logger.NewProcessingLogger(database.DB)}).WithConfig(Config{MaxFetchURLs: 3})
+
+	data := &models.ProcessedData{}
+	for i := 0; i < 5; i++ {
+		data.ExtractedURLs = append(data.ExtractedURLs, models.ExtractedURL{URL: fmt.Sprintf("https://example.com/%d", i)})
+	}
+
+	assert.Equal(t, 3, service.urlsToFetch("e1", data))
+	assert.Equal(t, 2, data.Metadata["urls_skipped"])
+	assert.Len(t, data.ExtractedURLs, 5, "skipped URLs are kept without content")
+
+	// Within the limit nothing is recorded
+	small := &models.ProcessedData{ExtractedURLs: data.ExtractedURLs[:2]}
+	assert.Equal(t, 2, service.urlsToFetch("e2", small))
+	assert.NotContains(t, small.Metadata, "urls_skipped")
+}
+
+func TestURLsToFetchDefaultsLimit(t *testing.T) {
+	database, _ := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{logger: logger.NewProcessingLogger(database.DB)}
+
+	data := &models.ProcessedData{ExtractedURLs: make([]models.ExtractedURL, DefaultMaxFetchURLs+1)}
+	assert.Equal(t, DefaultMaxFetchURLs, service.urlsToFetch("e1", data))
+	assert.Equal(t, 1, data.Metadata["urls_skipped"])
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultMaxContentBytes is the largest entry accepted when
+// Config.MaxContentBytes is unset. Larger pastes fail deep in the pipeline
+// (analysis truncation, embedding context limits) rather than up front.
+const DefaultMaxContentBytes = 1 << 20
+
+// validateContent rejects content that is blank after trimming whitespace or
+// larger than the configured limit
+func (s *JournalService) validateContent(content string) error {
+	if strings.TrimSpace(content) == "" {
+		return Invalidf("content cannot be empty")
+	}
+
+	limit := s.config.MaxContentBytes
+	if limit <= 0 {
+		limit = DefaultMaxContentBytes
+	}
+	if len(content) > limit {
+		return Invalidf("content is %d bytes, larger than the %d byte limit", len(content), limit)
+	}
+
+	return nil
+}
+
+// createdAtClockSkew tolerates a client clock running slightly ahead of the
+// server's when a creation time is supplied
+const createdAtClockSkew = time.Minute
+
+// validateCreatedAt rejects a supplied creation time that lies in the future
+func validateCreatedAt(createdAt, now time.Time) error {
+	if createdAt.After(now.Add(createdAtClockSkew)) {
+		return Invalidf("created_at %s is in the future", createdAt.Format(time.RFC3339))
+	}
+	return nil
+}
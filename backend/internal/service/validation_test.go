@@ -0,0 +1,74 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateContent(t *testing.T) {
+	service := &JournalService{config: Config{MaxContentBytes: 10}}
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{"empty", "", true},
+		{"whitespace only", " \n\t ", true},
+		{"single character", "a", false},
+		{"at limit", strings.Repeat("a", 10), false},
+		{"over limit", strings.Repeat("a", 11), true},
+		{"surrounding whitespace counts toward limit", " " + strings.Repeat("a", 10), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.validateContent(tt.content)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrValidation)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateContentDefaultLimit(t *testing.T) {
+	service := &JournalService{}
+
+	assert.NoError(t, service.validateContent(strings.Repeat("a", DefaultMaxContentBytes)))
+	assert.ErrorIs(t, service.validateContent(strings.Repeat("a", DefaultMaxContentBytes+1)), ErrValidation)
+}
+
+func TestCreateEntryRejectsInvalidContent(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+
+	_, err := service.CreateEntry("   ")
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestValidateCreatedAt(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, validateCreatedAt(now.AddDate(-5, 0, 0), now))
+	assert.NoError(t, validateCreatedAt(now.Add(30*time.Second), now), "small clock skew is tolerated")
+	assert.ErrorIs(t, validateCreatedAt(now.Add(time.Hour), now), ErrValidation)
+}
+
+func TestCreateEntryAtRejectsFutureDate(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+
+	_, err := service.CreateEntryAt("written tomorrow", time.Now().AddDate(0, 0, 1))
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/models"
+)
+
+// versionChainCTE defines a "versions" CTE holding the IDs of every version
+// in the chain containing the entry with id $1
+const versionChainCTE = `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, original_entry_id FROM journal_entries WHERE id = $1
+			UNION
+			SELECT parent.id, parent.original_entry_id
+			FROM journal_entries parent
+			JOIN ancestors a ON parent.id = a.original_entry_id
+		),
+		root AS (
+			SELECT a.id FROM ancestors a
+			WHERE NOT EXISTS (SELECT 1 FROM ancestors p WHERE p.id = a.original_entry_id)
+		),
+		versions AS (
+			SELECT id FROM root
+			UNION
+			SELECT child.id
+			FROM journal_entries child
+			JOIN versions v ON child.original_entry_id = v.id
+		)`
+
+// GetEntryHistory returns every version of an entry ordered oldest first.
+// UpdateEntry links each new version to the one it replaced through
+// original_entry_id, so the chain is walked up from id to its root and then
+// back down to every descendant; id may be any version in the chain.
+func (s *JournalService) GetEntryHistory(id string) ([]models.JournalEntry, error) {
+	scope, scopeArgs := s.scopeClause("je.user_id", 2)
+	query := versionChainCTE + `
+		SELECT` + entryColumns + `
+		FROM journal_entries je
+		LEFT JOIN journal_collection jc ON je.id = jc.journal_id
+		WHERE je.id IN (SELECT id FROM versions) AND je.deleted_at IS NULL` + scope + `
+		GROUP BY je.id
+		ORDER BY je.created_at ASC`
+
+	rows, err := s.db.Query(query, append([]interface{}{id}, scopeArgs...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entry history: %w", err)
+	}
+	defer rows.Close()
+
+	history, err := s.scanEntries(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, NotFoundf("entry not found")
+	}
+
+	return history, nil
+}
+
+// RestoreVersion makes an older version current again by inserting a new head
+// version with its content, linked to the previous head through
+// original_entry_id. Historical rows are left untouched; the new version
+// keeps the restored version's original content, inherits the head's
+// favorite flag and collections and is processed in the background like a
+// new entry.
+func (s *JournalService) RestoreVersion(versionID string) (*models.JournalEntry, error) {
+	history, err := s.GetEntryHistory(versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	head := history[len(history)-1]
+	if head.ID == versionID {
+		return nil, Conflictf("version is already the current version")
+	}
+
+	var version models.JournalEntry
+	for _, v := range history {
+		if v.ID == versionID {
+			version = v
+			break
+		}
+	}
+
+	now := time.Now()
+	entry := models.JournalEntry{
+		Content:             version.Content,
+		ProcessedData:       placeholderProcessedData(),
+		CreatedAt:           now,
+		UpdatedAt:           now,
+		IsFavorite:          head.IsFavorite,
+		PinnedAt:            head.PinnedAt,
+		CollectionIDs:       head.CollectionIDs,
+		OriginalEntryID:     &head.ID,
+		ProcessingStage:     models.StageCreated,
+		ProcessingStartedAt: &now,
+		Attachments:         version.Attachments,
+		OriginalContent:     version.OriginalContent,
+	}
+
+	processedJSON, err := json.Marshal(entry.ProcessedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal processed data: %w", err)
+	}
+
+	query := `
+		INSERT INTO journal_entries (content, processed_data, created_at, updated_at, is_favorite, pinned_at, original_entry_id, processing_stage, processing_started_at, user_id, ts_config, attachments, original_content)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id`
+
+	err = s.db.QueryRow(query,
+		entry.Content,
+		processedJSON,
+		entry.CreatedAt,
+		entry.UpdatedAt,
+		entry.IsFavorite,
+		entry.PinnedAt,
+		entry.OriginalEntryID,
+		entry.ProcessingStage,
+		entry.ProcessingStartedAt,
+		s.ownerValue(),
+		detectTSConfig(entry.Content),
+		entry.Attachments,
+		entry.OriginalContent,
+	).Scan(&entry.ID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert restored version: %w", err)
+	}
+
+	for _, collID := range head.CollectionIDs {
+		_, err = s.db.Exec(
+			"INSERT INTO journal_collection (journal_id, collection_id) VALUES ($1, $2)",
+			entry.ID, collID,
+		)
+		if err != nil {
+			slog.Error("Failed to copy collection association", "error", err)
+		}
+	}
+
+	s.logger.LogInfo(entry.ID, models.StageCreated, "Restored previous version", map[string]interface{}{
+		"restored_from": versionID,
+		"previous_head": head.ID,
+	})
+
+	s.sendEvent(events.EventEntryUpdated, entry.ID, map[string]interface{}{
+		"entry":         &entry,
+		"original_id":   head.ID,
+		"restored_from": versionID,
+	})
+
+	go s.processEntry(entry.ID, entry.Content)
+
+	return &entry, nil
+}
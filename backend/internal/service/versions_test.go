@@ -0,0 +1,129 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/logger"
+	"github.com/journal/internal/models"
+	"github.com/journal/internal/ollama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEntryHistory(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	root := "v1"
+	now := time.Now()
+
+	mock.ExpectQuery(`WITH RECURSIVE ancestors AS .* ORDER BY je.created_at ASC`).
+		WithArgs("v2").
+		WillReturnRows(entryRows(
+			mockEntry{ID: "v1", Content: "first", CreatedAt: now.Add(-time.Hour)},
+			mockEntry{ID: "v2", Content: "second", CreatedAt: now, OriginalEntryID: root},
+		))
+
+	history, err := service.GetEntryHistory("v2")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "v1", history[0].ID)
+	assert.Equal(t, "v2", history[1].ID)
+	assert.Equal(t, "v1", *history[1].OriginalEntryID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetEntryHistoryNotFound(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+
+	mock.ExpectQuery(`WITH RECURSIVE ancestors AS`).
+		WithArgs("missing").
+		WillReturnRows(entryRows())
+
+	_, err := service.GetEntryHistory("missing")
+	assert.EqualError(t, err, "entry not found")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreVersionRejectsCurrentHead(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+
+	service := &JournalService{db: database}
+	root := "v1"
+	now := time.Now()
+
+	mock.ExpectQuery(`WITH RECURSIVE ancestors AS`).
+		WithArgs("v2").
+		WillReturnRows(entryRows(
+			mockEntry{ID: "v1", Content: "first", CreatedAt: now.Add(-time.Hour)},
+			mockEntry{ID: "v2", Content: "second", CreatedAt: now, OriginalEntryID: root},
+		))
+
+	_, err := service.RestoreVersion("v2")
+	assert.EqualError(t, err, "version is already the current version")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRestoreVersionKeepsOriginalContent(t *testing.T) {
+	database, mock := setupMockDB(t)
+	defer database.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	// The model is down, so the restored version's analysis fails
+	ollamaServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model not loaded", http.StatusInternalServerError)
+	}))
+	defer ollamaServer.Close()
+
+	broadcaster := events.NewBroadcaster()
+	recorded := recordEvents(broadcaster)
+	service := &JournalService{
+		db:          database,
+		processor:   ollama.NewProcessor(ollama.NewClient(ollamaServer.URL)),
+		broadcaster: broadcaster,
+		logger:      logger.NewProcessingLogger(database.DB),
+	}
+	now := time.Now()
+
+	mock.ExpectQuery(`WITH RECURSIVE ancestors AS`).
+		WithArgs("v1").
+		WillReturnRows(entryRows(
+			mockEntry{ID: "v1", Content: "first", OriginalContent: "first\u200b", CreatedAt: now.Add(-time.Hour)},
+			mockEntry{ID: "v2", Content: "second", CreatedAt: now, OriginalEntryID: "v1"},
+		))
+
+	// The restored head keeps the content as submitted alongside the
+	// sanitized text, as an edit does
+	mock.ExpectQuery(`INSERT INTO journal_entries \(.*attachments, original_content\)`).
+		WithArgs("first", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), false, nil, "v2",
+			models.StageCreated, sqlmock.AnyArg(), nil, "english", sqlmock.AnyArg(), "first\u200b").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("v3"))
+
+	entry, err := service.RestoreVersion("v1")
+	require.NoError(t, err)
+	assert.Equal(t, "v3", entry.ID)
+	require.NotNil(t, entry.OriginalContent)
+	assert.Equal(t, "first\u200b", *entry.OriginalContent)
+
+	require.Eventually(t, func() bool {
+		for _, e := range recorded() {
+			if e.Type == string(events.EventEntryFailed) {
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+}
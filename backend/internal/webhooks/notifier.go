@@ -0,0 +1,168 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/models"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the request body, prefixed
+// with "sha256=", when a secret is configured
+const SignatureHeader = "X-Journal-Signature"
+
+const (
+	// DefaultTimeout bounds each delivery attempt
+	DefaultTimeout = 5 * time.Second
+	// DefaultMaxRetries is how many times a failed delivery is retried
+	DefaultMaxRetries = 2
+	// DefaultRetryBackoff is the wait before the first retry; it doubles
+	// with every further attempt
+	DefaultRetryBackoff = time.Second
+)
+
+// Payload is the JSON body POSTed to the webhook URL
+type Payload struct {
+	Event     string    `json:"event"`
+	EntryID   string    `json:"entry_id"`
+	Summary   string    `json:"summary,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Notifier delivers entry processed and failed events to an external URL.
+// Delivery is best-effort: it happens in the background and failures are
+// only logged, each retried attempt as a warning and the last as an error.
+type Notifier struct {
+	url        string
+	secret     []byte
+	httpClient *http.Client
+	maxRetries int
+	backoff    time.Duration
+}
+
+// NewNotifier creates a notifier posting to url. An empty secret sends
+// unsigned requests.
+func NewNotifier(url, secret string) *Notifier {
+	return &Notifier{
+		url:        url,
+		secret:     []byte(secret),
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		maxRetries: DefaultMaxRetries,
+		backoff:    DefaultRetryBackoff,
+	}
+}
+
+// WithBackoff overrides the wait before the first retry
+func (n *Notifier) WithBackoff(d time.Duration) *Notifier {
+	n.backoff = d
+	return n
+}
+
+// HandleEvent is an events.Broadcaster hook. It ignores everything except
+// entry processed and failed events and never blocks the caller.
+func (n *Notifier) HandleEvent(event *events.Event) {
+	payload, ok := payloadFor(event)
+	if !ok {
+		return
+	}
+
+	// Deliver logs every failed attempt itself
+	go n.Deliver(context.Background(), payload)
+}
+
+// Deliver POSTs payload to the webhook URL, retrying on connection errors
+// and non-2xx responses. Failed attempts are logged with the URL, event and
+// attempt number.
+func (n *Notifier) Deliver(ctx context.Context, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	backoff := n.backoff
+	for attempt := 0; ; attempt++ {
+		err = n.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if attempt >= n.maxRetries {
+			slog.Error("Webhook delivery failed", "url", n.url, "event", payload.Event,
+				"entry_id", payload.EntryID, "attempt", attempt+1, "error", err)
+			return fmt.Errorf("%w (after %d attempts)", err, attempt+1)
+		}
+		slog.Warn("Webhook delivery attempt failed, retrying", "url", n.url, "event", payload.Event,
+			"entry_id", payload.EntryID, "attempt", attempt+1, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (n *Notifier) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(n.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(n.secret, body))
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the signature header value for body: "sha256=" followed by
+// the hex HMAC-SHA256 of body under secret
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// payloadFor builds the webhook payload for event, reporting false for
+// event types that are not delivered
+func payloadFor(event *events.Event) (Payload, bool) {
+	eventType := events.EventType(event.Type)
+	if eventType != events.EventEntryProcessed && eventType != events.EventEntryFailed {
+		return Payload{}, false
+	}
+
+	payload := Payload{
+		Event:     event.Type,
+		EntryID:   event.EntryID,
+		Timestamp: event.Timestamp,
+	}
+
+	data, _ := event.Data.(map[string]interface{})
+	if entry, ok := data["entry"].(*models.JournalEntry); ok && entry != nil {
+		payload.Summary = entry.ProcessedData.Summary
+	}
+	if msg, ok := data["error"].(string); ok {
+		payload.Error = msg
+	}
+
+	return payload, true
+}
@@ -0,0 +1,103 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/journal/internal/events"
+	"github.com/journal/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeliverSignsAndRetries(t *testing.T) {
+	var calls int32
+	received := make(chan Payload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, Sign([]byte("s3cret"), body), r.Header.Get(SignatureHeader))
+
+		var p Payload
+		require.NoError(t, json.Unmarshal(body, &p))
+		received <- p
+	}))
+	defer server.Close()
+
+	notifier := NewNotifier(server.URL, "s3cret").WithBackoff(time.Millisecond)
+	err := notifier.Deliver(context.Background(), Payload{Event: "entry.processed", EntryID: "e1", Summary: "A walk"})
+	require.NoError(t, err)
+
+	p := <-received
+	assert.Equal(t, "e1", p.EntryID)
+	assert.Equal(t, "A walk", p.Summary)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestDeliverGivesUp(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	defaultLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	defer slog.SetDefault(defaultLogger)
+
+	notifier := NewNotifier(server.URL, "").WithBackoff(time.Millisecond)
+	err := notifier.Deliver(context.Background(), Payload{Event: "entry.failed", EntryID: "e1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "after 3 attempts")
+	assert.Equal(t, int32(DefaultMaxRetries+1), atomic.LoadInt32(&calls))
+
+	// One warning per retried attempt, then an error for the last
+	var records []map[string]interface{}
+	decoder := json.NewDecoder(&logs)
+	for decoder.More() {
+		var record map[string]interface{}
+		require.NoError(t, decoder.Decode(&record))
+		records = append(records, record)
+	}
+	require.Len(t, records, DefaultMaxRetries+1)
+	for i, record := range records {
+		level := "WARN"
+		if i == DefaultMaxRetries {
+			level = "ERROR"
+		}
+		assert.Equal(t, level, record["level"])
+		assert.Equal(t, server.URL, record["url"])
+		assert.Equal(t, "entry.failed", record["event"])
+		assert.Equal(t, float64(i+1), record["attempt"])
+	}
+}
+
+func TestPayloadFor(t *testing.T) {
+	entry := &models.JournalEntry{ID: "e1", ProcessedData: models.ProcessedData{Summary: "A walk"}}
+
+	p, ok := payloadFor(&events.Event{Type: string(events.EventEntryProcessed), EntryID: "e1",
+		Data: map[string]interface{}{"entry": entry}})
+	require.True(t, ok)
+	assert.Equal(t, "A walk", p.Summary)
+
+	p, ok = payloadFor(&events.Event{Type: string(events.EventEntryFailed), EntryID: "e2",
+		Data: map[string]interface{}{"error": "timeout"}})
+	require.True(t, ok)
+	assert.Equal(t, "timeout", p.Error)
+
+	_, ok = payloadFor(&events.Event{Type: string(events.EventEntryCreated), EntryID: "e3"})
+	assert.False(t, ok)
+}
@@ -2,6 +2,14 @@ import axios from 'axios';
 
 const API_URL = 'http://localhost:8080/api/rpc';
 
+// JSON-RPC error codes returned by the server, by error class
+const ERROR_KINDS = {
+  [-32602]: 'validation',
+  [-32001]: 'not_found',
+  [-32002]: 'conflict',
+  [-32003]: 'unavailable',
+};
+
 class JSONRPCClient {
   constructor() {
     this.id = 0;
@@ -16,7 +24,11 @@ class JSONRPCClient {
     });
 
     if (response.data.error) {
-      throw new Error(response.data.error.message);
+      const { code, message, data } = response.data.error;
+      const error = new Error(typeof data === 'string' ? data : message);
+      error.code = code;
+      error.kind = ERROR_KINDS[code] || 'server';
+      throw error;
     }
 
     return response.data.result;
@@ -27,28 +39,82 @@ const client = new JSONRPCClient();
 
 export const journalAPI = {
   // Journal entries
-  createEntry: (content) => client.call('journal.create', { content }),
-  updateEntry: (id, content) => client.call('journal.update', { id, content }),
+  createEntry: (content, idempotencyKey, attachments, createdAt, process = true) =>
+    client.call('journal.create', { content, idempotency_key: idempotencyKey, attachments, created_at: createdAt, process }),
+  updateEntry: (id, content, attachments) => client.call('journal.update', { id, content, attachments }),
   getEntry: (id) => client.call('journal.get', { id }),
+  getEntries: (ids) => client.call('journal.getMany', { ids }),
+  getEntryHistory: (id) => client.call('journal.getHistory', { id }),
+  restoreVersion: (versionId) => client.call('journal.restoreVersion', { version_id: versionId }),
+  deleteEntry: (id) => client.call('journal.delete', { id }),
+  restoreEntry: (id) => client.call('journal.restore', { id }),
+  addAttachment: (entryId, attachment) => client.call('journal.addAttachment', { entry_id: entryId, attachment }),
+  getRelatedEntries: (entryId, limit) => client.call('journal.getRelated', { entry_id: entryId, limit }),
+  findDuplicates: (threshold) => client.call('journal.findDuplicates', { threshold }),
+  mergeEntries: (keepId, mergeIds) => client.call('journal.merge', { keep_id: keepId, merge_ids: mergeIds }),
+  exportEntry: (id) => client.call('journal.exportEntry', { id }),
+  getOnThisDay: (date) => client.call('journal.onThisDay', date ? { date } : {}),
   search: (params) => client.call('journal.search', params),
+  countEntries: (params) => client.call('journal.count', params),
+  searchPage: (params, afterCursor) =>
+    client.call('journal.search', { ...params, search_type: 'classic', paginate: true, after_cursor: afterCursor }),
   toggleFavorite: (id) => client.call('journal.toggleFavorite', { id }),
-  getProcessingLogs: (entryId) => client.call('journal.getProcessingLogs', { entry_id: entryId }),
+  batchFavorite: (ids, favorite) => client.call('journal.batchFavorite', { ids, favorite }),
+  togglePin: (id) => client.call('journal.togglePin', { id }),
+  getProcessingLogs: (entryId, options = {}) =>
+    client.call('journal.getProcessingLogs', { entry_id: entryId, ...options }),
+  listByStage: (stage, limit) => client.call('journal.listByStage', { stage, limit }),
+  listLowQuality: (threshold, limit) => client.call('journal.listLowQuality', { threshold, limit }),
   analyzeFailure: (entryId) => client.call('journal.analyzeFailure', { entry_id: entryId }),
+  analyzeAllFailures: (useAI = false) => client.call('journal.analyzeAllFailures', { use_ai: useAI }),
   retryProcessing: (entryId) => client.call('journal.retryProcessing', { entry_id: entryId }),
+  cancelProcessing: (entryId) => client.call('journal.cancelProcessing', { entry_id: entryId }),
+  processDraft: (entryId) => client.call('journal.process', { entry_id: entryId }),
+  reprocessEntry: (entryId) => client.call('journal.reprocess', { entry_id: entryId, confirm: true }),
+  reprocessAll: (filter = {}) => client.call('journal.reprocessAll', filter),
+  cancelReprocess: () => client.call('journal.cancelReprocess', {}),
   getSearchSuggestions: () => client.call('journal.getSearchSuggestions', {}),
+  getFacet: (kind, prefix = '', limit = 0) => client.call('journal.getFacet', { kind, prefix, limit }),
+  clearSearchHistory: () => client.call('journal.clearSearchHistory', {}),
+  getAnalytics: (params = {}) => client.call('journal.getAnalytics', params),
+  getSentimentTrend: (params = {}) => client.call('journal.getSentimentTrend', params),
+  getTopicGraph: () => client.call('journal.getTopicGraph', {}),
+  suggestCollections: (entryId, minScore) =>
+    client.call('journal.suggestCollections', { entry_id: entryId, min_score: minScore }),
 
   // Collections
   createCollection: (name, description) => 
     client.call('collection.create', { name, description }),
+  createSmartCollection: (name, description, queryParams) =>
+    client.call('collection.createSmart', { name, description, query_params: queryParams }),
   getCollections: () => client.call('collection.list', {}),
+  getCollectionEntries: (collectionId, limit, offset) =>
+    client.call('collection.entries', { collection_id: collectionId, limit, offset }),
+  updateCollection: (id, name, description) =>
+    client.call('collection.update', { id, name, description }),
+  deleteCollection: (id) => client.call('collection.delete', { id }),
+  exportCollection: (collectionId, format = 'markdown') =>
+    client.call('collection.export', { collection_id: collectionId, format }),
   addToCollection: (entryId, collectionId) => 
     client.call('collection.addEntry', { entry_id: entryId, collection_id: collectionId }),
   removeFromCollection: (entryId, collectionId) => 
     client.call('collection.removeEntry', { entry_id: entryId, collection_id: collectionId }),
+  batchAddToCollection: (entryIds, collectionId) =>
+    client.call('collection.batchAddEntries', { entry_ids: entryIds, collection_id: collectionId }),
+  batchRemoveFromCollection: (entryIds, collectionId) =>
+    client.call('collection.batchRemoveEntries', { entry_ids: entryIds, collection_id: collectionId }),
+
+  // Import a Markdown file with front matter, or a zip of them
+  importEntries: async (file) => {
+    const form = new FormData();
+    form.append('file', file);
+    const response = await axios.post('http://localhost:8080/api/import', form);
+    return response.data;
+  },
 
   // Evaluation endpoints
-  generateTestData: (size = 100) => 
-    client.call('evaluation.generateTestData', { size }),
+  generateTestData: (size = 100, dryRun = false) => 
+    client.call('evaluation.generateTestData', { size, dry_run: dryRun }),
   runEvaluation: (mode = 'all') => 
     client.call('evaluation.run', { mode }),
   generateReport: (format = 'html') => 
@@ -57,4 +123,7 @@ export const journalAPI = {
     client.call('evaluation.getLatestResults', {}),
   runFullEvaluation: (size = 100) => 
     client.call('evaluation.runFull', { size }),
+
+  // System
+  getSystemInfo: () => client.call('system.info', {}),
 };
\ No newline at end of file
@@ -1,4 +1,4 @@
-import { Star, Tag, Calendar, Brain, Loader2, AlertCircle, CheckCircle2, Package, Cpu, Link, Database } from 'lucide-react';
+import { Star, Pin, Tag, Calendar, Brain, Loader2, AlertCircle, CheckCircle2, Package, Cpu, Link, Database } from 'lucide-react';
 import { format } from 'date-fns';
 
 function EntryCard({ entry, isSelected, onClick }) {
@@ -12,8 +12,12 @@ function EntryCard({ entry, isSelected, onClick }) {
   };
 
   const similarityScore = entry.processed_data?.metadata?.similarity_score;
+  const contrastScore = entry.processed_data?.metadata?.contrast_score;
   const processingStage = entry.processing_stage || 'created';
-  const isProcessing = processingStage !== 'completed' && processingStage !== 'failed';
+  // Entries completed without an embedding are done; retrying adds the embedding
+  const isFinished = processingStage === 'completed' || processingStage === 'completed_no_embedding';
+  const isCancelled = processingStage === 'cancelled';
+  const isProcessing = !isFinished && !isCancelled && processingStage !== 'failed';
   const hasFailed = processingStage === 'failed';
   const processingError = entry.processing_error;
   
@@ -90,9 +94,15 @@ function EntryCard({ entry, isSelected, onClick }) {
                 <span>{Math.round(similarityScore * 100)}% match</span>
               </>
             )}
+            {contrastScore != null && (
+              <>
+                <Brain className="w-3 h-3 ml-2" />
+                <span>{Math.round(contrastScore * 100)}% contrast</span>
+              </>
+            )}
           </div>
           {/* Processing Stage Icons */}
-          {processingStage !== 'completed' && (
+          {!isFinished && (
             <div className="flex items-center gap-1 mt-1">
               {getStageIcon('created')}
               <span className="text-gray-400">→</span>
@@ -106,9 +116,14 @@ function EntryCard({ entry, isSelected, onClick }) {
             </div>
           )}
         </div>
-        {entry.is_favorite && (
-          <Star className="w-4 h-4 text-yellow-500 fill-current" />
-        )}
+        <div className="flex items-center gap-1">
+          {entry.pinned_at && (
+            <Pin className="w-4 h-4 text-blue-500 fill-current" />
+          )}
+          {entry.is_favorite && (
+            <Star className="w-4 h-4 text-yellow-500 fill-current" />
+          )}
+        </div>
       </div>
 
       {/* Summary */}
@@ -2,7 +2,7 @@ import { useState, useEffect } from 'react';
 import { useMutation, useQuery, useQueryClient } from '@tanstack/react-query';
 import { journalAPI } from '../api/client';
 import { format } from 'date-fns';
-import { X, Star, Edit2, Save, Tag, Link2, Brain, Calendar, Hash, Maximize2, Minimize2, ChevronDown, ChevronUp } from 'lucide-react';
+import { X, Star, Pin, Edit2, Save, Tag, Link2, Brain, Calendar, Hash, Maximize2, Minimize2, ChevronDown, ChevronUp } from 'lucide-react';
 import ProcessingTracker from './ProcessingTracker';
 import ProcessingLogsModal from './ProcessingLogsModal';
 import { useKeyboardShortcuts, SHORTCUTS } from '../hooks/useKeyboardShortcuts';
@@ -45,6 +45,19 @@ function EntryEditor({ entry, onClose, onUpdate }) {
     },
   });
 
+  const togglePinMutation = useMutation({
+    mutationFn: (id) => journalAPI.togglePin(id),
+    onSuccess: (result) => {
+      entry.pinned_at = result.pinned_at;
+      queryClient.invalidateQueries(['entries']);
+      onUpdate();
+    },
+    onError: (error) => {
+      console.error('Failed to toggle pin:', error);
+      alert(`Failed to toggle pin: ${error.message || 'Unknown error occurred'}`);
+    },
+  });
+
   const handleSave = () => {
     if (editContent.trim() !== entry.content) {
       updateMutation.mutate({ id: entry.id, content: editContent });
@@ -137,6 +150,19 @@ function EntryEditor({ entry, onClose, onUpdate }) {
               <Maximize2 className="w-5 h-5" />
             )}
           </button>
+          <button
+            onClick={() => togglePinMutation.mutate(entry.id)}
+            disabled={togglePinMutation.isPending}
+            title={entry.pinned_at ? 'Unpin' : 'Pin to top'}
+            className={`p-2 rounded-lg transition-colors ${
+              togglePinMutation.isPending ? 'opacity-50 cursor-not-allowed' :
+              entry.pinned_at
+                ? 'text-blue-500 hover:bg-blue-50 dark:hover:bg-blue-900/20'
+                : 'text-gray-400 hover:bg-gray-100 dark:hover:bg-gray-700'
+            }`}
+          >
+            <Pin className="w-5 h-5" fill={entry.pinned_at ? 'currentColor' : 'none'} />
+          </button>
           <button
             onClick={handleToggleFavorite}
             disabled={toggleFavoriteMutation.isPending}
@@ -177,7 +203,7 @@ function EntryEditor({ entry, onClose, onUpdate }) {
           : 'p-6'
       }`}>
         {/* Processing Tracker */}
-        {(showProcessingTracker || (entry.processing_stage && entry.processing_stage !== 'completed')) && (
+        {(showProcessingTracker || (entry.processing_stage && entry.processing_stage !== 'completed' && entry.processing_stage !== 'completed_no_embedding')) && (
           <ProcessingTracker 
             entry={entry} 
             onViewLogs={() => setShowLogsModal(true)}
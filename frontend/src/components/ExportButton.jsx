@@ -1,5 +1,5 @@
 import { useState } from 'react';
-import { Download, FileJson, FileText, FileSpreadsheet } from 'lucide-react';
+import { Download, FileJson, FileText, FileSpreadsheet, Archive } from 'lucide-react';
 
 function ExportButton({ searchParams }) {
   const [isExporting, setIsExporting] = useState(false);
@@ -9,9 +9,10 @@ function ExportButton({ searchParams }) {
     { id: 'json', name: 'JSON', icon: FileJson, description: 'Complete data with metadata' },
     { id: 'markdown', name: 'Markdown', icon: FileText, description: 'Formatted for reading' },
     { id: 'csv', name: 'CSV', icon: FileSpreadsheet, description: 'For spreadsheet apps' },
+    { id: 'jsonl', name: 'Raw Backup', icon: Archive, description: 'Every entry, re-importable', raw: true },
   ];
 
-  const handleExport = async (format) => {
+  const handleExport = async (format, raw = false) => {
     setIsExporting(true);
     setShowMenu(false);
 
@@ -19,16 +20,21 @@ function ExportButton({ searchParams }) {
       // Build query string
       const params = new URLSearchParams();
       params.append('format', format);
+
+      // A raw backup always covers the whole journal, ignoring filters
+      if (raw) {
+        params.append('raw', 'true');
+      }
       
-      if (searchParams.query) {
+      if (!raw && searchParams.query) {
         params.append('query', searchParams.query);
       }
       
-      if (searchParams.is_favorite) {
+      if (!raw && searchParams.is_favorite) {
         params.append('is_favorite', 'true');
       }
       
-      if (searchParams.collection_ids?.length > 0) {
+      if (!raw && searchParams.collection_ids?.length > 0) {
         params.append('collection_ids', searchParams.collection_ids.join(','));
       }
 
@@ -83,7 +89,7 @@ function ExportButton({ searchParams }) {
               return (
                 <button
                   key={format.id}
-                  onClick={() => handleExport(format.id)}
+                  onClick={() => handleExport(format.id, format.raw)}
                   className="w-full text-left p-2 rounded hover:bg-gray-100 dark:hover:bg-gray-700 transition-colors"
                 >
                   <div className="flex items-start gap-3">
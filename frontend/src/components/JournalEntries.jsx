@@ -3,15 +3,24 @@ import { journalAPI } from '../api/client';
 import EntryCard from './EntryCard';
 import CollectionView from './CollectionView';
 import ExportButton from './ExportButton';
-import { Loader2 } from 'lucide-react';
+import { Loader2, AlertTriangle } from 'lucide-react';
 
 function JournalEntries({ searchParams, onSelectEntry, selectedEntry }) {
-  const { data: entries = [], isLoading, error } = useQuery({
+  const { data: result, isLoading, error } = useQuery({
     queryKey: ['entries', searchParams],
     queryFn: () => journalAPI.search(searchParams),
     enabled: true,
   });
 
+  // Hybrid search wraps its entries and flags when semantic search was down
+  const entries = (Array.isArray(result) ? result : result?.entries) ?? [];
+  const degradedBanner = result?.degraded && (
+    <div className="px-4 py-2 flex items-center gap-2 text-sm text-yellow-700 bg-yellow-50 dark:text-yellow-300 dark:bg-yellow-900/30">
+      <AlertTriangle className="w-4 h-4" />
+      <span>Showing keyword matches only: {result.reason}</span>
+    </div>
+  );
+
   // Group entries by collection if we're filtering by collections
   const isCollectionView = searchParams.collection_ids && searchParams.collection_ids.length > 0;
 
@@ -36,10 +45,13 @@ function JournalEntries({ searchParams, onSelectEntry, selectedEntry }) {
 
   if (entries.length === 0) {
     return (
-      <div className="h-full flex items-center justify-center p-8">
-        <div className="text-center text-gray-500 dark:text-gray-400">
-          <p className="text-lg font-medium">No entries found</p>
-          <p className="text-sm mt-1">Try adjusting your search criteria</p>
+      <div className="h-full flex flex-col">
+        {degradedBanner}
+        <div className="flex-1 flex items-center justify-center p-8">
+          <div className="text-center text-gray-500 dark:text-gray-400">
+            <p className="text-lg font-medium">No entries found</p>
+            <p className="text-sm mt-1">Try adjusting your search criteria</p>
+          </div>
         </div>
       </div>
     );
@@ -66,6 +78,7 @@ function JournalEntries({ searchParams, onSelectEntry, selectedEntry }) {
         </h2>
         <ExportButton searchParams={searchParams} />
       </div>
+      {degradedBanner}
       
       {/* Entries list */}
       <div className="flex-1 overflow-y-auto p-4 space-y-3">
@@ -1,5 +1,5 @@
 import React, { useMemo, useState, useEffect } from 'react';
-import { CheckCircle, AlertCircle, Loader, Link, Package, Cpu, Database, RefreshCw, ChevronUp } from 'lucide-react';
+import { CheckCircle, AlertCircle, Loader, Link, Package, Cpu, Database, RefreshCw, ChevronUp, XCircle } from 'lucide-react';
 import { useMutation, useQueryClient } from '@tanstack/react-query';
 import { journalAPI } from '../api/client';
 
@@ -40,18 +40,29 @@ const ProcessingTracker = ({ entry, onViewLogs }) => {
     },
   });
   
+  const cancelMutation = useMutation({
+    mutationFn: () => journalAPI.cancelProcessing(entry.id),
+    onSuccess: () => {
+      queryClient.invalidateQueries(['entries']);
+    },
+    onError: (error) => {
+      console.error('Failed to cancel processing:', error);
+      alert(`Failed to cancel processing: ${error.message || 'Unknown error occurred'}`);
+    },
+  });
+
+  const isRunning = ['created', 'analyzing', 'fetching_urls', 'generating_embeddings'].includes(entry.processing_stage);
+
   // Update timer every second while processing
   useEffect(() => {
-    if (entry.processing_stage && 
-        entry.processing_stage !== 'completed' && 
-        entry.processing_stage !== 'failed') {
+    if (isRunning) {
       const interval = setInterval(() => {
         setCurrentTime(Date.now());
       }, 1000);
       
       return () => clearInterval(interval);
     }
-  }, [entry.processing_stage]);
+  }, [isRunning]);
   
   const stages = useMemo(() => [
     {
@@ -219,7 +230,17 @@ const ProcessingTracker = ({ entry, onViewLogs }) => {
               Time: {getProcessingTime()}
             </span>
           )}
-          {entry.processing_stage === 'failed' && (
+          {isRunning && (
+            <button
+              onClick={() => cancelMutation.mutate()}
+              disabled={cancelMutation.isPending}
+              className="text-sm text-red-600 hover:text-red-700 flex items-center gap-1 disabled:opacity-50 disabled:cursor-not-allowed"
+            >
+              <XCircle className="w-4 h-4" />
+              {cancelMutation.isPending ? 'Cancelling...' : 'Cancel'}
+            </button>
+          )}
+          {(entry.processing_stage === 'failed' || entry.processing_stage === 'cancelled') && (
             <div className="flex items-center gap-2">
               <button
                 onClick={onViewLogs}
@@ -354,6 +375,7 @@ const ProcessingTracker = ({ entry, onViewLogs }) => {
             {entry.processing_stage === 'fetching_urls' && 'Fetching content from linked URLs...'}
             {entry.processing_stage === 'generating_embeddings' && 'Creating semantic search index...'}
             {entry.processing_stage === 'created' && 'Preparing to process your entry...'}
+            {entry.processing_stage === 'cancelled' && 'Processing was cancelled.'}
           </p>
         </div>
       )}
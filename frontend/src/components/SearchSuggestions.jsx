@@ -72,16 +72,19 @@ function SearchSuggestions({ onSelectSuggestion }) {
         <div>
           <h4 className="flex items-center gap-2 text-xs font-medium text-gray-600 dark:text-gray-400 mb-2">
             <Clock className="w-3 h-3" />
-            Recent Entries
+            Recent Searches
           </h4>
           <div className="space-y-1">
-            {suggestions.recent.map((phrase, idx) => (
+            {suggestions.recent.map((search, idx) => (
               <button
                 key={idx}
-                onClick={() => onSelectSuggestion(phrase)}
+                onClick={() => onSelectSuggestion(search.text)}
                 className="w-full text-left px-2 py-1 text-xs text-gray-600 dark:text-gray-400 hover:bg-gray-100 dark:hover:bg-gray-700 rounded transition-colors truncate"
               >
-                "{phrase}"
+                "{search.text}"
+                {search.count > 1 && (
+                  <span className="ml-1 text-gray-400 dark:text-gray-500">({search.count})</span>
+                )}
               </button>
             ))}
           </div>
@@ -17,7 +17,8 @@ function Sidebar({ activeTab, onTabChange, tabs, onSearch, searchParams, isColla
   });
 
   const createEntryMutation = useMutation({
-    mutationFn: (content) => journalAPI.createEntry(content),
+    // One key per submission, so a retried request returns the same entry
+    mutationFn: ({ content, idempotencyKey }) => journalAPI.createEntry(content, idempotencyKey),
     onSuccess: (newEntry) => {
       // The SSE event will handle adding to cache, but we can do optimistic update
       setNewEntryContent('');
@@ -33,7 +34,7 @@ function Sidebar({ activeTab, onTabChange, tabs, onSearch, searchParams, isColla
 
   const handleCreateEntry = () => {
     if (!newEntryContent.trim()) return;
-    createEntryMutation.mutate(newEntryContent);
+    createEntryMutation.mutate({ content: newEntryContent, idempotencyKey: crypto.randomUUID() });
   };
 
   return (
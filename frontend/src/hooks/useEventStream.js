@@ -137,6 +137,24 @@ export function useEventStream() {
             });
             break;
 
+          case 'entry.analyzing.progress':
+            // Show the partial summary while the analysis is still streaming
+            queryClient.setQueriesData(['entries'], (oldData) => {
+              if (!oldData) return oldData;
+
+              return oldData.map(entry => {
+                if (entry.id !== data.entry_id || !data.data.partial_summary) return entry;
+                return {
+                  ...entry,
+                  processed_data: {
+                    ...entry.processed_data,
+                    summary: data.data.partial_summary
+                  }
+                };
+              });
+            });
+            break;
+
           case 'entry.processed':
             console.log('Processing completed event received:', data);
             
@@ -220,6 +238,48 @@ export function useEventStream() {
             });
             break;
 
+          case 'entry.cancelled':
+            queryClient.setQueriesData(['entries'], (oldData) => {
+              if (!oldData) return oldData;
+
+              return oldData.map(entry => {
+                if (entry.id !== data.entry_id) return entry;
+                return {
+                  ...entry,
+                  processing_stage: 'cancelled',
+                  processing_error: null,
+                  processing_completed_at: new Date().toISOString()
+                };
+              });
+            });
+
+            queryClient.setQueryData(['entry', data.entry_id], (oldEntry) => {
+              if (!oldEntry) return oldEntry;
+              return {
+                ...oldEntry,
+                processing_stage: 'cancelled',
+                processing_error: null,
+                processing_completed_at: new Date().toISOString()
+              };
+            });
+            break;
+
+          case 'entry.related':
+            // Earlier entries close to a new one; read with useQuery(['related', id])
+            queryClient.setQueryData(['related', data.entry_id], data.data.related || []);
+            break;
+
+          case 'collection.created':
+          case 'collection.updated':
+            queryClient.invalidateQueries(['collections']);
+            break;
+
+          case 'collection.deleted':
+            // Entries may still list the deleted collection in collection_ids
+            queryClient.invalidateQueries(['collections']);
+            queryClient.invalidateQueries(['entries']);
+            break;
+
           default:
             console.log('Unknown event type:', data.type);
         }
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// contentKind selects the extraction path for a fetched body
+type contentKind int
+
+const (
+	kindText contentKind = iota
+	kindHTML
+	kindJSON
+)
+
+// sniffLength is how much of a body is inspected when the Content-Type
+// header does not settle its type, matching http.DetectContentType
+const sniffLength = 512
+
+// detectContentKind classifies a body by its Content-Type header, falling
+// back to sniffing the first bytes when the header is missing or generic.
+// Servers commonly send HTML as application/octet-stream or with no type.
+func detectContentKind(contentType string, body []byte) contentKind {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	mediaType = strings.ToLower(mediaType)
+
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		return kindHTML
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return kindJSON
+	case mediaType == "" || mediaType == "text/plain" ||
+		mediaType == "application/octet-stream" || mediaType == "binary/octet-stream":
+		return sniffContentKind(body)
+	}
+	return kindText
+}
+
+// sniffContentKind guesses the kind of a body from its leading bytes
+func sniffContentKind(body []byte) contentKind {
+	head := body
+	if len(head) > sniffLength {
+		head = head[:sniffLength]
+	}
+
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(head, utf8BOM))
+	lower := bytes.ToLower(trimmed)
+	if bytes.HasPrefix(lower, []byte("<!doctype html")) || bytes.Contains(lower, []byte("<html")) {
+		return kindHTML
+	}
+	if strings.HasPrefix(http.DetectContentType(body), "text/html") {
+		return kindHTML
+	}
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(bytes.TrimSpace(body)) {
+		return kindJSON
+	}
+	return kindText
+}
+
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// decodeToUTF8 converts body to UTF-8 text. The charset comes from a byte
+// order mark, the Content-Type header, or for HTML a <meta> charset
+// declaration. UTF-8, UTF-16 and Latin-1/Windows-1252 are decoded; bodies in
+// other charsets keep their valid UTF-8 and have invalid bytes replaced.
+func decodeToUTF8(body []byte, contentType string, html bool) string {
+	switch {
+	case bytes.HasPrefix(body, utf8BOM):
+		return strings.ToValidUTF8(string(body[len(utf8BOM):]), "�")
+	case bytes.HasPrefix(body, utf16LEBOM):
+		return decodeUTF16(body[len(utf16LEBOM):], false)
+	case bytes.HasPrefix(body, utf16BEBOM):
+		return decodeUTF16(body[len(utf16BEBOM):], true)
+	}
+
+	charset := ""
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		charset = params["charset"]
+	}
+	if charset == "" && html {
+		charset = metaCharset(body)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(charset)) {
+	case "utf-16le":
+		return decodeUTF16(body, false)
+	case "utf-16", "utf-16be":
+		return decodeUTF16(body, true)
+	case "iso-8859-1", "latin1", "latin-1", "windows-1252", "cp1252", "us-ascii", "ascii":
+		// Browsers treat all of these as Windows-1252, a superset of ASCII
+		// and Latin-1's printable range
+		return decodeWindows1252(body)
+	case "", "utf-8", "utf8":
+		if utf8.Valid(body) {
+			return string(body)
+		}
+		// Undeclared or mislabelled legacy pages are most often Windows-1252
+		return decodeWindows1252(body)
+	}
+	return strings.ToValidUTF8(string(body), "�")
+}
+
+// metaCharset returns the charset declared by a <meta charset> or
+// <meta http-equiv="Content-Type"> tag near the start of an HTML document
+func metaCharset(body []byte) string {
+	head := body
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	lower := strings.ToLower(string(head))
+
+	i := strings.Index(lower, "charset=")
+	if i == -1 {
+		return ""
+	}
+	value := strings.TrimLeft(lower[i+len("charset="):], `"' `)
+	end := strings.IndexAny(value, `"'; />`)
+	if end == -1 {
+		return ""
+	}
+	return value[:end]
+}
+
+func decodeUTF16(body []byte, bigEndian bool) string {
+	units := make([]uint16, 0, len(body)/2)
+	for i := 0; i+1 < len(body); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(body[i])<<8|uint16(body[i+1]))
+		} else {
+			units = append(units, uint16(body[i+1])<<8|uint16(body[i]))
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// windows1252High maps bytes 0x80-0x9F, where Windows-1252 differs from
+// Latin-1; zero entries are undefined and become U+FFFD
+var windows1252High = [32]rune{
+	'€', 0, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+	0, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+}
+
+func decodeWindows1252(body []byte) string {
+	var sb strings.Builder
+	sb.Grow(len(body))
+	for _, b := range body {
+		switch {
+		case b < 0x80:
+			sb.WriteByte(b)
+		case b < 0xA0:
+			r := windows1252High[b-0x80]
+			if r == 0 {
+				r = utf8.RuneError
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune(rune(b))
+		}
+	}
+	return sb.String()
+}
+
+// defaultMinContentLength is the fewest characters of extracted text worth
+// summarizing and embedding; MIN_CONTENT_LENGTH overrides it
+const defaultMinContentLength = 200
+
+// blockedPageMaxLength bounds the pages checked for consent walls and bot
+// checks. Longer pages that merely mention cookies are real content.
+const blockedPageMaxLength = 2000
+
+// blockedPageMarkers are phrases of consent walls, bot checks and soft error
+// pages, matched case-insensitively
+var blockedPageMarkers = []string{
+	"accept all cookies",
+	"accept cookies",
+	"we use cookies",
+	"cookie consent",
+	"enable javascript",
+	"javascript is disabled",
+	"checking your browser",
+	"verify you are human",
+	"are you a robot",
+	"captcha",
+	"access denied",
+	"page not found",
+	"404 not found",
+}
+
+// lowContentReason explains why extracted text is not worth embedding: it is
+// shorter than minLength characters or reads like a consent, bot-check or
+// error page. It returns "" for useful content.
+func lowContentReason(content string, minLength int) string {
+	text := strings.TrimSpace(content)
+	length := utf8.RuneCountInString(text)
+	if length < minLength {
+		return fmt.Sprintf("only %d characters of content, fewer than %d", length, minLength)
+	}
+	if length <= blockedPageMaxLength {
+		lower := strings.ToLower(text)
+		for _, marker := range blockedPageMarkers {
+			if strings.Contains(lower, marker) {
+				return fmt.Sprintf("looks like a consent or blocked page (%q)", marker)
+			}
+		}
+	}
+	return ""
+}
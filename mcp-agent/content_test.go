@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectContentKind(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        contentKind
+	}{
+		{"html header", "text/html; charset=utf-8", "hello", kindHTML},
+		{"json header", "application/json", "{}", kindJSON},
+		{"octet-stream html", "application/octet-stream", "\n  <!DOCTYPE html><html><title>x</title></html>", kindHTML},
+		{"missing header html", "", "<html><body>hi</body></html>", kindHTML},
+		{"missing header json", "", `{"a": 1}`, kindJSON},
+		{"missing header text", "", "just some notes", kindText},
+		{"explicit markdown", "text/markdown", "<html>", kindText},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectContentKind(tt.contentType, []byte(tt.body)); got != tt.want {
+				t.Errorf("detectContentKind() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeToUTF8(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		html        bool
+		want        string
+	}{
+		{"utf-8", "text/plain; charset=utf-8", []byte("café"), false, "café"},
+		{"latin-1 header", "text/html; charset=ISO-8859-1", []byte("caf\xe9"), true, "café"},
+		{"windows-1252 quotes", "text/plain; charset=windows-1252", []byte("\x93hi\x94"), false, "“hi”"},
+		{"meta charset", "text/html", []byte(`<meta charset="windows-1252"><p>caf` + "\xe9"), true, `<meta charset="windows-1252"><p>café`},
+		{"undeclared invalid utf-8", "", []byte("caf\xe9"), false, "café"},
+		{"utf-16le bom", "", []byte{0xFF, 0xFE, 'h', 0, 'i', 0}, false, "hi"},
+		{"utf-8 bom", "", []byte("\xEF\xBB\xBFhi"), false, "hi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := decodeToUTF8(tt.body, tt.contentType, tt.html); got != tt.want {
+				t.Errorf("decodeToUTF8() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLowContentReason(t *testing.T) {
+	article := strings.Repeat("The trail climbs steadily through pine forest. ", 10)
+	tests := []struct {
+		name    string
+		content string
+		low     bool
+	}{
+		{"empty", "   ", true},
+		{"short", "Loading...", true},
+		{"article", article, false},
+		{"consent wall", "We use cookies to improve your experience. " + article, true},
+		{"bot check", "Checking your browser before accessing the site. " + article, true},
+		{"long article mentioning cookies", strings.Repeat(article, 5) + "Accept cookies from the bakery.", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lowContentReason(tt.content, defaultMinContentLength); (got != "") != tt.low {
+				t.Errorf("lowContentReason() = %q, want low content %v", got, tt.low)
+			}
+		})
+	}
+}
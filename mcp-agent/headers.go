@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// defaultUserAgent identifies the agent to the sites it fetches. It is only
+// replaced when FETCH_USER_AGENT says so.
+const defaultUserAgent = "Journal-MCP-Agent/1.0"
+
+// rotateUserAgents is the FETCH_USER_AGENT value that cycles through
+// browserUserAgents
+const rotateUserAgents = "rotate"
+
+// browserUserAgents are current desktop browser user agents, for sites that
+// reject anything bot-like
+var browserUserAgents = []string{
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+	"Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+}
+
+// fetchHeaders are the identifying headers sent with every outgoing fetch
+type fetchHeaders struct {
+	userAgents []string
+	next       atomic.Uint64
+	extra      map[string]string
+}
+
+// requestHeaders is set from FETCH_USER_AGENT and FETCH_HEADERS at startup
+var requestHeaders = &fetchHeaders{userAgents: []string{defaultUserAgent}}
+
+// parseFetchHeaders builds the fetch headers from the values of
+// FETCH_USER_AGENT and FETCH_HEADERS. An empty userAgent keeps
+// defaultUserAgent, "rotate" cycles through browserUserAgents and anything
+// else is sent as is. extra is a JSON object of header names to values, such
+// as {"Accept-Language": "en-US,en;q=0.9"}; it may not set User-Agent.
+func parseFetchHeaders(userAgent, extra string) (*fetchHeaders, error) {
+	h := &fetchHeaders{}
+	switch userAgent = strings.TrimSpace(userAgent); userAgent {
+	case "":
+		h.userAgents = []string{defaultUserAgent}
+	case rotateUserAgents:
+		h.userAgents = browserUserAgents
+	default:
+		h.userAgents = []string{userAgent}
+	}
+
+	if strings.TrimSpace(extra) == "" {
+		return h, nil
+	}
+	if err := json.Unmarshal([]byte(extra), &h.extra); err != nil {
+		return nil, fmt.Errorf("FETCH_HEADERS must be a JSON object of header names to values: %w", err)
+	}
+	for name := range h.extra {
+		if http.CanonicalHeaderKey(name) == "User-Agent" {
+			return nil, fmt.Errorf("FETCH_HEADERS cannot set User-Agent; use FETCH_USER_AGENT")
+		}
+	}
+	return h, nil
+}
+
+// loadFetchHeaders sets requestHeaders from the environment
+func loadFetchHeaders() error {
+	h, err := parseFetchHeaders(os.Getenv("FETCH_USER_AGENT"), os.Getenv("FETCH_HEADERS"))
+	if err != nil {
+		return err
+	}
+	requestHeaders = h
+	return nil
+}
+
+// apply sets the user agent, the next one when rotating, and the custom
+// headers on req. Custom headers replace any the caller set.
+func (h *fetchHeaders) apply(req *http.Request) {
+	n := h.next.Add(1) - 1
+	req.Header.Set("User-Agent", h.userAgents[n%uint64(len(h.userAgents))])
+	for name, value := range h.extra {
+		req.Header.Set(name, value)
+	}
+}
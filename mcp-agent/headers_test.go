@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestParseFetchHeadersDefaultsToHonestAgent(t *testing.T) {
+	h, err := parseFetchHeaders("", "")
+	if err != nil {
+		t.Fatalf("parseFetchHeaders() error = %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", "https://example.com", nil)
+	h.apply(req)
+	if got := req.Header.Get("User-Agent"); got != defaultUserAgent {
+		t.Errorf("User-Agent = %q, want %q", got, defaultUserAgent)
+	}
+}
+
+func TestParseFetchHeadersCustomAgentAndHeaders(t *testing.T) {
+	h, err := parseFetchHeaders("MyJournal/2.0 (+mailto:me@example.com)", `{"accept-language": "en-US,en;q=0.9", "Accept": "text/html"}`)
+	if err != nil {
+		t.Fatalf("parseFetchHeaders() error = %v", err)
+	}
+
+	req, _ := http.NewRequest("GET", "https://example.com", nil)
+	req.Header.Set("Accept", "*/*")
+	h.apply(req)
+
+	want := map[string]string{
+		"User-Agent":      "MyJournal/2.0 (+mailto:me@example.com)",
+		"Accept-Language": "en-US,en;q=0.9",
+		"Accept":          "text/html",
+	}
+	for name, value := range want {
+		if got := req.Header.Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestParseFetchHeadersRotates(t *testing.T) {
+	h, err := parseFetchHeaders("rotate", "")
+	if err != nil {
+		t.Fatalf("parseFetchHeaders() error = %v", err)
+	}
+
+	for i := 0; i < 2*len(browserUserAgents); i++ {
+		req, _ := http.NewRequest("GET", "https://example.com", nil)
+		h.apply(req)
+		if got, want := req.Header.Get("User-Agent"), browserUserAgents[i%len(browserUserAgents)]; got != want {
+			t.Errorf("request %d: User-Agent = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestParseFetchHeadersRejectsInvalidHeaders(t *testing.T) {
+	tests := []struct {
+		name  string
+		extra string
+	}{
+		{"not json", "Accept-Language: en"},
+		{"not an object", `["Accept-Language"]`},
+		{"user agent", `{"user-agent": "Sneaky/1.0"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseFetchHeaders("", tt.extra); err == nil {
+				t.Errorf("parseFetchHeaders(%q) succeeded, want error", tt.extra)
+			}
+		})
+	}
+}
@@ -10,7 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
-	"strings"
+	"strconv"
 	"time"
 )
 
@@ -27,8 +27,15 @@ type HTTPFetchResult struct {
 	Content     string    `json:"content"`
 	ExtractedAt time.Time `json:"extracted_at"`
 	Source      string    `json:"source"`
+	// Metadata flags the result; "low_content" marks pages whose text is
+	// too thin or junk to embed
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// minContentLength is the fewest characters of extracted text a fetch must
+// yield to count as content, set from MIN_CONTENT_LENGTH
+var minContentLength = defaultMinContentLength
+
 // QwenRequest represents a request to Ollama/Qwen
 type QwenRequest struct {
 	Model  string `json:"model"`
@@ -45,6 +52,17 @@ type QwenResponse struct {
 }
 
 func startHTTPServer() {
+	if value := os.Getenv("MIN_CONTENT_LENGTH"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			log.Fatalf("Invalid MIN_CONTENT_LENGTH %q: want a non-negative number of characters", value)
+		}
+		minContentLength = n
+	}
+	if err := loadFetchHeaders(); err != nil {
+		log.Fatalf("Invalid fetch headers: %v", err)
+	}
+
 	// HTTP handler for fetching URLs
 	http.HandleFunc("/fetch", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -109,6 +127,23 @@ func fetchURLWithAnalysis(ctx context.Context, params HTTPFetchParams) (*HTTPFet
 		return nil, err
 	}
 
+	// Thin pages are returned as they are, flagged so the journal does not
+	// embed them; summarizing them would only dress up the junk
+	if reason := lowContentReason(content, minContentLength); reason != "" {
+		log.Printf("Low content at %s, skipping analysis: %s", parsedURL, reason)
+		return &HTTPFetchResult{
+			URL:         parsedURL.String(),
+			Title:       title,
+			Content:     content,
+			ExtractedAt: time.Now(),
+			Source:      parsedURL.Host,
+			Metadata: map[string]interface{}{
+				"low_content":        true,
+				"low_content_reason": reason,
+			},
+		}, nil
+	}
+
 	// Analyze with Qwen to create a summary
 	summary, err := analyzeContentWithQwen(content, params.Reason, title, parsedURL.String())
 	if err != nil {
@@ -133,13 +168,26 @@ func fetchWebContent(ctx context.Context, parsedURL *url.URL) (string, string, e
 		Timeout: 30 * time.Second,
 	}
 
+	// Use a YouTube video's details and transcript rather than its page
+	if videoID, ok := youtubeVideoID(parsedURL); ok {
+		video, err := fetchYouTubeVideo(ctx, client, videoID)
+		if err == nil {
+			content := video.content()
+			if len(content) > 10000 {
+				content = content[:10000] + "\n\n... (content truncated for processing)"
+			}
+			return content, video.Title, nil
+		}
+		log.Printf("Falling back to page fetch for YouTube video %s: %v", videoID, err)
+	}
+
 	req, err := http.NewRequestWithContext(ctx, "GET", parsedURL.String(), nil)
 	if err != nil {
 		return "", "", err
 	}
 
-	req.Header.Set("User-Agent", "Journal-MCP-Agent/1.0")
 	req.Header.Set("Accept", "text/html,application/json,text/plain,*/*")
+	requestHeaders.apply(req)
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -157,24 +205,27 @@ func fetchWebContent(ctx context.Context, parsedURL *url.URL) (string, string, e
 		return "", "", err
 	}
 
-	// Extract content based on content type
+	// Extract content based on content type, sniffing the body when the
+	// header is missing or generic
 	contentType := resp.Header.Get("Content-Type")
+	kind := detectContentKind(contentType, body)
+	text := decodeToUTF8(body, contentType, kind == kindHTML)
 	var title, content string
 
-	if strings.Contains(contentType, "text/html") {
-		title = extractTitle(string(body))
-		content = extractTextContent(string(body))
-	} else if strings.Contains(contentType, "application/json") {
+	if kind == kindHTML {
+		title = extractTitle(text)
+		content = extractTextContent(text)
+	} else if kind == kindJSON {
 		var jsonData interface{}
-		if err := json.Unmarshal(body, &jsonData); err == nil {
+		if err := json.Unmarshal([]byte(text), &jsonData); err == nil {
 			prettyJSON, _ := json.MarshalIndent(jsonData, "", "  ")
 			content = string(prettyJSON)
 		} else {
-			content = string(body)
+			content = text
 		}
 		title = "JSON Data from " + parsedURL.Host
 	} else {
-		content = string(body)
+		content = text
 		title = "Content from " + parsedURL.Host
 	}
 
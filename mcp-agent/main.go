@@ -43,14 +43,34 @@ func fetchURL(ctx context.Context, params FetchParams) (*FetchResult, error) {
 		Timeout: 30 * time.Second,
 	}
 
+	// YouTube watch pages are mostly boilerplate, so use the video's details
+	// and transcript when it has one
+	if videoID, ok := youtubeVideoID(parsedURL); ok {
+		video, err := fetchYouTubeVideo(ctx, client, videoID)
+		if err == nil {
+			content := video.content()
+			if len(content) > 5000 {
+				content = content[:5000] + "... (truncated)"
+			}
+			return &FetchResult{
+				URL:         parsedURL.String(),
+				Title:       video.Title,
+				Content:     content,
+				ExtractedAt: time.Now(),
+				Source:      parsedURL.Host,
+			}, nil
+		}
+		log.Printf("Falling back to page fetch for YouTube video %s: %v", videoID, err)
+	}
+
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "GET", parsedURL.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Set user agent
-	req.Header.Set("User-Agent", "Journal-MCP-Agent/1.0")
+	// Set user agent and custom headers
+	requestHeaders.apply(req)
 
 	// Fetch the URL
 	resp, err := client.Do(req)
@@ -69,28 +89,31 @@ func fetchURL(ctx context.Context, params FetchParams) (*FetchResult, error) {
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	// Extract content based on content type
+	// Extract content based on content type, sniffing the body when the
+	// header is missing or generic
 	contentType := resp.Header.Get("Content-Type")
+	kind := detectContentKind(contentType, body)
+	text := decodeToUTF8(body, contentType, kind == kindHTML)
 	var title, content string
 
-	if strings.Contains(contentType, "text/html") {
+	if kind == kindHTML {
 		// For HTML, we'd normally parse it properly
 		// For now, just extract basic content
-		title = extractTitle(string(body))
-		content = extractTextContent(string(body))
-	} else if strings.Contains(contentType, "application/json") {
+		title = extractTitle(text)
+		content = extractTextContent(text)
+	} else if kind == kindJSON {
 		// Pretty print JSON
 		var jsonData interface{}
-		if err := json.Unmarshal(body, &jsonData); err == nil {
+		if err := json.Unmarshal([]byte(text), &jsonData); err == nil {
 			prettyJSON, _ := json.MarshalIndent(jsonData, "", "  ")
 			content = string(prettyJSON)
 		} else {
-			content = string(body)
+			content = text
 		}
 		title = "JSON Data"
 	} else {
 		// Plain text or other
-		content = string(body)
+		content = text
 		title = "Text Content"
 	}
 
@@ -178,6 +201,10 @@ func extractTextContent(html string) string {
 }
 
 func main() {
+	if err := loadFetchHeaders(); err != nil {
+		log.Fatalf("Invalid fetch headers: %v", err)
+	}
+
 	// HTTP handler for fetching URLs
 	http.HandleFunc("/fetch", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
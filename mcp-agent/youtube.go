@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// youtubeBaseURL is where watch pages and the timedtext endpoint are fetched
+// from; tests point it at a local server
+var youtubeBaseURL = "https://www.youtube.com"
+
+// errNoTranscript means the video has no captions, so callers should fall
+// back to fetching the URL like any other page
+var errNoTranscript = errors.New("no transcript available")
+
+var youtubeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
+// youtubeVideo is what the agent extracts from a YouTube video
+type youtubeVideo struct {
+	ID          string
+	Title       string
+	Author      string
+	Description string
+	Transcript  string
+}
+
+// youtubeVideoID returns the video ID of a YouTube watch, short or embed URL
+func youtubeVideoID(u *url.URL) (string, bool) {
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	host = strings.TrimPrefix(host, "m.")
+
+	var id string
+	switch host {
+	case "youtu.be":
+		id = strings.Trim(u.Path, "/")
+	case "youtube.com", "music.youtube.com", "youtube-nocookie.com":
+		switch {
+		case u.Path == "/watch":
+			id = u.Query().Get("v")
+		case strings.HasPrefix(u.Path, "/shorts/"), strings.HasPrefix(u.Path, "/embed/"), strings.HasPrefix(u.Path, "/live/"):
+			parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+			if len(parts) >= 2 {
+				id = parts[1]
+			}
+		}
+	}
+
+	if !youtubeIDPattern.MatchString(id) {
+		return "", false
+	}
+	return id, true
+}
+
+// fetchYouTubeVideo reads the title, channel and description from the watch
+// page and the transcript from the timedtext endpoint. It returns
+// errNoTranscript when the video has no captions.
+func fetchYouTubeVideo(ctx context.Context, client *http.Client, id string) (*youtubeVideo, error) {
+	page, err := youtubeGet(ctx, client, youtubeBaseURL+"/watch?v="+id+"&hl=en")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch watch page: %w", err)
+	}
+
+	player, err := parsePlayerResponse(page)
+	if err != nil {
+		return nil, err
+	}
+
+	video := &youtubeVideo{
+		ID:          id,
+		Title:       player.VideoDetails.Title,
+		Author:      player.VideoDetails.Author,
+		Description: player.VideoDetails.ShortDescription,
+	}
+
+	trackURL := captionTrackURL(player.Captions.Renderer.CaptionTracks)
+	if trackURL == "" {
+		// Videos without listed tracks sometimes still serve English captions
+		trackURL = youtubeBaseURL + "/api/timedtext?lang=en&v=" + id
+	}
+
+	transcriptXML, err := youtubeGet(ctx, client, trackURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch transcript: %w", err)
+	}
+	video.Transcript = parseTimedText(transcriptXML)
+	if video.Transcript == "" {
+		return video, errNoTranscript
+	}
+
+	return video, nil
+}
+
+// content renders the video for embedding alongside the journal entry
+func (v *youtubeVideo) content() string {
+	var sb strings.Builder
+	sb.WriteString("Video: " + v.Title + "\n")
+	if v.Author != "" {
+		sb.WriteString("Channel: " + v.Author + "\n")
+	}
+	if desc := strings.TrimSpace(v.Description); desc != "" {
+		sb.WriteString("\nDescription:\n" + desc + "\n")
+	}
+	sb.WriteString("\nTranscript:\n" + v.Transcript)
+	return sb.String()
+}
+
+func youtubeGet(ctx context.Context, client *http.Client, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept-Language", "en")
+	requestHeaders.apply(req)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	// Watch pages are large; 4MB comfortably holds the player response
+	return io.ReadAll(io.LimitReader(resp.Body, 4*1024*1024))
+}
+
+type captionTrack struct {
+	BaseURL      string `json:"baseUrl"`
+	LanguageCode string `json:"languageCode"`
+	Kind         string `json:"kind"` // "asr" for automatic captions
+}
+
+// playerResponse is the subset of ytInitialPlayerResponse the agent reads
+type playerResponse struct {
+	VideoDetails struct {
+		Title            string `json:"title"`
+		Author           string `json:"author"`
+		ShortDescription string `json:"shortDescription"`
+	} `json:"videoDetails"`
+	Captions struct {
+		Renderer struct {
+			CaptionTracks []captionTrack `json:"captionTracks"`
+		} `json:"playerCaptionsTracklistRenderer"`
+	} `json:"captions"`
+}
+
+// parsePlayerResponse decodes the ytInitialPlayerResponse object embedded in
+// a watch page
+func parsePlayerResponse(page []byte) (*playerResponse, error) {
+	marker := []byte("ytInitialPlayerResponse")
+	i := bytes.Index(page, marker)
+	if i == -1 {
+		return nil, errors.New("watch page has no player response")
+	}
+	start := bytes.IndexByte(page[i:], '{')
+	if start == -1 {
+		return nil, errors.New("watch page has no player response")
+	}
+
+	// The decoder stops after the first complete object, ignoring the
+	// script that follows it
+	var player playerResponse
+	if err := json.NewDecoder(bytes.NewReader(page[i+start:])).Decode(&player); err != nil {
+		return nil, fmt.Errorf("failed to decode player response: %w", err)
+	}
+	return &player, nil
+}
+
+// captionTrackURL picks a timedtext URL, preferring manual English captions,
+// then automatic English captions, then the first track
+func captionTrackURL(tracks []captionTrack) string {
+	var best *captionTrack
+	score := func(t captionTrack) int {
+		s := 0
+		if strings.HasPrefix(t.LanguageCode, "en") {
+			s += 2
+		}
+		if t.Kind != "asr" {
+			s++
+		}
+		return s
+	}
+
+	for i := range tracks {
+		if tracks[i].BaseURL == "" {
+			continue
+		}
+		if best == nil || score(tracks[i]) > score(*best) {
+			best = &tracks[i]
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.BaseURL
+}
+
+// parseTimedText joins the caption lines of a timedtext XML document
+func parseTimedText(data []byte) string {
+	var doc struct {
+		Texts []string `xml:"text"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return ""
+	}
+
+	lines := make([]string, 0, len(doc.Texts))
+	for _, text := range doc.Texts {
+		// Caption text is HTML-escaped a second time inside the XML
+		line := strings.Join(strings.Fields(html.UnescapeString(text)), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, " ")
+}
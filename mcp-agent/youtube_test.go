@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestYoutubeVideoID(t *testing.T) {
+	tests := map[string]string{
+		"https://www.youtube.com/watch?v=dQw4w9WgXcQ&t=42": "dQw4w9WgXcQ",
+		"https://youtu.be/dQw4w9WgXcQ":                     "dQw4w9WgXcQ",
+		"https://m.youtube.com/shorts/dQw4w9WgXcQ":         "dQw4w9WgXcQ",
+		"https://www.youtube.com/embed/dQw4w9WgXcQ":        "dQw4w9WgXcQ",
+		"https://www.youtube.com/channel/UC123":            "",
+		"https://example.com/watch?v=dQw4w9WgXcQ":          "",
+	}
+
+	for raw, want := range tests {
+		u, _ := url.Parse(raw)
+		got, ok := youtubeVideoID(u)
+		if got != want || ok != (want != "") {
+			t.Errorf("youtubeVideoID(%s) = %q, %v; want %q", raw, got, ok, want)
+		}
+	}
+}
+
+func TestFetchYouTubeVideo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/watch":
+			w.Write([]byte(`<html><script>var ytInitialPlayerResponse = {"videoDetails":{"title":"Go Concurrency",` +
+				`"author":"GopherCon","shortDescription":"Patterns talk"},"captions":{"playerCaptionsTracklistRenderer":` +
+				`{"captionTracks":[{"baseUrl":"` + "http://" + r.Host + `/api/timedtext?v=x&lang=de","languageCode":"de"},` +
+				`{"baseUrl":"` + "http://" + r.Host + `/api/timedtext?v=x&lang=en","languageCode":"en"}]}}};var other = {};</script></html>`))
+		case "/api/timedtext":
+			if r.URL.Query().Get("lang") != "en" {
+				t.Errorf("fetched %s captions, want en", r.URL.Query().Get("lang"))
+			}
+			w.Write([]byte(`<transcript><text start="0">Channels are</text><text start="1">&amp;#39;first-class&amp;#39;</text></transcript>`))
+		}
+	}))
+	defer server.Close()
+
+	youtubeBaseURL = server.URL
+	defer func() { youtubeBaseURL = "https://www.youtube.com" }()
+
+	video, err := fetchYouTubeVideo(context.Background(), server.Client(), "dQw4w9WgXcQ")
+	if err != nil {
+		t.Fatalf("fetchYouTubeVideo() error = %v", err)
+	}
+	if video.Title != "Go Concurrency" || video.Transcript != "Channels are 'first-class'" {
+		t.Errorf("unexpected video: %+v", video)
+	}
+	if content := video.content(); !strings.Contains(content, "Channel: GopherCon") || !strings.Contains(content, "Description:\nPatterns talk") {
+		t.Errorf("unexpected content: %q", content)
+	}
+}
+
+func TestFetchYouTubeVideoWithoutTranscript(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/watch" {
+			w.Write([]byte(`<script>ytInitialPlayerResponse = {"videoDetails":{"title":"Silent"}};</script>`))
+			return
+		}
+		// timedtext answers an empty body for videos without captions
+	}))
+	defer server.Close()
+
+	youtubeBaseURL = server.URL
+	defer func() { youtubeBaseURL = "https://www.youtube.com" }()
+
+	if _, err := fetchYouTubeVideo(context.Background(), server.Client(), "dQw4w9WgXcQ"); err != errNoTranscript {
+		t.Errorf("fetchYouTubeVideo() error = %v, want errNoTranscript", err)
+	}
+}